@@ -9,15 +9,23 @@ package advisor
 import (
 	"testing"
 
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/database/memory"
 	"github.com/blicero/badnews/model"
 )
 
 var ad *Advisor
 
 func TestInitAdvisor(t *testing.T) {
-	var err error
+	var (
+		err error
+		db  database.Store
+	)
 
-	if ad, err = NewAdvisor(); err != nil {
+	if db, err = memory.New().Open(); err != nil {
+		t.Fatalf("Cannot open in-memory Store: %s",
+			err.Error())
+	} else if ad, err = NewAdvisor(db); err != nil {
 		ad = nil
 		t.Fatalf("Cannot create new Advisor: %s",
 			err.Error())
@@ -10,6 +10,7 @@ package advisor
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"path/filepath"
 	"runtime"
@@ -44,6 +45,8 @@ func backOff() {
 var (
 	cache    cacheme.Backend
 	openLock sync.Mutex
+	advisors []*Advisor
+	listLock sync.Mutex
 )
 
 func getCache() (cacheme.Backend, error) {
@@ -61,6 +64,29 @@ func getCache() (cacheme.Backend, error) {
 	return cache, nil
 } // func getCache() (cachego.Cache, error)
 
+// Evict removes the cached tag suggestions for the given Items, e.g. because
+// the Items have been deleted. It works without loading the classifier.
+func Evict(items ...*model.Item) error {
+	var (
+		err error
+		c   cacheme.Backend
+	)
+
+	if c, err = getCache(); err != nil {
+		return err
+	}
+
+	for _, i := range items {
+		if err = c.Delete(i.IDString()); err != nil {
+			return fmt.Errorf("Cannot remove Item %d from cache: %w",
+				i.ID,
+				err)
+		}
+	}
+
+	return nil
+} // func Evict(items ...*model.Item) error
+
 // SuggestedTag is a suggestion to attach a specific Tag to a specific Item.
 type SuggestedTag struct {
 	model.Tag
@@ -69,18 +95,21 @@ type SuggestedTag struct {
 
 // Advisor can suggest Tags for News Items.
 type Advisor struct {
-	db     *database.Database
+	db     database.Store
 	log    *log.Logger
 	shield map[string]shield.Shield
 	tags   map[string]*model.Tag
 	cache  cacheme.Backend
+	lock   sync.RWMutex
 }
 
-// NewAdvisor returns a new Advisor, but it does not train it, yet.
-func NewAdvisor() (*Advisor, error) {
+// NewAdvisor returns a new Advisor that works on the given Store, but it does
+// not train it, yet. The caller remains responsible for closing the Store.
+func NewAdvisor(db database.Store) (*Advisor, error) {
 	var (
 		err error
 		adv = &Advisor{
+			db: db,
 			shield: map[string]shield.Shield{
 				"de": shield.New(
 					shield.NewGermanTokenizer(),
@@ -103,10 +132,6 @@ func NewAdvisor() (*Advisor, error) {
 
 	if adv.log, err = common.GetLogger(logdomain.Advisor); err != nil {
 		return nil, err
-	} else if adv.db, err = database.Open(common.Path(path.Database)); err != nil {
-		adv.log.Printf("[ERROR] Cannot open database: %s\n",
-			err.Error())
-		return nil, err
 	} else if err = adv.loadTags(); err != nil {
 		return nil, err
 	} else if adv.cache, err = getCache(); err != nil {
@@ -116,8 +141,35 @@ func NewAdvisor() (*Advisor, error) {
 		return nil, err
 	}
 
+	listLock.Lock()
+	advisors = append(advisors, adv)
+	listLock.Unlock()
+
 	return adv, nil
-} // func NewAdvisor() (*Advisor, error)
+} // func NewAdvisor(db database.Store) (*Advisor, error)
+
+// Freeze closes the LevelDB stores that hold the training data of all
+// Advisors and blocks them until the returned function is called, so the
+// stores can be copied consistently while the application is running. New
+// Advisors cannot be created while the stores are frozen. The stores are
+// reopened the next time they are used.
+func Freeze() func() {
+	listLock.Lock()
+
+	for _, adv := range advisors {
+		adv.lock.Lock()
+		for _, s := range adv.shield {
+			s.Destroy()
+		}
+	}
+
+	return func() {
+		for _, adv := range advisors {
+			adv.lock.Unlock()
+		}
+		listLock.Unlock()
+	}
+} // func Freeze() func()
 
 func (adv *Advisor) loadTags() error {
 	var (
@@ -141,7 +193,8 @@ func (adv *Advisor) loadTags() error {
 } // func (adv *advisor) loadTags() error
 
 // Train trains the Advisor based on the Tags that have been attached to
-// Items previously.
+// Items previously. Tags attached automatically from the publishers'
+// categories are left out, we only learn from the user's own choices.
 func (adv *Advisor) Train() error {
 	var (
 		err   error
@@ -149,6 +202,9 @@ func (adv *Advisor) Train() error {
 		items []*model.Item
 	)
 
+	adv.lock.RLock()
+	defer adv.lock.RUnlock()
+
 	for k, v := range adv.shield {
 		adv.log.Printf("[DEBUG] Reset Shield instance for %s\n",
 			k)
@@ -167,7 +223,7 @@ func (adv *Advisor) Train() error {
 	}
 
 	for _, t := range tags {
-		if items, err = adv.db.TagLinkGetByTag(t); err != nil {
+		if items, err = adv.db.TagLinkGetByTagManual(t); err != nil {
 			adv.log.Printf("[ERROR] Failed to load Items for Tag %s: %s",
 				t.Name,
 				err.Error())
@@ -207,6 +263,9 @@ func (adv *Advisor) Learn(t *model.Tag, i *model.Item) error {
 		s         shield.Shield
 	)
 
+	adv.lock.RLock()
+	defer adv.lock.RUnlock()
+
 	lng, body = adv.getLanguage(i)
 
 	if s = adv.shield[lng]; s == nil {
@@ -241,6 +300,9 @@ func (adv *Advisor) Unlearn(t *model.Tag, i *model.Item) error {
 		s         shield.Shield
 	)
 
+	adv.lock.RLock()
+	defer adv.lock.RUnlock()
+
 	lng, body = adv.getLanguage(i)
 
 	if s = adv.shield[lng]; s == nil {
@@ -279,6 +341,9 @@ func (adv *Advisor) Suggest(item *model.Item, n int) []SuggestedTag {
 		found                         bool
 	)
 
+	adv.lock.RLock()
+	defer adv.lock.RUnlock()
+
 	idstr = item.IDString()
 	if serialized, found, _, err = adv.cache.Lookup(idstr); err != nil {
 		adv.log.Printf("[ERROR] Error looking up Item %d in advice cache: %s\n",
@@ -0,0 +1,331 @@
+// /home/krylon/go/src/github.com/blicero/badnews/backup/01_backup_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 01:12:44 krylon>
+
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/common"
+	"github.com/blicero/badnews/common/path"
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/judge"
+	"github.com/blicero/badnews/model"
+)
+
+var archive string
+
+func purl(s string) *url.URL {
+	var u, _ = url.Parse(s)
+	return u
+} // func purl(s string) *url.URL
+
+func addFeed(title string) error {
+	var (
+		err error
+		db  *database.Database
+		f   = &model.Feed{
+			Title:          title,
+			URL:            purl("https://" + title + ".example.com/feed.rss"),
+			Homepage:       purl("https://" + title + ".example.com/"),
+			UpdateInterval: time.Hour,
+			Active:         true,
+		}
+	)
+
+	if db, err = database.Open(common.Path(path.Database)); err != nil {
+		return err
+	}
+
+	defer db.Close() // nolint: errcheck
+
+	return db.FeedAdd(f)
+} // func addFeed(title string) error
+
+func feedCnt() (int, error) {
+	var (
+		err   error
+		db    *database.Database
+		feeds []model.Feed
+	)
+
+	if db, err = database.Open(common.Path(path.Database)); err != nil {
+		return 0, err
+	}
+
+	defer db.Close() // nolint: errcheck
+
+	if feeds, err = db.FeedGetAll(); err != nil {
+		return 0, err
+	}
+
+	return len(feeds), nil
+} // func feedCnt() (int, error)
+
+func TestSave(t *testing.T) {
+	var (
+		err  error
+		m    *Manifest
+		jdb  database.Store
+		jdg  *judge.Judge
+		item = &model.Item{
+			ID:          1,
+			Headline:    "Backups are important",
+			Description: "Nobody wants backups, everybody wants restores.",
+			Rating:      1,
+		}
+	)
+
+	if err = addFeed("saved"); err != nil {
+		t.Fatalf("Cannot add Feed: %s", err.Error())
+	} else if jdb, err = database.OpenDefault(); err != nil {
+		t.Fatalf("Cannot open database for Judge: %s", err.Error())
+	} else if jdg, err = judge.New(jdb); err != nil {
+		t.Fatalf("Cannot create Judge: %s", err.Error())
+	} else if err = jdg.Learn(item); err != nil {
+		t.Fatalf("Cannot train Judge: %s", err.Error())
+	}
+
+	// Otherwise, Restore would find the database in use.
+	defer jdb.Close() // nolint: errcheck
+
+	// The Judge's store is open now, so Save has to freeze it.
+	// The archive must outlive the base directory, which TestRestore
+	// replaces.
+	archive = common.Path(path.Base) + ".tar.gz"
+
+	if m, err = Save(archive); err != nil {
+		archive = ""
+		t.Fatalf("Failed to save backup: %s", err.Error())
+	} else if m.Schema != database.SchemaVersion() {
+		t.Errorf("Unexpected schema version in Manifest: %d (expected %d)",
+			m.Schema,
+			database.SchemaVersion())
+	} else if _, err = jdg.Rate(item); err != nil {
+		t.Errorf("Judge does not work after backup: %s", err.Error())
+	}
+
+	var names = make(map[string]bool, len(m.Files))
+	for _, f := range m.Files {
+		names[f.Name] = true
+	}
+
+	for _, n := range []string{
+		filepath.Base(common.Path(path.Database)),
+		filepath.Base(common.Path(path.Judge)) + "/en/CURRENT",
+	} {
+		if !names[n] {
+			t.Errorf("%s is missing from backup", n)
+		}
+	}
+
+	if _, err = Verify(archive); err != nil {
+		t.Errorf("Failed to verify backup: %s", err.Error())
+	}
+} // func TestSave(t *testing.T)
+
+func TestVerifyTampered(t *testing.T) {
+	if archive == "" {
+		t.SkipNow()
+	}
+
+	var (
+		err      error
+		in, out  *os.File
+		gin      *gzip.Reader
+		tr       *tar.Reader
+		tampered = filepath.Join(t.TempDir(), "tampered.tar.gz")
+	)
+
+	if in, err = os.Open(archive); err != nil {
+		t.Fatalf("Cannot open %s: %s", archive, err.Error())
+	}
+
+	defer in.Close() // nolint: errcheck
+
+	if out, err = os.Create(tampered); err != nil {
+		t.Fatalf("Cannot create %s: %s", tampered, err.Error())
+	} else if gin, err = gzip.NewReader(in); err != nil {
+		t.Fatalf("Cannot read %s: %s", archive, err.Error())
+	}
+
+	defer out.Close() // nolint: errcheck
+
+	var (
+		gout = gzip.NewWriter(out)
+		tw   = tar.NewWriter(gout)
+	)
+
+	tr = tar.NewReader(gin)
+
+	// Flip one byte in the database and copy everything else.
+	for {
+		var (
+			hdr *tar.Header
+			buf []byte
+		)
+
+		if hdr, err = tr.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Cannot read %s: %s", archive, err.Error())
+		} else if buf, err = io.ReadAll(tr); err != nil {
+			t.Fatalf("Cannot read %s from %s: %s", hdr.Name, archive, err.Error())
+		} else if hdr.Name == filepath.Base(common.Path(path.Database)) {
+			buf[len(buf)/2] ^= 0xff
+		}
+
+		if err = tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("Cannot write header for %s: %s", hdr.Name, err.Error())
+		} else if _, err = tw.Write(buf); err != nil {
+			t.Fatalf("Cannot write %s: %s", hdr.Name, err.Error())
+		}
+	}
+
+	if err = tw.Close(); err != nil {
+		t.Fatalf("Cannot close tar writer: %s", err.Error())
+	} else if err = gout.Close(); err != nil {
+		t.Fatalf("Cannot close gzip writer: %s", err.Error())
+	} else if _, err = Verify(tampered); err == nil {
+		t.Error("Verify did not notice the archive has been tampered with")
+	} else if !errors.Is(err, ErrInvalidArchive) {
+		t.Errorf("Unexpected error from Verify: %s", err.Error())
+	}
+} // func TestVerifyTampered(t *testing.T)
+
+func TestRestore(t *testing.T) {
+	if archive == "" {
+		t.SkipNow()
+	}
+
+	var (
+		err     error
+		old     string
+		cnt     int
+		exists  bool
+		thaw    func()
+		before  int
+		db      *database.Database
+		logPath = common.Path(path.Log)
+	)
+
+	if before, err = feedCnt(); err != nil {
+		t.Fatalf("Cannot count Feeds: %s", err.Error())
+	} else if err = addFeed("lost"); err != nil {
+		t.Fatalf("Cannot add Feed: %s", err.Error())
+	}
+
+	// The Judge from TestSave still has its store open, which is what
+	// we would see if the application was still running.
+	if _, err = Restore(archive); err == nil {
+		t.Fatal("Restore did not notice the Judge's store is in use")
+	}
+
+	thaw = judge.Freeze()
+
+	if db, err = database.Open(common.Path(path.Database)); err != nil {
+		thaw()
+		t.Fatalf("Cannot open database: %s", err.Error())
+	} else if _, err = Restore(archive); !errors.Is(err, database.ErrInUse) {
+		t.Errorf("Restore did not notice the database is in use: %v", err)
+	}
+
+	db.Close() // nolint: errcheck
+
+	old, err = Restore(archive)
+	thaw()
+
+	if err != nil {
+		t.Fatalf("Failed to restore backup: %s", err.Error())
+	} else if _, err = os.Stat(filepath.Join(old, filepath.Base(common.Path(path.Database)))); err != nil {
+		t.Errorf("Previous database was not kept: %s", err.Error())
+	} else if _, err = os.Stat(logPath); err != nil {
+		t.Errorf("Log file was not moved to restored base directory: %s", err.Error())
+	} else if cnt, err = feedCnt(); err != nil {
+		t.Fatalf("Cannot count Feeds: %s", err.Error())
+	} else if cnt != before {
+		t.Errorf("Expected %d Feeds after restore, got %d", before, cnt)
+	}
+
+	for _, p := range []path.Path{path.SessionStore, path.Judge, path.Advisor} {
+		if exists, err = dirExists(common.Path(p)); err != nil {
+			t.Errorf("Cannot stat %s: %s", common.Path(p), err.Error())
+		} else if !exists {
+			t.Errorf("%s was not created by Restore", common.Path(p))
+		}
+	}
+
+	os.RemoveAll(old)  // nolint: errcheck
+	os.Remove(archive) // nolint: errcheck
+} // func TestRestore(t *testing.T)
+
+func dirExists(p string) (bool, error) {
+	var (
+		err  error
+		info os.FileInfo
+	)
+
+	if info, err = os.Stat(p); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return info.IsDir(), nil
+} // func dirExists(p string) (bool, error)
+
+func TestSchedulerPrune(t *testing.T) {
+	var (
+		err   error
+		s     *Scheduler
+		list  []string
+		dir   = t.TempDir()
+		stale = []string{
+			"badnews-20010101_000000.tar.gz",
+			"badnews-20020101_000000.tar.gz",
+		}
+	)
+
+	for _, name := range stale {
+		var (
+			p     = filepath.Join(dir, name)
+			stamp = time.Now().Add(-time.Hour * 24)
+		)
+
+		if err = os.WriteFile(p, nil, 0600); err != nil {
+			t.Fatalf("Cannot create %s: %s", name, err.Error())
+		} else if err = os.Chtimes(p, stamp, stamp); err != nil {
+			t.Fatalf("Cannot set timestamp of %s: %s", name, err.Error())
+		}
+	}
+
+	if s, err = Create(dir, 2, time.Hour); err != nil {
+		t.Fatalf("Cannot create Scheduler: %s", err.Error())
+	} else if s.due() != 0 {
+		t.Errorf("Backup should be due immediately, not in %s", s.due())
+	}
+
+	if _, err = s.Backup(); err != nil {
+		t.Fatalf("Scheduled backup failed: %s", err.Error())
+	} else if list, err = s.archives(); err != nil {
+		t.Fatalf("Cannot list archives: %s", err.Error())
+	} else if len(list) != 2 {
+		t.Errorf("Expected 2 archives, found %d", len(list))
+	} else if filepath.Base(list[0]) != stale[1] {
+		t.Errorf("Expected the oldest archive to be removed, got %v", list)
+	} else if s.due() < time.Minute {
+		t.Errorf("Next backup should not be due for an hour, but is due in %s", s.due())
+	}
+} // func TestSchedulerPrune(t *testing.T)
@@ -0,0 +1,651 @@
+// /home/krylon/go/src/github.com/blicero/badnews/backup/backup.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 23:52:40 krylon>
+
+// Package backup saves the state of the application to a single compressed
+// archive and restores it from one. An archive contains the database, the
+// training data of the Judge and the Advisor, the Blacklist, the web
+// sessions and the secret key, plus a manifest with the SHA-256 checksum of
+// every file.
+// The caches of ratings and Tag suggestions are left out, they are rebuilt
+// on demand. So are the log file, downloaded Enclosures and Feed icons.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/blicero/badnews/advisor"
+	"github.com/blicero/badnews/blacklist"
+	"github.com/blicero/badnews/common"
+	"github.com/blicero/badnews/common/path"
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/judge"
+	"github.com/blicero/badnews/logdomain"
+	"github.com/blicero/krylib"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+)
+
+const (
+	// FormatVersion is the version of the archive format.
+	FormatVersion = 1
+	manifestName  = "MANIFEST.json"
+	stampFormat   = "20060102_150405"
+	archiveSuffix = ".tar.gz"
+)
+
+// files are the parts of the state that are plain files or directories.
+// Missing ones are skipped.
+var files = []path.Path{
+	path.AgentConfig,
+	path.SessionStore,
+	path.Cookiejar,
+	path.SecretKey,
+}
+
+// stores are the directories that hold LevelDB stores.
+var stores = []path.Path{
+	path.Judge,
+	path.Advisor,
+}
+
+// kept are the parts of the base directory that are not part of an archive,
+// but survive a Restore.
+var kept = []path.Path{
+	path.Log,
+	path.Downloads,
+	path.Icons,
+}
+
+// File describes a single file in a backup archive.
+type File struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	Sum  string `json:"sha256"`
+}
+
+// Manifest describes the contents of a backup archive. It is always the
+// first entry of the archive.
+type Manifest struct {
+	Version    int       `json:"version"`
+	AppVersion string    `json:"app_version"`
+	Timestamp  time.Time `json:"timestamp"`
+	Schema     int       `json:"schema"`
+	Files      []File    `json:"files"`
+}
+
+func (m *Manifest) String() string {
+	var size int64
+
+	for _, f := range m.Files {
+		size += f.Size
+	}
+
+	return fmt.Sprintf("Backup from %s (%s %s, schema version %d): %d file(s), %d bytes",
+		m.Timestamp.Format(common.TimestampFormat),
+		common.AppName,
+		m.AppVersion,
+		m.Schema,
+		len(m.Files),
+		size)
+} // func (m *Manifest) String() string
+
+// Save writes a backup archive to the given path, which must not exist yet.
+// It can be used while the application is running, the database is copied
+// using SQLite's online backup API, and the LevelDB stores of the Judge and
+// the Advisor are frozen while they are copied.
+func Save(archive string) (*Manifest, error) {
+	var (
+		err error
+		l   *log.Logger
+	)
+
+	if l, err = common.GetLogger(logdomain.Backup); err != nil {
+		return nil, err
+	}
+
+	return save(l, archive)
+} // func Save(archive string) (*Manifest, error)
+
+func save(l *log.Logger, archive string) (*Manifest, error) {
+	var (
+		err    error
+		exists bool
+		stage  string
+		m      *Manifest
+	)
+
+	if exists, err = krylib.Fexists(archive); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, fmt.Errorf("Cannot save backup to %s: file already exists", archive)
+	} else if stage, err = os.MkdirTemp(filepath.Dir(archive), ".backup-"); err != nil {
+		l.Printf("[ERROR] Cannot create staging directory for backup: %s\n",
+			err.Error())
+		return nil, err
+	}
+
+	defer os.RemoveAll(stage) // nolint: errcheck
+
+	l.Printf("[INFO] Save backup of %s to %s\n",
+		common.Path(path.Base),
+		archive)
+
+	if err = stageDatabase(stage); err != nil {
+		l.Printf("[ERROR] Cannot copy database: %s\n",
+			err.Error())
+		return nil, err
+	} else if err = stageStores(stage); err != nil {
+		l.Printf("[ERROR] Cannot copy training data: %s\n",
+			err.Error())
+		return nil, err
+	} else if err = stageBlacklist(stage); err != nil {
+		l.Printf("[ERROR] Cannot copy Blacklist: %s\n",
+			err.Error())
+		return nil, err
+	}
+
+	for _, p := range files {
+		if err = copyTree(common.Path(p), rebase(stage, p)); err != nil {
+			l.Printf("[ERROR] Cannot copy %s: %s\n",
+				common.Path(p),
+				err.Error())
+			return nil, err
+		}
+	}
+
+	if m, err = writeArchive(stage, archive); err != nil {
+		l.Printf("[ERROR] Cannot write archive %s: %s\n",
+			archive,
+			err.Error())
+		return nil, err
+	}
+
+	l.Printf("[INFO] %s\n", m)
+
+	return m, nil
+} // func save(l *log.Logger, archive string) (*Manifest, error)
+
+// rebase returns the path p would have if the base directory was dir.
+func rebase(dir string, p path.Path) string {
+	return filepath.Join(dir, filepath.Base(common.Path(p)))
+} // func rebase(dir string, p path.Path) string
+
+func stageDatabase(stage string) error {
+	var (
+		err error
+		db  *database.Database
+	)
+
+	if db, err = database.Open(common.Path(path.Database)); err != nil {
+		return err
+	}
+
+	defer db.Close() // nolint: errcheck
+
+	return db.Backup(rebase(stage, path.Database))
+} // func stageDatabase(stage string) error
+
+// stageStores copies the LevelDB stores of the Judge and the Advisor. The
+// stores of this process are frozen while we copy them, and we hold
+// LevelDB's lock on each store, so another process using them makes the
+// backup fail rather than producing an inconsistent copy.
+func stageStores(stage string) error {
+	var err error
+
+	defer judge.Freeze()()
+	defer advisor.Freeze()()
+
+	for _, p := range stores {
+		var dirs []fs.DirEntry
+
+		if dirs, err = os.ReadDir(common.Path(p)); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		for _, d := range dirs {
+			var (
+				unlock func()
+				src    = filepath.Join(common.Path(p), d.Name())
+				dst    = filepath.Join(rebase(stage, p), d.Name())
+			)
+
+			if !d.IsDir() {
+				continue
+			} else if unlock, err = lockStore(src); err != nil {
+				return err
+			}
+
+			err = copyTree(src, dst)
+			unlock()
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+} // func stageStores(stage string) error
+
+// lockStore acquires LevelDB's lock on the store in the given directory and
+// returns a function to release it.
+func lockStore(dir string) (func(), error) {
+	var (
+		err    error
+		exists bool
+		stor   storage.Storage
+	)
+
+	if exists, err = krylib.Fexists(filepath.Join(dir, "LOCK")); err != nil {
+		return nil, err
+	} else if !exists {
+		return func() {}, nil
+	} else if stor, err = storage.OpenFile(dir, true); err != nil {
+		return nil, fmt.Errorf("LevelDB store %s is in use by another process: %w",
+			dir,
+			err)
+	}
+
+	return func() { stor.Close() }, nil // nolint: errcheck
+} // func lockStore(dir string) (func(), error)
+
+// stageBlacklist saves pending changes to the Blacklist before copying it,
+// so the copy is up to date.
+func stageBlacklist(stage string) error {
+	var (
+		err error
+		bl  *blacklist.Blacklist
+	)
+
+	if bl, err = blacklist.NewFromFile(common.Path(path.Blacklist)); err != nil {
+		return err
+	} else if bl.Changed() {
+		if err = bl.Dump(common.Path(path.Blacklist)); err != nil {
+			return err
+		}
+	}
+
+	return copyTree(common.Path(path.Blacklist), rebase(stage, path.Blacklist))
+} // func stageBlacklist(stage string) error
+
+// copyTree copies the file or directory src to dst. If src does not exist,
+// copyTree does nothing.
+func copyTree(src, dst string) error {
+	var (
+		err  error
+		info fs.FileInfo
+	)
+
+	if info, err = os.Stat(src); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	} else if !info.IsDir() {
+		return copyFile(src, dst, info.Mode())
+	}
+
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		var (
+			rel  string
+			info fs.FileInfo
+		)
+
+		if err != nil {
+			return err
+		} else if rel, err = filepath.Rel(src, p); err != nil {
+			return err
+		} else if info, err = d.Info(); err != nil {
+			return err
+		} else if d.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), info.Mode().Perm())
+		} else if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		return copyFile(p, filepath.Join(dst, rel), info.Mode())
+	})
+} // func copyTree(src, dst string) error
+
+func copyFile(src, dst string, mode fs.FileMode) error {
+	var (
+		err     error
+		in, out *os.File
+	)
+
+	if in, err = os.Open(src); err != nil {
+		return err
+	}
+
+	defer in.Close() // nolint: errcheck
+
+	if out, err = os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode.Perm()); err != nil {
+		return err
+	} else if _, err = io.Copy(out, in); err != nil {
+		out.Close() // nolint: errcheck
+		return err
+	}
+
+	return out.Close()
+} // func copyFile(src, dst string, mode fs.FileMode) error
+
+// writeArchive writes the contents of the staging directory to a gzipped tar
+// archive, preceded by the Manifest. The archive is written to a temporary
+// file first, so an archive that exists under its final name is complete.
+func writeArchive(stage, archive string) (*Manifest, error) {
+	var (
+		err   error
+		buf   []byte
+		fh    *os.File
+		gz    *gzip.Writer
+		tw    *tar.Writer
+		names []string
+		tmp   = archive + ".tmp"
+		m     = &Manifest{
+			Version:    FormatVersion,
+			AppVersion: common.Version,
+			Timestamp:  time.Now(),
+			Schema:     database.SchemaVersion(),
+		}
+	)
+
+	if err = filepath.WalkDir(stage, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			names = append(names, p)
+		}
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(names)
+
+	for _, p := range names {
+		var f File
+
+		if f, err = checksum(stage, p); err != nil {
+			return nil, err
+		}
+
+		m.Files = append(m.Files, f)
+	}
+
+	if buf, err = json.MarshalIndent(m, "", "  "); err != nil {
+		return nil, err
+	} else if fh, err = os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600); err != nil {
+		return nil, err
+	}
+
+	defer os.Remove(tmp) // nolint: errcheck
+	defer fh.Close()     // nolint: errcheck
+
+	gz = gzip.NewWriter(fh)
+	tw = tar.NewWriter(gz)
+
+	if err = tw.WriteHeader(&tar.Header{
+		Name:    manifestName,
+		Mode:    0600,
+		Size:    int64(len(buf)),
+		ModTime: m.Timestamp,
+	}); err != nil {
+		return nil, err
+	} else if _, err = tw.Write(buf); err != nil {
+		return nil, err
+	}
+
+	for idx, f := range m.Files {
+		if err = addFile(tw, names[idx], &f); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tw.Close(); err != nil {
+		return nil, err
+	} else if err = gz.Close(); err != nil {
+		return nil, err
+	} else if err = fh.Sync(); err != nil {
+		return nil, err
+	} else if err = fh.Close(); err != nil {
+		return nil, err
+	} else if err = os.Rename(tmp, archive); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+} // func writeArchive(stage, archive string) (*Manifest, error)
+
+// checksum returns the name, relative to dir, the size and the SHA-256
+// checksum of the file at p.
+func checksum(dir, p string) (File, error) {
+	var (
+		err error
+		fh  *os.File
+		f   File
+		h   = sha256.New()
+	)
+
+	if f.Name, err = filepath.Rel(dir, p); err != nil {
+		return f, err
+	} else if fh, err = os.Open(p); err != nil {
+		return f, err
+	}
+
+	defer fh.Close() // nolint: errcheck
+
+	if f.Size, err = io.Copy(h, fh); err != nil {
+		return f, err
+	}
+
+	f.Name = filepath.ToSlash(f.Name)
+	f.Sum = hex.EncodeToString(h.Sum(nil))
+
+	return f, nil
+} // func checksum(dir, p string) (File, error)
+
+func addFile(tw *tar.Writer, p string, f *File) error {
+	var (
+		err  error
+		fh   *os.File
+		info fs.FileInfo
+	)
+
+	if fh, err = os.Open(p); err != nil {
+		return err
+	}
+
+	defer fh.Close() // nolint: errcheck
+
+	if info, err = fh.Stat(); err != nil {
+		return err
+	} else if err = tw.WriteHeader(&tar.Header{
+		Name:    f.Name,
+		Mode:    int64(info.Mode().Perm()),
+		Size:    f.Size,
+		ModTime: info.ModTime(),
+	}); err != nil {
+		return err
+	} else if _, err = io.Copy(tw, fh); err != nil {
+		return err
+	}
+
+	return nil
+} // func addFile(tw *tar.Writer, p string, f *File) error
+
+// Scheduler saves backups periodically and removes old ones.
+type Scheduler struct {
+	active   atomic.Bool
+	log      *log.Logger
+	dir      string
+	keep     int
+	interval time.Duration
+}
+
+// Create returns a Scheduler that saves a backup to dir every interval and
+// keeps the most recent keep archives. If keep is zero or less, it keeps all
+// of them.
+func Create(dir string, keep int, interval time.Duration) (*Scheduler, error) {
+	var (
+		err error
+		s   = &Scheduler{
+			dir:      dir,
+			keep:     keep,
+			interval: interval,
+		}
+	)
+
+	if s.log, err = common.GetLogger(logdomain.Backup); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Failed to create Logger for Backup: %s\n",
+			err.Error())
+		return nil, err
+	} else if interval <= 0 {
+		return nil, fmt.Errorf("Invalid backup interval %s", interval)
+	} else if err = os.MkdirAll(dir, 0700); err != nil {
+		s.log.Printf("[ERROR] Cannot create backup directory %s: %s\n",
+			dir,
+			err.Error())
+		return nil, err
+	}
+
+	return s, nil
+} // func Create(dir string, keep int, interval time.Duration) (*Scheduler, error)
+
+// IsActive returns the Scheduler's active flag.
+func (s *Scheduler) IsActive() bool {
+	return s.active.Load()
+} // func (s *Scheduler) IsActive() bool
+
+// Run saves backups periodically until ctx is cancelled. The first backup is
+// due one interval after the most recent archive in the backup directory.
+func (s *Scheduler) Run(ctx context.Context) {
+	var (
+		err   error
+		timer = time.NewTimer(s.due())
+	)
+
+	defer timer.Stop()
+
+	s.active.Store(true)
+	defer s.active.Store(false)
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.log.Println("[INFO] Backup Scheduler is stopping.")
+			return
+		case <-timer.C:
+		}
+
+		if _, err = s.Backup(); err != nil {
+			s.log.Printf("[ERROR] Scheduled backup failed: %s\n",
+				err.Error())
+		}
+
+		timer.Reset(s.interval)
+	}
+} // func (s *Scheduler) Run(ctx context.Context)
+
+// Backup saves a backup to the backup directory and removes archives beyond
+// the number the Scheduler keeps. It returns the path of the new archive.
+func (s *Scheduler) Backup() (string, error) {
+	var (
+		err     error
+		archive = filepath.Join(
+			s.dir,
+			fmt.Sprintf("%s-%s%s",
+				strings.ToLower(common.AppName),
+				time.Now().Format(stampFormat),
+				archiveSuffix))
+	)
+
+	if _, err = save(s.log, archive); err != nil {
+		return "", err
+	} else if err = s.prune(); err != nil {
+		s.log.Printf("[ERROR] Cannot remove old backups: %s\n",
+			err.Error())
+	}
+
+	return archive, nil
+} // func (s *Scheduler) Backup() (string, error)
+
+// archives returns the paths of the archives in the backup directory,
+// oldest first.
+func (s *Scheduler) archives() ([]string, error) {
+	var (
+		err  error
+		list []string
+	)
+
+	if list, err = filepath.Glob(filepath.Join(
+		s.dir,
+		fmt.Sprintf("%s-*%s", strings.ToLower(common.AppName), archiveSuffix))); err != nil {
+		return nil, err
+	}
+
+	// The names contain the timestamp, so sorting them by name sorts them
+	// by age.
+	sort.Strings(list)
+
+	return list, nil
+} // func (s *Scheduler) archives() ([]string, error)
+
+// due returns how long it is until the next backup is due.
+func (s *Scheduler) due() time.Duration {
+	var (
+		err  error
+		list []string
+		info fs.FileInfo
+	)
+
+	if list, err = s.archives(); err != nil || len(list) == 0 {
+		return 0
+	} else if info, err = os.Stat(list[len(list)-1]); err != nil {
+		return 0
+	}
+
+	return max(time.Until(info.ModTime().Add(s.interval)), 0)
+} // func (s *Scheduler) due() time.Duration
+
+func (s *Scheduler) prune() error {
+	var (
+		err  error
+		list []string
+	)
+
+	if s.keep <= 0 {
+		return nil
+	} else if list, err = s.archives(); err != nil {
+		return err
+	}
+
+	for len(list) > s.keep {
+		s.log.Printf("[INFO] Remove old backup %s\n", list[0])
+		if err = os.Remove(list[0]); err != nil {
+			return err
+		}
+		list = list[1:]
+	}
+
+	return nil
+} // func (s *Scheduler) prune() error
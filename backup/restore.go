@@ -0,0 +1,305 @@
+// /home/krylon/go/src/github.com/blicero/badnews/backup/restore.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 00:37:15 krylon>
+
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/blicero/badnews/common"
+	"github.com/blicero/badnews/common/path"
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/logdomain"
+	"github.com/blicero/krylib"
+)
+
+// maxManifestSize is the largest Manifest we are willing to read.
+const maxManifestSize = 16 * 1024 * 1024
+
+// ErrInvalidArchive indicates that a backup archive is damaged or was not
+// created by this application.
+var ErrInvalidArchive = errors.New("invalid backup archive")
+
+// Verify reads the backup archive at the given path and checks every file
+// in it against the checksums in its Manifest.
+func Verify(archive string) (*Manifest, error) {
+	return readArchive(archive, "")
+} // func Verify(archive string) (*Manifest, error)
+
+// Restore replaces the base directory with the contents of the backup
+// archive at the given path. The application must not be running while
+// Restore does its work, Restore refuses to proceed if the database or the
+// LevelDB stores are in use.
+// The archive is unpacked next to the base directory and checked against
+// its Manifest first, the base directory is only replaced if the archive is
+// valid. The previous base directory is not deleted, but renamed, Restore
+// returns its new path. The log file, downloaded Enclosures and Feed icons
+// are moved over to the restored base directory.
+func Restore(archive string) (string, error) {
+	var (
+		err     error
+		l       *log.Logger
+		m       *Manifest
+		exists  bool
+		unlock  []func()
+		release func()
+		base    = common.Path(path.Base)
+		stamp   = time.Now().Format(stampFormat)
+		stage   = fmt.Sprintf("%s.restore.%s", base, stamp)
+		old     = fmt.Sprintf("%s.%s.old", base, stamp)
+		done    bool
+	)
+
+	if l, err = common.GetLogger(logdomain.Backup); err != nil {
+		return "", err
+	}
+
+	l.Printf("[INFO] Restore %s from %s\n",
+		base,
+		archive)
+
+	defer func() {
+		for _, f := range unlock {
+			f()
+		}
+		if !done {
+			os.RemoveAll(stage) // nolint: errcheck
+		}
+	}()
+
+	// If the database or the LevelDB stores are in use, the application
+	// is probably still running.
+	if release, err = database.Lock(common.Path(path.Database)); err != nil {
+		l.Printf("[ERROR] Cannot restore %s, is %s still running? %s\n",
+			base,
+			common.AppName,
+			err.Error())
+		return "", err
+	}
+
+	unlock = append(unlock, release)
+
+	for _, p := range stores {
+		var dirs []os.DirEntry
+
+		if dirs, err = os.ReadDir(common.Path(p)); err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+
+		for _, d := range dirs {
+			var f func()
+
+			if !d.IsDir() {
+				continue
+			} else if f, err = lockStore(filepath.Join(common.Path(p), d.Name())); err != nil {
+				l.Printf("[ERROR] Cannot restore %s, is %s still running? %s\n",
+					base,
+					common.AppName,
+					err.Error())
+				return "", err
+			}
+
+			unlock = append(unlock, f)
+		}
+	}
+
+	if m, err = readArchive(archive, stage); err != nil {
+		l.Printf("[ERROR] Cannot restore from %s: %s\n",
+			archive,
+			err.Error())
+		return "", err
+	} else if m.Schema > database.SchemaVersion() {
+		l.Printf("[ERROR] %s contains a database with schema version %d, we only know up to %d\n",
+			archive,
+			m.Schema,
+			database.SchemaVersion())
+		return "", database.ErrSchemaTooNew
+	}
+
+	// The archive does not contain empty directories, but the application
+	// expects these to exist.
+	for _, p := range []path.Path{path.SessionStore, path.Judge, path.Advisor} {
+		if err = os.MkdirAll(rebase(stage, p), 0700); err != nil {
+			return "", err
+		}
+	}
+
+	if err = os.Rename(base, old); err != nil {
+		l.Printf("[ERROR] Cannot move %s out of the way: %s\n",
+			base,
+			err.Error())
+		return "", err
+	} else if err = os.Rename(stage, base); err != nil {
+		l.Printf("[ERROR] Cannot move %s to %s: %s\n",
+			stage,
+			base,
+			err.Error())
+		if e2 := os.Rename(old, base); e2 != nil {
+			l.Printf("[CRITICAL] Cannot move %s back to %s: %s\n",
+				old,
+				base,
+				e2.Error())
+		}
+		return "", err
+	}
+
+	done = true
+
+	for _, p := range kept {
+		var src = rebase(old, p)
+
+		if exists, err = krylib.Fexists(src); err != nil || !exists {
+			continue
+		} else if err = os.Rename(src, common.Path(p)); err != nil {
+			l.Printf("[ERROR] Cannot move %s to %s: %s\n",
+				src,
+				common.Path(p),
+				err.Error())
+		}
+	}
+
+	l.Printf("[INFO] Restored %s, the previous state is kept in %s\n",
+		m,
+		old)
+
+	return old, nil
+} // func Restore(archive string) (string, error)
+
+// readArchive reads the backup archive at the given path and checks its
+// contents against the Manifest. If dest is not empty, the files are
+// unpacked to dest, which must not exist yet.
+func readArchive(archive, dest string) (*Manifest, error) {
+	var (
+		err  error
+		fh   *os.File
+		gz   *gzip.Reader
+		tr   *tar.Reader
+		hdr  *tar.Header
+		buf  []byte
+		m    Manifest
+		want map[string]File
+		seen = make(map[string]bool)
+	)
+
+	if fh, err = os.Open(archive); err != nil {
+		return nil, err
+	}
+
+	defer fh.Close() // nolint: errcheck
+
+	if gz, err = gzip.NewReader(fh); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err.Error())
+	}
+
+	tr = tar.NewReader(gz)
+
+	if hdr, err = tr.Next(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err.Error())
+	} else if hdr.Name != manifestName || hdr.Size > maxManifestSize {
+		return nil, fmt.Errorf("%w: archive does not start with a manifest", ErrInvalidArchive)
+	} else if buf, err = io.ReadAll(tr); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err.Error())
+	} else if err = json.Unmarshal(buf, &m); err != nil {
+		return nil, fmt.Errorf("%w: cannot parse manifest: %s", ErrInvalidArchive, err.Error())
+	} else if m.Version > FormatVersion {
+		return nil, fmt.Errorf("%w: unsupported format version %d", ErrInvalidArchive, m.Version)
+	}
+
+	want = make(map[string]File, len(m.Files))
+	for _, f := range m.Files {
+		want[f.Name] = f
+	}
+
+	// The archive contains the secret key, so nobody else gets to look
+	// at it while we unpack it.
+	if dest != "" {
+		if err = os.Mkdir(dest, 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		var (
+			f  File
+			ok bool
+		)
+
+		if hdr, err = tr.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidArchive, err.Error())
+		} else if f, ok = want[hdr.Name]; !ok || seen[hdr.Name] {
+			return nil, fmt.Errorf("%w: unexpected entry %s", ErrInvalidArchive, hdr.Name)
+		} else if hdr.Typeflag != tar.TypeReg || hdr.Size != f.Size {
+			return nil, fmt.Errorf("%w: entry %s does not match manifest", ErrInvalidArchive, hdr.Name)
+		} else if err = extract(tr, hdr, &f, dest); err != nil {
+			return nil, err
+		}
+
+		seen[hdr.Name] = true
+	}
+
+	for name := range want {
+		if !seen[name] {
+			return nil, fmt.Errorf("%w: %s is missing", ErrInvalidArchive, name)
+		}
+	}
+
+	return &m, nil
+} // func readArchive(archive, dest string) (*Manifest, error)
+
+// extract reads a file from the archive, compares it against the Manifest
+// and writes it to dest, unless dest is empty.
+func extract(r io.Reader, hdr *tar.Header, f *File, dest string) error {
+	var (
+		err    error
+		target string
+		out    *os.File
+		w      io.Writer
+		h      = sha256.New()
+	)
+
+	if !filepath.IsLocal(filepath.FromSlash(f.Name)) || strings.Contains(f.Name, "\\") {
+		return fmt.Errorf("%w: invalid file name %s", ErrInvalidArchive, f.Name)
+	}
+
+	w = h
+
+	if dest != "" {
+		target = filepath.Join(dest, filepath.FromSlash(f.Name))
+
+		if err = os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+			return err
+		} else if out, err = os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, os.FileMode(hdr.Mode).Perm()); err != nil {
+			return err
+		}
+
+		defer out.Close() // nolint: errcheck
+		w = io.MultiWriter(h, out)
+	}
+
+	if _, err = io.Copy(w, r); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidArchive, err.Error())
+	} else if hex.EncodeToString(h.Sum(nil)) != f.Sum {
+		return fmt.Errorf("%w: checksum mismatch for %s", ErrInvalidArchive, f.Name)
+	} else if out != nil {
+		return out.Close()
+	}
+
+	return nil
+} // func extract(r io.Reader, hdr *tar.Header, f *File, dest string) error
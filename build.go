@@ -71,7 +71,10 @@ var candidates = map[string][]string{
 	"test": {
 		"common",
 		"database",
+		"opml",
 		"reader",
+		"download",
+		"backup",
 		"web",
 	},
 	"vet": {
@@ -81,10 +84,13 @@ var candidates = map[string][]string{
 		"database/query",
 		"database",
 		"model",
+		"opml",
 		"reader",
+		"download",
 		"web",
 		"judge",
 		"blacklist",
+		"backup",
 	},
 	"lint": {
 		"common/path",
@@ -93,10 +99,13 @@ var candidates = map[string][]string{
 		"database/query",
 		"database",
 		"model",
+		"opml",
 		"reader",
+		"download",
 		"web",
 		"judge",
 		"blacklist",
+		"backup",
 	},
 	"nilaway": {
 		"common/path",
@@ -105,10 +114,13 @@ var candidates = map[string][]string{
 		"database/query",
 		"database",
 		"model",
+		"opml",
 		"reader",
+		"download",
 		"web",
 		"judge",
 		"blacklist",
+		"backup",
 	},
 }
 
@@ -281,7 +293,8 @@ This flag is not case-sensitive.`, strings.Join(orderedSteps, ", ")))
 		var sWorkerCnt = strconv.FormatInt(int64(workerCnt), 10)
 
 		// The -tags flag is required so the build will succeed on Debian.
-		var args = []string{"build", "-v", "-tags", "pango_1_42,gtk_3_22", "-p", sWorkerCnt}
+		// sqlite_fts5 enables the full-text index for Searches.
+		var args = []string{"build", "-v", "-tags", "pango_1_42,gtk_3_22,sqlite_fts5", "-p", sWorkerCnt}
 
 		if raceDetect && ((runtime.GOOS == "linux" || runtime.GOOS == "freebsd") && runtime.GOARCH == "amd64") {
 			dbg.Println("[INFO] Building with race detection enabled.")
@@ -415,9 +428,9 @@ func worker(n int, op string, pkgq <-chan string, errq chan<- error, wg *sync.Wa
 			cmd = exec.Command(lintCommand, pkg)
 		} else if op == "test" {
 			if raceDetect && ((runtime.GOOS == "linux" || runtime.GOOS == "freebsd") && runtime.GOARCH == "amd64") {
-				cmd = exec.Command("go", op, "-v", "-timeout", "30m", "-race", pkg)
+				cmd = exec.Command("go", op, "-v", "-tags", "sqlite_fts5", "-timeout", "30m", "-race", pkg)
 			} else {
-				cmd = exec.Command("go", op, "-v", "-timeout", "30m", pkg)
+				cmd = exec.Command("go", op, "-v", "-tags", "sqlite_fts5", "-timeout", "30m", pkg)
 			}
 		} else if op == "nilaway" {
 			cmd = exec.Command(nilaway, pkg)
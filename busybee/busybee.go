@@ -9,6 +9,7 @@
 package busybee
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -45,11 +46,14 @@ type BusyBee struct {
 	log    *log.Logger
 	adv    *advisor.Advisor
 	jdg    *judge.Judge
+	advDB  database.Store
+	jdgDB  database.Store
 	pool   *database.Pool
 }
 
-// Create instantiates a new BusyBee.
-func Create() (*BusyBee, error) {
+// Create instantiates a new BusyBee. The BusyBee gets its database
+// connections from the given Opener.
+func Create(open database.Opener) (*BusyBee, error) {
 	var (
 		err error
 		bee = new(BusyBee)
@@ -61,35 +65,56 @@ func Create() (*BusyBee, error) {
 			"Failed to create Logger for BusyBee: %s\n",
 			err.Error())
 		return nil, err
-	} else if bee.adv, err = advisor.NewAdvisor(); err != nil {
+	} else if bee.advDB, err = open(); err != nil {
+		bee.log.Printf("[ERROR] Cannot open database for Advisor: %s\n",
+			err.Error())
+		return nil, err
+	} else if bee.adv, err = advisor.NewAdvisor(bee.advDB); err != nil {
 		bee.log.Printf("[ERROR] Failed to create Advisor: %s\n",
 			err.Error())
+		bee.close()
+		return nil, err
+	} else if bee.jdgDB, err = open(); err != nil {
+		bee.log.Printf("[ERROR] Cannot open database for Judge: %s\n",
+			err.Error())
+		bee.close()
 		return nil, err
-	} else if bee.jdg, err = judge.New(); err != nil {
+	} else if bee.jdg, err = judge.New(bee.jdgDB); err != nil {
 		bee.log.Printf("[ERROR] Failed to create Judge: %s\n",
 			err.Error())
+		bee.close()
 		return nil, err
-	} else if bee.pool, err = database.NewPool(4); err != nil {
+	} else if bee.pool, err = database.NewPoolFrom(4, open); err != nil {
 		bee.log.Printf("[ERROR] Failed to create database connection pool: %s\n",
 			err.Error())
+		bee.close()
 		return nil, err
 	}
 
 	return bee, nil
-} // func Create() (*BusyBee, error)
+} // func Create(open database.Opener) (*BusyBee, error)
+
+// close closes all database connections the BusyBee has opened so far.
+func (bee *BusyBee) close() {
+	if bee.pool != nil {
+		bee.pool.Close() // nolint: errcheck
+	}
+
+	for _, db := range []database.Store{bee.advDB, bee.jdgDB} {
+		if db != nil {
+			db.Close() // nolint: errcheck
+		}
+	}
+} // func (bee *BusyBee) close()
 
 // IsActive returns the BusyBee's active flag
 func (bee *BusyBee) IsActive() bool {
 	return bee.active.Load()
 } // func (bee *BusyBee) IsActive() bool
 
-// Stop clears the BusyBee's active flag
-func (bee *BusyBee) Stop() {
-	bee.active.Store(false)
-} // func (bee *BusyBee) Stop()
-
-// Run executes the BusyBee's main loop.
-func (bee *BusyBee) Run() {
+// Run executes the BusyBee's main loop until ctx is cancelled. When it
+// returns, the BusyBee has closed its database connections.
+func (bee *BusyBee) Run(ctx context.Context) {
 	var (
 		err    error
 		ticker *time.Ticker
@@ -99,24 +124,29 @@ func (bee *BusyBee) Run() {
 	defer ticker.Stop()
 
 	bee.active.Store(true)
-
-	for bee.active.Load() {
-		<-ticker.C
-
-		if err = bee.preComputeAdvice(checkPeriod); err != nil {
-			bee.log.Printf("[ERROR] Failed to precompute Advice/Ratings: %s\n",
-				err.Error())
-			continue
+	defer bee.active.Store(false)
+	defer bee.close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			bee.log.Println("[INFO] BusyBee is stopping.")
+			return
+		case <-ticker.C:
+			if err = bee.preComputeAdvice(ctx, checkPeriod); err != nil {
+				bee.log.Printf("[ERROR] Failed to precompute Advice/Ratings: %s\n",
+					err.Error())
+			}
 		}
 	}
-} // func (bee *BusyBee) Run()
+} // func (bee *BusyBee) Run(ctx context.Context)
 
-func (bee *BusyBee) preComputeAdvice(period time.Duration) error {
+func (bee *BusyBee) preComputeAdvice(ctx context.Context, period time.Duration) error {
 	const suggCnt = 10
 	var (
 		err   error
 		items []*model.Item
-		db    *database.Database
+		db    database.Store
 	)
 
 	if period > 0 {
@@ -149,7 +179,7 @@ func (bee *BusyBee) preComputeAdvice(period time.Duration) error {
 	}()
 
 	for _, i := range items {
-		if !bee.active.Load() {
+		if ctx.Err() != nil {
 			bee.log.Println("[TRACE] BusyBee has been stopped, aborting processing.")
 			break
 		}
@@ -184,4 +214,4 @@ func (bee *BusyBee) preComputeAdvice(period time.Duration) error {
 	}
 
 	return nil
-} // func preComputeAdvice(period time.Duration) error
+} // func preComputeAdvice(ctx context.Context, period time.Duration) error
@@ -6,7 +6,10 @@
 
 package common
 
-import "testing"
+import (
+	"net/url"
+	"testing"
+)
 
 func TestFibonacci(t *testing.T) {
 	type testCase struct {
@@ -33,3 +36,69 @@ func TestFibonacci(t *testing.T) {
 		}
 	}
 } // func TestFibonacci(t *testing.T)
+
+func TestCanonicalURL(t *testing.T) {
+	type testCase struct {
+		input    string
+		expected string
+	}
+
+	var tests = []testCase{
+		{"https://www.example.com/news/1.html", "https://www.example.com/news/1.html"},
+		{"http://WWW.Example.COM/news/1.html", "https://www.example.com/news/1.html"},
+		{"https://www.example.com:443/news/1.html#comments", "https://www.example.com/news/1.html"},
+		{"https://www.example.com/news/1.html?utm_source=rss&utm_medium=feed", "https://www.example.com/news/1.html"},
+		{"https://www.example.com/article?id=42&utm_campaign=x&fbclid=abc", "https://www.example.com/article?id=42"},
+		{"https://www.example.com/article?b=2&a=1", "https://www.example.com/article?a=1&b=2"},
+		{"https://www.example.com", "https://www.example.com/"},
+		{"https://www.example.com:8080/x", "https://www.example.com:8080/x"},
+	}
+
+	for _, c := range tests {
+		var (
+			err error
+			u   *url.URL
+			res string
+		)
+
+		if u, err = url.Parse(c.input); err != nil {
+			t.Fatalf("Cannot parse URL %q: %s", c.input, err.Error())
+		} else if res = CanonicalURL(u); res != c.expected {
+			t.Errorf("Unexpected result for CanonicalURL(%q): %q (expected %q)",
+				c.input,
+				res,
+				c.expected)
+		}
+	}
+} // func TestCanonicalURL(t *testing.T)
+
+func TestEncrypt(t *testing.T) {
+	var (
+		err       error
+		encrypted string
+		decrypted string
+		again     string
+		oldBase   = BaseDir
+	)
+
+	BaseDir = t.TempDir()
+	defer func() { BaseDir = oldBase }()
+
+	const plain = "correct horse battery staple"
+
+	if encrypted, err = Encrypt(plain); err != nil {
+		t.Fatalf("Failed to encrypt: %s", err.Error())
+	} else if encrypted == plain {
+		t.Fatal("Encrypted value is the same as the plain text")
+	} else if again, err = Encrypt(plain); err != nil {
+		t.Fatalf("Failed to encrypt: %s", err.Error())
+	} else if again == encrypted {
+		t.Error("Encrypting the same value twice should yield different results")
+	} else if decrypted, err = Decrypt(encrypted); err != nil {
+		t.Fatalf("Failed to decrypt: %s", err.Error())
+	} else if decrypted != plain {
+		t.Errorf("Decrypted value %q does not match %q", decrypted, plain)
+	} else if _, err = Decrypt(encrypted[:len(encrypted)-4] + "AAAA"); err == nil {
+		t.Error("Decrypting a tampered value should fail")
+	}
+} // func TestEncrypt(t *testing.T)
@@ -0,0 +1,69 @@
+// /home/krylon/go/src/github.com/blicero/badnews/common/canonical.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 15:31:09 krylon>
+
+package common
+
+import (
+	"net/url"
+	"strings"
+)
+
+// trackingParams are query parameters that are used to track where a click
+// came from, but do not change the resource a URL points to.
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"igshid":  true,
+	"yclid":   true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+	"wt_mc":   true,
+	"wt.mc":   true,
+}
+
+// CanonicalURL returns a normalized form of the given URL, suitable to tell
+// if two URLs point to the same resource: The scheme is always https, the
+// host is lowercased, default ports, fragments and tracking parameters
+// (utm_* and friends) are removed, and the remaining query parameters are
+// sorted.
+func CanonicalURL(u *url.URL) string {
+	var (
+		c     = *u
+		query = c.Query()
+	)
+
+	c.Scheme = "https"
+	c.Host = strings.ToLower(c.Host)
+	c.Fragment = ""
+	c.RawFragment = ""
+	c.User = nil
+
+	if port := c.Port(); port == "80" || port == "443" {
+		c.Host = c.Hostname()
+	}
+
+	if c.Path == "" {
+		c.Path = "/"
+		c.RawPath = ""
+	}
+
+	for key := range query {
+		var lkey = strings.ToLower(key)
+		if strings.HasPrefix(lkey, "utm_") || trackingParams[lkey] {
+			query.Del(key)
+		}
+	}
+
+	// Encode sorts the parameters by key.
+	c.RawQuery = query.Encode()
+	c.ForceQuery = false
+
+	return c.String()
+} // func CanonicalURL(u *url.URL) string
@@ -138,6 +138,21 @@ func Path(p path.Path) string {
 			BaseDir,
 			"blacklist.json",
 		)
+	case path.Downloads:
+		return filepath.Join(
+			BaseDir,
+			"downloads",
+		)
+	case path.SecretKey:
+		return filepath.Join(
+			BaseDir,
+			"secret.key",
+		)
+	case path.Icons:
+		return filepath.Join(
+			BaseDir,
+			"icons",
+		)
 	default:
 		panic(fmt.Sprintf("Invalid Path value: %s", p))
 	}
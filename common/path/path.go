@@ -22,4 +22,7 @@ const (
 	Advisor
 	AdviceCache
 	Blacklist
+	Downloads
+	SecretKey
+	Icons
 )
@@ -0,0 +1,131 @@
+// /home/krylon/go/src/github.com/blicero/badnews/common/secret.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 17:55:42 krylon>
+
+package common
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/blicero/badnews/common/path"
+)
+
+// Secrets, such as the credentials for Feeds, are encrypted with AES-GCM.
+// The key is generated randomly the first time we need it and stored in the
+// base directory, readable only by the user.
+
+const keySize = 32
+
+var (
+	keyLock sync.Mutex
+	keyPath string
+	key     []byte
+)
+
+func getKey() ([]byte, error) {
+	var (
+		err   error
+		kpath = Path(path.SecretKey)
+		buf   []byte
+	)
+
+	keyLock.Lock()
+	defer keyLock.Unlock()
+
+	if key != nil && keyPath == kpath {
+		return key, nil
+	}
+
+	if buf, err = os.ReadFile(kpath); err == nil {
+		if len(buf) != keySize {
+			return nil, fmt.Errorf("Invalid key in %s: expected %d bytes, got %d",
+				kpath,
+				keySize,
+				len(buf))
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	} else {
+		buf = make([]byte, keySize)
+		if _, err = rand.Read(buf); err != nil {
+			return nil, err
+		} else if err = os.WriteFile(kpath, buf, 0600); err != nil {
+			return nil, fmt.Errorf("Cannot save key to %s: %w", kpath, err)
+		}
+	}
+
+	key, keyPath = buf, kpath
+	return key, nil
+} // func getKey() ([]byte, error)
+
+func getAEAD() (cipher.AEAD, error) {
+	var (
+		err   error
+		k     []byte
+		block cipher.Block
+	)
+
+	if k, err = getKey(); err != nil {
+		return nil, err
+	} else if block, err = aes.NewCipher(k); err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+} // func getAEAD() (cipher.AEAD, error)
+
+// Encrypt encrypts a string and returns the result base64-encoded.
+// The empty string is returned unchanged.
+func Encrypt(plain string) (string, error) {
+	var (
+		err   error
+		aead  cipher.AEAD
+		nonce []byte
+	)
+
+	if plain == "" {
+		return "", nil
+	} else if aead, err = getAEAD(); err != nil {
+		return "", err
+	}
+
+	nonce = make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(plain), nil)), nil
+} // func Encrypt(plain string) (string, error)
+
+// Decrypt reverses Encrypt.
+func Decrypt(encrypted string) (string, error) {
+	var (
+		err   error
+		aead  cipher.AEAD
+		buf   []byte
+		plain []byte
+	)
+
+	if encrypted == "" {
+		return "", nil
+	} else if buf, err = base64.StdEncoding.DecodeString(encrypted); err != nil {
+		return "", err
+	} else if aead, err = getAEAD(); err != nil {
+		return "", err
+	} else if len(buf) < aead.NonceSize() {
+		return "", errors.New("Encrypted value is too short")
+	} else if plain, err = aead.Open(nil, buf[:aead.NonceSize()], buf[aead.NonceSize():], nil); err != nil {
+		return "", err
+	}
+
+	return string(plain), nil
+} // func Decrypt(encrypted string) (string, error)
@@ -46,3 +46,27 @@ func TestDBQueryPrepare(t *testing.T) {
 		}
 	}
 } // func TestDBQueryPrepare(t *testing.T)
+
+func TestDBFailedCommit(t *testing.T) {
+	if db == nil {
+		t.SkipNow()
+	}
+
+	var err error
+
+	if err = db.Begin(); err != nil {
+		t.Fatalf("Cannot start transaction: %s", err.Error())
+	}
+
+	// Finish the transaction behind the Database's back, so Commit fails.
+	db.tx.Rollback() // nolint: errcheck
+
+	if err = db.Commit(); err == nil {
+		t.Fatal("Commit of a finished transaction should fail")
+	} else if err = db.Begin(); err != nil {
+		t.Fatalf("Cannot start transaction after failed Commit: %s",
+			err.Error())
+	} else if err = db.Rollback(); err != nil {
+		t.Errorf("Cannot roll back transaction: %s", err.Error())
+	}
+} // func TestDBFailedCommit(t *testing.T)
@@ -7,6 +7,7 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"testing"
@@ -147,3 +148,150 @@ func TestDBFeedUpdateRefresh(t *testing.T) {
 			len(pending))
 	}
 } // func TestDBFeedUpdateRefresh(t *testing.T)
+
+func TestDBFeedHTTPSettings(t *testing.T) {
+	if db == nil || len(feeds) == 0 {
+		t.SkipNow()
+	}
+
+	var (
+		err     error
+		f       *model.Feed
+		headers = map[string]string{"X-Api-Key": "4711"}
+		auth    = model.Credentials{
+			Kind:     model.AuthBasic,
+			Username: "krylon",
+			Secret:   "hunter2",
+		}
+	)
+
+	if err = db.FeedSetHTTPSettings(&feeds[0], headers, auth); err != nil {
+		t.Fatalf("Failed to set HTTP settings of Feed %s: %s",
+			feeds[0].Title,
+			err.Error())
+	} else if f, err = db.FeedGetByID(feeds[0].ID); err != nil {
+		t.Fatalf("Failed to reload Feed %d: %s",
+			feeds[0].ID,
+			err.Error())
+	} else if f.Headers["X-Api-Key"] != "4711" {
+		t.Errorf("Unexpected headers: %v", f.Headers)
+	} else if f.Auth != auth {
+		t.Errorf("Unexpected credentials: %v (expected %v)", f.Auth, auth)
+	}
+} // func TestDBFeedHTTPSettings(t *testing.T)
+
+func TestDBFeedUpdate(t *testing.T) {
+	if db == nil || len(feeds) == 0 {
+		t.SkipNow()
+	}
+
+	var (
+		err      error
+		f        *model.Feed
+		sub      *model.Subscription
+		hist     []model.FeedMove
+		feed     = &feeds[len(feeds)-1]
+		hub, _   = url.Parse("https://hub.example.org/")
+		addr, _  = url.Parse("https://www.example.org/news/moved.rss")
+		relative = &url.URL{Path: "/feed.rss"}
+	)
+
+	if err = db.FeedUpdateHTTPState(feed, `"abc"`, "Sat, 17 Oct 2026 12:00:00 GMT", 200); err != nil {
+		t.Fatalf("Failed to set HTTP state of Feed %s: %s", feed.Title, err.Error())
+	} else if err = db.WebSubAdd(&model.Subscription{FeedID: feed.ID, Hub: hub, Topic: feed.URL, Secret: "s3cr3t"}); err != nil {
+		t.Fatalf("Failed to add Subscription for Feed %s: %s", feed.Title, err.Error())
+	}
+
+	// Renaming the Feed keeps everything else.
+	if err = db.FeedUpdate(feed, "  Renamed Feed  ", feed.URL, feed.Homepage, time.Hour); err != nil {
+		t.Fatalf("Failed to rename Feed %s: %s", feed.Title, err.Error())
+	} else if f, err = db.FeedGetByID(feed.ID); err != nil {
+		t.Fatalf("Failed to reload Feed %d: %s", feed.ID, err.Error())
+	} else if f.Title != "Renamed Feed" || f.UpdateInterval != time.Hour {
+		t.Errorf("Feed was not updated: %s", f)
+	} else if f.ETag != `"abc"` {
+		t.Errorf("ETag should be kept if the URL does not change, got %q", f.ETag)
+	}
+
+	for _, c := range []struct {
+		title    string
+		addr     *url.URL
+		interval time.Duration
+	}{
+		{"", addr, time.Hour},
+		{"Valid Title", nil, time.Hour},
+		{"Valid Title", relative, time.Hour},
+		{"Valid Title", addr, time.Second},
+	} {
+		if err = db.FeedUpdate(feed, c.title, c.addr, feed.Homepage, c.interval); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("Expected ErrInvalidValue for %q / %v / %s, got %v",
+				c.title,
+				c.addr,
+				c.interval,
+				err)
+		}
+	}
+
+	// Changing the URL resets what we know about the Feed.
+	if err = db.FeedUpdate(feed, feed.Title, addr, feed.Homepage, time.Hour); err != nil {
+		t.Fatalf("Failed to change URL of Feed %s: %s", feed.Title, err.Error())
+	} else if f, err = db.FeedGetByID(feed.ID); err != nil {
+		t.Fatalf("Failed to reload Feed %d: %s", feed.ID, err.Error())
+	} else if f.URL.String() != addr.String() {
+		t.Errorf("URL was not updated: %s", f.URL)
+	} else if f.ETag != "" || f.LastModified != "" || f.LastStatus != 0 {
+		t.Errorf("Conditional request state was not reset: %q / %q / %d",
+			f.ETag,
+			f.LastModified,
+			f.LastStatus)
+	} else if !f.IsDue() {
+		t.Error("Feed should be due for a refresh after its URL changed")
+	} else if sub, err = db.WebSubGetByFeed(f); err != nil {
+		t.Fatalf("Failed to load Subscription of Feed %s: %s", f.Title, err.Error())
+	} else if sub != nil {
+		t.Error("Subscription for old URL was not deleted")
+	} else if hist, err = db.FeedHistoryGetByFeed(f); err != nil {
+		t.Fatalf("Failed to load history of Feed %s: %s", f.Title, err.Error())
+	} else if len(hist) != 1 {
+		t.Errorf("Expected 1 history entry, got %d", len(hist))
+	} else if hist[0].NewURL.String() != addr.String() {
+		t.Errorf("Unexpected new URL in history: %s", hist[0].NewURL)
+	}
+} // func TestDBFeedUpdate(t *testing.T)
+
+func TestDBFeedMeta(t *testing.T) {
+	if db == nil || len(feeds) == 0 {
+		t.SkipNow()
+	}
+
+	var (
+		err      error
+		f        *model.Feed
+		image, _ = url.Parse("https://www.example.org/logo.png")
+		checked  = time.Now().Truncate(time.Second)
+		meta     = model.FeedMeta{
+			Description: "News from nowhere",
+			Language:    "de-DE",
+			Image:       image,
+			Generator:   "Hugo",
+		}
+	)
+
+	if err = db.FeedSetMeta(&feeds[0], meta); err != nil {
+		t.Fatalf("Failed to set metadata of Feed %s: %s", feeds[0].Title, err.Error())
+	} else if err = db.FeedSetIcon(&feeds[0], "/tmp/icons/1.png", "image/png", checked); err != nil {
+		t.Fatalf("Failed to set icon of Feed %s: %s", feeds[0].Title, err.Error())
+	} else if f, err = db.FeedGetByID(feeds[0].ID); err != nil {
+		t.Fatalf("Failed to reload Feed %d: %s", feeds[0].ID, err.Error())
+	} else if f.Meta.Description != meta.Description ||
+		f.Meta.Language != meta.Language ||
+		f.Meta.Generator != meta.Generator ||
+		f.Meta.ImageString() != image.String() {
+		t.Errorf("Unexpected metadata: %#v", f.Meta)
+	} else if !f.Meta.HasIcon() || f.Meta.IconType != "image/png" || !f.Meta.IconChecked.Equal(checked) {
+		t.Errorf("Unexpected icon: %q / %q / %s",
+			f.Meta.Icon,
+			f.Meta.IconType,
+			f.Meta.IconChecked)
+	}
+} // func TestDBFeedMeta(t *testing.T)
@@ -65,3 +65,163 @@ func TestItemAdd(t *testing.T) {
 
 	status = true
 } // func TestItemAdd(t *testing.T)
+
+func TestItemContent(t *testing.T) {
+	if db == nil || len(items) == 0 {
+		t.SkipNow()
+	}
+
+	const content = "This is the full text of the article."
+
+	var (
+		err  error
+		item *model.Item
+		txt  string
+	)
+
+	if err = db.ItemContentAdd(items[0], content); err != nil {
+		t.Fatalf("Failed to add content to Item %d: %s",
+			items[0].ID,
+			err.Error())
+	} else if txt, err = db.ItemContentGet(items[0]); err != nil {
+		t.Fatalf("Failed to load content of Item %d: %s",
+			items[0].ID,
+			err.Error())
+	} else if txt != content {
+		t.Errorf("Unexpected content: %q (expected %q)", txt, content)
+	} else if item, err = db.ItemGetByID(items[0].ID); err != nil {
+		t.Fatalf("Failed to load Item %d: %s",
+			items[0].ID,
+			err.Error())
+	} else if item.Content != content {
+		t.Errorf("Item was loaded without its content: %q", item.Content)
+	} else if err = db.ItemContentAdd(items[0], "Updated"); err != nil {
+		t.Fatalf("Failed to replace content of Item %d: %s",
+			items[0].ID,
+			err.Error())
+	} else if txt, err = db.ItemContentGet(items[0]); err != nil {
+		t.Fatalf("Failed to load content of Item %d: %s",
+			items[0].ID,
+			err.Error())
+	} else if txt != "Updated" {
+		t.Errorf("Content was not replaced: %q", txt)
+	}
+} // func TestItemContent(t *testing.T)
+
+func TestItemDuplicates(t *testing.T) {
+	if db == nil || len(feeds) == 0 {
+		t.SkipNow()
+	}
+
+	var (
+		err    error
+		exists bool
+		cnt    int64
+		f      = feeds[0]
+		orig   = &model.Item{
+			FeedID:      f.ID,
+			URL:         purl("https://dup.example.com/story.html"),
+			Timestamp:   time.Now(),
+			Headline:    "Duplicate story",
+			Description: "Bla",
+			GUID:        "urn:story:4711",
+		}
+		dup = &model.Item{
+			FeedID:      f.ID,
+			URL:         purl("http://DUP.example.com/story.html?utm_source=rss#top"),
+			Timestamp:   time.Now(),
+			Headline:    "Duplicate story",
+			Description: "Bla",
+		}
+		byGUID = &model.Item{
+			FeedID:      f.ID,
+			URL:         purl("https://dup.example.com/other.html"),
+			Timestamp:   time.Now(),
+			Headline:    "Duplicate story, moved",
+			Description: "Bla",
+			GUID:        "urn:story:4711",
+		}
+	)
+
+	if err = db.ItemAdd(orig); err != nil {
+		t.Fatalf("Failed to add Item: %s", err.Error())
+	} else if exists, err = db.ItemExists(dup); err != nil {
+		t.Fatalf("Failed to check for Item: %s", err.Error())
+	} else if !exists {
+		t.Error("Item with tracking parameters was not recognized as duplicate")
+	} else if exists, err = db.ItemExists(byGUID); err != nil {
+		t.Fatalf("Failed to check for Item: %s", err.Error())
+	} else if !exists {
+		t.Error("Item with same GUID was not recognized as duplicate")
+	}
+
+	// Simulate a duplicate that was added before we knew better.
+	if err = db.ItemAdd(dup); err != nil {
+		t.Fatalf("Failed to add duplicate Item: %s", err.Error())
+	} else if err = db.ItemRate(dup, 1); err != nil {
+		t.Fatalf("Failed to rate duplicate Item: %s", err.Error())
+	} else if cnt, err = db.ItemMergeDuplicates(); err != nil {
+		t.Fatalf("Failed to merge duplicates: %s", err.Error())
+	} else if cnt != 1 {
+		t.Errorf("Expected 1 duplicate to be removed, not %d", cnt)
+	}
+
+	var item *model.Item
+
+	if item, err = db.ItemGetByID(dup.ID); err != nil {
+		t.Fatalf("Failed to look up Item %d: %s", dup.ID, err.Error())
+	} else if item != nil {
+		t.Errorf("Duplicate Item %d still exists", dup.ID)
+	} else if item, err = db.ItemGetByID(orig.ID); err != nil {
+		t.Fatalf("Failed to look up Item %d: %s", orig.ID, err.Error())
+	} else if item == nil {
+		t.Fatalf("Original Item %d was deleted", orig.ID)
+	} else if item.Rating != 1 {
+		t.Errorf("Rating of duplicate was not transferred: %d", item.Rating)
+	}
+} // func TestItemDuplicates(t *testing.T)
+
+func TestItemRevisions(t *testing.T) {
+	if db == nil || len(feeds) == 0 {
+		t.SkipNow()
+	}
+
+	var (
+		err   error
+		revs  []model.Revision
+		known *model.Item
+		item  *model.Item
+		f     = feeds[0]
+		orig  = &model.Item{
+			FeedID:      f.ID,
+			URL:         purl("https://rev.example.com/story.html"),
+			Timestamp:   time.Now(),
+			Headline:    "Original headline",
+			Description: "Original description",
+		}
+	)
+
+	if err = db.ItemAdd(orig); err != nil {
+		t.Fatalf("Failed to add Item: %s", err.Error())
+	} else if known, err = db.ItemGetByKey(&model.Item{FeedID: f.ID, URL: orig.URL}); err != nil {
+		t.Fatalf("Failed to look up Item by key: %s", err.Error())
+	} else if known == nil || known.ID != orig.ID {
+		t.Fatalf("ItemGetByKey did not find Item %d", orig.ID)
+	} else if err = db.ItemRevisionAdd(known, time.Now()); err != nil {
+		t.Fatalf("Failed to add Revision: %s", err.Error())
+	} else if err = db.ItemUpdate(known, "Corrected headline", "Original description"); err != nil {
+		t.Fatalf("Failed to update Item: %s", err.Error())
+	} else if revs, err = db.ItemRevisionGetByItem(known); err != nil {
+		t.Fatalf("Failed to load Revisions: %s", err.Error())
+	} else if len(revs) != 1 {
+		t.Fatalf("Expected 1 Revision, got %d", len(revs))
+	} else if revs[0].Headline != orig.Headline {
+		t.Errorf("Unexpected Headline in Revision: %q", revs[0].Headline)
+	} else if item, err = db.ItemGetByID(orig.ID); err != nil {
+		t.Fatalf("Failed to look up Item %d: %s", orig.ID, err.Error())
+	} else if item.Headline != "Corrected headline" {
+		t.Errorf("Item was not updated: %q", item.Headline)
+	} else if item.Revisions != 1 {
+		t.Errorf("Unexpected number of Revisions: %d", item.Revisions)
+	}
+} // func TestItemRevisions(t *testing.T)
@@ -7,9 +7,12 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/blicero/badnews/model"
 )
@@ -112,3 +115,193 @@ func TestTagItemCnt(t *testing.T) {
 			len(tags))
 	}
 } // func TestTagItemCnt(t *testing.T)
+
+func TestCategoryMap(t *testing.T) {
+	if db == nil || len(tags) == 0 {
+		t.SkipNow()
+	}
+
+	var (
+		err        error
+		linked     []*model.Tag
+		tagged     []*model.Item
+		mappings   []model.CategoryMapping
+		categories []string
+		loaded     *model.Item
+		f          = &feeds[0]
+		tag        = tags[0]
+		item       = &model.Item{
+			FeedID:      f.ID,
+			URL:         purl("https://feeds.example.com/categories/item001.html"),
+			Timestamp:   time.Now(),
+			Headline:    "Categorized Item",
+			Description: "Bla",
+			Categories:  model.StringList{"Sport", "Politik"},
+			Authors:     model.StringList{"Jane Doe"},
+		}
+		m = &model.CategoryMapping{
+			FeedID:   f.ID,
+			Category: "sport",
+			TagID:    tag.ID,
+		}
+	)
+
+	var isAuto = func() (bool, bool) {
+		if linked, err = db.TagLinkGetByItem(item); err != nil {
+			t.Fatalf("Cannot load Tags of Item %d: %s", item.ID, err.Error())
+		}
+
+		for _, l := range linked {
+			if l.ID == tag.ID {
+				return true, l.Auto
+			}
+		}
+
+		return false, false
+	}
+
+	if err = db.ItemAdd(item); err != nil {
+		t.Fatalf("Cannot add Item: %s", err.Error())
+	} else if loaded, err = db.ItemGetByID(item.ID); err != nil {
+		t.Fatalf("Cannot load Item %d: %s", item.ID, err.Error())
+	} else if !slices.Equal(loaded.Categories, item.Categories) ||
+		!slices.Equal(loaded.Authors, item.Authors) {
+		t.Errorf("Unexpected categories/authors: %v / %v",
+			loaded.Categories,
+			loaded.Authors)
+	} else if categories, err = db.CategoryGetByFeed(f); err != nil {
+		t.Fatalf("Cannot load categories of Feed %d: %s", f.ID, err.Error())
+	} else if !slices.Contains(categories, "Sport") {
+		t.Errorf("Category Sport not found: %v", categories)
+	}
+
+	if err = db.CategoryMapAdd(&model.CategoryMapping{FeedID: f.ID, TagID: tag.ID}); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("Mapping without a category should be rejected, got %v", err)
+	} else if err = db.CategoryMapAdd(m); err != nil {
+		t.Fatalf("Cannot add category mapping: %s", err.Error())
+	} else if m.ID == 0 {
+		t.Error("Category mapping has no ID")
+	}
+
+	// The mapping is applied to Items we already have.
+	if ok, auto := isAuto(); !ok || !auto {
+		t.Errorf("Item should have Tag %s attached automatically (%t, %t)",
+			tag.Name,
+			ok,
+			auto)
+	} else if tagged, err = db.TagLinkGetByTagManual(tag); err != nil {
+		t.Fatalf("Cannot load manually tagged Items: %s", err.Error())
+	} else if slices.ContainsFunc(tagged, func(i *model.Item) bool { return i.ID == item.ID }) {
+		t.Error("Automatically tagged Item should not be among the manual ones")
+	} else if tagged, err = db.TagLinkGetByTag(tag); err != nil {
+		t.Fatalf("Cannot load tagged Items: %s", err.Error())
+	} else if !slices.ContainsFunc(tagged, func(i *model.Item) bool { return i.ID == item.ID }) {
+		t.Error("Automatically tagged Item is missing")
+	}
+
+	// Once the user attaches the Tag, it stays a manual one.
+	if err = db.TagLinkAdd(item, tag); err != nil {
+		t.Fatalf("Cannot attach Tag: %s", err.Error())
+	} else if err = db.TagLinkAddAuto(item, tag); err != nil {
+		t.Fatalf("Cannot attach Tag automatically: %s", err.Error())
+	} else if ok, auto := isAuto(); !ok || auto {
+		t.Errorf("Tag %s should be attached manually (%t, %t)",
+			tag.Name,
+			ok,
+			auto)
+	}
+
+	if mappings, err = db.CategoryMapGetByFeed(f); err != nil {
+		t.Fatalf("Cannot load category mappings: %s", err.Error())
+	} else if len(mappings) != 1 || mappings[0] != *m {
+		t.Errorf("Unexpected category mappings: %#v", mappings)
+	} else if err = db.CategoryMapDelete(m); err != nil {
+		t.Fatalf("Cannot delete category mapping: %s", err.Error())
+	} else if mappings, err = db.CategoryMapGetByFeed(f); err != nil {
+		t.Fatalf("Cannot load category mappings: %s", err.Error())
+	} else if len(mappings) != 0 {
+		t.Errorf("Category mapping was not deleted: %#v", mappings)
+	}
+} // func TestCategoryMap(t *testing.T)
+
+func TestItemPurge(t *testing.T) {
+	if db == nil || len(feeds) == 0 || len(tags) == 0 {
+		t.SkipNow()
+	}
+
+	var (
+		err       error
+		ok        bool
+		purgeable []*model.Item
+		f         = &feeds[len(feeds)-1]
+		now       = time.Now()
+		old       = now.Add(-time.Hour * 24 * 60)
+		mkItem    = func(name string, stamp time.Time) *model.Item {
+			return &model.Item{
+				FeedID:    f.ID,
+				URL:       purl("https://purge.example.com/" + name),
+				Timestamp: stamp,
+				Headline:  name,
+			}
+		}
+		expired = mkItem("expired", old)
+		rated   = mkItem("rated", old)
+		tagged  = mkItem("tagged", old)
+		recent  = mkItem("recent", now)
+	)
+
+	for _, i := range []*model.Item{expired, rated, tagged, recent} {
+		if err = db.ItemAdd(i); err != nil {
+			t.Fatalf("Failed to add Item %s: %s", i.Headline, err.Error())
+		}
+	}
+
+	if err = db.ItemRate(rated, 1); err != nil {
+		t.Fatalf("Failed to rate Item: %s", err.Error())
+	} else if err = db.TagLinkAdd(tagged, tags[0]); err != nil {
+		t.Fatalf("Failed to tag Item: %s", err.Error())
+	} else if purgeable, err = db.ItemGetPurgeable(0, now); err != nil {
+		t.Fatalf("Failed to load purgeable Items: %s", err.Error())
+	} else if len(purgeable) != 0 {
+		t.Fatalf("Expected no purgeable Items without a retention period, got %d",
+			len(purgeable))
+	} else if err = db.FeedSetRetention(f, 30); err != nil {
+		t.Fatalf("Failed to set retention period: %s", err.Error())
+	} else if purgeable, err = db.ItemGetPurgeable(0, now); err != nil {
+		t.Fatalf("Failed to load purgeable Items: %s", err.Error())
+	} else if len(purgeable) != 1 {
+		t.Fatalf("Expected 1 purgeable Item, got %d", len(purgeable))
+	} else if purgeable[0].ID != expired.ID {
+		t.Fatalf("Unexpected purgeable Item %q (%d)",
+			purgeable[0].Headline,
+			purgeable[0].ID)
+	} else if ok, err = db.ItemPurge(rated); err != nil {
+		t.Fatalf("Failed to purge rated Item: %s", err.Error())
+	} else if ok {
+		t.Errorf("Rated Item %d was purged", rated.ID)
+	} else if ok, err = db.ItemPurge(expired); err != nil {
+		t.Fatalf("Failed to purge Item: %s", err.Error())
+	} else if !ok {
+		t.Errorf("Expired Item %d was not purged", expired.ID)
+	}
+
+	var item *model.Item
+
+	if item, err = db.ItemGetByID(expired.ID); err != nil {
+		t.Fatalf("Failed to look up Item %d: %s", expired.ID, err.Error())
+	} else if item != nil {
+		t.Errorf("Purged Item %d still exists", expired.ID)
+	} else if err = db.FeedSetRetention(f, -1); err != nil {
+		t.Fatalf("Failed to set retention period: %s", err.Error())
+	} else if purgeable, err = db.ItemGetPurgeable(1, now); err != nil {
+		t.Fatalf("Failed to load purgeable Items: %s", err.Error())
+	}
+
+	for _, i := range purgeable {
+		if i.FeedID == f.ID {
+			t.Errorf("Item %d of Feed %d is purgeable, but the Feed keeps its Items forever",
+				i.ID,
+				f.ID)
+		}
+	}
+} // func TestItemPurge(t *testing.T)
@@ -0,0 +1,241 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/06_db_migrate_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 18:41:09 krylon>
+
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/blicero/badnews/common"
+	"github.com/blicero/badnews/common/path"
+	"github.com/blicero/badnews/model"
+)
+
+// legacyQueries create the schema as it was before we kept track of schema
+// versions, i.e. version 0.
+var legacyQueries = []string{
+	`
+CREATE TABLE feed (
+    id                  INTEGER PRIMARY KEY,
+    title               TEXT UNIQUE NOT NULL,
+    url                 TEXT UNIQUE NOT NULL,
+    homepage            TEXT NOT NULL,
+    interval            INTEGER NOT NULL DEFAULT 1800,
+    last_refresh        INTEGER NOT NULL DEFAULT 0,
+    active              INTEGER NOT NULL DEFAULT 1,
+    CHECK (interval > 0)
+) STRICT
+`,
+	"CREATE INDEX feed_last_refresh_idx ON feed (last_refresh)",
+	"CREATE INDEX feed_active_idx ON feed (active <> 0)",
+	`
+CREATE TABLE item (
+    id                  INTEGER PRIMARY KEY,
+    feed_id             INTEGER NOT NULL,
+    url                 TEXT UNIQUE NOT NULL,
+    timestamp           INTEGER NOT NULL,
+    headline            TEXT NOT NULL,
+    description         TEXT NOT NULL DEFAULT '',
+    rating              INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (feed_id) REFERENCES feed (id),
+    CHECK (rating IN (-1, 0, 1))
+) STRICT
+`,
+	"CREATE INDEX item_feed_idx ON item (feed_id)",
+	"CREATE INDEX item_time_idx ON item (timestamp)",
+	"CREATE INDEX item_headline_idx ON item (headline)",
+	"CREATE INDEX item_rating_idx ON item (rating)",
+
+	`
+CREATE TABLE tag (
+    id		INTEGER PRIMARY KEY,
+    parent	INTEGER,
+    name	TEXT NOT NULL,
+    FOREIGN KEY (parent) REFERENCES tag (id)
+       ON UPDATE RESTRICT
+       ON DELETE CASCADE,
+    UNIQUE (name, parent),
+    CHECK (name <> ''),
+    CHECK (parent <> id)
+) STRICT`,
+	"CREATE INDEX tag_parent_idx ON tag (parent)",
+
+	`
+CREATE TABLE tag_link (
+    id		INTEGER PRIMARY KEY,
+    tag_id	INTEGER NOT NULL,
+    item_id	INTEGER NOT NULL,
+    FOREIGN KEY (tag_id) REFERENCES tag (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE,
+    FOREIGN KEY (item_id) REFERENCES item (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE,
+    UNIQUE (tag_id, item_id)
+) STRICT
+`,
+	"CREATE INDEX tl_tag_idx ON tag_link (tag_id)",
+	"CREATE INDEX tl_item_idx ON tag_link (item_id)",
+
+	`
+CREATE TABLE search (
+    id			INTEGER PRIMARY KEY,
+    title		TEXT NOT NULL DEFAULT '',
+    time_created	INTEGER NOT NULL,
+    time_started	INTEGER,
+    time_finished	INTEGER,
+    status		INTEGER NOT NULL DEFAULT 0,
+    msg			TEXT NOT NULL DEFAULT '',
+    tags		TEXT NOT NULL DEFAULT '',
+    tags_all		INTEGER NOT NULL DEFAULT 0,
+    filter_by_period	INTEGER NOT NULL DEFAULT 0,
+    filter_period_begin INTEGER NOT NULL DEFAULT 0,
+    filter_period_end	INTEGER NOT NULL DEFAULT 0,
+    query_string	TEXT NOT NULL,
+    regex		INTEGER NOT NULL DEFAULT 0,
+    results		TEXT,
+    CHECK (time_started IS NULL OR time_started >= time_created),
+    CHECK (time_finished IS NULL OR (time_started IS NOT NULL AND time_finished >= time_started)),
+    CHECK ((filter_by_period = 0 AND filter_period_begin = 0 AND filter_period_end = 0) OR
+           (filter_period_begin > 0 AND filter_period_end > 0 AND filter_period_begin < filter_period_end))
+) STRICT
+`,
+	"CREATE INDEX search_active_idx ON search (time_started IS NOT NULL, time_finished IS NULL)",
+	"CREATE INDEX search_status_idx ON search (status)",
+	"CREATE INDEX search_ctime_idx ON search (time_created)",
+}
+
+// schemaOf returns the columns of all tables and the names of all indices in
+// the database.
+func schemaOf(t *testing.T, sdb *sql.DB) map[string][]string {
+	var (
+		err    error
+		rows   *sql.Rows
+		schema = make(map[string][]string)
+	)
+
+	if rows, err = sdb.Query(`
+SELECT m.type, m.name, COALESCE(c.name, '')
+FROM sqlite_master m
+LEFT OUTER JOIN pragma_table_info(m.name) c ON m.type = 'table'
+WHERE m.type IN ('table', 'index') AND m.name NOT LIKE 'sqlite_%'
+`); err != nil {
+		t.Fatalf("Cannot query schema: %s", err.Error())
+	}
+
+	defer rows.Close() // nolint: errcheck
+
+	for rows.Next() {
+		var kind, name, col string
+
+		if err = rows.Scan(&kind, &name, &col); err != nil {
+			t.Fatalf("Cannot scan schema: %s", err.Error())
+		}
+
+		schema[kind+" "+name] = append(schema[kind+" "+name], col)
+	}
+
+	for k := range schema {
+		slices.Sort(schema[k])
+	}
+
+	return schema
+} // func schemaOf(t *testing.T, sdb *sql.DB) map[string][]string
+
+func TestMigrate(t *testing.T) {
+	if db == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err      error
+		legacy   *sql.DB
+		mdb      *Database
+		version  int
+		pending  []Migration
+		all      []model.Feed
+		backups  []string
+		dbpath   = filepath.Join(common.Path(path.Base), "legacy.db")
+		expected = schemaOf(t, db.db)
+	)
+
+	if legacy, err = sql.Open("sqlite3", dbpath); err != nil {
+		t.Fatalf("Cannot create legacy database: %s", err.Error())
+	}
+
+	for _, q := range legacyQueries {
+		if _, err = legacy.Exec(q); err != nil {
+			legacy.Close() // nolint: errcheck
+			t.Fatalf("Cannot execute legacy init query: %s\n%s", err.Error(), q)
+		}
+	}
+
+	if _, err = legacy.Exec("INSERT INTO feed (title, url, homepage) VALUES ('Legacy', 'https://legacy.example.com/rss', 'https://legacy.example.com/')"); err != nil {
+		t.Errorf("Cannot add Feed to legacy database: %s", err.Error())
+	}
+
+	legacy.Close() // nolint: errcheck
+
+	if version, pending, err = SchemaStatus(dbpath); err != nil {
+		t.Fatalf("Cannot get schema status: %s", err.Error())
+	} else if version != 0 {
+		t.Errorf("Unexpected schema version of legacy database: %d", version)
+	} else if len(pending) != len(migrations) {
+		t.Errorf("Expected %d pending migrations, got %d",
+			len(migrations),
+			len(pending))
+	}
+
+	if mdb, err = Open(dbpath); err != nil {
+		t.Fatalf("Cannot open legacy database: %s", err.Error())
+	}
+
+	defer mdb.Close() // nolint: errcheck
+
+	if version, err = getSchemaVersion(mdb.db); err != nil {
+		t.Fatalf("Cannot get schema version: %s", err.Error())
+	} else if version != SchemaVersion() {
+		t.Errorf("Database was migrated to version %d, expected %d",
+			version,
+			SchemaVersion())
+	} else if backups, err = filepath.Glob(dbpath + ".v0.*.bak"); err != nil {
+		t.Fatalf("Cannot look for backups: %s", err.Error())
+	} else if len(backups) != 1 {
+		t.Errorf("Expected 1 backup, found %d", len(backups))
+	} else if all, err = mdb.FeedGetAll(); err != nil {
+		t.Fatalf("Cannot load Feeds from migrated database: %s", err.Error())
+	} else if len(all) != 1 || all[0].Title != "Legacy" {
+		t.Errorf("Unexpected Feeds in migrated database: %v", all)
+	}
+
+	var actual = schemaOf(t, mdb.db)
+
+	for k, cols := range expected {
+		if !slices.Equal(cols, actual[k]) {
+			t.Errorf("Schema mismatch for %s after migration:\n%v\n%v",
+				k,
+				cols,
+				actual[k])
+		}
+	}
+
+	for k := range actual {
+		if _, ok := expected[k]; !ok {
+			t.Errorf("Migrations created %s, but initQueries do not", k)
+		}
+	}
+
+	for qid := range dbQueries {
+		if _, err = mdb.getQuery(qid); err != nil {
+			t.Errorf("Failed to prepare query %s on migrated database: %s",
+				qid,
+				err.Error())
+		}
+	}
+} // func TestMigrate(t *testing.T)
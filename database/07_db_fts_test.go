@@ -0,0 +1,171 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/07_db_fts_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 19:48:26 krylon>
+
+package database
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/model"
+)
+
+func TestFTSQuery(t *testing.T) {
+	var cases = []struct {
+		in, out string
+	}{
+		{"", ""},
+		{"zebra", `"zebra"`},
+		{"zebra crossing", `"zebra" "crossing"`},
+		{`"zebra crossing" light`, `"zebra crossing" "light"`},
+		{"zeb* light", `"zeb"* "light"`},
+		{"AND OR - ( )", `"AND" "OR"`},
+		{`say "hello`, `"say" "hello"`},
+	}
+
+	for _, c := range cases {
+		if q := ftsQuery(c.in); q != c.out {
+			t.Errorf("ftsQuery(%q) = %q, expected %q",
+				c.in,
+				q,
+				c.out)
+		}
+	}
+} // func TestFTSQuery(t *testing.T)
+
+func TestFTSSearch(t *testing.T) {
+	if db == nil {
+		t.SkipNow()
+	} else if !db.FTSAvailable() {
+		t.Skip("FTS5 is not available, build with -tags sqlite_fts5")
+	}
+
+	var (
+		err error
+		en  = &model.Feed{
+			Title:          "FTS English",
+			URL:            purl("https://fts.example.com/en.rss"),
+			Homepage:       purl("https://fts.example.com/en/"),
+			UpdateInterval: time.Hour,
+			Active:         true,
+		}
+		de = &model.Feed{
+			Title:          "FTS Deutsch",
+			URL:            purl("https://fts.example.com/de.rss"),
+			Homepage:       purl("https://fts.example.com/de/"),
+			UpdateInterval: time.Hour,
+			Active:         true,
+		}
+		runner = &model.Item{
+			URL:         purl("https://fts.example.com/en/runner.html"),
+			Timestamp:   time.Now(),
+			Headline:    "Zebras are running wild",
+			Description: "A herd escaped from the zoo.",
+		}
+		walker = &model.Item{
+			URL:         purl("https://fts.example.com/en/walker.html"),
+			Timestamp:   time.Now(),
+			Headline:    "Nothing to see",
+			Description: "Move along.",
+		}
+		german = &model.Item{
+			URL:         purl("https://fts.example.com/de/zebra.html"),
+			Timestamp:   time.Now(),
+			Headline:    "Zebrastreifen werden neu gestrichen",
+			Description: "Die Stadt erneuert die Fußgängerüberwege.",
+		}
+	)
+
+	if err = db.FeedAdd(en); err != nil {
+		t.Fatalf("Failed to add Feed: %s", err.Error())
+	} else if err = db.FeedAdd(de); err != nil {
+		t.Fatalf("Failed to add Feed: %s", err.Error())
+	} else if err = db.FeedSetMeta(en, model.FeedMeta{Language: "en-US"}); err != nil {
+		t.Fatalf("Failed to set Feed metadata: %s", err.Error())
+	} else if err = db.FeedSetMeta(de, model.FeedMeta{Language: "de"}); err != nil {
+		t.Fatalf("Failed to set Feed metadata: %s", err.Error())
+	}
+
+	runner.FeedID = en.ID
+	walker.FeedID = en.ID
+	german.FeedID = de.ID
+
+	for _, i := range []*model.Item{runner, walker, german} {
+		if err = db.ItemAdd(i); err != nil {
+			t.Fatalf("Failed to add Item %q: %s", i.Headline, err.Error())
+		}
+	}
+
+	if err = db.ItemContentAdd(walker, "Later that day, a zebra ran past the office, wild with fear."); err != nil {
+		t.Fatalf("Failed to add Item content: %s", err.Error())
+	}
+
+	var cases = []struct {
+		query    string
+		ordered  bool
+		expected []int64
+	}{
+		// Stemming applies to the English Feed only.
+		{"runs", true, []int64{runner.ID}},
+		// Matches in the headline rank before matches in the content.
+		{"wild", true, []int64{runner.ID, walker.ID}},
+		// Scores from different indices are not comparable.
+		{"zebra", false, []int64{runner.ID, german.ID, walker.ID}},
+		{"zebra*", false, []int64{runner.ID, german.ID, walker.ID}},
+		{"fußgangeruberwege", true, []int64{german.ID}},
+		// The German index matches parts of compound words.
+		{"streifen", true, []int64{german.ID}},
+		{"überweg", true, []int64{german.ID}},
+		{`"zebra ran"`, true, []int64{walker.ID}},
+	}
+
+	for _, c := range cases {
+		var (
+			res []*model.Item
+			s   = &model.Search{QueryString: c.query}
+		)
+
+		if res, err = db.searchFTS(s); err != nil {
+			t.Errorf("Search for %q failed: %s", c.query, err.Error())
+			continue
+		} else if len(res) != len(c.expected) {
+			t.Errorf("Search for %q returned %d Items, expected %d",
+				c.query,
+				len(res),
+				len(c.expected))
+			continue
+		}
+
+		for idx, i := range res {
+			if !c.ordered && slices.Contains(c.expected, i.ID) {
+				continue
+			} else if i.ID != c.expected[idx] {
+				t.Errorf("Search for %q: Result #%d is Item %d (%q), expected %d",
+					c.query,
+					idx,
+					i.ID,
+					i.Headline,
+					c.expected[idx])
+			} else if i.Score <= 0 {
+				t.Errorf("Search for %q: Result #%d has no relevance score",
+					c.query,
+					idx)
+			}
+		}
+	}
+
+	// The index follows changes to the Feed's language.
+	var res []*model.Item
+
+	if err = db.FeedSetMeta(de, model.FeedMeta{Language: "en"}); err != nil {
+		t.Fatalf("Failed to set Feed metadata: %s", err.Error())
+	} else if res, err = db.searchFTS(&model.Search{QueryString: "gestrichen"}); err != nil {
+		t.Fatalf("Search failed: %s", err.Error())
+	} else if len(res) != 1 {
+		t.Errorf("Item was lost when its Feed's language changed")
+	}
+} // func TestFTSSearch(t *testing.T)
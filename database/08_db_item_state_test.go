@@ -0,0 +1,174 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/08_db_item_state_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 21:30:14 krylon>
+
+package database
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/model"
+)
+
+func TestItemReadState(t *testing.T) {
+	if db == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err    error
+		cnt    int64
+		unread map[int64]int64
+		list   []*model.Item
+		items  = make([]*model.Item, 5)
+		now    = time.Now().Truncate(time.Second)
+		f      = &model.Feed{
+			Title:          "Read State",
+			URL:            purl("https://read.example.com/feed.rss"),
+			Homepage:       purl("https://read.example.com/"),
+			UpdateInterval: time.Hour,
+			Active:         true,
+		}
+	)
+
+	if err = db.FeedAdd(f); err != nil {
+		t.Fatalf("Failed to add Feed: %s", err.Error())
+	}
+
+	// items[0] is the oldest, items[4] the most recent Item.
+	for idx := range items {
+		items[idx] = &model.Item{
+			FeedID:    f.ID,
+			URL:       purl(fmt.Sprintf("https://read.example.com/item%02d.html", idx)),
+			Timestamp: now.Add(time.Duration(idx-len(items)) * time.Minute),
+			Headline:  fmt.Sprintf("Item %02d", idx),
+		}
+
+		if err = db.ItemAdd(items[idx]); err != nil {
+			t.Fatalf("Failed to add Item %s: %s", items[idx].Headline, err.Error())
+		}
+	}
+
+	if unread, err = db.FeedGetUnreadCnt(); err != nil {
+		t.Fatalf("Failed to count unread Items: %s", err.Error())
+	} else if unread[f.ID] != int64(len(items)) {
+		t.Fatalf("Expected %d unread Items, got %d", len(items), unread[f.ID])
+	} else if err = db.ItemMarkRead(items[0]); err != nil {
+		t.Fatalf("Failed to mark Item as read: %s", err.Error())
+	} else if !items[0].IsRead() {
+		t.Errorf("Item %d is not marked as read", items[0].ID)
+	} else if list, err = db.ItemGetByFeed(f, 10, 0, model.ItemFilter{Unread: true}); err != nil {
+		t.Fatalf("Failed to load unread Items: %s", err.Error())
+	} else if len(list) != len(items)-1 {
+		t.Errorf("Expected %d unread Items, got %d", len(items)-1, len(list))
+	}
+
+	// Everything above items[2] is items[3] and items[4].
+	if cnt, err = db.ItemMarkReadAbove(items[2], f); err != nil {
+		t.Fatalf("Failed to mark Items as read: %s", err.Error())
+	} else if cnt != 3 {
+		t.Errorf("Expected 3 Items to be marked as read, got %d", cnt)
+	} else if list, err = db.ItemGetByFeed(f, 10, 0, model.ItemFilter{Unread: true}); err != nil {
+		t.Fatalf("Failed to load unread Items: %s", err.Error())
+	} else if len(list) != 1 || list[0].ID != items[1].ID {
+		t.Errorf("Expected only Item %d to be unread, got %d Items",
+			items[1].ID,
+			len(list))
+	} else if err = db.ItemMarkUnread(items[4]); err != nil {
+		t.Fatalf("Failed to mark Item as unread: %s", err.Error())
+	} else if items[4].IsRead() {
+		t.Errorf("Item %d is still marked as read", items[4].ID)
+	} else if cnt, err = db.ItemMarkReadList([]int64{items[1].ID, items[4].ID, items[0].ID}); err != nil {
+		t.Fatalf("Failed to mark list of Items as read: %s", err.Error())
+	} else if cnt != 2 {
+		t.Errorf("Expected 2 Items to be marked as read, got %d", cnt)
+	} else if unread, err = db.FeedGetUnreadCnt(); err != nil {
+		t.Fatalf("Failed to count unread Items: %s", err.Error())
+	} else if unread[f.ID] != 0 {
+		t.Errorf("Expected no unread Items, got %d", unread[f.ID])
+	}
+} // func TestItemReadState(t *testing.T)
+
+func TestItemStarred(t *testing.T) {
+	if db == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err    error
+		item   *model.Item
+		list   []*model.Item
+		unread map[int64]int64
+		tag    = &model.Tag{Name: "Starred Test"}
+		f      = &model.Feed{
+			Title:          "Starred",
+			URL:            purl("https://star.example.com/feed.rss"),
+			Homepage:       purl("https://star.example.com/"),
+			UpdateInterval: time.Hour,
+			Active:         true,
+		}
+		star = &model.Item{
+			URL:       purl("https://star.example.com/star.html"),
+			Timestamp: time.Now(),
+			Headline:  "A star is born",
+		}
+		plain = &model.Item{
+			URL:       purl("https://star.example.com/plain.html"),
+			Timestamp: time.Now(),
+			Headline:  "Nothing special",
+		}
+	)
+
+	if err = db.FeedAdd(f); err != nil {
+		t.Fatalf("Failed to add Feed: %s", err.Error())
+	} else if err = db.TagAdd(tag); err != nil {
+		t.Fatalf("Failed to add Tag: %s", err.Error())
+	}
+
+	star.FeedID = f.ID
+	plain.FeedID = f.ID
+
+	for _, i := range []*model.Item{star, plain} {
+		if err = db.ItemAdd(i); err != nil {
+			t.Fatalf("Failed to add Item %q: %s", i.Headline, err.Error())
+		} else if err = db.TagLinkAdd(i, tag); err != nil {
+			t.Fatalf("Failed to tag Item %q: %s", i.Headline, err.Error())
+		}
+	}
+
+	if err = db.ItemSetStarred(star, true); err != nil {
+		t.Fatalf("Failed to star Item: %s", err.Error())
+	} else if item, err = db.ItemGetByID(star.ID); err != nil {
+		t.Fatalf("Failed to load Item %d: %s", star.ID, err.Error())
+	} else if !item.Starred {
+		t.Errorf("Item %d is not starred", star.ID)
+	} else if list, err = db.ItemGetByFeed(f, 10, 0, model.ItemFilter{Starred: true}); err != nil {
+		t.Fatalf("Failed to load starred Items: %s", err.Error())
+	} else if len(list) != 1 || list[0].ID != star.ID {
+		t.Errorf("Expected only Item %d to be starred, got %d Items",
+			star.ID,
+			len(list))
+	} else if err = db.ItemMarkRead(star); err != nil {
+		t.Fatalf("Failed to mark Item as read: %s", err.Error())
+	} else if list, err = db.ItemGetRecentPaged(100, 0, model.ItemFilter{Unread: true, Starred: true}); err != nil {
+		t.Fatalf("Failed to load unread, starred Items: %s", err.Error())
+	} else if len(list) != 0 {
+		t.Errorf("Expected no unread, starred Items, got %d", len(list))
+	} else if unread, err = db.TagGetUnreadCnt(); err != nil {
+		t.Fatalf("Failed to count unread Items per Tag: %s", err.Error())
+	} else if unread[tag.ID] != 1 {
+		t.Errorf("Expected 1 unread Item for Tag %s, got %d",
+			tag.Name,
+			unread[tag.ID])
+	} else if err = db.ItemSetStarred(star, false); err != nil {
+		t.Fatalf("Failed to unstar Item: %s", err.Error())
+	} else if list, err = db.ItemGetByFeed(f, 10, 0, model.ItemFilter{Starred: true}); err != nil {
+		t.Fatalf("Failed to load starred Items: %s", err.Error())
+	} else if len(list) != 0 {
+		t.Errorf("Expected no starred Items, got %d", len(list))
+	}
+} // func TestItemStarred(t *testing.T)
@@ -0,0 +1,36 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/09_db_store_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 01:10:26 krylon>
+
+package database_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/database/storetest"
+)
+
+// TestStore runs the test cases every Store has to pass against a fresh
+// database for every case.
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) database.Opener {
+		var dbPath = filepath.Join(t.TempDir(), "store.db")
+
+		return func() (database.Store, error) {
+			var (
+				err error
+				db  *database.Database
+			)
+
+			if db, err = database.Open(dbPath); err != nil {
+				return nil, err
+			}
+
+			return db, nil
+		}
+	})
+} // func TestStore(t *testing.T)
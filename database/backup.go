@@ -0,0 +1,180 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/backup.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 22:41:09 krylon>
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/blicero/krylib"
+	"github.com/mattn/go-sqlite3"
+)
+
+// backupPageCnt is the number of pages a Backup copies in one step. Between
+// two steps, other connections may access the database.
+const backupPageCnt = 512
+
+// ErrInUse indicates that a database could not be locked because another
+// connection, probably from a running instance of the application, is
+// using it.
+var ErrInUse = errors.New("database is in use")
+
+// Backup writes a consistent copy of the database to the given path, which
+// must not exist yet. It uses SQLite's online backup API, so the database
+// remains usable while the Backup is running. If another connection modifies
+// the database in the meantime, SQLite starts over, so the copy always
+// reflects a single point in time.
+func (db *Database) Backup(path string) error {
+	var (
+		err      error
+		exists   bool
+		dst      *sql.DB
+		src, out *sql.Conn
+		ctx      = context.Background()
+	)
+
+	if exists, err = krylib.Fexists(path); err != nil {
+		return err
+	} else if exists {
+		return fmt.Errorf("Cannot save backup to %s: file already exists", path)
+	} else if dst, err = sql.Open("sqlite3", path); err != nil {
+		db.log.Printf("[ERROR] Cannot create backup database %s: %s\n",
+			path,
+			err.Error())
+		return err
+	}
+
+	defer dst.Close() // nolint: errcheck
+
+	if out, err = dst.Conn(ctx); err != nil {
+		db.log.Printf("[ERROR] Cannot connect to backup database %s: %s\n",
+			path,
+			err.Error())
+		return err
+	}
+
+	defer out.Close() // nolint: errcheck
+
+	if src, err = db.db.Conn(ctx); err != nil {
+		db.log.Printf("[ERROR] Cannot get connection to %s: %s\n",
+			db.path,
+			err.Error())
+		return err
+	}
+
+	defer src.Close() // nolint: errcheck
+
+	err = out.Raw(func(dconn any) error {
+		return src.Raw(func(sconn any) error {
+			var (
+				ok   bool
+				d, s *sqlite3.SQLiteConn
+			)
+
+			if d, ok = dconn.(*sqlite3.SQLiteConn); !ok {
+				return fmt.Errorf("Unexpected type of connection: %T", dconn)
+			} else if s, ok = sconn.(*sqlite3.SQLiteConn); !ok {
+				return fmt.Errorf("Unexpected type of connection: %T", sconn)
+			}
+
+			return backupConn(d, s)
+		})
+	})
+
+	if err != nil {
+		db.log.Printf("[ERROR] Failed to save backup of %s to %s: %s\n",
+			db.path,
+			path,
+			err.Error())
+		out.Close()     // nolint: errcheck
+		dst.Close()     // nolint: errcheck
+		os.Remove(path) // nolint: errcheck
+		return err
+	}
+
+	return nil
+} // func (db *Database) Backup(path string) error
+
+// backupConn copies the main database of src to dst in steps of
+// backupPageCnt pages.
+func backupConn(dst, src *sqlite3.SQLiteConn) error {
+	var (
+		err  error
+		done bool
+		bak  *sqlite3.SQLiteBackup
+	)
+
+	if bak, err = dst.Backup("main", src, "main"); err != nil {
+		return err
+	}
+
+	for !done {
+		// Step does not report SQLITE_BUSY or SQLITE_LOCKED as errors,
+		// it just has not made any progress in that case.
+		if done, err = bak.Step(backupPageCnt); err != nil {
+			bak.Finish() // nolint: errcheck
+			return err
+		} else if !done {
+			time.Sleep(retryDelay)
+		}
+	}
+
+	return bak.Finish()
+} // func backupConn(dst, src *sqlite3.SQLiteConn) error
+
+// Lock acquires an exclusive lock on the database at the given path and
+// returns a function that releases it. As long as the lock is held, no other
+// connection, in this process or another one, can read from or write to the
+// database. If the database does not exist, there is nothing to lock.
+func Lock(path string) (func(), error) {
+	var (
+		err    error
+		exists bool
+		db     *sql.DB
+		conn   *sql.Conn
+		ctx    = context.Background()
+		dsn    = fmt.Sprintf("file:%s?_locking=EXCLUSIVE&_busy_timeout=0", path)
+	)
+
+	if exists, err = krylib.Fexists(path); err != nil {
+		return nil, err
+	} else if !exists {
+		return func() {}, nil
+	} else if db, err = sql.Open("sqlite3", dsn); err != nil {
+		return nil, err
+	} else if conn, err = db.Conn(ctx); err != nil {
+		db.Close() // nolint: errcheck
+		return nil, lockError(err)
+	} else if _, err = conn.ExecContext(ctx, "BEGIN EXCLUSIVE"); err != nil {
+		conn.Close() // nolint: errcheck
+		db.Close()   // nolint: errcheck
+		return nil, lockError(err)
+	}
+
+	return func() {
+		conn.ExecContext(ctx, "ROLLBACK") // nolint: errcheck
+		conn.Close()                      // nolint: errcheck
+		db.Close()                        // nolint: errcheck
+	}, nil
+} // func Lock(path string) (func(), error)
+
+// lockError wraps err in ErrInUse if SQLite reports the database as busy
+// or locked.
+func lockError(err error) error {
+	var serr sqlite3.Error
+
+	if errors.As(err, &serr) &&
+		(serr.Code == sqlite3.ErrBusy || serr.Code == sqlite3.ErrLocked) {
+		return fmt.Errorf("%w: %s", ErrInUse, err.Error())
+	}
+
+	return err
+} // func lockError(err error) error
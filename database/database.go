@@ -9,6 +9,7 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
@@ -89,10 +90,12 @@ type Database struct {
 	spNameCounter int
 	spNameCache   map[string]string
 	queries       map[query.ID]*sql.Stmt
+	fts           bool
 }
 
 // Open opens a Database. If the database specified by the path does not exist,
-// yet, it is created and initialized.
+// yet, it is created and initialized. If it exists, but has an older schema
+// version, the pending Migrations are applied.
 func Open(path string) (*Database, error) {
 	var (
 		err      error
@@ -147,6 +150,20 @@ func Open(path string) (*Database, error) {
 		}
 		db.log.Printf("[INFO] Database at %s has been initialized\n",
 			path)
+	} else if err = db.migrate(); err != nil {
+		db.log.Printf("[ERROR] Failed to upgrade schema of %s: %s\n",
+			path,
+			err.Error())
+		if e2 := db.db.Close(); e2 != nil {
+			db.log.Printf("[CRITICAL] Failed to close database: %s\n",
+				e2.Error())
+		}
+		return nil, err
+	}
+
+	if err = db.ftsSetup(); err != nil {
+		db.log.Printf("[ERROR] Cannot set up full-text index, Searches will be slow: %s\n",
+			err.Error())
 	}
 
 	return db, nil
@@ -183,7 +200,16 @@ func (db *Database) initialize() error {
 		}
 	}
 
-	if err = tx.Commit(); err != nil {
+	if _, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion())); err != nil {
+		db.log.Printf("[ERROR] Cannot set schema version: %s\n",
+			err.Error())
+		if rbErr := tx.Rollback(); rbErr != nil {
+			db.log.Printf("[CANTHAPPEN] Cannot rollback transaction: %s\n",
+				rbErr.Error())
+			return rbErr
+		}
+		return err
+	} else if err = tx.Commit(); err != nil {
 		db.log.Printf("[CANTHAPPEN] Failed to commit init transaction: %s\n",
 			err.Error())
 		return err
@@ -518,25 +544,32 @@ SAVEPOINT:
 func (db *Database) Rollback() error {
 	var err error
 
-	db.log.Printf("[DEBUG] Database#%d Roll back Transaction\n",
-		db.id)
-
 	if db.tx == nil {
 		return ErrNoTxInProgress
-	} else if err = db.tx.Rollback(); err != nil {
-		return fmt.Errorf("Cannot roll back database transaction: %s",
-			err.Error())
 	}
 
+	db.log.Printf("[DEBUG] Database#%d Roll back Transaction\n",
+		db.id)
+
+	// Even if the rollback fails, the transaction is over, database/sql
+	// will not let us use it anymore.
+	err = db.tx.Rollback()
 	db.tx = nil
 	db.resetSPNamespace()
 
+	if err != nil {
+		return fmt.Errorf("Cannot roll back database transaction: %s",
+			err.Error())
+	}
+
 	return nil
 } // func (db *Database) Rollback() error
 
 // Commit ends the active transaction, making any changes made during that
 // transaction permanent and visible to other connections.
 // If no transaction is active, it returns ErrNoTxInProgress
+// If the commit fails, the transaction has been rolled back, and the
+// Database is ready for the next one, there is no need to call Rollback.
 func (db *Database) Commit() error {
 	var err error
 
@@ -545,13 +578,17 @@ func (db *Database) Commit() error {
 
 	if db.tx == nil {
 		return ErrNoTxInProgress
-	} else if err = db.tx.Commit(); err != nil {
-		return fmt.Errorf("Cannot commit transaction: %s",
-			err.Error())
 	}
 
+	err = db.tx.Commit()
 	db.resetSPNamespace()
 	db.tx = nil
+
+	if err != nil {
+		return fmt.Errorf("Cannot commit transaction: %s",
+			err.Error())
+	}
+
 	return nil
 } // func (db *Database) Commit() error
 
@@ -608,7 +645,7 @@ func (db *Database) FeedAdd(f *model.Feed) error {
 	var rows *sql.Rows
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(f.Title, f.URL.String(), f.Homepage.String(), f.UpdateInterval.Seconds()); err != nil {
+	if rows, err = stmt.Query(f.Title, f.URL.String(), f.Homepage.String(), f.UpdateInterval.Seconds(), f.Folder); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
@@ -678,11 +715,17 @@ EXEC_QUERY:
 	if rows.Next() {
 		var (
 			timestamp, interval int64
+			nextAttempt         int64
 			ustr, hstr          string
+			headers, secret     string
+			ttl, cadence        int64
+			nextRefresh         int64
+			image               string
+			iconChecked         int64
 			f                   = &model.Feed{ID: id}
 		)
 
-		if err = rows.Scan(&f.Title, &ustr, &hstr, &interval, &timestamp, &f.Active); err != nil {
+		if err = rows.Scan(&f.Title, &ustr, &hstr, &interval, &timestamp, &f.Active, &f.Folder, &f.FetchFull, &f.Download, &f.ETag, &f.LastModified, &f.LastStatus, &f.Failures, &f.LastError, &nextAttempt, &headers, &f.Auth.Kind, &f.Auth.Username, &secret, &f.Bound, &ttl, &f.Schedule.SkipHours, &f.Schedule.SkipDays, &cadence, &nextRefresh, &f.Meta.Description, &f.Meta.Language, &image, &f.Meta.Generator, &f.Meta.Icon, &f.Meta.IconType, &iconChecked, &f.Retention); err != nil {
 			msg = fmt.Sprintf("Error scanning row for Feed %d: %s",
 				id,
 				err.Error())
@@ -702,6 +745,16 @@ EXEC_QUERY:
 
 		f.LastRefresh = time.Unix(timestamp, 0)
 		f.UpdateInterval = time.Second * time.Duration(interval)
+		if nextAttempt != 0 {
+			f.NextAttempt = time.Unix(nextAttempt, 0)
+		}
+		f.Schedule.TTL = time.Second * time.Duration(ttl)
+		f.Schedule.Cadence = time.Second * time.Duration(cadence)
+		if nextRefresh != 0 {
+			f.Schedule.Next = time.Unix(nextRefresh, 0)
+		}
+		db.decodeFeedSettings(f, headers, secret)
+		db.decodeFeedMeta(f, image, iconChecked)
 
 		return f, nil
 	}
@@ -746,11 +799,17 @@ EXEC_QUERY:
 	for rows.Next() {
 		var (
 			timestamp, interval int64
+			nextAttempt         int64
 			ustr, hstr          string
+			headers, secret     string
+			ttl, cadence        int64
+			nextRefresh         int64
+			image               string
+			iconChecked         int64
 			f                   model.Feed
 		)
 
-		if err = rows.Scan(&f.ID, &f.Title, &ustr, &hstr, &interval, &timestamp, &f.Active); err != nil {
+		if err = rows.Scan(&f.ID, &f.Title, &ustr, &hstr, &interval, &timestamp, &f.Active, &f.Folder, &f.FetchFull, &f.Download, &f.ETag, &f.LastModified, &f.LastStatus, &f.Failures, &f.LastError, &nextAttempt, &headers, &f.Auth.Kind, &f.Auth.Username, &secret, &f.Bound, &ttl, &f.Schedule.SkipHours, &f.Schedule.SkipDays, &cadence, &nextRefresh, &f.Meta.Description, &f.Meta.Language, &image, &f.Meta.Generator, &f.Meta.Icon, &f.Meta.IconType, &iconChecked, &f.Retention); err != nil {
 			msg = fmt.Sprintf("Error scanning row for Feed: %s",
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", msg)
@@ -769,6 +828,16 @@ EXEC_QUERY:
 
 		f.LastRefresh = time.Unix(timestamp, 0)
 		f.UpdateInterval = time.Second * time.Duration(interval)
+		if nextAttempt != 0 {
+			f.NextAttempt = time.Unix(nextAttempt, 0)
+		}
+		f.Schedule.TTL = time.Second * time.Duration(ttl)
+		f.Schedule.Cadence = time.Second * time.Duration(cadence)
+		if nextRefresh != 0 {
+			f.Schedule.Next = time.Unix(nextRefresh, 0)
+		}
+		db.decodeFeedSettings(&f, headers, secret)
+		db.decodeFeedMeta(&f, image, iconChecked)
 		feeds = append(feeds, f)
 	}
 
@@ -811,11 +880,17 @@ EXEC_QUERY:
 	for rows.Next() {
 		var (
 			timestamp, interval int64
+			nextAttempt         int64
 			ustr, hstr          string
+			headers, secret     string
+			ttl, cadence        int64
+			nextRefresh         int64
+			image               string
+			iconChecked         int64
 			f                   model.Feed
 		)
 
-		if err = rows.Scan(&f.ID, &f.Title, &ustr, &hstr, &interval, &timestamp, &f.Active); err != nil {
+		if err = rows.Scan(&f.ID, &f.Title, &ustr, &hstr, &interval, &timestamp, &f.Active, &f.Folder, &f.FetchFull, &f.Download, &f.ETag, &f.LastModified, &f.LastStatus, &f.Failures, &f.LastError, &nextAttempt, &headers, &f.Auth.Kind, &f.Auth.Username, &secret, &f.Bound, &ttl, &f.Schedule.SkipHours, &f.Schedule.SkipDays, &cadence, &nextRefresh, &f.Meta.Description, &f.Meta.Language, &image, &f.Meta.Generator, &f.Meta.Icon, &f.Meta.IconType, &iconChecked, &f.Retention); err != nil {
 			msg = fmt.Sprintf("Error scanning row for Feed: %s",
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", msg)
@@ -834,6 +909,16 @@ EXEC_QUERY:
 
 		f.LastRefresh = time.Unix(timestamp, 0)
 		f.UpdateInterval = time.Second * time.Duration(interval)
+		if nextAttempt != 0 {
+			f.NextAttempt = time.Unix(nextAttempt, 0)
+		}
+		f.Schedule.TTL = time.Second * time.Duration(ttl)
+		f.Schedule.Cadence = time.Second * time.Duration(cadence)
+		if nextRefresh != 0 {
+			f.Schedule.Next = time.Unix(nextRefresh, 0)
+		}
+		db.decodeFeedSettings(&f, headers, secret)
+		db.decodeFeedMeta(&f, image, iconChecked)
 		feeds = append(feeds, f)
 	}
 
@@ -910,9 +995,10 @@ EXEC_QUERY:
 	return nil
 } // func (db *Database) FeedUpdateRefresh(f *model.Feed, stamp time.Time) error
 
-// FeedSetActive sets the given Feed's Active flag
-func (db *Database) FeedSetActive(f *model.Feed, active bool) error {
-	const qid query.ID = query.FeedSetActive
+// FeedUpdateHTTPState stores the ETag and Last-Modified values as well as
+// the HTTP status we got the last time we fetched the given Feed.
+func (db *Database) FeedUpdateHTTPState(f *model.Feed, etag, lastMod string, code int) error {
+	const qid query.ID = query.FeedUpdateHTTPState
 	var (
 		err    error
 		msg    string
@@ -929,8 +1015,6 @@ func (db *Database) FeedSetActive(f *model.Feed, active bool) error {
 	} else if db.tx != nil {
 		tx = db.tx
 	} else {
-		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
-		// 	f.Title)
 	BEGIN_AD_HOC:
 		if tx, err = db.db.Begin(); err != nil {
 			if worthARetry(err) {
@@ -962,12 +1046,12 @@ func (db *Database) FeedSetActive(f *model.Feed, active bool) error {
 	stmt = tx.Stmt(stmt)
 
 EXEC_QUERY:
-	if _, err = stmt.Exec(active, f.ID); err != nil {
+	if _, err = stmt.Exec(etag, lastMod, code, f.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		} else {
-			err = fmt.Errorf("Cannot add Feed %s to database: %s",
+			err = fmt.Errorf("Cannot update HTTP state of Feed %s: %s",
 				f.Title,
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", err.Error())
@@ -975,14 +1059,18 @@ EXEC_QUERY:
 		}
 	}
 
-	f.Active = active
+	f.ETag = etag
+	f.LastModified = lastMod
+	f.LastStatus = code
 	status = true
 	return nil
-} // func (db *Database) FeedSetActive(f *model.Feed, active bool) error
+} // func (db *Database) FeedUpdateHTTPState(f *model.Feed, etag, lastMod string, code int) error
 
-// FeedDelete removes the given Feed from the database.
-func (db *Database) FeedDelete(f *model.Feed) error {
-	const qid query.ID = query.FeedDelete
+// FeedRecordFailure increments the given Feed's failure counter and stores the
+// error message and the earliest time for the next attempt to fetch the Feed.
+// If active is false, the Feed is disabled.
+func (db *Database) FeedRecordFailure(f *model.Feed, errmsg string, next time.Time, active bool) error {
+	const qid query.ID = query.FeedRecordFailure
 	var (
 		err    error
 		msg    string
@@ -999,8 +1087,6 @@ func (db *Database) FeedDelete(f *model.Feed) error {
 	} else if db.tx != nil {
 		tx = db.tx
 	} else {
-		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
-		// 	f.Title)
 	BEGIN_AD_HOC:
 		if tx, err = db.db.Begin(); err != nil {
 			if worthARetry(err) {
@@ -1032,12 +1118,12 @@ func (db *Database) FeedDelete(f *model.Feed) error {
 	stmt = tx.Stmt(stmt)
 
 EXEC_QUERY:
-	if _, err = stmt.Exec(f.ID); err != nil {
+	if _, err = stmt.Exec(f.Failures+1, errmsg, next.Unix(), active, f.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		} else {
-			err = fmt.Errorf("Cannot add Feed %s to database: %s",
+			err = fmt.Errorf("Cannot record failure for Feed %s: %s",
 				f.Title,
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", err.Error())
@@ -1045,13 +1131,18 @@ EXEC_QUERY:
 		}
 	}
 
+	f.Failures++
+	f.LastError = errmsg
+	f.NextAttempt = next
+	f.Active = active
 	status = true
 	return nil
-} // func (db *Database) FeedDelete(f *model.Feed) error
+} // func (db *Database) FeedRecordFailure(f *model.Feed, errmsg string, next time.Time, active bool) error
 
-// ItemAdd adds a news item to the database.
-func (db *Database) ItemAdd(i *model.Item) error {
-	const qid query.ID = query.ItemAdd
+// FeedPostpone sets the earliest time for the next attempt to fetch the Feed
+// without counting it as a failure.
+func (db *Database) FeedPostpone(f *model.Feed, next time.Time) error {
+	const qid query.ID = query.FeedPostpone
 	var (
 		err    error
 		msg    string
@@ -1068,8 +1159,6 @@ func (db *Database) ItemAdd(i *model.Item) error {
 	} else if db.tx != nil {
 		tx = db.tx
 	} else {
-		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
-		// 	f.Title)
 	BEGIN_AD_HOC:
 		if tx, err = db.db.Begin(); err != nil {
 			if worthARetry(err) {
@@ -1099,47 +1188,30 @@ func (db *Database) ItemAdd(i *model.Item) error {
 	}
 
 	stmt = tx.Stmt(stmt)
-	var rows *sql.Rows
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(i.FeedID, i.URL.String(), i.Timestamp.Unix(), i.Headline, i.Description); err != nil {
+	if _, err = stmt.Exec(next.Unix(), f.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		} else {
-			err = fmt.Errorf("Cannot add Item %s to database: %s",
-				i.Headline,
+			err = fmt.Errorf("Cannot postpone Feed %s: %s",
+				f.Title,
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", err.Error())
 			return err
 		}
-	} else {
-		var id int64
-
-		defer rows.Close()
-
-		if !rows.Next() {
-			// CANTHAPPEN
-			db.log.Printf("[ERROR] Query %s did not return a value\n",
-				qid)
-			return fmt.Errorf("Query %s did not return a value", qid)
-		} else if err = rows.Scan(&id); err != nil {
-			msg = fmt.Sprintf("Failed to get ID for newly added host %s: %s",
-				i.Headline,
-				err.Error())
-			db.log.Printf("[ERROR] %s\n", msg)
-			return errors.New(msg)
-		}
-
-		i.ID = id
-		status = true
-		return nil
 	}
-} // func (db *Database) ItemAdd(i *model.Item) error
 
-// ItemDeleteByFeed removes all Items that belong to the given Feed.
-func (db *Database) ItemDeleteByFeed(f *model.Feed) error {
-	const qid query.ID = query.ItemDeleteByFeed
+	f.NextAttempt = next
+	status = true
+	return nil
+} // func (db *Database) FeedPostpone(f *model.Feed, next time.Time) error
+
+// FeedSetMeta stores the description, language, image and generator the Feed
+// reports about itself. The Feed's icon is left alone.
+func (db *Database) FeedSetMeta(f *model.Feed, meta model.FeedMeta) error {
+	const qid query.ID = query.FeedSetMeta
 	var (
 		err    error
 		msg    string
@@ -1156,8 +1228,6 @@ func (db *Database) ItemDeleteByFeed(f *model.Feed) error {
 	} else if db.tx != nil {
 		tx = db.tx
 	} else {
-		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
-		// 	f.Title)
 	BEGIN_AD_HOC:
 		if tx, err = db.db.Begin(); err != nil {
 			if worthARetry(err) {
@@ -1189,321 +1259,425 @@ func (db *Database) ItemDeleteByFeed(f *model.Feed) error {
 	stmt = tx.Stmt(stmt)
 
 EXEC_QUERY:
-	if _, err = stmt.Exec(f.ID); err != nil {
+	if _, err = stmt.Exec(meta.Description, meta.Language, meta.ImageString(), meta.Generator, f.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		} else {
-			err = fmt.Errorf("Cannot delete Items from Feed %s (%d): %s",
+			err = fmt.Errorf("Cannot update metadata of Feed %s: %s",
 				f.Title,
-				f.ID,
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", err.Error())
 			return err
 		}
 	}
 
+	f.Meta.Description = meta.Description
+	f.Meta.Language = meta.Language
+	f.Meta.Image = meta.Image
+	f.Meta.Generator = meta.Generator
 	status = true
 	return nil
-} // func (db *Database) ItemDeleteByFeed(f *model.Feed) error
+} // func (db *Database) FeedSetMeta(f *model.Feed, meta model.FeedMeta) error
 
-// ItemExists checks if the given Item is already in the database.
-func (db *Database) ItemExists(i *model.Item) (bool, error) {
-	const qid query.ID = query.ItemExists
+// FeedSetIcon stores the path and MIME type of the Feed's cached icon and
+// when we last looked for it. An empty path means the Feed has no icon.
+func (db *Database) FeedSetIcon(f *model.Feed, icon, mimeType string, checked time.Time) error {
+	const qid query.ID = query.FeedSetIcon
 	var (
-		err  error
-		msg  string
-		stmt *sql.Stmt
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
 	)
 
 	if stmt, err = db.getQuery(qid); err != nil {
 		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
 			qid,
 			err.Error())
-		return false, err
+		return err
 	} else if db.tx != nil {
-		stmt = db.tx.Stmt(stmt)
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
 	}
 
-	var rows *sql.Rows
+	stmt = tx.Stmt(stmt)
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(i.URL.String()); err != nil {
+	if _, err = stmt.Exec(icon, mimeType, checked.Unix(), f.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot set icon of Feed %s: %s",
+				f.Title,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
 		}
-
-		return false, err
 	}
 
-	defer rows.Close() // nolint: errcheck,gosec
+	f.Meta.Icon = icon
+	f.Meta.IconType = mimeType
+	f.Meta.IconChecked = checked
+	status = true
+	return nil
+} // func (db *Database) FeedSetIcon(f *model.Feed, icon, mimeType string, checked time.Time) error
 
-	if rows.Next() {
-		var cnt int64
+// FeedUpdate changes the title, URL, homepage and update interval of a Feed.
+// If the URL changes, the state the Reader keeps about the Feed is reset, as
+// is any WebSub subscription, so the next fetch starts from scratch. The
+// change of the URL is recorded in the Feed's history.
+func (db *Database) FeedUpdate(f *model.Feed, title string, addr, homepage *url.URL, interval time.Duration) error {
+	return db.feedChange(f, title, addr, homepage, interval, "Changed by user")
+} // func (db *Database) FeedUpdate(f *model.Feed, title string, addr, homepage *url.URL, interval time.Duration) error
 
-		if err = rows.Scan(&cnt); err != nil {
-			msg = fmt.Sprintf("Error scanning row for Feed: %s",
-				err.Error())
-			db.log.Printf("[ERROR] %s\n", msg)
-			return false, errors.New(msg)
-		}
+// FeedSetURL changes the URL of a Feed, e.g. because it has moved
+// permanently. Like FeedUpdate, it resets the Feed's state and records the
+// change, along with the given reason, in the Feed's history.
+// Since the homepage must be absolute, a relative one is resolved first,
+// otherwise we could never record that such a Feed has moved.
+func (db *Database) FeedSetURL(f *model.Feed, addr *url.URL, reason string) error {
+	return db.feedChange(f, f.Title, addr, ResolveHomepage(f, addr), f.UpdateInterval, reason)
+} // func (db *Database) FeedSetURL(f *model.Feed, addr *url.URL, reason string) error
 
-		return cnt > 0, nil
+// ResolveHomepage returns the homepage of the given Feed as an absolute URL.
+// Feeds added before we checked homepages may have a relative one, which is
+// resolved against the Feed's URL, or against addr if the Feed has none.
+func ResolveHomepage(f *model.Feed, addr *url.URL) *url.URL {
+	var base = f.URL
+
+	if f.Homepage == nil || f.Homepage.String() == "" || f.Homepage.IsAbs() {
+		return f.Homepage
+	} else if base == nil || !base.IsAbs() {
+		base = addr
 	}
 
-	db.log.Printf("[CANTHAPPEN] Query %s did not return any data looking for Item %q\n",
-		qid,
-		i.URL)
-	return false, nil
-} // func (db *Database) ItemExists(i *model.Item) (bool, error)
+	if base == nil {
+		return f.Homepage
+	}
 
-// ItemGetRecent loads all items newer than the given timestamp.
-func (db *Database) ItemGetRecent(begin time.Time) ([]*model.Item, error) {
-	const qid query.ID = query.ItemGetRecent
+	return base.ResolveReference(f.Homepage)
+} // func ResolveHomepage(f *model.Feed, addr *url.URL) *url.URL
+
+func (db *Database) feedChange(f *model.Feed, title string, addr, homepage *url.URL, interval time.Duration, reason string) error {
 	var (
-		err  error
-		msg  string
-		stmt *sql.Stmt
+		err   error
+		moved bool
+		adHoc = db.tx == nil
 	)
 
-	if stmt, err = db.getQuery(qid); err != nil {
-		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
-			qid,
-			err.Error())
-		return nil, err
-	} else if db.tx != nil {
-		stmt = db.tx.Stmt(stmt)
+	title = strings.TrimSpace(title)
+
+	if homepage == nil {
+		homepage = new(url.URL)
 	}
 
-	var rows *sql.Rows
+	if title == "" {
+		return fmt.Errorf("%w: Feed title must not be empty", ErrInvalidValue)
+	} else if addr == nil || !addr.IsAbs() || (addr.Scheme != "http" && addr.Scheme != "https") || addr.Host == "" {
+		return fmt.Errorf("%w: Feed URL must be an absolute http(s) URL", ErrInvalidValue)
+	} else if homepage.String() != "" && !homepage.IsAbs() {
+		return fmt.Errorf("%w: Homepage must be an absolute URL", ErrInvalidValue)
+	} else if interval < time.Minute {
+		return fmt.Errorf("%w: Update interval must be at least one minute", ErrInvalidValue)
+	}
 
-EXEC_QUERY:
-	if rows, err = stmt.Query(begin.Unix()); err != nil {
-		if worthARetry(err) {
-			waitForRetry()
-			goto EXEC_QUERY
-		}
+	moved = f.URL == nil || f.URL.String() != addr.String()
 
-		return nil, err
+	if adHoc {
+		if err = db.Begin(); err != nil {
+			return err
+		}
 	}
 
-	defer rows.Close() // nolint: errcheck,gosec
-	var items = make([]*model.Item, 0, 16)
+	if err = db.feedUpdate(f, title, addr, homepage, interval); err != nil {
+		goto FAIL
+	} else if moved {
+		var m = &model.FeedMove{
+			FeedID:    f.ID,
+			Timestamp: time.Now(),
+			OldURL:    f.URL,
+			NewURL:    addr,
+			Reason:    reason,
+		}
 
-	for rows.Next() {
-		var (
-			timestamp int64
-			ustr      string
-			i         = new(model.Item)
-		)
+		if m.OldURL == nil {
+			m.OldURL = new(url.URL)
+		}
 
-		if err = rows.Scan(&i.ID, &i.FeedID, &ustr, &timestamp, &i.Headline, &i.Description, &i.Rating); err != nil {
-			msg = fmt.Sprintf("Error scanning row for Feed: %s",
-				err.Error())
-			db.log.Printf("[ERROR] %s\n", msg)
-			return nil, errors.New(msg)
-		} else if i.URL, err = url.Parse(ustr); err != nil {
-			db.log.Printf("[ERROR] Cannot parse URL %q: %s\n",
-				ustr,
-				err.Error())
-			return nil, err
+		if err = db.FeedResetState(f); err != nil {
+			goto FAIL
+		} else if err = db.WebSubDeleteByFeed(f); err != nil {
+			goto FAIL
+		} else if err = db.FeedHistoryAdd(m); err != nil {
+			goto FAIL
 		}
+	}
 
-		i.Timestamp = time.Unix(timestamp, 0)
-		items = append(items, i)
+	if adHoc {
+		if err = db.Commit(); err != nil {
+			goto FAIL
+		}
 	}
 
-	return items, nil
-} // func (db *Database) ItemGetRecent(begin time.Time) ([]model.Item, error)
+	f.Title = title
+	f.URL = addr
+	f.Homepage = homepage
+	f.UpdateInterval = interval
+	return nil
 
-// ItemGetRecentPaged fetches up to cnt of the most recent news items, skipping the first offset items,
-// in descending chronological order.
-func (db *Database) ItemGetRecentPaged(cnt, offset int64) ([]*model.Item, error) {
-	const qid query.ID = query.ItemGetRecentPaged
+FAIL:
+	if adHoc {
+		db.Rollback() // nolint: errcheck
+	}
+
+	return err
+} // func (db *Database) feedChange(f *model.Feed, title string, addr, homepage *url.URL, interval time.Duration, reason string) error
+
+// feedUpdate stores the given properties of a Feed.
+func (db *Database) feedUpdate(f *model.Feed, title string, addr, homepage *url.URL, interval time.Duration) error {
+	const qid query.ID = query.FeedUpdate
 	var (
-		err   error
-		msg   string
-		stmt  *sql.Stmt
-		rsize int64
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
 	)
 
 	if stmt, err = db.getQuery(qid); err != nil {
 		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
 			qid,
 			err.Error())
-		return nil, err
+		return err
 	} else if db.tx != nil {
-		stmt = db.tx.Stmt(stmt)
-	}
-
-	var rows *sql.Rows
-
-EXEC_QUERY:
-	if rows, err = stmt.Query(cnt, offset); err != nil {
-		if worthARetry(err) {
-			waitForRetry()
-			goto EXEC_QUERY
-		}
-
-		return nil, err
-	}
-
-	if cnt < 0 {
-		rsize = 16
+		tx = db.tx
 	} else {
-		rsize = cnt
-	}
-
-	defer rows.Close() // nolint: errcheck,gosec
-	var items = make([]*model.Item, 0, rsize)
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
 
-	for rows.Next() {
-		var (
-			timestamp int64
-			ustr      string
-			i         = new(model.Item)
-		)
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
 
-		if err = rows.Scan(&i.ID, &i.FeedID, &ustr, &timestamp, &i.Headline, &i.Description, &i.Rating); err != nil {
-			msg = fmt.Sprintf("Error scanning row for Feed: %s",
-				err.Error())
-			db.log.Printf("[ERROR] %s\n", msg)
-			return nil, errors.New(msg)
-		} else if i.URL, err = url.Parse(ustr); err != nil {
-			db.log.Printf("[ERROR] Cannot parse URL %q: %s\n",
-				ustr,
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(title, addr.String(), homepage.String(), interval.Seconds(), f.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot update Feed %s: %s",
+				f.Title,
 				err.Error())
-			return nil, err
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
 		}
-
-		i.Timestamp = time.Unix(timestamp, 0)
-		items = append(items, i)
 	}
 
-	return items, nil
-} // func (db *Database) ItemGetRecentPaged(cnt, offset int64) ([]model.Item, error)
+	status = true
+	return nil
+} // func (db *Database) feedUpdate(f *model.Feed, title string, addr, homepage *url.URL, interval time.Duration) error
 
-// ItemGetByID loads an Item by its ID
-func (db *Database) ItemGetByID(id int64) (*model.Item, error) {
-	const qid query.ID = query.ItemGetByID
+// FeedResetState forgets everything we have learned about a Feed by fetching
+// it: the state for conditional requests, failures and the refresh schedule.
+// The next time the Feed is checked is treated like the first time.
+func (db *Database) FeedResetState(f *model.Feed) error {
+	const qid query.ID = query.FeedResetState
 	var (
-		err  error
-		msg  string
-		stmt *sql.Stmt
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
 	)
 
 	if stmt, err = db.getQuery(qid); err != nil {
 		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
 			qid,
 			err.Error())
-		return nil, err
+		return err
 	} else if db.tx != nil {
-		stmt = db.tx.Stmt(stmt)
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
 	}
 
-	var rows *sql.Rows
+	stmt = tx.Stmt(stmt)
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(id); err != nil {
+	if _, err = stmt.Exec(f.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
-		}
-
-		return nil, err
-	}
-
-	defer rows.Close() // nolint: errcheck,gosec
-
-	if rows.Next() {
-		var (
-			timestamp int64
-			ustr      string
-			i         = &model.Item{ID: id}
-		)
-
-		if err = rows.Scan(&i.FeedID, &ustr, &timestamp, &i.Headline, &i.Description, &i.Rating); err != nil {
-			msg = fmt.Sprintf("Error scanning row for Feed: %s",
-				err.Error())
-			db.log.Printf("[ERROR] %s\n", msg)
-			return nil, errors.New(msg)
-		} else if i.URL, err = url.Parse(ustr); err != nil {
-			db.log.Printf("[ERROR] Cannot parse URL %q: %s\n",
-				ustr,
+		} else {
+			err = fmt.Errorf("Cannot reset state of Feed %s: %s",
+				f.Title,
 				err.Error())
-			return nil, err
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
 		}
-
-		i.Timestamp = time.Unix(timestamp, 0)
-		return i, nil
 	}
 
-	return nil, nil
-} // func (db *Database) ItemGetByID(id int64) (*model.Item, error)
+	f.ETag = ""
+	f.LastModified = ""
+	f.LastStatus = 0
+	f.LastRefresh = time.Unix(0, 0)
+	f.Failures = 0
+	f.LastError = ""
+	f.NextAttempt = time.Unix(0, 0)
+	f.Schedule = model.Schedule{}
+	status = true
+	return nil
+} // func (db *Database) FeedResetState(f *model.Feed) error
 
-// ItemGetByFeed loads items from the given Feed.
-func (db *Database) ItemGetByFeed(f *model.Feed, limit, offset int64) ([]*model.Item, error) {
-	const qid query.ID = query.ItemGetByFeed
+// FeedHistoryAdd records a change of a Feed's URL.
+func (db *Database) FeedHistoryAdd(m *model.FeedMove) error {
+	const qid query.ID = query.FeedHistoryAdd
 	var (
-		err  error
-		msg  string
-		stmt *sql.Stmt
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
 	)
 
 	if stmt, err = db.getQuery(qid); err != nil {
 		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
 			qid,
 			err.Error())
-		return nil, err
+		return err
 	} else if db.tx != nil {
-		stmt = db.tx.Stmt(stmt)
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
 	}
 
-	var rows *sql.Rows
+	stmt = tx.Stmt(stmt)
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(f.ID, limit, offset); err != nil {
+	if _, err = stmt.Exec(m.FeedID, m.Timestamp.Unix(), m.OldURL.String(), m.NewURL.String(), m.Reason); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
-		}
-
-		return nil, err
-	}
-
-	defer rows.Close() // nolint: errcheck,gosec
-	var items = make([]*model.Item, 0, 16)
-
-	for rows.Next() {
-		var (
-			timestamp int64
-			ustr      string
-			i         = &model.Item{FeedID: f.ID}
-		)
-
-		if err = rows.Scan(&i.ID, &ustr, &timestamp, &i.Headline, &i.Description, &i.Rating); err != nil {
-			msg = fmt.Sprintf("Error scanning row for Feed: %s",
-				err.Error())
-			db.log.Printf("[ERROR] %s\n", msg)
-			return nil, errors.New(msg)
-		} else if i.URL, err = url.Parse(ustr); err != nil {
-			db.log.Printf("[ERROR] Cannot parse URL %q: %s\n",
-				ustr,
+		} else {
+			err = fmt.Errorf("Cannot record move of Feed %d to %s: %s",
+				m.FeedID,
+				m.NewURL,
 				err.Error())
-			return nil, err
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
 		}
-
-		i.Timestamp = time.Unix(timestamp, 0)
-		items = append(items, i)
 	}
 
-	return items, nil
-} // func (db *Database) ItemGetByFeed(f *model.Feed, limit, offset int64) ([]*model.Item, error)
+	status = true
+	return nil
+} // func (db *Database) FeedHistoryAdd(m *model.FeedMove) error
 
-// ItemGetByPeriod loads all Items from the given period
-func (db *Database) ItemGetByPeriod(begin, end time.Time) ([]*model.Item, error) {
-	const qid query.ID = query.ItemGetByPeriod
+// FeedHistoryGetByFeed returns the changes of the given Feed's URL, most
+// recent first.
+func (db *Database) FeedHistoryGetByFeed(f *model.Feed) ([]model.FeedMove, error) {
+	const qid query.ID = query.FeedHistoryGetByFeed
 	var (
 		err  error
 		msg  string
@@ -1522,7 +1696,7 @@ func (db *Database) ItemGetByPeriod(begin, end time.Time) ([]*model.Item, error)
 	var rows *sql.Rows
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(begin.Unix(), end.Unix()); err != nil {
+	if rows, err = stmt.Query(f.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
@@ -1532,37 +1706,47 @@ EXEC_QUERY:
 	}
 
 	defer rows.Close() // nolint: errcheck,gosec
-	var items = make([]*model.Item, 0, 16)
+
+	var history = make([]model.FeedMove, 0, 2)
 
 	for rows.Next() {
 		var (
-			timestamp int64
-			ustr      string
-			i         = new(model.Item)
+			timestamp      int64
+			oldURL, newURL string
+			m              = model.FeedMove{FeedID: f.ID}
 		)
 
-		if err = rows.Scan(&i.ID, &i.FeedID, &ustr, &timestamp, &i.Headline, &i.Description, &i.Rating); err != nil {
-			msg = fmt.Sprintf("Error scanning row for Feed: %s",
+		if err = rows.Scan(&m.ID, &timestamp, &oldURL, &newURL, &m.Reason); err != nil {
+			msg = fmt.Sprintf("Error scanning row for history of Feed %d: %s",
+				f.ID,
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", msg)
 			return nil, errors.New(msg)
-		} else if i.URL, err = url.Parse(ustr); err != nil {
-			db.log.Printf("[ERROR] Cannot parse URL %q: %s\n",
-				ustr,
+		} else if m.OldURL, err = url.Parse(oldURL); err != nil {
+			db.log.Printf("[ERROR] Cannot parse old URL %q of Feed %d: %s\n",
+				oldURL,
+				f.ID,
+				err.Error())
+			return nil, err
+		} else if m.NewURL, err = url.Parse(newURL); err != nil {
+			db.log.Printf("[ERROR] Cannot parse new URL %q of Feed %d: %s\n",
+				newURL,
+				f.ID,
 				err.Error())
 			return nil, err
 		}
 
-		i.Timestamp = time.Unix(timestamp, 0)
-		items = append(items, i)
+		m.Timestamp = time.Unix(timestamp, 0)
+		history = append(history, m)
 	}
 
-	return items, nil
-} // func (db *Database) ItemGetByFeed(f *model.Feed, limit, offset int64) ([]*model.Item, error)
+	return history, nil
+} // func (db *Database) FeedHistoryGetByFeed(f *model.Feed) ([]model.FeedMove, error)
 
-// ItemGetRated loads all items that have been manually rated.
-func (db *Database) ItemGetRated() ([]model.Item, error) {
-	const qid query.ID = query.ItemGetRated
+// FeedGetUnreadCnt returns a map of all Feed IDs and the number of unread
+// Items in each Feed.
+func (db *Database) FeedGetUnreadCnt() (map[int64]int64, error) {
+	const qid query.ID = query.FeedGetUnreadCnt
 	var (
 		err  error
 		msg  string
@@ -1591,105 +1775,100 @@ EXEC_QUERY:
 	}
 
 	defer rows.Close() // nolint: errcheck,gosec
-	var items = make([]model.Item, 0, 16)
+	var feeds = make(map[int64]int64, 16)
 
 	for rows.Next() {
 		var (
-			timestamp int64
-			ustr      string
-			i         model.Item
+			id, cnt int64
 		)
 
-		if err = rows.Scan(&i.ID, &i.FeedID, &ustr, &timestamp, &i.Headline, &i.Description, &i.Rating); err != nil {
+		if err = rows.Scan(&id, &cnt); err != nil {
 			msg = fmt.Sprintf("Error scanning row for Feed: %s",
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", msg)
 			return nil, errors.New(msg)
-		} else if i.URL, err = url.Parse(ustr); err != nil {
-			db.log.Printf("[ERROR] Cannot parse URL %q: %s\n",
-				ustr,
-				err.Error())
-			return nil, err
 		}
 
-		i.Timestamp = time.Unix(timestamp, 0)
-		items = append(items, i)
+		feeds[id] = cnt
 	}
 
-	return items, nil
-} // func (db *Database) ItemGetRated() ([]model.Item, error)
-
-// ItemGetFiltered processes all(!) Items in the database, checks them against the
-// given filter function, and sends the ones that pass in the given channel.
-// When finished, this method closes the channel.
-func (db *Database) ItemGetFiltered(q chan<- *model.Item, filter func(*model.Item) bool) error {
-	const qid query.ID = query.ItemGetAll
+	return feeds, nil
+} // func (db *Database) FeedGetUnreadCnt() (map[int64]int64, error)
 
+// FeedSetRetention sets the number of days we keep the Items of the given
+// Feed. Zero means the global default applies, a negative number means we
+// keep the Items forever.
+func (db *Database) FeedSetRetention(f *model.Feed, days int) error {
+	const qid query.ID = query.FeedSetRetention
 	var (
-		err  error
-		msg  string
-		stmt *sql.Stmt
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
 	)
 
-	defer func() {
-		close(q)
-	}()
-
 	if stmt, err = db.getQuery(qid); err != nil {
 		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
 			qid,
 			err.Error())
 		return err
 	} else if db.tx != nil {
-		stmt = db.tx.Stmt(stmt)
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
 	}
 
-	var rows *sql.Rows
+	stmt = tx.Stmt(stmt)
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(); err != nil {
+	if _, err = stmt.Exec(days, f.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
-		}
-
-		return err
-	}
-
-	defer rows.Close() // nolint: errcheck,gosec
-
-	for rows.Next() {
-		var (
-			timestamp int64
-			ustr      string
-			i         = new(model.Item)
-		)
-
-		if err = rows.Scan(&i.ID, &i.FeedID, &ustr, &timestamp, &i.Headline, &i.Description, &i.Rating); err != nil {
-			msg = fmt.Sprintf("Error scanning row for Feed: %s",
-				err.Error())
-			db.log.Printf("[ERROR] %s\n", msg)
-			return errors.New(msg)
-		} else if i.URL, err = url.Parse(ustr); err != nil {
-			db.log.Printf("[ERROR] Cannot parse URL %q: %s\n",
-				ustr,
+		} else {
+			err = fmt.Errorf("Cannot set retention of Feed %s (%d): %s",
+				f.Title,
+				f.ID,
 				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
 			return err
 		}
-
-		i.Timestamp = time.Unix(timestamp, 0)
-
-		if filter(i) {
-			q <- i
-		}
 	}
 
+	f.Retention = days
+	status = true
 	return nil
-} // func (db *Database) ItemGetFiltered(q chan<-*model.Item, filter func(*model.Item) bool) error
+} // func (db *Database) FeedSetRetention(f *model.Feed, days int) error
 
-// ItemRate sets an Item's rating to the given value
-func (db *Database) ItemRate(i *model.Item, r int8) error {
-	const qid query.ID = query.ItemRate
+// FeedResetFailures clears the failure counter and error message of the given Feed.
+func (db *Database) FeedResetFailures(f *model.Feed) error {
+	const qid query.ID = query.FeedResetFailures
 	var (
 		err    error
 		msg    string
@@ -1706,8 +1885,6 @@ func (db *Database) ItemRate(i *model.Item, r int8) error {
 	} else if db.tx != nil {
 		tx = db.tx
 	} else {
-		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
-		// 	f.Title)
 	BEGIN_AD_HOC:
 		if tx, err = db.db.Begin(); err != nil {
 			if worthARetry(err) {
@@ -1739,27 +1916,29 @@ func (db *Database) ItemRate(i *model.Item, r int8) error {
 	stmt = tx.Stmt(stmt)
 
 EXEC_QUERY:
-	if _, err = stmt.Exec(r, i.ID); err != nil {
+	if _, err = stmt.Exec(f.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		} else {
-			err = fmt.Errorf("Cannot add Item %s to database: %s",
-				i.Headline,
+			err = fmt.Errorf("Cannot reset failure counter for Feed %s: %s",
+				f.Title,
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", err.Error())
 			return err
 		}
 	}
 
-	i.Rating = r
+	f.Failures = 0
+	f.LastError = ""
+	f.NextAttempt = time.Time{}
 	status = true
 	return nil
-} // func (db *Database) ItemRate(i *model.Item, r int64) error
+} // func (db *Database) FeedResetFailures(f *model.Feed) error
 
-// ItemUnrate resets an Item's rating to zero.
-func (db *Database) ItemUnrate(i *model.Item) error {
-	const qid query.ID = query.ItemUnrate
+// FeedSetActive sets the given Feed's Active flag
+func (db *Database) FeedSetActive(f *model.Feed, active bool) error {
+	const qid query.ID = query.FeedSetActive
 	var (
 		err    error
 		msg    string
@@ -1809,27 +1988,28 @@ func (db *Database) ItemUnrate(i *model.Item) error {
 	stmt = tx.Stmt(stmt)
 
 EXEC_QUERY:
-	if _, err = stmt.Query(i.ID); err != nil {
+	if _, err = stmt.Exec(active, f.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		} else {
-			err = fmt.Errorf("Cannot add Item %s to database: %s",
-				i.Headline,
+			err = fmt.Errorf("Cannot add Feed %s to database: %s",
+				f.Title,
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", err.Error())
 			return err
 		}
 	}
 
-	i.Rating = 0
+	f.Active = active
 	status = true
 	return nil
-} // func (db *Database) ItemUnrate(i *model.Item, r int64) error
+} // func (db *Database) FeedSetActive(f *model.Feed, active bool) error
 
-// TagAdd adds a new Tag to the database.
-func (db *Database) TagAdd(t *model.Tag) error {
-	const qid query.ID = query.TagAdd
+// FeedSetFetchFull sets the flag that tells the Reader to fetch the full
+// article text for the given Feed's Items.
+func (db *Database) FeedSetFetchFull(f *model.Feed, full bool) error {
+	const qid query.ID = query.FeedSetFetchFull
 	var (
 		err    error
 		msg    string
@@ -1846,8 +2026,6 @@ func (db *Database) TagAdd(t *model.Tag) error {
 	} else if db.tx != nil {
 		tx = db.tx
 	} else {
-		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
-		// 	f.Title)
 	BEGIN_AD_HOC:
 		if tx, err = db.db.Begin(); err != nil {
 			if worthARetry(err) {
@@ -1877,336 +2055,141 @@ func (db *Database) TagAdd(t *model.Tag) error {
 	}
 
 	stmt = tx.Stmt(stmt)
-	var (
-		rows   *sql.Rows
-		parent *int64
-	)
-
-	if t.Parent != 0 {
-		parent = &t.Parent
-	}
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(t.Name, parent); err != nil {
+	if _, err = stmt.Exec(full, f.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		} else {
-			err = fmt.Errorf("Cannot add Tag %s to database: %s",
-				t.Name,
+			err = fmt.Errorf("Cannot set fetch_full flag on Feed %s: %s",
+				f.Title,
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", err.Error())
 			return err
 		}
-	} else {
-		var id int64
-
-		defer rows.Close()
-
-		if !rows.Next() {
-			// CANTHAPPEN
-			db.log.Printf("[ERROR] Query %s did not return a value\n",
-				qid)
-			return fmt.Errorf("Query %s did not return a value", qid)
-		} else if err = rows.Scan(&id); err != nil {
-			msg = fmt.Sprintf("Failed to get ID for newly added Tag %s: %s",
-				t.Name,
-				err.Error())
-			db.log.Printf("[ERROR] %s\n", msg)
-			return errors.New(msg)
-		}
-
-		t.ID = id
-		status = true
-		return nil
 	}
-} // func (db *Database) TagAdd(t *model.Tag) error
 
-// TagGetByID loads a Tag by its ID
-func (db *Database) TagGetByID(id int64) (*model.Tag, error) {
-	const qid query.ID = query.TagGetByID
+	f.FetchFull = full
+	status = true
+	return nil
+} // func (db *Database) FeedSetFetchFull(f *model.Feed, full bool) error
+
+// FeedSetDownload sets the flag that tells the download manager to fetch the
+// Enclosures of the given Feed's Items.
+func (db *Database) FeedSetDownload(f *model.Feed, download bool) error {
+	const qid query.ID = query.FeedSetDownload
 	var (
-		err  error
-		msg  string
-		stmt *sql.Stmt
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
 	)
 
 	if stmt, err = db.getQuery(qid); err != nil {
 		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
 			qid,
 			err.Error())
-		return nil, err
+		return err
 	} else if db.tx != nil {
-		stmt = db.tx.Stmt(stmt)
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
 	}
 
-	var rows *sql.Rows
+	stmt = tx.Stmt(stmt)
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(id); err != nil {
+	if _, err = stmt.Exec(download, f.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot set download flag for Feed %s (%d): %s",
+				f.Title,
+				f.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
 		}
-
-		return nil, err
 	}
 
-	defer rows.Close() // nolint: errcheck,gosec
+	f.Download = download
+	status = true
+	return nil
+} // func (db *Database) FeedSetDownload(f *model.Feed, download bool) error
 
-	if rows.Next() {
-		var (
-			parent *int64
-			t      = &model.Tag{ID: id}
-		)
+// FeedSetHTTPSettings sets the custom headers and the credentials we send when
+// fetching the given Feed. The secret part of the credentials is encrypted
+// before it is stored.
+func (db *Database) FeedSetHTTPSettings(f *model.Feed, headers map[string]string, auth model.Credentials) error {
+	const qid query.ID = query.FeedSetHTTPSettings
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+		hstr   string
+		secret string
+	)
 
-		if err = rows.Scan(&t.Name, &parent); err != nil {
-			msg = fmt.Sprintf("Error scanning row for Feed: %s",
+	if len(headers) > 0 {
+		var buf []byte
+
+		if buf, err = json.Marshal(headers); err != nil {
+			db.log.Printf("[ERROR] Cannot serialize headers for Feed %s (%d): %s\n",
+				f.Title,
+				f.ID,
 				err.Error())
-			db.log.Printf("[ERROR] %s\n", msg)
-			return nil, errors.New(msg)
-		} else if parent != nil {
-			t.Parent = *parent
+			return err
 		}
 
-		return t, nil
+		hstr = string(buf)
 	}
 
-	return nil, nil
-} // func (db *Database) TagGetByID(id int64) (*model.Tag, error)
-
-// TagGetChildren loads all Tags that are immediate children of the given Tag.
-func (db *Database) TagGetChildren(t *model.Tag) ([]*model.Tag, error) {
-	const qid query.ID = query.TagGetChildren
-	var (
-		err  error
-		msg  string
-		stmt *sql.Stmt
-	)
+	if secret, err = common.Encrypt(auth.Secret); err != nil {
+		db.log.Printf("[ERROR] Cannot encrypt credentials for Feed %s (%d): %s\n",
+			f.Title,
+			f.ID,
+			err.Error())
+		return err
+	}
 
 	if stmt, err = db.getQuery(qid); err != nil {
 		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
 			qid,
 			err.Error())
-		return nil, err
-	} else if db.tx != nil {
-		stmt = db.tx.Stmt(stmt)
-	}
-
-	var rows *sql.Rows
-
-EXEC_QUERY:
-	if rows, err = stmt.Query(); err != nil {
-		if worthARetry(err) {
-			waitForRetry()
-			goto EXEC_QUERY
-		}
-
-		return nil, err
-	}
-
-	defer rows.Close() // nolint: errcheck,gosec
-	var tags = make([]*model.Tag, 0, 16)
-
-	for rows.Next() {
-		var (
-			t = &model.Tag{Parent: t.ID}
-		)
-
-		if err = rows.Scan(&t.ID, &t.Name); err != nil {
-			msg = fmt.Sprintf("Error scanning row for Feed: %s",
-				err.Error())
-			db.log.Printf("[ERROR] %s\n", msg)
-			return nil, errors.New(msg)
-		}
-
-		tags = append(tags, t)
-	}
-
-	return tags, nil
-} // func (db *Database) TagGetChildren(t *model.Tag) ([]*model.Tag, error)
-
-// TagGetAll loads ALL Tags from the database.
-func (db *Database) TagGetAll() ([]*model.Tag, error) {
-	const qid query.ID = query.TagGetAll
-	var (
-		err  error
-		msg  string
-		stmt *sql.Stmt
-	)
-
-	if stmt, err = db.getQuery(qid); err != nil {
-		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
-			qid,
-			err.Error())
-		return nil, err
-	} else if db.tx != nil {
-		stmt = db.tx.Stmt(stmt)
-	}
-
-	var rows *sql.Rows
-
-EXEC_QUERY:
-	if rows, err = stmt.Query(); err != nil {
-		if worthARetry(err) {
-			waitForRetry()
-			goto EXEC_QUERY
-		}
-
-		return nil, err
-	}
-
-	defer rows.Close() // nolint: errcheck,gosec
-	var tags = make([]*model.Tag, 0, 16)
-
-	for rows.Next() {
-		var (
-			parent *int64
-			t      = new(model.Tag)
-		)
-
-		if err = rows.Scan(&t.ID, &parent, &t.Name); err != nil {
-			msg = fmt.Sprintf("Error scanning row for Feed: %s",
-				err.Error())
-			db.log.Printf("[ERROR] %s\n", msg)
-			return nil, errors.New(msg)
-		} else if parent != nil {
-			t.Parent = *parent
-		}
-
-		tags = append(tags, t)
-	}
-
-	return tags, nil
-} // func (db *Database) TagGetAll() ([]*model.Tag, error)
-
-// TagGetSorted returns all Tags, sorted hierarchically.
-func (db *Database) TagGetSorted() ([]*model.Tag, error) {
-	const qid query.ID = query.TagGetSorted
-	var (
-		err  error
-		msg  string
-		stmt *sql.Stmt
-	)
-
-	if stmt, err = db.getQuery(qid); err != nil {
-		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
-			qid,
-			err.Error())
-		return nil, err
-	} else if db.tx != nil {
-		stmt = db.tx.Stmt(stmt)
-	}
-
-	var rows *sql.Rows
-
-EXEC_QUERY:
-	if rows, err = stmt.Query(); err != nil {
-		if worthARetry(err) {
-			waitForRetry()
-			goto EXEC_QUERY
-		}
-
-		return nil, err
-	}
-
-	defer rows.Close() // nolint: errcheck,gosec
-	var tags = make([]*model.Tag, 0, 16)
-
-	for rows.Next() {
-		var (
-			parent *int64
-			t      = new(model.Tag)
-		)
-
-		if err = rows.Scan(&t.ID, &t.Name, &parent, &t.Level, &t.FullName); err != nil {
-			msg = fmt.Sprintf("Error scanning row for Feed: %s",
-				err.Error())
-			db.log.Printf("[ERROR] %s\n", msg)
-			return nil, errors.New(msg)
-		} else if parent != nil {
-			t.Parent = *parent
-		}
-
-		tags = append(tags, t)
-	}
-
-	return tags, nil
-} // func (db *Database) TagGetSorted() ([]*model.Tag, error)
-
-// TagGetItemCnt returns a map of all Tag IDs and the number of Items that have
-// the Tag linked.
-func (db *Database) TagGetItemCnt() (map[int64]int64, error) {
-	const qid query.ID = query.TagGetItemCnt
-	var (
-		err  error
-		msg  string
-		stmt *sql.Stmt
-	)
-
-	if stmt, err = db.getQuery(qid); err != nil {
-		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
-			qid,
-			err.Error())
-		return nil, err
-	} else if db.tx != nil {
-		stmt = db.tx.Stmt(stmt)
-	}
-
-	var rows *sql.Rows
-
-EXEC_QUERY:
-	if rows, err = stmt.Query(); err != nil {
-		if worthARetry(err) {
-			waitForRetry()
-			goto EXEC_QUERY
-		}
-
-		return nil, err
-	}
-
-	defer rows.Close() // nolint: errcheck,gosec
-	var tags = make(map[int64]int64, 16)
-
-	for rows.Next() {
-		var (
-			id, cnt int64
-		)
-
-		if err = rows.Scan(&id, &cnt); err != nil {
-			msg = fmt.Sprintf("Error scanning row for Feed: %s",
-				err.Error())
-			db.log.Printf("[ERROR] %s\n", msg)
-			return nil, errors.New(msg)
-		}
-
-		tags[id] = cnt
-	}
-
-	return tags, nil
-} // func (db *Database) TagGetItemCnt() (map[int64]int64, error)
-
-// TagRename changes a Tag's name.
-func (db *Database) TagRename(t *model.Tag, name string) error {
-	const qid query.ID = query.TagRename
-	var (
-		err    error
-		msg    string
-		stmt   *sql.Stmt
-		tx     *sql.Tx
-		status bool
-	)
-
-	if stmt, err = db.getQuery(qid); err != nil {
-		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
-			qid,
-			err.Error())
-		return err
+		return err
 	} else if db.tx != nil {
 		tx = db.tx
 	} else {
-		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
-		// 	f.Title)
 	BEGIN_AD_HOC:
 		if tx, err = db.db.Begin(); err != nil {
 			if worthARetry(err) {
@@ -2238,28 +2221,30 @@ func (db *Database) TagRename(t *model.Tag, name string) error {
 	stmt = tx.Stmt(stmt)
 
 EXEC_QUERY:
-	if _, err = stmt.Exec(name, t.ID); err != nil {
+	if _, err = stmt.Exec(hstr, auth.Kind, auth.Username, secret, f.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		} else {
-			err = fmt.Errorf("Cannot rename Tag %s to %s: %s",
-				t.Name,
-				name,
+			err = fmt.Errorf("Cannot update HTTP settings of Feed %s (%d): %s",
+				f.Title,
+				f.ID,
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", err.Error())
 			return err
 		}
 	}
 
-	t.Name = name
+	f.Headers = headers
+	f.Auth = auth
 	status = true
 	return nil
-} // func (db *Database) TagRename(t *model.Tag, name string) error
+} // func (db *Database) FeedSetHTTPSettings(f *model.Feed, headers map[string]string, auth model.Credentials) error
 
-// TagSetParent updates a Tag's parent ID.
-func (db *Database) TagSetParent(t *model.Tag, parent int64) error {
-	const qid query.ID = query.TagSetParent
+// FeedSetIntervalBound sets whether the UpdateInterval of the given Feed is
+// a lower or upper bound for its refresh schedule.
+func (db *Database) FeedSetIntervalBound(f *model.Feed, bound model.IntervalBound) error {
+	const qid query.ID = query.FeedSetIntervalBound
 	var (
 		err    error
 		msg    string
@@ -2276,8 +2261,6 @@ func (db *Database) TagSetParent(t *model.Tag, parent int64) error {
 	} else if db.tx != nil {
 		tx = db.tx
 	} else {
-		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
-		// 	f.Title)
 	BEGIN_AD_HOC:
 		if tx, err = db.db.Begin(); err != nil {
 			if worthARetry(err) {
@@ -2309,36 +2292,33 @@ func (db *Database) TagSetParent(t *model.Tag, parent int64) error {
 	stmt = tx.Stmt(stmt)
 
 EXEC_QUERY:
-	if _, err = stmt.Exec(parent, t.ID); err != nil {
+	if _, err = stmt.Exec(bound, f.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		} else {
-			err = fmt.Errorf("Cannot update parent of Tag %s (%d) to %d: %s",
-				t.Name,
-				t.ID,
-				parent,
+			err = fmt.Errorf("Cannot set interval bound of Feed %s: %s",
+				f.Title,
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", err.Error())
 			return err
 		}
 	}
 
-	t.Parent = parent
+	f.Bound = bound
 	status = true
 	return nil
-} // func (db *Database) TagSetParent(t *model.Tag, parent int64) error
+} // func (db *Database) FeedSetIntervalBound(f *model.Feed, bound model.IntervalBound) error
 
-// TagUpdate updates a Tag's name and parent at once.
-func (db *Database) TagUpdate(t *model.Tag, name string, parent int64) error {
-	const qid query.ID = query.TagUpdate
+// FeedSetSchedule stores the refresh schedule of the given Feed.
+func (db *Database) FeedSetSchedule(f *model.Feed, sched model.Schedule) error {
+	const qid query.ID = query.FeedSetSchedule
 	var (
 		err    error
 		msg    string
 		stmt   *sql.Stmt
 		tx     *sql.Tx
 		status bool
-		p      *int64
 	)
 
 	if stmt, err = db.getQuery(qid); err != nil {
@@ -2349,8 +2329,6 @@ func (db *Database) TagUpdate(t *model.Tag, name string, parent int64) error {
 	} else if db.tx != nil {
 		tx = db.tx
 	} else {
-		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
-		// 	f.Title)
 	BEGIN_AD_HOC:
 		if tx, err = db.db.Begin(); err != nil {
 			if worthARetry(err) {
@@ -2381,47 +2359,166 @@ func (db *Database) TagUpdate(t *model.Tag, name string, parent int64) error {
 
 	stmt = tx.Stmt(stmt)
 
-	if parent != 0 {
-		p = &parent
-	}
-
 EXEC_QUERY:
-	if _, err = stmt.Exec(name, p, t.ID); err != nil {
+	if _, err = stmt.Exec(int64(sched.TTL.Seconds()),
+		sched.SkipHours,
+		sched.SkipDays,
+		int64(sched.Cadence.Seconds()),
+		sched.Next.Unix(),
+		f.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		} else {
-			err = fmt.Errorf("Cannot update parent of Tag %s (%d) to %d: %s",
-				t.Name,
-				t.ID,
-				parent,
+			err = fmt.Errorf("Cannot update schedule of Feed %s: %s",
+				f.Title,
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", err.Error())
 			return err
 		}
 	}
 
-	t.Name = name
-	t.Parent = parent
+	f.Schedule = sched
 	status = true
 	return nil
-} // func (db *Database) TagUpdate(t *model.Tag, name string, parent int64) error
+} // func (db *Database) FeedSetSchedule(f *model.Feed, sched model.Schedule) error
 
-// TagDelete removes a Tag from the database.
-func (db *Database) TagDelete(t *model.Tag) error {
-	const qid query.ID = query.TagDelete
-	var (
-		err    error
-		msg    string
-		stmt   *sql.Stmt
-		tx     *sql.Tx
-		status bool
-	)
+// decodeFeedSettings restores a Feed's custom headers and credentials from
+// their database representation. Errors are logged, but not fatal, the Feed
+// is simply fetched without them.
+func (db *Database) decodeFeedSettings(f *model.Feed, headers, secret string) {
+	var err error
 
-	if stmt, err = db.getQuery(qid); err != nil {
-		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
-			qid,
-			err.Error())
+	if headers != "" {
+		if err = json.Unmarshal([]byte(headers), &f.Headers); err != nil {
+			db.log.Printf("[ERROR] Cannot parse headers of Feed %s (%d): %s\n",
+				f.Title,
+				f.ID,
+				err.Error())
+		}
+	}
+
+	if f.Auth.Secret, err = common.Decrypt(secret); err != nil {
+		db.log.Printf("[ERROR] Cannot decrypt credentials of Feed %s (%d): %s\n",
+			f.Title,
+			f.ID,
+			err.Error())
+		f.Auth = model.Credentials{}
+	}
+} // func (db *Database) decodeFeedSettings(f *model.Feed, headers, secret string)
+
+func (db *Database) decodeFeedMeta(f *model.Feed, image string, iconChecked int64) {
+	var err error
+
+	if image != "" {
+		if f.Meta.Image, err = url.Parse(image); err != nil {
+			db.log.Printf("[ERROR] Cannot parse image URL %q of Feed %s (%d): %s\n",
+				image,
+				f.Title,
+				f.ID,
+				err.Error())
+		}
+	}
+
+	if iconChecked != 0 {
+		f.Meta.IconChecked = time.Unix(iconChecked, 0)
+	}
+} // func (db *Database) decodeFeedMeta(f *model.Feed, image string, iconChecked int64)
+
+// readStamp converts the read_at column of an Item to a time.Time. Zero means
+// the Item has not been read, which we represent by the zero Time.
+func readStamp(stamp int64) time.Time {
+	if stamp == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(stamp, 0)
+} // func readStamp(stamp int64) time.Time
+
+// FeedDelete removes the given Feed from the database.
+func (db *Database) FeedDelete(f *model.Feed) error {
+	const qid query.ID = query.FeedDelete
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
+		// 	f.Title)
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(f.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot add Feed %s to database: %s",
+				f.Title,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	status = true
+	return nil
+} // func (db *Database) FeedDelete(f *model.Feed) error
+
+// ItemAdd adds a news item to the database.
+func (db *Database) ItemAdd(i *model.Item) error {
+	const qid query.ID = query.ItemAdd
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
 		return err
 	} else if db.tx != nil {
 		tx = db.tx
@@ -2456,29 +2553,4113 @@ func (db *Database) TagDelete(t *model.Tag) error {
 		}
 	}
 
-	stmt = tx.Stmt(stmt)
+	stmt = tx.Stmt(stmt)
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(i.FeedID, i.URL.String(), i.Timestamp.Unix(), i.Headline, i.Description, i.GUID, common.CanonicalURL(i.URL), i.Categories, i.Authors); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot add Item %s to database: %s",
+				i.Headline,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	} else {
+		var id int64
+
+		defer rows.Close()
+
+		if !rows.Next() {
+			// CANTHAPPEN
+			db.log.Printf("[ERROR] Query %s did not return a value\n",
+				qid)
+			return fmt.Errorf("Query %s did not return a value", qid)
+		} else if err = rows.Scan(&id); err != nil {
+			msg = fmt.Sprintf("Failed to get ID for newly added host %s: %s",
+				i.Headline,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return errors.New(msg)
+		}
+
+		i.ID = id
+		status = true
+		return nil
+	}
+} // func (db *Database) ItemAdd(i *model.Item) error
+
+// ItemUpsert adds a news item to the database, unless an Item with the same
+// URL exists already. It returns true if the Item was added.
+// It is meant to be used within a transaction to add many Items at once.
+func (db *Database) ItemUpsert(i *model.Item) (bool, error) {
+	const qid query.ID = query.ItemUpsert
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return false, err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
+		// 	f.Title)
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return false, errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(i.FeedID, i.URL.String(), i.Timestamp.Unix(), i.Headline, i.Description, i.GUID, common.CanonicalURL(i.URL), i.Categories, i.Authors); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot add Item %s to database: %s",
+				i.Headline,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return false, err
+		}
+	} else {
+		var id int64
+
+		defer rows.Close()
+
+		if !rows.Next() {
+			if err = rows.Err(); err != nil {
+				err = fmt.Errorf("Cannot add Item %s to database: %s",
+					i.Headline,
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", err.Error())
+				return false, err
+			}
+
+			// The Item exists already.
+			status = true
+			return false, nil
+		} else if err = rows.Scan(&id); err != nil {
+			msg = fmt.Sprintf("Failed to get ID for newly added Item %s: %s",
+				i.Headline,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return false, errors.New(msg)
+		}
+
+		i.ID = id
+		status = true
+		return true, nil
+	}
+} // func (db *Database) ItemUpsert(i *model.Item) (bool, error)
+
+// ItemGetPurgeable returns the Items that the retention policy says we can
+// delete: Items older than their Feed's retention period that have not been
+// rated, starred, tagged or found by a search. global is the retention period
+// in days for Feeds that do not have their own, zero means forever.
+// The Items are loaded without Description and Content.
+func (db *Database) ItemGetPurgeable(global int, now time.Time) ([]*model.Item, error) {
+	const qid query.ID = query.ItemGetPurgeable
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(global, now.Unix(), global); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var items = make([]*model.Item, 0, 64)
+
+	for rows.Next() {
+		var (
+			stamp int64
+			ustr  string
+			i     = new(model.Item)
+		)
+
+		if err = rows.Scan(&i.ID, &i.FeedID, &ustr, &stamp, &i.Headline); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Item: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		} else if i.URL, err = url.Parse(ustr); err != nil {
+			db.log.Printf("[ERROR] Invalid URL for Item %q (%d): %s\n\t%s\n",
+				i.Headline,
+				i.ID,
+				err.Error(),
+				ustr)
+			return nil, err
+		}
+
+		i.Timestamp = time.Unix(stamp, 0)
+		items = append(items, i)
+	}
+
+	return items, nil
+} // func (db *Database) ItemGetPurgeable(global int, now time.Time) ([]*model.Item, error)
+
+// ItemPurge deletes an Item the retention policy has selected for removal.
+// Its content, revisions and enclosures go with it. If the user has rated,
+// starred or tagged the Item in the meantime, or it turned up in a search, it
+// is kept and ItemPurge returns false.
+func (db *Database) ItemPurge(i *model.Item) (bool, error) {
+	const qid query.ID = query.ItemPurge
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		res    sql.Result
+		cnt    int64
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return false, err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return false, errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if res, err = stmt.Exec(i.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot purge Item %q (%d): %s",
+				i.Headline,
+				i.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return false, err
+		}
+	}
+
+	if cnt, err = res.RowsAffected(); err != nil {
+		db.log.Printf("[ERROR] Cannot get number of purged Items: %s\n",
+			err.Error())
+		return false, err
+	}
+
+	status = true
+	return cnt > 0, nil
+} // func (db *Database) ItemPurge(i *model.Item) (bool, error)
+
+// ItemDeleteByFeed removes all Items that belong to the given Feed.
+func (db *Database) ItemDeleteByFeed(f *model.Feed) error {
+	const qid query.ID = query.ItemDeleteByFeed
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
+		// 	f.Title)
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(f.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot delete Items from Feed %s (%d): %s",
+				f.Title,
+				f.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	status = true
+	return nil
+} // func (db *Database) ItemDeleteByFeed(f *model.Feed) error
+
+// ItemExists checks if the given Item is already in the database.
+func (db *Database) ItemExists(i *model.Item) (bool, error) {
+	const qid query.ID = query.ItemExists
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return false, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(i.URL.String(), common.CanonicalURL(i.URL), i.FeedID, i.GUID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return false, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if rows.Next() {
+		var cnt int64
+
+		if err = rows.Scan(&cnt); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Feed: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return false, errors.New(msg)
+		}
+
+		return cnt > 0, nil
+	}
+
+	db.log.Printf("[CANTHAPPEN] Query %s did not return any data looking for Item %q\n",
+		qid,
+		i.URL)
+	return false, nil
+} // func (db *Database) ItemExists(i *model.Item) (bool, error)
+
+// ItemGetByKey looks up the Item that the given Item is a duplicate of, that
+// is an Item with the same URL, canonical URL, or GUID (from the same Feed).
+// If no such Item exists, it returns nil.
+func (db *Database) ItemGetByKey(i *model.Item) (*model.Item, error) {
+	const qid query.ID = query.ItemGetByKey
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(i.URL.String(), common.CanonicalURL(i.URL), i.FeedID, i.GUID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if rows.Next() {
+		var (
+			ustr      string
+			timestamp int64
+			item      = new(model.Item)
+		)
+
+		if err = rows.Scan(&item.ID, &item.FeedID, &ustr, &timestamp, &item.Headline, &item.Description, &item.Rating); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Item: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		} else if item.URL, err = url.Parse(ustr); err != nil {
+			db.log.Printf("[ERROR] Cannot parse URL %q: %s\n",
+				ustr,
+				err.Error())
+			return nil, err
+		}
+
+		item.Timestamp = time.Unix(timestamp, 0)
+		return item, nil
+	}
+
+	return nil, nil
+} // func (db *Database) ItemGetByKey(i *model.Item) (*model.Item, error)
+
+// ItemUpdate sets the Headline and Description of the given Item.
+func (db *Database) ItemUpdate(i *model.Item, headline, description string) error {
+	const qid query.ID = query.ItemUpdate
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(headline, description, i.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot update Item %d: %s",
+				i.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	i.Headline = headline
+	i.Description = description
+	status = true
+	return nil
+} // func (db *Database) ItemUpdate(i *model.Item, headline, description string) error
+
+// ItemRevisionAdd saves the current Headline and Description of the given Item
+// as a Revision.
+func (db *Database) ItemRevisionAdd(i *model.Item, stamp time.Time) error {
+	const qid query.ID = query.ItemRevisionAdd
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(i.ID, stamp.Unix(), i.Headline, i.Description); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot save revision of Item %d: %s",
+				i.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	i.Revisions++
+	status = true
+	return nil
+} // func (db *Database) ItemRevisionAdd(i *model.Item, stamp time.Time) error
+
+// ItemRevisionGetByItem loads all Revisions of the given Item, oldest first.
+func (db *Database) ItemRevisionGetByItem(i *model.Item) ([]model.Revision, error) {
+	const qid query.ID = query.ItemRevisionGetByItem
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(i.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var revisions = make([]model.Revision, 0, 4)
+
+	for rows.Next() {
+		var (
+			timestamp int64
+			r         = model.Revision{ItemID: i.ID}
+		)
+
+		if err = rows.Scan(&r.ID, &timestamp, &r.Headline, &r.Description); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Revision of Item %d: %s",
+				i.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		}
+
+		r.Timestamp = time.Unix(timestamp, 0)
+		revisions = append(revisions, r)
+	}
+
+	return revisions, nil
+} // func (db *Database) ItemRevisionGetByItem(i *model.Item) ([]model.Revision, error)
+
+// ItemMergeDuplicates computes the canonical URL of all Items that do not
+// have one, yet, and then merges all Items that share the same canonical URL:
+// The oldest Item is kept, the Tags and the rating of its duplicates are
+// transferred to it, and the duplicates are deleted.
+// It returns the number of Items that were removed.
+func (db *Database) ItemMergeDuplicates() (int64, error) {
+	var (
+		err    error
+		stmt   *sql.Stmt
+		rows   *sql.Rows
+		res    sql.Result
+		cnt    int64
+		status bool
+		canon  = make(map[int64]string)
+	)
+
+	if db.tx == nil {
+		if err = db.Begin(); err != nil {
+			return 0, err
+		}
+
+		defer func() {
+			var err2 error
+			if status {
+				err2 = db.Commit()
+			} else {
+				err2 = db.Rollback()
+			}
+
+			if err2 != nil {
+				db.log.Printf("[ERROR] Failed to finish transaction: %s\n",
+					err2.Error())
+			}
+		}()
+	}
+
+	if stmt, err = db.getQuery(query.ItemGetUncanonical); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			query.ItemGetUncanonical,
+			err.Error())
+		return 0, err
+	}
+
+	stmt = db.tx.Stmt(stmt)
+
+QUERY_UNCANONICAL:
+	if rows, err = stmt.Query(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto QUERY_UNCANONICAL
+		}
+
+		return 0, err
+	}
+
+	for rows.Next() {
+		var (
+			id   int64
+			ustr string
+			u    *url.URL
+		)
+
+		if err = rows.Scan(&id, &ustr); err != nil {
+			rows.Close() // nolint: errcheck
+			db.log.Printf("[ERROR] Cannot scan row: %s\n", err.Error())
+			return 0, err
+		} else if u, err = url.Parse(ustr); err != nil {
+			db.log.Printf("[ERROR] Cannot parse URL %q of Item %d: %s\n",
+				ustr,
+				id,
+				err.Error())
+			continue
+		}
+
+		canon[id] = common.CanonicalURL(u)
+	}
+
+	rows.Close() // nolint: errcheck
+
+	if len(canon) > 0 {
+		db.log.Printf("[INFO] Computing canonical URL for %d Items\n",
+			len(canon))
+
+		if stmt, err = db.getQuery(query.ItemSetCanonical); err != nil {
+			db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+				query.ItemSetCanonical,
+				err.Error())
+			return 0, err
+		}
+
+		stmt = db.tx.Stmt(stmt)
+
+		for id, c := range canon {
+		EXEC_CANONICAL:
+			if _, err = stmt.Exec(c, id); err != nil {
+				if worthARetry(err) {
+					waitForRetry()
+					goto EXEC_CANONICAL
+				}
+
+				db.log.Printf("[ERROR] Cannot set canonical URL of Item %d: %s\n",
+					id,
+					err.Error())
+				return 0, err
+			}
+		}
+	}
+
+	for _, qid := range []query.ID{query.ItemMergeDuplicates, query.ItemMergeRating, query.ItemDeleteDuplicates} {
+		if stmt, err = db.getQuery(qid); err != nil {
+			db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+				qid,
+				err.Error())
+			return 0, err
+		}
+
+		stmt = db.tx.Stmt(stmt)
+
+	EXEC_MERGE:
+		if res, err = stmt.Exec(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto EXEC_MERGE
+			}
+
+			db.log.Printf("[ERROR] Failed to execute query %s: %s\n",
+				qid,
+				err.Error())
+			return 0, err
+		}
+	}
+
+	if cnt, err = res.RowsAffected(); err != nil {
+		db.log.Printf("[ERROR] Cannot get number of deleted Items: %s\n",
+			err.Error())
+		return 0, err
+	}
+
+	status = true
+	return cnt, nil
+} // func (db *Database) ItemMergeDuplicates() (int64, error)
+
+// ItemGetRecent loads all items newer than the given timestamp.
+func (db *Database) ItemGetRecent(begin time.Time) ([]*model.Item, error) {
+	const qid query.ID = query.ItemGetRecent
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(begin.Unix()); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+	var items = make([]*model.Item, 0, 16)
+
+	for rows.Next() {
+		var (
+			readAt    int64
+			timestamp int64
+			ustr      string
+			i         = new(model.Item)
+		)
+
+		if err = rows.Scan(&i.ID, &i.FeedID, &ustr, &timestamp, &i.Headline, &i.Description, &i.Categories, &i.Authors, &i.Rating, &readAt, &i.Starred, &i.Content, &i.Revisions); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Feed: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		} else if i.URL, err = url.Parse(ustr); err != nil {
+			db.log.Printf("[ERROR] Cannot parse URL %q: %s\n",
+				ustr,
+				err.Error())
+			return nil, err
+		}
+
+		i.Timestamp = time.Unix(timestamp, 0)
+		i.ReadAt = readStamp(readAt)
+		items = append(items, i)
+	}
+
+	return items, nil
+} // func (db *Database) ItemGetRecent(begin time.Time) ([]model.Item, error)
+
+// ItemGetRecentPaged fetches up to cnt of the most recent news items, skipping the first offset items,
+// in descending chronological order. The filter can restrict the result to unread and/or starred Items.
+func (db *Database) ItemGetRecentPaged(cnt, offset int64, filter model.ItemFilter) ([]*model.Item, error) {
+	const qid query.ID = query.ItemGetRecentPaged
+	var (
+		err   error
+		msg   string
+		stmt  *sql.Stmt
+		rsize int64
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(filter.Unread, filter.Starred, cnt, offset); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	if cnt < 0 {
+		rsize = 16
+	} else {
+		rsize = cnt
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+	var items = make([]*model.Item, 0, rsize)
+
+	for rows.Next() {
+		var (
+			readAt    int64
+			timestamp int64
+			ustr      string
+			i         = new(model.Item)
+		)
+
+		if err = rows.Scan(&i.ID, &i.FeedID, &ustr, &timestamp, &i.Headline, &i.Description, &i.Categories, &i.Authors, &i.Rating, &readAt, &i.Starred, &i.Content, &i.Revisions); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Feed: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		} else if i.URL, err = url.Parse(ustr); err != nil {
+			db.log.Printf("[ERROR] Cannot parse URL %q: %s\n",
+				ustr,
+				err.Error())
+			return nil, err
+		}
+
+		i.Timestamp = time.Unix(timestamp, 0)
+		i.ReadAt = readStamp(readAt)
+		items = append(items, i)
+	}
+
+	return items, nil
+} // func (db *Database) ItemGetRecentPaged(cnt, offset int64, filter model.ItemFilter) ([]model.Item, error)
+
+// ItemGetByID loads an Item by its ID
+func (db *Database) ItemGetByID(id int64) (*model.Item, error) {
+	const qid query.ID = query.ItemGetByID
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(id); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if rows.Next() {
+		var (
+			readAt    int64
+			timestamp int64
+			ustr      string
+			i         = &model.Item{ID: id}
+		)
+
+		if err = rows.Scan(&i.FeedID, &ustr, &timestamp, &i.Headline, &i.Description, &i.Categories, &i.Authors, &i.Rating, &readAt, &i.Starred, &i.Content, &i.Revisions); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Feed: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		} else if i.URL, err = url.Parse(ustr); err != nil {
+			db.log.Printf("[ERROR] Cannot parse URL %q: %s\n",
+				ustr,
+				err.Error())
+			return nil, err
+		}
+
+		i.Timestamp = time.Unix(timestamp, 0)
+		i.ReadAt = readStamp(readAt)
+		return i, nil
+	}
+
+	return nil, nil
+} // func (db *Database) ItemGetByID(id int64) (*model.Item, error)
+
+// ItemGetByFeed loads items from the given Feed, restricted by the filter.
+func (db *Database) ItemGetByFeed(f *model.Feed, limit, offset int64, filter model.ItemFilter) ([]*model.Item, error) {
+	const qid query.ID = query.ItemGetByFeed
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(f.ID, filter.Unread, filter.Starred, limit, offset); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+	var items = make([]*model.Item, 0, 16)
+
+	for rows.Next() {
+		var (
+			readAt    int64
+			timestamp int64
+			ustr      string
+			i         = &model.Item{FeedID: f.ID}
+		)
+
+		if err = rows.Scan(&i.ID, &ustr, &timestamp, &i.Headline, &i.Description, &i.Categories, &i.Authors, &i.Rating, &readAt, &i.Starred, &i.Content, &i.Revisions); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Feed: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		} else if i.URL, err = url.Parse(ustr); err != nil {
+			db.log.Printf("[ERROR] Cannot parse URL %q: %s\n",
+				ustr,
+				err.Error())
+			return nil, err
+		}
+
+		i.Timestamp = time.Unix(timestamp, 0)
+		i.ReadAt = readStamp(readAt)
+		items = append(items, i)
+	}
+
+	return items, nil
+} // func (db *Database) ItemGetByFeed(f *model.Feed, limit, offset int64, filter model.ItemFilter) ([]*model.Item, error)
+
+// ItemGetByPeriod loads all Items from the given period
+func (db *Database) ItemGetByPeriod(begin, end time.Time) ([]*model.Item, error) {
+	const qid query.ID = query.ItemGetByPeriod
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(begin.Unix(), end.Unix()); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+	var items = make([]*model.Item, 0, 16)
+
+	for rows.Next() {
+		var (
+			readAt    int64
+			timestamp int64
+			ustr      string
+			i         = new(model.Item)
+		)
+
+		if err = rows.Scan(&i.ID, &i.FeedID, &ustr, &timestamp, &i.Headline, &i.Description, &i.Categories, &i.Authors, &i.Rating, &readAt, &i.Starred, &i.Content, &i.Revisions); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Feed: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		} else if i.URL, err = url.Parse(ustr); err != nil {
+			db.log.Printf("[ERROR] Cannot parse URL %q: %s\n",
+				ustr,
+				err.Error())
+			return nil, err
+		}
+
+		i.Timestamp = time.Unix(timestamp, 0)
+		i.ReadAt = readStamp(readAt)
+		items = append(items, i)
+	}
+
+	return items, nil
+} // func (db *Database) ItemGetByFeed(f *model.Feed, limit, offset int64, filter model.ItemFilter) ([]*model.Item, error)
+
+// ItemGetRated loads all items that have been manually rated.
+func (db *Database) ItemGetRated() ([]model.Item, error) {
+	const qid query.ID = query.ItemGetRated
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+	var items = make([]model.Item, 0, 16)
+
+	for rows.Next() {
+		var (
+			readAt    int64
+			timestamp int64
+			ustr      string
+			i         model.Item
+		)
+
+		if err = rows.Scan(&i.ID, &i.FeedID, &ustr, &timestamp, &i.Headline, &i.Description, &i.Categories, &i.Authors, &i.Rating, &readAt, &i.Starred, &i.Content, &i.Revisions); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Feed: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		} else if i.URL, err = url.Parse(ustr); err != nil {
+			db.log.Printf("[ERROR] Cannot parse URL %q: %s\n",
+				ustr,
+				err.Error())
+			return nil, err
+		}
+
+		i.Timestamp = time.Unix(timestamp, 0)
+		i.ReadAt = readStamp(readAt)
+		items = append(items, i)
+	}
+
+	return items, nil
+} // func (db *Database) ItemGetRated() ([]model.Item, error)
+
+// ItemGetFiltered processes all(!) Items in the database, checks them against the
+// given filter function, and sends the ones that pass in the given channel.
+// When finished, this method closes the channel.
+func (db *Database) ItemGetFiltered(q chan<- *model.Item, filter func(*model.Item) bool) error {
+	const qid query.ID = query.ItemGetAll
+
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	defer func() {
+		close(q)
+	}()
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	for rows.Next() {
+		var (
+			readAt    int64
+			timestamp int64
+			ustr      string
+			i         = new(model.Item)
+		)
+
+		if err = rows.Scan(&i.ID, &i.FeedID, &ustr, &timestamp, &i.Headline, &i.Description, &i.Categories, &i.Authors, &i.Rating, &readAt, &i.Starred, &i.Content, &i.Revisions); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Feed: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return errors.New(msg)
+		} else if i.URL, err = url.Parse(ustr); err != nil {
+			db.log.Printf("[ERROR] Cannot parse URL %q: %s\n",
+				ustr,
+				err.Error())
+			return err
+		}
+
+		i.Timestamp = time.Unix(timestamp, 0)
+		i.ReadAt = readStamp(readAt)
+
+		if filter(i) {
+			q <- i
+		}
+	}
+
+	return nil
+} // func (db *Database) ItemGetFiltered(q chan<-*model.Item, filter func(*model.Item) bool) error
+
+// ItemContentAdd stores the full text of the article the given Item links to.
+// If content for the Item already exists, it is replaced.
+func (db *Database) ItemContentAdd(i *model.Item, content string) error {
+	const qid query.ID = query.ItemContentAdd
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(i.ID, time.Now().Unix(), content); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot store content of Item %d: %s",
+				i.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	i.Content = content
+	status = true
+	return nil
+} // func (db *Database) ItemContentAdd(i *model.Item, content string) error
+
+// ItemContentGet loads the full article text for the given Item, if any.
+func (db *Database) ItemContentGet(i *model.Item) (string, error) {
+	const qid query.ID = query.ItemContentGet
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return "", err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(i.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return "", err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if rows.Next() {
+		var content string
+
+		if err = rows.Scan(&content); err != nil {
+			msg = fmt.Sprintf("Error scanning content of Item %d: %s",
+				i.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return "", errors.New(msg)
+		}
+
+		return content, nil
+	}
+
+	return "", nil
+} // func (db *Database) ItemContentGet(i *model.Item) (string, error)
+
+// ItemRate sets an Item's rating to the given value
+func (db *Database) ItemRate(i *model.Item, r int8) error {
+	const qid query.ID = query.ItemRate
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
+		// 	f.Title)
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(r, i.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot add Item %s to database: %s",
+				i.Headline,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	i.Rating = r
+	status = true
+	return nil
+} // func (db *Database) ItemRate(i *model.Item, r int64) error
+
+// ItemUnrate resets an Item's rating to zero.
+func (db *Database) ItemUnrate(i *model.Item) error {
+	const qid query.ID = query.ItemUnrate
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
+		// 	f.Title)
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(i.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot add Item %s to database: %s",
+				i.Headline,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	i.Rating = 0
+	status = true
+	return nil
+} // func (db *Database) ItemUnrate(i *model.Item, r int64) error
+
+// ItemMarkRead marks an Item as read. If the Item has been read before, the
+// time it was first read is kept.
+func (db *Database) ItemMarkRead(i *model.Item) error {
+	var (
+		err   error
+		cnt   int64
+		stamp = time.Now()
+	)
+
+	if cnt, err = db.itemSetState(query.ItemMarkRead, stamp.Unix(), i.ID); err != nil {
+		db.log.Printf("[ERROR] Cannot mark Item %q (%d) as read: %s\n",
+			i.Headline,
+			i.ID,
+			err.Error())
+		return err
+	} else if cnt > 0 {
+		i.ReadAt = time.Unix(stamp.Unix(), 0)
+	}
+
+	return nil
+} // func (db *Database) ItemMarkRead(i *model.Item) error
+
+// ItemMarkUnread marks an Item as not read.
+func (db *Database) ItemMarkUnread(i *model.Item) error {
+	var err error
+
+	if _, err = db.itemSetState(query.ItemMarkUnread, i.ID); err != nil {
+		db.log.Printf("[ERROR] Cannot mark Item %q (%d) as unread: %s\n",
+			i.Headline,
+			i.ID,
+			err.Error())
+		return err
+	}
+
+	i.ReadAt = time.Time{}
+	return nil
+} // func (db *Database) ItemMarkUnread(i *model.Item) error
+
+// ItemMarkReadList marks the Items with the given IDs as read. It returns the
+// number of Items that had not been read before.
+func (db *Database) ItemMarkReadList(ids []int64) (int64, error) {
+	var (
+		err  error
+		cnt  int64
+		jbuf []byte
+	)
+
+	if len(ids) == 0 {
+		return 0, nil
+	} else if jbuf, err = json.Marshal(ids); err != nil {
+		db.log.Printf("[ERROR] Cannot serialize list of %d Item IDs: %s\n",
+			len(ids),
+			err.Error())
+		return 0, err
+	} else if cnt, err = db.itemSetState(query.ItemMarkReadList, time.Now().Unix(), string(jbuf)); err != nil {
+		db.log.Printf("[ERROR] Cannot mark %d Items as read: %s\n",
+			len(ids),
+			err.Error())
+		return 0, err
+	}
+
+	return cnt, nil
+} // func (db *Database) ItemMarkReadList(ids []int64) (int64, error)
+
+// ItemMarkReadAbove marks the given Item and all Items that are newer than it
+// as read, that is, all Items that are shown above it in a list. If f is not
+// nil, only Items from that Feed are marked. It returns the number of Items
+// that had not been read before.
+func (db *Database) ItemMarkReadAbove(i *model.Item, f *model.Feed) (int64, error) {
+	var (
+		err    error
+		cnt    int64
+		feedID int64
+	)
+
+	if f != nil {
+		feedID = f.ID
+	}
+
+	if cnt, err = db.itemSetState(query.ItemMarkReadAbove, time.Now().Unix(), i.ID, feedID, feedID); err != nil {
+		db.log.Printf("[ERROR] Cannot mark Items above %q (%d) as read: %s\n",
+			i.Headline,
+			i.ID,
+			err.Error())
+		return 0, err
+	}
+
+	return cnt, nil
+} // func (db *Database) ItemMarkReadAbove(i *model.Item, f *model.Feed) (int64, error)
+
+// ItemSetStarred sets or clears the Starred flag of an Item.
+func (db *Database) ItemSetStarred(i *model.Item, starred bool) error {
+	var err error
+
+	if _, err = db.itemSetState(query.ItemSetStarred, starred, i.ID); err != nil {
+		db.log.Printf("[ERROR] Cannot set starred flag of Item %q (%d) to %t: %s\n",
+			i.Headline,
+			i.ID,
+			starred,
+			err.Error())
+		return err
+	}
+
+	i.Starred = starred
+	return nil
+} // func (db *Database) ItemSetStarred(i *model.Item, starred bool) error
+
+// itemSetState executes one of the queries that change the read or starred
+// state of Items and returns the number of rows it changed.
+func (db *Database) itemSetState(qid query.ID, args ...any) (int64, error) {
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		res    sql.Result
+		cnt    int64
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return 0, err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return 0, errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if res, err = stmt.Exec(args...); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return 0, err
+	} else if cnt, err = res.RowsAffected(); err != nil {
+		db.log.Printf("[ERROR] Cannot get number of rows affected by %s: %s\n",
+			qid,
+			err.Error())
+		return 0, err
+	}
+
+	status = true
+	return cnt, nil
+} // func (db *Database) itemSetState(qid query.ID, args ...any) (int64, error)
+
+// EnclosureAdd adds an Enclosure to the database.
+func (db *Database) EnclosureAdd(e *model.Enclosure) error {
+	const qid query.ID = query.EnclosureAdd
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(e.ItemID, e.URL.String(), e.MimeType, e.Length); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot add Enclosure %s to database: %s",
+				e.URL,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	} else {
+		var id int64
+
+		defer rows.Close()
+
+		if !rows.Next() {
+			// CANTHAPPEN
+			db.log.Printf("[ERROR] Query %s did not return a value\n",
+				qid)
+			return fmt.Errorf("Query %s did not return a value", qid)
+		} else if err = rows.Scan(&id); err != nil {
+			msg = fmt.Sprintf("Failed to get ID for newly added Enclosure %s: %s",
+				e.URL,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return errors.New(msg)
+		}
+
+		e.ID = id
+		status = true
+		return nil
+	}
+} // func (db *Database) EnclosureAdd(e *model.Enclosure) error
+
+// EnclosureGetByID loads an Enclosure by its ID.
+func (db *Database) EnclosureGetByID(id int64) (*model.Enclosure, error) {
+	const qid query.ID = query.EnclosureGetByID
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(id); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if rows.Next() {
+		var (
+			ustr       string
+			downloaded int64
+			e          = &model.Enclosure{ID: id}
+		)
+
+		if err = rows.Scan(&e.ItemID, &ustr, &e.MimeType, &e.Length, &e.Path, &e.Size, &downloaded, &e.Attempts, &e.Expired); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Enclosure %d: %s",
+				id,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		} else if e.URL, err = url.Parse(ustr); err != nil {
+			msg = fmt.Sprintf("Cannot parse URL of Enclosure %d (%q): %s",
+				id,
+				ustr,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		}
+
+		if downloaded != 0 {
+			e.Downloaded = time.Unix(downloaded, 0)
+		}
+
+		return e, nil
+	}
+
+	return nil, nil
+} // func (db *Database) EnclosureGetByID(id int64) (*model.Enclosure, error)
+
+// EnclosureGetByItem loads all Enclosures of the given Item.
+func (db *Database) EnclosureGetByItem(i *model.Item) ([]*model.Enclosure, error) {
+	const qid query.ID = query.EnclosureGetByItem
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(i.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var list = make([]*model.Enclosure, 0, 1)
+
+	for rows.Next() {
+		var (
+			ustr       string
+			downloaded int64
+			e          = &model.Enclosure{ItemID: i.ID}
+		)
+
+		if err = rows.Scan(&e.ID, &ustr, &e.MimeType, &e.Length, &e.Path, &e.Size, &downloaded, &e.Attempts, &e.Expired); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Enclosure of Item %d: %s",
+				i.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		} else if e.URL, err = url.Parse(ustr); err != nil {
+			msg = fmt.Sprintf("Cannot parse URL of Enclosure %d (%q): %s",
+				e.ID,
+				ustr,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		}
+
+		if downloaded != 0 {
+			e.Downloaded = time.Unix(downloaded, 0)
+		}
+
+		list = append(list, e)
+	}
+
+	return list, nil
+} // func (db *Database) EnclosureGetByItem(i *model.Item) ([]*model.Enclosure, error)
+
+// EnclosureGetPending returns up to max Enclosures of Feeds with downloads
+// enabled that have not been downloaded, yet, and that have failed less than
+// maxAttempts times. Enclosures of the most recent Items come first.
+func (db *Database) EnclosureGetPending(maxAttempts, max int) ([]*model.Enclosure, error) {
+	return db.enclosureGetList(query.EnclosureGetPending, maxAttempts, max)
+} // func (db *Database) EnclosureGetPending(maxAttempts, max int) ([]*model.Enclosure, error)
+
+// EnclosureGetDownloaded returns all Enclosures that are currently stored
+// locally, oldest download first.
+func (db *Database) EnclosureGetDownloaded() ([]*model.Enclosure, error) {
+	return db.enclosureGetList(query.EnclosureGetDownloaded)
+} // func (db *Database) EnclosureGetDownloaded() ([]*model.Enclosure, error)
+
+func (db *Database) enclosureGetList(qid query.ID, args ...any) ([]*model.Enclosure, error) {
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(args...); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var list = make([]*model.Enclosure, 0, 16)
+
+	for rows.Next() {
+		var (
+			ustr       string
+			downloaded int64
+			e          = new(model.Enclosure)
+		)
+
+		if err = rows.Scan(&e.ID, &e.ItemID, &ustr, &e.MimeType, &e.Length, &e.Path, &e.Size, &downloaded, &e.Attempts, &e.Expired); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Enclosure: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		} else if e.URL, err = url.Parse(ustr); err != nil {
+			msg = fmt.Sprintf("Cannot parse URL of Enclosure %d (%q): %s",
+				e.ID,
+				ustr,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		}
+
+		if downloaded != 0 {
+			e.Downloaded = time.Unix(downloaded, 0)
+		}
+
+		list = append(list, e)
+	}
+
+	return list, nil
+} // func (db *Database) enclosureGetList(qid query.ID, args ...any) ([]*model.Enclosure, error)
+
+// EnclosureGetTotalSize returns the number of bytes used by all downloaded
+// Enclosures.
+func (db *Database) EnclosureGetTotalSize() (int64, error) {
+	const qid query.ID = query.EnclosureGetTotalSize
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return 0, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return 0, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var size int64
+
+	if rows.Next() {
+		if err = rows.Scan(&size); err != nil {
+			db.log.Printf("[ERROR] Cannot scan total size of Enclosures: %s\n",
+				err.Error())
+			return 0, err
+		}
+	}
+
+	return size, nil
+} // func (db *Database) EnclosureGetTotalSize() (int64, error)
+
+// EnclosureSetDownloaded records that the given Enclosure has been saved
+// to the given path.
+func (db *Database) EnclosureSetDownloaded(e *model.Enclosure, path string, size int64, stamp time.Time) error {
+	const qid query.ID = query.EnclosureSetDownloaded
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(path, size, stamp.Unix(), e.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot mark Enclosure %d as downloaded: %s",
+				e.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	e.Path = path
+	e.Size = size
+	e.Downloaded = stamp
+	status = true
+	return nil
+} // func (db *Database) EnclosureSetDownloaded(e *model.Enclosure, path string, size int64, stamp time.Time) error
+
+// EnclosureRecordFailure increments the number of failed attempts to download
+// the given Enclosure.
+func (db *Database) EnclosureRecordFailure(e *model.Enclosure) error {
+	const qid query.ID = query.EnclosureRecordFailure
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(e.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot record failed download of Enclosure %d: %s",
+				e.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	e.Attempts++
+	status = true
+	return nil
+} // func (db *Database) EnclosureRecordFailure(e *model.Enclosure) error
+
+// EnclosureExpire marks the given Enclosure as expired, i.e. its file has
+// been removed and it will not be downloaded again.
+func (db *Database) EnclosureExpire(e *model.Enclosure) error {
+	const qid query.ID = query.EnclosureExpire
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(e.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot expire Enclosure %d: %s",
+				e.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	e.Expired = true
+	e.Path = ""
+	e.Size = 0
+	status = true
+	return nil
+} // func (db *Database) EnclosureExpire(e *model.Enclosure) error
+
+// WebSubAdd adds a Subscription to the database. If the Feed already has a
+// Subscription, it is replaced. Unless the hub or topic have changed, it keeps
+// its state while we wait for the hub to confirm the renewal.
+func (db *Database) WebSubAdd(s *model.Subscription) error {
+	const qid query.ID = query.WebSubAdd
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+		secret string
+	)
+
+	if secret, err = common.Encrypt(s.Secret); err != nil {
+		db.log.Printf("[ERROR] Cannot encrypt secret of Subscription for Feed %d: %s\n",
+			s.FeedID,
+			err.Error())
+		return err
+	}
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(s.FeedID, s.Hub.String(), s.Topic.String(), secret, s.Requested.Unix(), s.LeaseExpires.Unix()); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot add Subscription for Feed %d to database: %s",
+				s.FeedID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	} else {
+		var (
+			id    int64
+			state model.SubState
+		)
+
+		defer rows.Close()
+
+		if !rows.Next() {
+			// CANTHAPPEN
+			db.log.Printf("[ERROR] Query %s did not return a value\n",
+				qid)
+			return fmt.Errorf("Query %s did not return a value", qid)
+		} else if err = rows.Scan(&id, &state); err != nil {
+			msg = fmt.Sprintf("Failed to get ID for newly added Subscription for Feed %d: %s",
+				s.FeedID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return errors.New(msg)
+		}
+
+		s.ID = id
+		s.State = state
+		status = true
+		return nil
+	}
+} // func (db *Database) WebSubAdd(s *model.Subscription) error
+
+// WebSubGetByFeed loads the Subscription for the given Feed, if there is one.
+func (db *Database) WebSubGetByFeed(f *model.Feed) (*model.Subscription, error) {
+	const qid query.ID = query.WebSubGetByFeed
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(f.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if rows.Next() {
+		var (
+			hub, topic, secret         string
+			requested, lease, lastPush int64
+			s                          = &model.Subscription{FeedID: f.ID}
+		)
+
+		if err = rows.Scan(&s.ID, &hub, &topic, &secret, &s.State, &requested, &lease, &lastPush); err != nil {
+			err = fmt.Errorf("Error scanning row for Subscription of Feed %d: %s",
+				f.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return nil, err
+		} else if err = db.decodeSubscription(s, hub, topic, secret, requested, lease, lastPush); err != nil {
+			return nil, err
+		}
+
+		return s, nil
+	}
+
+	return nil, nil
+} // func (db *Database) WebSubGetByFeed(f *model.Feed) (*model.Subscription, error)
+
+// WebSubGetRenewable returns all active Subscriptions whose lease expires
+// before the given time, and all pending Subscriptions, as long as they were
+// last requested before the given deadline.
+func (db *Database) WebSubGetRenewable(expires, deadline time.Time) ([]*model.Subscription, error) {
+	const qid query.ID = query.WebSubGetRenewable
+	var (
+		err  error
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(expires.Unix(), deadline.Unix()); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var list = make([]*model.Subscription, 0)
+
+	for rows.Next() {
+		var (
+			hub, topic, secret         string
+			requested, lease, lastPush int64
+			s                          = new(model.Subscription)
+		)
+
+		if err = rows.Scan(&s.ID, &s.FeedID, &hub, &topic, &secret, &s.State, &requested, &lease, &lastPush); err != nil {
+			err = fmt.Errorf("Error scanning row for Subscription: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return nil, err
+		} else if err = db.decodeSubscription(s, hub, topic, secret, requested, lease, lastPush); err != nil {
+			return nil, err
+		}
+
+		list = append(list, s)
+	}
+
+	return list, nil
+} // func (db *Database) WebSubGetRenewable(expires, deadline time.Time) ([]*model.Subscription, error)
+
+// decodeSubscription fills in the fields of a Subscription from their
+// database representation.
+func (db *Database) decodeSubscription(s *model.Subscription, hub, topic, secret string, requested, lease, lastPush int64) error {
+	var err error
+
+	if s.Hub, err = url.Parse(hub); err != nil {
+		err = fmt.Errorf("Cannot parse hub URL of Subscription %d (%q): %s",
+			s.ID,
+			hub,
+			err.Error())
+		db.log.Printf("[ERROR] %s\n", err.Error())
+		return err
+	} else if s.Topic, err = url.Parse(topic); err != nil {
+		err = fmt.Errorf("Cannot parse topic URL of Subscription %d (%q): %s",
+			s.ID,
+			topic,
+			err.Error())
+		db.log.Printf("[ERROR] %s\n", err.Error())
+		return err
+	} else if s.Secret, err = common.Decrypt(secret); err != nil {
+		err = fmt.Errorf("Cannot decrypt secret of Subscription %d: %s",
+			s.ID,
+			err.Error())
+		db.log.Printf("[ERROR] %s\n", err.Error())
+		return err
+	}
+
+	s.Requested = time.Unix(requested, 0)
+
+	if lease != 0 {
+		s.LeaseExpires = time.Unix(lease, 0)
+	}
+
+	if lastPush != 0 {
+		s.LastPush = time.Unix(lastPush, 0)
+	}
+
+	return nil
+} // func (db *Database) decodeSubscription(s *model.Subscription, hub, topic, secret string, requested, lease, lastPush int64) error
+
+// WebSubSetActive marks a Subscription as confirmed by the hub, with the lease
+// expiring at the given time.
+func (db *Database) WebSubSetActive(s *model.Subscription, expires time.Time) error {
+	const qid query.ID = query.WebSubSetActive
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(expires.Unix(), s.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot activate Subscription %d: %s",
+				s.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	s.State = model.SubActive
+	s.LeaseExpires = expires
+	status = true
+	return nil
+} // func (db *Database) WebSubSetActive(s *model.Subscription, expires time.Time) error
+
+// WebSubSetState sets the state of a Subscription.
+func (db *Database) WebSubSetState(s *model.Subscription, state model.SubState) error {
+	const qid query.ID = query.WebSubSetState
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(state, s.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot set state of Subscription %d to %s: %s",
+				s.ID,
+				state,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	s.State = state
+	status = true
+	return nil
+} // func (db *Database) WebSubSetState(s *model.Subscription, state model.SubState) error
+
+// WebSubSetLastPush records the time the hub last pushed an update to us.
+func (db *Database) WebSubSetLastPush(s *model.Subscription, stamp time.Time) error {
+	const qid query.ID = query.WebSubSetLastPush
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(stamp.Unix(), s.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot update timestamp of last push for Subscription %d: %s",
+				s.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	s.LastPush = stamp
+	status = true
+	return nil
+} // func (db *Database) WebSubSetLastPush(s *model.Subscription, stamp time.Time) error
+
+// WebSubDelete removes a Subscription from the database.
+func (db *Database) WebSubDelete(s *model.Subscription) error {
+	const qid query.ID = query.WebSubDelete
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(s.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot delete Subscription %d: %s",
+				s.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	status = true
+	return nil
+} // func (db *Database) WebSubDelete(s *model.Subscription) error
+
+// WebSubDeleteByFeed deletes the Subscription of the given Feed, if there is one.
+func (db *Database) WebSubDeleteByFeed(f *model.Feed) error {
+	const qid query.ID = query.WebSubDeleteByFeed
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(f.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot delete Subscription of Feed %s: %s",
+				f.Title,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	status = true
+	return nil
+} // func (db *Database) WebSubDeleteByFeed(f *model.Feed) error
+
+// TagAdd adds a new Tag to the database.
+func (db *Database) TagAdd(t *model.Tag) error {
+	const qid query.ID = query.TagAdd
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
+		// 	f.Title)
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+	var (
+		rows   *sql.Rows
+		parent *int64
+	)
+
+	if t.Parent != 0 {
+		parent = &t.Parent
+	}
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(t.Name, parent); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot add Tag %s to database: %s",
+				t.Name,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	} else {
+		var id int64
+
+		defer rows.Close()
+
+		if !rows.Next() {
+			// CANTHAPPEN
+			db.log.Printf("[ERROR] Query %s did not return a value\n",
+				qid)
+			return fmt.Errorf("Query %s did not return a value", qid)
+		} else if err = rows.Scan(&id); err != nil {
+			msg = fmt.Sprintf("Failed to get ID for newly added Tag %s: %s",
+				t.Name,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return errors.New(msg)
+		}
+
+		t.ID = id
+		status = true
+		return nil
+	}
+} // func (db *Database) TagAdd(t *model.Tag) error
+
+// TagGetByID loads a Tag by its ID
+func (db *Database) TagGetByID(id int64) (*model.Tag, error) {
+	const qid query.ID = query.TagGetByID
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(id); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	if rows.Next() {
+		var (
+			parent *int64
+			t      = &model.Tag{ID: id}
+		)
+
+		if err = rows.Scan(&t.Name, &parent); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Feed: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		} else if parent != nil {
+			t.Parent = *parent
+		}
+
+		return t, nil
+	}
+
+	return nil, nil
+} // func (db *Database) TagGetByID(id int64) (*model.Tag, error)
+
+// TagGetChildren loads all Tags that are immediate children of the given Tag.
+func (db *Database) TagGetChildren(t *model.Tag) ([]*model.Tag, error) {
+	const qid query.ID = query.TagGetChildren
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+	var tags = make([]*model.Tag, 0, 16)
+
+	for rows.Next() {
+		var (
+			t = &model.Tag{Parent: t.ID}
+		)
+
+		if err = rows.Scan(&t.ID, &t.Name); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Feed: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		}
+
+		tags = append(tags, t)
+	}
+
+	return tags, nil
+} // func (db *Database) TagGetChildren(t *model.Tag) ([]*model.Tag, error)
+
+// TagGetAll loads ALL Tags from the database.
+func (db *Database) TagGetAll() ([]*model.Tag, error) {
+	const qid query.ID = query.TagGetAll
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+	var tags = make([]*model.Tag, 0, 16)
+
+	for rows.Next() {
+		var (
+			parent *int64
+			t      = new(model.Tag)
+		)
+
+		if err = rows.Scan(&t.ID, &parent, &t.Name); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Feed: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		} else if parent != nil {
+			t.Parent = *parent
+		}
+
+		tags = append(tags, t)
+	}
+
+	return tags, nil
+} // func (db *Database) TagGetAll() ([]*model.Tag, error)
+
+// TagGetSorted returns all Tags, sorted hierarchically.
+func (db *Database) TagGetSorted() ([]*model.Tag, error) {
+	const qid query.ID = query.TagGetSorted
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+	var tags = make([]*model.Tag, 0, 16)
+
+	for rows.Next() {
+		var (
+			parent *int64
+			t      = new(model.Tag)
+		)
+
+		if err = rows.Scan(&t.ID, &t.Name, &parent, &t.Level, &t.FullName); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Feed: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		} else if parent != nil {
+			t.Parent = *parent
+		}
+
+		tags = append(tags, t)
+	}
+
+	return tags, nil
+} // func (db *Database) TagGetSorted() ([]*model.Tag, error)
+
+// TagGetItemCnt returns a map of all Tag IDs and the number of Items that have
+// the Tag linked.
+func (db *Database) TagGetItemCnt() (map[int64]int64, error) {
+	const qid query.ID = query.TagGetItemCnt
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+	var tags = make(map[int64]int64, 16)
+
+	for rows.Next() {
+		var (
+			id, cnt int64
+		)
+
+		if err = rows.Scan(&id, &cnt); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Feed: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		}
+
+		tags[id] = cnt
+	}
+
+	return tags, nil
+} // func (db *Database) TagGetItemCnt() (map[int64]int64, error)
+
+// TagGetUnreadCnt returns a map of all Tag IDs and the number of unread Items
+// that have the Tag linked.
+func (db *Database) TagGetUnreadCnt() (map[int64]int64, error) {
+	const qid query.ID = query.TagGetUnreadCnt
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+	var tags = make(map[int64]int64, 16)
+
+	for rows.Next() {
+		var (
+			id, cnt int64
+		)
+
+		if err = rows.Scan(&id, &cnt); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Feed: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		}
+
+		tags[id] = cnt
+	}
+
+	return tags, nil
+} // func (db *Database) TagGetUnreadCnt() (map[int64]int64, error)
+
+// TagRename changes a Tag's name.
+func (db *Database) TagRename(t *model.Tag, name string) error {
+	const qid query.ID = query.TagRename
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
+		// 	f.Title)
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(name, t.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot rename Tag %s to %s: %s",
+				t.Name,
+				name,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	t.Name = name
+	status = true
+	return nil
+} // func (db *Database) TagRename(t *model.Tag, name string) error
+
+// TagSetParent updates a Tag's parent ID.
+func (db *Database) TagSetParent(t *model.Tag, parent int64) error {
+	const qid query.ID = query.TagSetParent
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
+		// 	f.Title)
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(parent, t.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot update parent of Tag %s (%d) to %d: %s",
+				t.Name,
+				t.ID,
+				parent,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	t.Parent = parent
+	status = true
+	return nil
+} // func (db *Database) TagSetParent(t *model.Tag, parent int64) error
+
+// TagUpdate updates a Tag's name and parent at once.
+func (db *Database) TagUpdate(t *model.Tag, name string, parent int64) error {
+	const qid query.ID = query.TagUpdate
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+		p      *int64
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
+		// 	f.Title)
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+	if parent != 0 {
+		p = &parent
+	}
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(name, p, t.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot update parent of Tag %s (%d) to %d: %s",
+				t.Name,
+				t.ID,
+				parent,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	t.Name = name
+	t.Parent = parent
+	status = true
+	return nil
+} // func (db *Database) TagUpdate(t *model.Tag, name string, parent int64) error
+
+// TagDelete removes a Tag from the database.
+func (db *Database) TagDelete(t *model.Tag) error {
+	const qid query.ID = query.TagDelete
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
+		// 	f.Title)
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(t.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot delete Tag %s: %s",
+				t.Name,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	status = true
+	return nil
+} // func (db *Database) TagDelete(t *model.Tag) error
+
+// TagLinkAdd attaches the given Tag to the given Item. If the Item already
+// has the Tag from a CategoryMapping, the link becomes a manual one.
+func (db *Database) TagLinkAdd(item *model.Item, tag *model.Tag) error {
+	const qid query.ID = query.TagLinkAdd
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(tag.ID, item.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot add Tag %s to Item %q (%d): %s",
+				tag.Name,
+				item.Headline,
+				item.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	status = true
+	return nil
+} // func (db *Database) TagLinkAdd(item *model.Item, tag *model.Tag) error
+
+// TagLinkAddAuto attaches the given Tag to the given Item on behalf of a
+// CategoryMapping. If the Item already has the Tag, the link is left alone,
+// so a Tag the user attached stays a manual one.
+func (db *Database) TagLinkAddAuto(item *model.Item, tag *model.Tag) error {
+	const qid query.ID = query.TagLinkAddAuto
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(tag.ID, item.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot add Tag %s to Item %q (%d): %s",
+				tag.Name,
+				item.Headline,
+				item.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	status = true
+	return nil
+} // func (db *Database) TagLinkAddAuto(item *model.Item, tag *model.Tag) error
+
+// TagLinkDelete removes a Tag from the given Item.
+func (db *Database) TagLinkDelete(item *model.Item, tag *model.Tag) error {
+	const qid query.ID = query.TagLinkDelete
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(tag.ID, item.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot remove Tag %s from Item %s (%d): %s",
+				tag.Name,
+				item.Headline,
+				item.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	status = true
+	return nil
+} // func (db *Database) TagLinkDelete(item *model.Item, tag *model.Tag) error
+
+// TagLinkDeleteByFeed removes all links to Items that belong to the given
+// Feed.
+func (db *Database) TagLinkDeleteByFeed(f *model.Feed) error {
+	const qid query.ID = query.TagLinkDeleteByFeed
+	var (
+		err    error
+		msg    string
+		stmt   *sql.Stmt
+		tx     *sql.Tx
+		status bool
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return err
+	} else if db.tx != nil {
+		tx = db.tx
+	} else {
+	BEGIN_AD_HOC:
+		if tx, err = db.db.Begin(); err != nil {
+			if worthARetry(err) {
+				waitForRetry()
+				goto BEGIN_AD_HOC
+			} else {
+				msg = fmt.Sprintf("Error starting transaction: %s\n",
+					err.Error())
+				db.log.Printf("[ERROR] %s\n", msg)
+				return errors.New(msg)
+			}
+
+		} else {
+			defer func() {
+				var err2 error
+				if status {
+					if err2 = tx.Commit(); err2 != nil {
+						db.log.Printf("[ERROR] Failed to commit ad-hoc transaction: %s\n",
+							err2.Error())
+					}
+				} else if err2 = tx.Rollback(); err2 != nil {
+					db.log.Printf("[ERROR] Rollback of ad-hoc transaction failed: %s\n",
+						err2.Error())
+				}
+			}()
+		}
+	}
+
+	stmt = tx.Stmt(stmt)
+
+EXEC_QUERY:
+	if _, err = stmt.Exec(f.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		} else {
+			err = fmt.Errorf("Cannot remove Tag links to Items belonging to Feed %s (%d): %s",
+				f.Title,
+				f.ID,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", err.Error())
+			return err
+		}
+	}
+
+	status = true
+	return nil
+} // func (db *Database) TagLinkDeleteByFeed(f *model.Feed) error
+
+// TagLinkGetByItem loads all Tags that are attached to the given Item.
+func (db *Database) TagLinkGetByItem(item *model.Item) ([]*model.Tag, error) {
+	const qid query.ID = query.TagLinkGetByItem
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(item.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+	var tags = make([]*model.Tag, 0, 16)
+
+	for rows.Next() {
+		var (
+			parent *int64
+			t      = new(model.Tag)
+		)
+
+		if err = rows.Scan(&t.ID, &parent, &t.Name, &t.Auto); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Feed: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		}
+
+		if parent != nil {
+			t.Parent = *parent
+		}
+
+		tags = append(tags, t)
+	}
+
+	return tags, nil
+} // func (db *Database) TagLinkGetByItem(item *model.Item) ([]*model.Tag, error)
+
+// TagLinkGetByTag loads all Items that have the given Tag attached to them.
+func (db *Database) TagLinkGetByTag(tag *model.Tag) ([]*model.Item, error) {
+	return db.tagLinkGetByTag(query.TagLinkGetByTag, tag)
+} // func (db *Database) TagLinkGetByTag(tag *model.Tag) ([]*model.Item, error)
+
+// TagLinkGetByTagManual loads all Items the user has attached the given Tag
+// to, ignoring the links created automatically from the Items' categories.
+func (db *Database) TagLinkGetByTagManual(tag *model.Tag) ([]*model.Item, error) {
+	return db.tagLinkGetByTag(query.TagLinkGetByTagManual, tag)
+} // func (db *Database) TagLinkGetByTagManual(tag *model.Tag) ([]*model.Item, error)
+
+func (db *Database) tagLinkGetByTag(qid query.ID, tag *model.Tag) ([]*model.Item, error) {
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(tag.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+	var items = make([]*model.Item, 0, 16)
+
+	for rows.Next() {
+		var (
+			readAt        int64
+			rating, stamp int64
+			ustr          string
+			item          = new(model.Item)
+		)
+
+		if err = rows.Scan(&item.ID, &item.FeedID, &ustr, &stamp, &item.Headline, &item.Description, &item.Categories, &item.Authors, &rating, &readAt, &item.Starred, &item.Content, &item.Revisions); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Item: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		}
+
+		item.Rating = int8(rating)
+		item.Timestamp = time.Unix(stamp, 0)
+		item.ReadAt = readStamp(readAt)
+		if item.URL, err = url.Parse(ustr); err != nil {
+			db.log.Printf("[ERROR] Invalid URL for Item %q (%d): %s\n\t%s\n",
+				item.Headline,
+				item.ID,
+				err.Error(),
+				ustr)
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+} // func (db *Database) tagLinkGetByTag(qid query.ID, tag *model.Tag) ([]*model.Item, error)
+
+// TagLinkGetByTagHierarchy loads all Items that have the given Tag attached to them.
+func (db *Database) TagLinkGetByTagHierarchy(tag *model.Tag) ([]*model.Item, error) {
+	const qid query.ID = query.TagLinkGetByTagHierarchy
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(tag.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+	var items = make([]*model.Item, 0, 16)
+
+	for rows.Next() {
+		var (
+			readAt        int64
+			rating, stamp int64
+			ustr          string
+			item          = new(model.Item)
+		)
+
+		if err = rows.Scan(&item.ID, &item.FeedID, &ustr, &stamp, &item.Headline, &item.Description, &item.Categories, &item.Authors, &rating, &readAt, &item.Starred, &item.Content, &item.Revisions); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Item: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		}
+
+		item.Rating = int8(rating)
+		item.Timestamp = time.Unix(stamp, 0)
+		item.ReadAt = readStamp(readAt)
+		if item.URL, err = url.Parse(ustr); err != nil {
+			db.log.Printf("[ERROR] Invalid URL for Item %q (%d): %s\n\t%s\n",
+				item.Headline,
+				item.ID,
+				err.Error(),
+				ustr)
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+} // func (db *Database) TagLinkGetByTagHierarchy(tag *model.Tag) ([]*model.Item, error)
+
+// TagLinkGetByTagMap loads all Items that have the given Tag attached to them.
+// This method returns the results as a map rather than a slice.
+func (db *Database) TagLinkGetByTagMap(tag *model.Tag) (map[int64]*model.Item, error) {
+	const qid query.ID = query.TagLinkGetByTag
+	var (
+		err  error
+		msg  string
+		stmt *sql.Stmt
+	)
+
+	if stmt, err = db.getQuery(qid); err != nil {
+		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
+			qid,
+			err.Error())
+		return nil, err
+	} else if db.tx != nil {
+		stmt = db.tx.Stmt(stmt)
+	}
+
+	var rows *sql.Rows
+
+EXEC_QUERY:
+	if rows, err = stmt.Query(tag.ID); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+	var items = make(map[int64]*model.Item, 16)
+
+	for rows.Next() {
+		var (
+			readAt        int64
+			rating, stamp int64
+			ustr          string
+			item          = new(model.Item)
+		)
+
+		if err = rows.Scan(&item.ID, &item.FeedID, &ustr, &stamp, &item.Headline, &item.Description, &item.Categories, &item.Authors, &rating, &readAt, &item.Starred, &item.Content, &item.Revisions); err != nil {
+			msg = fmt.Sprintf("Error scanning row for Item: %s",
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return nil, errors.New(msg)
+		}
+
+		item.Rating = int8(rating)
+		item.Timestamp = time.Unix(stamp, 0)
+		item.ReadAt = readStamp(readAt)
+		if item.URL, err = url.Parse(ustr); err != nil {
+			db.log.Printf("[ERROR] Invalid URL for Item %q (%d): %s\n\t%s\n",
+				item.Headline,
+				item.ID,
+				err.Error(),
+				ustr)
+			return nil, err
+		}
+
+		// items = append(items, item)
+		items[item.ID] = item
+	}
+
+	return items, nil
+} // func (db *Database) TagLinkGetByTag(tag *model.Tag) ([]*model.Item, error)
+
+// CategoryMapAdd adds a mapping from one of a Feed's categories to a Tag and
+// attaches the Tag to all Items of the Feed we already have in that category.
+func (db *Database) CategoryMapAdd(m *model.CategoryMapping) error {
+	var (
+		err   error
+		adHoc = db.tx == nil
+	)
+
+	m.Category = strings.TrimSpace(m.Category)
+
+	if m.Category == "" {
+		return fmt.Errorf("%w: Category must not be empty", ErrInvalidValue)
+	} else if m.FeedID == 0 || m.TagID == 0 {
+		return fmt.Errorf("%w: Mapping needs a Feed and a Tag", ErrInvalidValue)
+	}
+
+	if adHoc {
+		if err = db.Begin(); err != nil {
+			return err
+		}
+	}
+
+	if err = db.categoryMapInsert(m); err != nil {
+		goto FAIL
+	} else if err = db.categoryMapApply(m); err != nil {
+		goto FAIL
+	}
 
-EXEC_QUERY:
-	if _, err = stmt.Exec(t.ID); err != nil {
-		if worthARetry(err) {
-			waitForRetry()
-			goto EXEC_QUERY
-		} else {
-			err = fmt.Errorf("Cannot delete Tag %s: %s",
-				t.Name,
-				err.Error())
-			db.log.Printf("[ERROR] %s\n", err.Error())
-			return err
+	if adHoc {
+		if err = db.Commit(); err != nil {
+			goto FAIL
 		}
 	}
 
-	status = true
 	return nil
-} // func (db *Database) TagDelete(t *model.Tag) error
 
-// TagLinkAdd attaches the given Tag to the given Item.
-func (db *Database) TagLinkAdd(item *model.Item, tag *model.Tag) error {
-	const qid query.ID = query.TagLinkAdd
+FAIL:
+	if adHoc {
+		db.Rollback() // nolint: errcheck
+	}
+
+	return err
+} // func (db *Database) CategoryMapAdd(m *model.CategoryMapping) error
+
+// categoryMapInsert stores a CategoryMapping in the database.
+func (db *Database) categoryMapInsert(m *model.CategoryMapping) error {
+	const qid query.ID = query.CategoryMapAdd
 	var (
 		err    error
 		msg    string
@@ -2495,6 +6676,8 @@ func (db *Database) TagLinkAdd(item *model.Item, tag *model.Tag) error {
 	} else if db.tx != nil {
 		tx = db.tx
 	} else {
+		// db.log.Printf("[INFO] Start ad-hoc transaction for adding Feed %s\n",
+		// 	f.Title)
 	BEGIN_AD_HOC:
 		if tx, err = db.db.Begin(); err != nil {
 			if worthARetry(err) {
@@ -2524,30 +6707,50 @@ func (db *Database) TagLinkAdd(item *model.Item, tag *model.Tag) error {
 	}
 
 	stmt = tx.Stmt(stmt)
+	var rows *sql.Rows
 
 EXEC_QUERY:
-	if _, err = stmt.Exec(tag.ID, item.ID); err != nil {
+	if rows, err = stmt.Query(m.FeedID, m.Category, m.TagID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		} else {
-			err = fmt.Errorf("Cannot add Tag %s to Item %q (%d): %s",
-				tag.Name,
-				item.Headline,
-				item.ID,
+			err = fmt.Errorf("Cannot add mapping of category %q of Feed %d to Tag %d: %s",
+				m.Category,
+				m.FeedID,
+				m.TagID,
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", err.Error())
 			return err
 		}
-	}
+	} else {
+		var id int64
 
-	status = true
-	return nil
-} // func (db *Database) TagLinkAdd(item *model.Item, tag *model.Tag) error
+		defer rows.Close()
 
-// TagLinkDelete removes a Tag from the given Item.
-func (db *Database) TagLinkDelete(item *model.Item, tag *model.Tag) error {
-	const qid query.ID = query.TagLinkDelete
+		if !rows.Next() {
+			// CANTHAPPEN
+			db.log.Printf("[ERROR] Query %s did not return a value\n",
+				qid)
+			return fmt.Errorf("Query %s did not return a value", qid)
+		} else if err = rows.Scan(&id); err != nil {
+			msg = fmt.Sprintf("Failed to get ID for newly added mapping of category %q: %s",
+				m.Category,
+				err.Error())
+			db.log.Printf("[ERROR] %s\n", msg)
+			return errors.New(msg)
+		}
+
+		m.ID = id
+		status = true
+		return nil
+	}
+} // func (db *Database) categoryMapInsert(m *model.CategoryMapping) error
+
+// categoryMapApply attaches the Tag of a CategoryMapping to the Items of its
+// Feed that are already in the database.
+func (db *Database) categoryMapApply(m *model.CategoryMapping) error {
+	const qid query.ID = query.CategoryMapApply
 	var (
 		err    error
 		msg    string
@@ -2595,15 +6798,15 @@ func (db *Database) TagLinkDelete(item *model.Item, tag *model.Tag) error {
 	stmt = tx.Stmt(stmt)
 
 EXEC_QUERY:
-	if _, err = stmt.Exec(tag.ID, item.ID); err != nil {
+	if _, err = stmt.Exec(m.TagID, m.FeedID, m.Category); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		} else {
-			err = fmt.Errorf("Cannot remove Tag %s from Item %s (%d): %s",
-				tag.Name,
-				item.Headline,
-				item.ID,
+			err = fmt.Errorf("Cannot apply mapping of category %q of Feed %d to Tag %d: %s",
+				m.Category,
+				m.FeedID,
+				m.TagID,
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", err.Error())
 			return err
@@ -2612,12 +6815,12 @@ EXEC_QUERY:
 
 	status = true
 	return nil
-} // func (db *Database) TagLinkDelete(item *model.Item, tag *model.Tag) error
+} // func (db *Database) categoryMapApply(m *model.CategoryMapping) error
 
-// TagLinkDeleteByFeed removes all links to Items that belong to the given
-// Feed.
-func (db *Database) TagLinkDeleteByFeed(f *model.Feed) error {
-	const qid query.ID = query.TagLinkDeleteByFeed
+// CategoryMapDelete removes a CategoryMapping. Tags it has already attached
+// to Items stay where they are.
+func (db *Database) CategoryMapDelete(m *model.CategoryMapping) error {
+	const qid query.ID = query.CategoryMapDelete
 	var (
 		err    error
 		msg    string
@@ -2665,14 +6868,14 @@ func (db *Database) TagLinkDeleteByFeed(f *model.Feed) error {
 	stmt = tx.Stmt(stmt)
 
 EXEC_QUERY:
-	if _, err = stmt.Exec(f.ID); err != nil {
+	if _, err = stmt.Exec(m.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
 		} else {
-			err = fmt.Errorf("Cannot remove Tag links to Items belonging to Feed %s (%d): %s",
-				f.Title,
-				f.ID,
+			err = fmt.Errorf("Cannot delete mapping of category %q of Feed %d: %s",
+				m.Category,
+				m.FeedID,
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", err.Error())
 			return err
@@ -2681,11 +6884,11 @@ EXEC_QUERY:
 
 	status = true
 	return nil
-} // func (db *Database) TagLinkDeleteByFeed(f *model.Feed) error
+} // func (db *Database) CategoryMapDelete(m *model.CategoryMapping) error
 
-// TagLinkGetByItem loads all Tags that are attached to the given Item.
-func (db *Database) TagLinkGetByItem(item *model.Item) ([]*model.Tag, error) {
-	const qid query.ID = query.TagLinkGetByItem
+// CategoryMapGetByFeed returns the CategoryMappings of the given Feed.
+func (db *Database) CategoryMapGetByFeed(f *model.Feed) ([]model.CategoryMapping, error) {
+	const qid query.ID = query.CategoryMapGetByFeed
 	var (
 		err  error
 		msg  string
@@ -2704,7 +6907,7 @@ func (db *Database) TagLinkGetByItem(item *model.Item) ([]*model.Tag, error) {
 	var rows *sql.Rows
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(item.ID); err != nil {
+	if rows, err = stmt.Query(f.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
@@ -2714,98 +6917,29 @@ EXEC_QUERY:
 	}
 
 	defer rows.Close() // nolint: errcheck,gosec
-	var tags = make([]*model.Tag, 0, 16)
-
-	for rows.Next() {
-		var (
-			parent *int64
-			t      = new(model.Tag)
-		)
-
-		if err = rows.Scan(&t.ID, &parent, &t.Name); err != nil {
-			msg = fmt.Sprintf("Error scanning row for Feed: %s",
-				err.Error())
-			db.log.Printf("[ERROR] %s\n", msg)
-			return nil, errors.New(msg)
-		}
-
-		if parent != nil {
-			t.Parent = *parent
-		}
-
-		tags = append(tags, t)
-	}
-
-	return tags, nil
-} // func (db *Database) TagLinkGetByItem(item *model.Item) ([]*model.Tag, error)
-
-// TagLinkGetByTag loads all Items that have the given Tag attached to them.
-func (db *Database) TagLinkGetByTag(tag *model.Tag) ([]*model.Item, error) {
-	const qid query.ID = query.TagLinkGetByTag
-	var (
-		err  error
-		msg  string
-		stmt *sql.Stmt
-	)
-
-	if stmt, err = db.getQuery(qid); err != nil {
-		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
-			qid,
-			err.Error())
-		return nil, err
-	} else if db.tx != nil {
-		stmt = db.tx.Stmt(stmt)
-	}
-
-	var rows *sql.Rows
-
-EXEC_QUERY:
-	if rows, err = stmt.Query(tag.ID); err != nil {
-		if worthARetry(err) {
-			waitForRetry()
-			goto EXEC_QUERY
-		}
-
-		return nil, err
-	}
 
-	defer rows.Close() // nolint: errcheck,gosec
-	var items = make([]*model.Item, 0, 16)
+	var mappings = make([]model.CategoryMapping, 0, 4)
 
 	for rows.Next() {
-		var (
-			rating, stamp int64
-			ustr          string
-			item          = new(model.Item)
-		)
+		var m = model.CategoryMapping{FeedID: f.ID}
 
-		if err = rows.Scan(&item.ID, &item.FeedID, &ustr, &stamp, &item.Headline, &item.Description, &rating); err != nil {
-			msg = fmt.Sprintf("Error scanning row for Item: %s",
+		if err = rows.Scan(&m.ID, &m.Category, &m.TagID); err != nil {
+			msg = fmt.Sprintf("Error scanning row for category mapping of Feed %d: %s",
+				f.ID,
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", msg)
 			return nil, errors.New(msg)
 		}
 
-		item.Rating = int8(rating)
-		item.Timestamp = time.Unix(stamp, 0)
-		if item.URL, err = url.Parse(ustr); err != nil {
-			db.log.Printf("[ERROR] Invalid URL for Item %q (%d): %s\n\t%s\n",
-				item.Headline,
-				item.ID,
-				err.Error(),
-				ustr)
-			return nil, err
-		}
-
-		items = append(items, item)
+		mappings = append(mappings, m)
 	}
 
-	return items, nil
-} // func (db *Database) TagLinkGetByTag(tag *model.Tag) ([]*model.Item, error)
+	return mappings, nil
+} // func (db *Database) CategoryMapGetByFeed(f *model.Feed) ([]model.CategoryMapping, error)
 
-// TagLinkGetByTagHierarchy loads all Items that have the given Tag attached to them.
-func (db *Database) TagLinkGetByTagHierarchy(tag *model.Tag) ([]*model.Item, error) {
-	const qid query.ID = query.TagLinkGetByTagHierarchy
+// CategoryGetByFeed returns the categories the Items of the given Feed are in.
+func (db *Database) CategoryGetByFeed(f *model.Feed) ([]string, error) {
+	const qid query.ID = query.CategoryGetByFeed
 	var (
 		err  error
 		msg  string
@@ -2824,7 +6958,7 @@ func (db *Database) TagLinkGetByTagHierarchy(tag *model.Tag) ([]*model.Item, err
 	var rows *sql.Rows
 
 EXEC_QUERY:
-	if rows, err = stmt.Query(tag.ID); err != nil {
+	if rows, err = stmt.Query(f.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
@@ -2834,104 +6968,25 @@ EXEC_QUERY:
 	}
 
 	defer rows.Close() // nolint: errcheck,gosec
-	var items = make([]*model.Item, 0, 16)
-
-	for rows.Next() {
-		var (
-			rating, stamp int64
-			ustr          string
-			item          = new(model.Item)
-		)
-
-		if err = rows.Scan(&item.ID, &item.FeedID, &ustr, &stamp, &item.Headline, &item.Description, &rating); err != nil {
-			msg = fmt.Sprintf("Error scanning row for Item: %s",
-				err.Error())
-			db.log.Printf("[ERROR] %s\n", msg)
-			return nil, errors.New(msg)
-		}
-
-		item.Rating = int8(rating)
-		item.Timestamp = time.Unix(stamp, 0)
-		if item.URL, err = url.Parse(ustr); err != nil {
-			db.log.Printf("[ERROR] Invalid URL for Item %q (%d): %s\n\t%s\n",
-				item.Headline,
-				item.ID,
-				err.Error(),
-				ustr)
-			return nil, err
-		}
-
-		items = append(items, item)
-	}
-
-	return items, nil
-} // func (db *Database) TagLinkGetByTagHierarchy(tag *model.Tag) ([]*model.Item, error)
-
-// TagLinkGetByTagMap loads all Items that have the given Tag attached to them.
-// This method returns the results as a map rather than a slice.
-func (db *Database) TagLinkGetByTagMap(tag *model.Tag) (map[int64]*model.Item, error) {
-	const qid query.ID = query.TagLinkGetByTag
-	var (
-		err  error
-		msg  string
-		stmt *sql.Stmt
-	)
-
-	if stmt, err = db.getQuery(qid); err != nil {
-		db.log.Printf("[ERROR] Cannot prepare query %s: %s\n",
-			qid,
-			err.Error())
-		return nil, err
-	} else if db.tx != nil {
-		stmt = db.tx.Stmt(stmt)
-	}
-
-	var rows *sql.Rows
 
-EXEC_QUERY:
-	if rows, err = stmt.Query(tag.ID); err != nil {
-		if worthARetry(err) {
-			waitForRetry()
-			goto EXEC_QUERY
-		}
-
-		return nil, err
-	}
-
-	defer rows.Close() // nolint: errcheck,gosec
-	var items = make(map[int64]*model.Item, 16)
+	var categories = make([]string, 0, 16)
 
 	for rows.Next() {
-		var (
-			rating, stamp int64
-			ustr          string
-			item          = new(model.Item)
-		)
+		var c string
 
-		if err = rows.Scan(&item.ID, &item.FeedID, &ustr, &stamp, &item.Headline, &item.Description, &rating); err != nil {
-			msg = fmt.Sprintf("Error scanning row for Item: %s",
+		if err = rows.Scan(&c); err != nil {
+			msg = fmt.Sprintf("Error scanning row for categories of Feed %d: %s",
+				f.ID,
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", msg)
 			return nil, errors.New(msg)
 		}
 
-		item.Rating = int8(rating)
-		item.Timestamp = time.Unix(stamp, 0)
-		if item.URL, err = url.Parse(ustr); err != nil {
-			db.log.Printf("[ERROR] Invalid URL for Item %q (%d): %s\n\t%s\n",
-				item.Headline,
-				item.ID,
-				err.Error(),
-				ustr)
-			return nil, err
-		}
-
-		// items = append(items, item)
-		items[item.ID] = item
+		categories = append(categories, c)
 	}
 
-	return items, nil
-} // func (db *Database) TagLinkGetByTag(tag *model.Tag) ([]*model.Item, error)
+	return categories, nil
+} // func (db *Database) CategoryGetByFeed(f *model.Feed) ([]string, error)
 
 // SearchAdd enters a Search query into the database.
 func (db *Database) SearchAdd(s *model.Search) error {
@@ -3130,10 +7185,11 @@ EXEC_QUERY:
 			tstarted, tfinished *int64
 			tagStr              string
 			resultStr           *string
+			scoreStr            *string
 			tags, results       []string
 		)
 
-		if err = rows.Scan(&s.Title, &tcreated, &tstarted, &tfinished, &s.Status, &s.Message, &tagStr, &s.TagsAll, &s.QueryString, &s.Regex, &resultStr); err != nil {
+		if err = rows.Scan(&s.Title, &tcreated, &tstarted, &tfinished, &s.Status, &s.Message, &tagStr, &s.TagsAll, &s.QueryString, &s.Regex, &resultStr, &scoreStr); err != nil {
 			msg = fmt.Sprintf("Error scanning row for Search %d: %s",
 				id,
 				err.Error())
@@ -3149,8 +7205,10 @@ EXEC_QUERY:
 			s.TimeFinished = time.Unix(*tfinished, 0)
 		}
 
-		tags = strings.Split(tagStr, ",")
-		if resultStr != nil {
+		if tagStr != "" {
+			tags = strings.Split(tagStr, ",")
+		}
+		if resultStr != nil && *resultStr != "" {
 			results = strings.Split(*resultStr, ",")
 		} else {
 			results = nil
@@ -3190,6 +7248,10 @@ EXEC_QUERY:
 			}
 		}
 
+		if err = db.searchSetScores(s, scoreStr); err != nil {
+			return nil, err
+		}
+
 		return s, nil
 	}
 
@@ -3252,7 +7314,9 @@ EXEC_QUERY:
 	s.FilterPeriod[0] = time.Unix(periodBegin, 0)
 	s.FilterPeriod[1] = time.Unix(periodEnd, 0)
 
-	tags = strings.Split(tagStr, ",")
+	if tagStr != "" {
+		tags = strings.Split(tagStr, ",")
+	}
 
 	if len(tags) > 0 {
 		s.Tags = make([]int64, len(tags))
@@ -3315,7 +7379,7 @@ EXEC_QUERY:
 			tags, results                    []string
 		)
 
-		if err = rows.Scan(&s.Title, &tcreated, &tstarted, &s.Status, &s.Message, &tagStr, &s.TagsAll, &s.FilterByPeriod, &periodBegin, &periodEnd, &s.QueryString, &s.Regex, &resultStr); err != nil {
+		if err = rows.Scan(&s.ID, &s.Title, &tcreated, &tstarted, &s.Status, &s.Message, &tagStr, &s.TagsAll, &s.FilterByPeriod, &periodBegin, &periodEnd, &s.QueryString, &s.Regex, &resultStr); err != nil {
 			msg = fmt.Sprintf("Error scanning row for pending Search queries: %s",
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", msg)
@@ -3329,8 +7393,10 @@ EXEC_QUERY:
 			s.TimeStarted = time.Unix(*tstarted, 0)
 		}
 
-		tags = strings.Split(tagStr, ",")
-		if resultStr != nil {
+		if tagStr != "" {
+			tags = strings.Split(tagStr, ",")
+		}
+		if resultStr != nil && *resultStr != "" {
 			results = strings.Split(*resultStr, ",")
 		}
 
@@ -3414,11 +7480,12 @@ EXEC_QUERY:
 			tstarted, tfinished    *int64
 			tagStr                 string
 			resultStr              *string
+			scoreStr               *string
 			periodBegin, periodEnd int64
 			tags, results          []string
 		)
 
-		if err = rows.Scan(&s.ID, &s.Title, &tcreated, &tstarted, &tfinished, &s.Status, &s.Message, &tagStr, &s.TagsAll, &s.FilterByPeriod, &periodBegin, &periodEnd, &s.QueryString, &s.Regex, &resultStr); err != nil {
+		if err = rows.Scan(&s.ID, &s.Title, &tcreated, &tstarted, &tfinished, &s.Status, &s.Message, &tagStr, &s.TagsAll, &s.FilterByPeriod, &periodBegin, &periodEnd, &s.QueryString, &s.Regex, &resultStr, &scoreStr); err != nil {
 			msg = fmt.Sprintf("Error scanning row for pending Search queries: %s",
 				err.Error())
 			db.log.Printf("[ERROR] %s\n", msg)
@@ -3435,8 +7502,10 @@ EXEC_QUERY:
 			s.TimeFinished = time.Unix(*tfinished, 0)
 		}
 
-		tags = strings.Split(tagStr, ",")
-		if resultStr != nil {
+		if tagStr != "" {
+			tags = strings.Split(tagStr, ",")
+		}
+		if resultStr != nil && *resultStr != "" {
 			results = strings.Split(*resultStr, ",")
 		} else {
 			results = nil
@@ -3476,6 +7545,10 @@ EXEC_QUERY:
 			}
 		}
 
+		if err = db.searchSetScores(s, scoreStr); err != nil {
+			return nil, err
+		}
+
 		queries = append(queries, s)
 	}
 
@@ -3604,17 +7677,21 @@ func (db *Database) SearchFinish(s *model.Search) error {
 	var (
 		finishStamp = time.Now()
 		resultsList = make([]string, len(s.Results))
+		scoreList   = make([]string, len(s.Results))
 		resultStr   string
+		scoreStr    string
 	)
 
 	for idx, item := range s.Results {
 		resultsList[idx] = strconv.FormatInt(item.ID, 10)
+		scoreList[idx] = strconv.FormatFloat(item.Score, 'g', 6, 64)
 	}
 
 	resultStr = strings.Join(resultsList, ",")
+	scoreStr = strings.Join(scoreList, ",")
 
 EXEC_QUERY:
-	if _, err = stmt.Exec(finishStamp.Unix(), s.Status, s.Message, resultStr, s.ID); err != nil {
+	if _, err = stmt.Exec(finishStamp.Unix(), s.Status, s.Message, resultStr, scoreStr, s.ID); err != nil {
 		if worthARetry(err) {
 			waitForRetry()
 			goto EXEC_QUERY
@@ -0,0 +1,437 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/fts.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 19:12:40 krylon>
+
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/blicero/badnews/model"
+)
+
+// The full-text index depends on the FTS5 extension, which go-sqlite3 only
+// includes when built with the sqlite_fts5 tag. So unlike the rest of the
+// schema, the index is not created by initQueries or migrations, but set up
+// by Open whenever the extension is available. Without it, Searches fall
+// back to matching Items one by one.
+// The index covers the headline, description and stored article text of
+// each Item. It is kept in sync by triggers on item, item_content and feed.
+// Since an index has exactly one tokenizer, we keep one index per language
+// we know how to handle, plus one for everything else, and pick the index by
+// the language of the Item's Feed.
+// FTS5 only comes with a stemmer for English. German forms most of its
+// words by inflection and compounding, so for German Feeds we use the
+// trigram tokenizer instead, which matches any part of a word: "Wahl" finds
+// "Wahlen" and "Bundestagswahl". Words shorter than three letters cannot be
+// matched that way, they are ignored unless they are all there is to the
+// query.
+
+// ftsIndex is a full-text index for the Items of Feeds in one language.
+type ftsIndex struct {
+	table    string
+	lang     string
+	tokenize string
+}
+
+var ftsIndexes = []ftsIndex{
+	{table: "item_fts_en", lang: "en", tokenize: "porter unicode61 remove_diacritics 2"},
+	{table: "item_fts_de", lang: "de", tokenize: "trigram remove_diacritics 1"},
+	{table: "item_fts", lang: "", tokenize: "unicode61 remove_diacritics 2"},
+}
+
+// ftsWeights are the weights bm25() gives to matches in the headline,
+// description and article text, respectively.
+const ftsWeights = "10.0, 5.0, 1.0"
+
+// ftsLang returns an SQL expression that yields the index language of the
+// Feed whose ID is the given SQL expression.
+func ftsLang(feedID string) string {
+	var clauses = make([]string, 0, len(ftsIndexes))
+
+	for _, idx := range ftsIndexes {
+		if idx.lang != "" {
+			clauses = append(clauses,
+				fmt.Sprintf("WHEN lower(language) LIKE '%s%%' THEN '%s'", idx.lang, idx.lang))
+		}
+	}
+
+	return fmt.Sprintf("(SELECT CASE %s ELSE '' END FROM feed WHERE id = %s)",
+		strings.Join(clauses, " "),
+		feedID)
+} // func ftsLang(feedID string) string
+
+// ftsReindex returns the statements to remove the Items matching the given
+// SQL condition (on item i) from all indices and to add them to the index
+// for their language.
+func ftsReindex(cond string) string {
+	var sb strings.Builder
+
+	for _, idx := range ftsIndexes {
+		fmt.Fprintf(&sb, "    DELETE FROM %s WHERE rowid IN (SELECT i.id FROM item i WHERE %s);\n",
+			idx.table,
+			cond)
+	}
+
+	for _, idx := range ftsIndexes {
+		fmt.Fprintf(&sb, `    INSERT INTO %s (rowid, headline, description, content)
+        SELECT i.id, i.headline, i.description, COALESCE(c.content, '')
+        FROM item i
+        LEFT OUTER JOIN item_content c ON c.item_id = i.id
+        WHERE %s AND %s = '%s';
+`,
+			idx.table,
+			cond,
+			ftsLang("i.feed_id"),
+			idx.lang)
+	}
+
+	return sb.String()
+} // func ftsReindex(cond string) string
+
+// ftsTriggers returns the names and definitions of the triggers that keep
+// the full-text indices in sync.
+func ftsTriggers() map[string]string {
+	var (
+		triggers = make(map[string]string)
+		defs     = []struct {
+			name, event, cond string
+		}{
+			{"item_fts_ai", "AFTER INSERT ON item", "i.id = NEW.id"},
+			{"item_fts_au", "AFTER UPDATE OF headline, description, feed_id ON item", "i.id = NEW.id"},
+			{"item_content_fts_ai", "AFTER INSERT ON item_content", "i.id = NEW.item_id"},
+			{"item_content_fts_au", "AFTER UPDATE OF content ON item_content", "i.id = NEW.item_id"},
+			{"item_content_fts_ad", "AFTER DELETE ON item_content", "i.id = OLD.item_id"},
+			{"feed_fts_au", "AFTER UPDATE OF language ON feed", "i.feed_id = NEW.id"},
+		}
+	)
+
+	for _, d := range defs {
+		triggers[d.name] = fmt.Sprintf("CREATE TRIGGER %s %s\nBEGIN\n%sEND",
+			d.name,
+			d.event,
+			ftsReindex(d.cond))
+	}
+
+	// When the Item is gone, we cannot look up its Feed's language
+	// anymore, so this one is different.
+	var sb strings.Builder
+	for _, idx := range ftsIndexes {
+		fmt.Fprintf(&sb, "    DELETE FROM %s WHERE rowid = OLD.id;\n", idx.table)
+	}
+	triggers["item_fts_ad"] = fmt.Sprintf("CREATE TRIGGER item_fts_ad AFTER DELETE ON item\nBEGIN\n%sEND",
+		sb.String())
+
+	return triggers
+} // func ftsTriggers() map[string]string
+
+// ftsSetup checks if the FTS5 extension is available and, if so, makes sure
+// the full-text indices and the triggers that maintain them exist. If they
+// are created, or the triggers were missing, e.g. because the database was
+// used by a build without FTS5 in the meantime, the indices are rebuilt.
+// Without FTS5, it removes the triggers, because they could not update the
+// indices.
+func (db *Database) ftsSetup() error {
+	var (
+		err      error
+		tx       *sql.Tx
+		rows     *sql.Rows
+		avail    bool
+		rebuild  bool
+		existing = make(map[string]string)
+		triggers = ftsTriggers()
+	)
+
+	if err = db.db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&avail); err != nil {
+		db.log.Printf("[ERROR] Cannot check if FTS5 is available: %s\n",
+			err.Error())
+		return err
+	} else if rows, err = db.db.Query("SELECT type, name, sql FROM sqlite_master WHERE type IN ('table', 'trigger')"); err != nil {
+		db.log.Printf("[ERROR] Cannot query schema: %s\n",
+			err.Error())
+		return err
+	}
+
+	for rows.Next() {
+		var kind, name string
+		var def *string
+
+		if err = rows.Scan(&kind, &name, &def); err != nil {
+			rows.Close() // nolint: errcheck
+			return err
+		} else if def != nil {
+			existing[kind+" "+name] = *def
+		}
+	}
+
+	rows.Close() // nolint: errcheck
+
+	if !avail {
+		for name := range triggers {
+			if _, ok := existing["trigger "+name]; !ok {
+				continue
+			}
+
+			db.log.Printf("[INFO] FTS5 is not available, dropping trigger %s\n", name)
+			if _, err = db.db.Exec("DROP TRIGGER IF EXISTS " + name); err != nil {
+				db.log.Printf("[ERROR] Cannot drop trigger %s: %s\n",
+					name,
+					err.Error())
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	var uptodate = true
+
+	for _, idx := range ftsIndexes {
+		if _, ok := existing["table "+idx.table]; !ok {
+			uptodate = false
+			rebuild = true
+		}
+	}
+
+	for name, def := range triggers {
+		if cur, ok := existing["trigger "+name]; !ok {
+			uptodate = false
+			rebuild = true
+		} else if cur != def {
+			uptodate = false
+		}
+	}
+
+	if uptodate {
+		db.fts = true
+		return nil
+	}
+
+BEGIN_TX:
+	if tx, err = db.db.Begin(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto BEGIN_TX
+		}
+
+		db.log.Printf("[ERROR] Cannot begin transaction: %s\n",
+			err.Error())
+		return err
+	}
+
+	var stmts = make([]string, 0, len(ftsIndexes)*3+len(triggers)*2)
+
+	for _, idx := range ftsIndexes {
+		stmts = append(stmts,
+			fmt.Sprintf("CREATE VIRTUAL TABLE IF NOT EXISTS %s USING fts5(headline, description, content, content='', contentless_delete=1, tokenize='%s')",
+				idx.table,
+				idx.tokenize))
+	}
+
+	for name, def := range triggers {
+		stmts = append(stmts,
+			"DROP TRIGGER IF EXISTS "+name,
+			def)
+	}
+
+	if rebuild {
+		db.log.Printf("[INFO] Rebuilding full-text index in %s\n", db.path)
+		for _, idx := range ftsIndexes {
+			stmts = append(stmts, "DELETE FROM "+idx.table)
+		}
+		stmts = append(stmts, ftsReindex("1"))
+	}
+
+	for _, q := range stmts {
+		if _, err = tx.Exec(q); err != nil {
+			db.log.Printf("[ERROR] Cannot set up full-text index: %s\n%s\n",
+				err.Error(),
+				q)
+			if rbErr := tx.Rollback(); rbErr != nil {
+				db.log.Printf("[CANTHAPPEN] Cannot rollback transaction: %s\n",
+					rbErr.Error())
+			}
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		db.log.Printf("[ERROR] Cannot commit transaction: %s\n",
+			err.Error())
+		return err
+	}
+
+	db.fts = true
+	return nil
+} // func (db *Database) ftsSetup() error
+
+// FTSAvailable returns true if Searches can use the full-text index.
+func (db *Database) FTSAvailable() bool {
+	return db.fts
+} // func (db *Database) FTSAvailable() bool
+
+// ftsQuery turns the query string of a Search into an FTS5 query. All words
+// must match, text in double quotes is matched as a phrase, and a word or
+// phrase ending in an asterisk matches as a prefix.
+func ftsQuery(qstr string) string {
+	var (
+		terms  []string
+		phrase bool
+		sb     strings.Builder
+	)
+
+	var flush = func() {
+		var (
+			t      = sb.String()
+			prefix = strings.HasSuffix(t, "*")
+		)
+
+		sb.Reset()
+		t = strings.TrimRight(t, "*")
+		if strings.TrimFunc(t, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsNumber(r) }) == "" {
+			return
+		}
+
+		t = `"` + strings.ReplaceAll(t, `"`, `""`) + `"`
+		if prefix {
+			t += "*"
+		}
+		terms = append(terms, t)
+	}
+
+	for _, r := range qstr {
+		switch {
+		case r == '"':
+			if phrase {
+				phrase = false
+			} else {
+				flush()
+				phrase = true
+			}
+		case unicode.IsSpace(r) && !phrase:
+			flush()
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	flush()
+
+	return strings.Join(terms, " ")
+} // func ftsQuery(qstr string) string
+
+// searchFTS looks up the Items matching the query string of the Search in
+// the full-text index, best match first. If the Search filters by period,
+// only Items from that period are returned.
+func (db *Database) searchFTS(s *model.Search) ([]*model.Item, error) {
+	var (
+		err    error
+		rows   *sql.Rows
+		qstr   = ftsQuery(s.QueryString)
+		parts  = make([]string, len(ftsIndexes))
+		args   = make([]any, 0, len(ftsIndexes)+3)
+		begin  int64
+		end    int64
+		period int
+	)
+
+	if qstr == "" {
+		return nil, nil
+	}
+
+	for idx, fts := range ftsIndexes {
+		parts[idx] = fmt.Sprintf("SELECT rowid AS id, -bm25(%s, %s) AS score FROM %s WHERE %s MATCH ?",
+			fts.table,
+			ftsWeights,
+			fts.table,
+			fts.table)
+		args = append(args, qstr)
+	}
+
+	if s.FilterByPeriod {
+		period = 1
+		begin = s.FilterPeriod[0].Unix()
+		end = s.FilterPeriod[1].Unix()
+	}
+
+	args = append(args, period, begin, end)
+
+	var q = fmt.Sprintf(`
+SELECT
+    i.id,
+    i.feed_id,
+    i.url,
+    i.timestamp,
+    i.headline,
+    i.description,
+    i.categories,
+    i.authors,
+    i.rating,
+    i.read_at,
+    i.starred,
+    COALESCE((SELECT c.content FROM item_content c WHERE c.item_id = i.id), '') AS content,
+    (SELECT COUNT(r.id) FROM item_revision r WHERE r.item_id = i.id) AS revisions,
+    m.score
+FROM (%s) m
+INNER JOIN item i ON i.id = m.id
+WHERE ? = 0 OR i.timestamp BETWEEN ? AND ?
+ORDER BY m.score DESC, i.timestamp DESC
+`,
+		strings.Join(parts, "\n    UNION ALL\n    "))
+
+EXEC_QUERY:
+	if db.tx != nil {
+		rows, err = db.tx.Query(q, args...)
+	} else {
+		rows, err = db.db.Query(q, args...)
+	}
+
+	if err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto EXEC_QUERY
+		}
+
+		db.log.Printf("[ERROR] Full-text search for %q failed: %s\n",
+			qstr,
+			err.Error())
+		return nil, err
+	}
+
+	defer rows.Close() // nolint: errcheck,gosec
+
+	var items = make([]*model.Item, 0, 16)
+
+	for rows.Next() {
+		var (
+			readAt    int64
+			timestamp int64
+			ustr      string
+			i         = new(model.Item)
+		)
+
+		if err = rows.Scan(&i.ID, &i.FeedID, &ustr, &timestamp, &i.Headline, &i.Description, &i.Categories, &i.Authors, &i.Rating, &readAt, &i.Starred, &i.Content, &i.Revisions, &i.Score); err != nil {
+			db.log.Printf("[ERROR] Error scanning row for Item: %s\n",
+				err.Error())
+			return nil, err
+		} else if i.URL, err = url.Parse(ustr); err != nil {
+			db.log.Printf("[ERROR] Cannot parse URL %q: %s\n",
+				ustr,
+				err.Error())
+			return nil, err
+		}
+
+		i.Timestamp = time.Unix(timestamp, 0)
+		i.ReadAt = readStamp(readAt)
+		items = append(items, i)
+	}
+
+	return items, nil
+} // func (db *Database) searchFTS(s *model.Search) ([]*model.Item, error)
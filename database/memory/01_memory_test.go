@@ -0,0 +1,260 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/memory/01_memory_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 23:34:05 krylon>
+
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/model"
+)
+
+func purl(s string) *url.URL {
+	var u, _ = url.Parse(s)
+	return u
+} // func purl(s string) *url.URL
+
+func openStore(t *testing.T, mem *Memory) database.Store {
+	var (
+		err error
+		db  database.Store
+	)
+
+	if db, err = mem.Open(); err != nil {
+		t.Fatalf("Cannot open Store: %s", err.Error())
+	}
+
+	return db
+} // func openStore(t *testing.T, mem *Memory) database.Store
+
+func addFeed(t *testing.T, db database.Store, name string) *model.Feed {
+	var f = &model.Feed{
+		Title:          name,
+		URL:            purl(fmt.Sprintf("https://%s.example.com/feed.rss", name)),
+		Homepage:       purl(fmt.Sprintf("https://%s.example.com/", name)),
+		UpdateInterval: time.Hour,
+		Active:         true,
+	}
+
+	if err := db.FeedAdd(f); err != nil {
+		t.Fatalf("Failed to add Feed %s: %s", name, err.Error())
+	}
+
+	return f
+} // func addFeed(t *testing.T, db database.Store, name string) *model.Feed
+
+func addItems(t *testing.T, db database.Store, f *model.Feed, cnt int) []*model.Item {
+	var (
+		items = make([]*model.Item, cnt)
+		now   = time.Now().Truncate(time.Second)
+	)
+
+	// items[0] is the oldest, items[cnt-1] the most recent Item.
+	for idx := range items {
+		items[idx] = &model.Item{
+			FeedID:    f.ID,
+			URL:       purl(fmt.Sprintf("%sitem%02d.html", f.Homepage, idx)),
+			Timestamp: now.Add(time.Duration(idx-cnt) * time.Minute),
+			Headline:  fmt.Sprintf("%s %02d", f.Title, idx),
+		}
+
+		if err := db.ItemAdd(items[idx]); err != nil {
+			t.Fatalf("Failed to add Item %s: %s", items[idx].Headline, err.Error())
+		}
+	}
+
+	return items
+} // func addItems(t *testing.T, db database.Store, f *model.Feed, cnt int) []*model.Item
+
+func TestFeedItem(t *testing.T) {
+	var (
+		err    error
+		added  bool
+		list   []*model.Item
+		unread map[int64]int64
+		mem    = New()
+		db     = openStore(t, mem)
+		other  = openStore(t, mem)
+		f      = addFeed(t, db, "items")
+		items  = addItems(t, db, f, 5)
+	)
+
+	if err = db.FeedAdd(&model.Feed{Title: "dup", URL: f.URL, UpdateInterval: time.Hour}); !errors.Is(err, ErrConstraint) {
+		t.Errorf("Adding a Feed with a duplicate URL should fail, got %v", err)
+	} else if added, err = db.ItemUpsert(&model.Item{FeedID: f.ID, URL: items[0].URL}); err != nil {
+		t.Fatalf("Failed to upsert Item: %s", err.Error())
+	} else if added {
+		t.Error("Item with a duplicate URL should not have been added")
+	} else if list, err = other.ItemGetByFeed(f, -1, 0, model.ItemFilter{}); err != nil {
+		t.Fatalf("Failed to load Items: %s", err.Error())
+	} else if len(list) != len(items) {
+		t.Fatalf("Expected %d Items, got %d", len(items), len(list))
+	} else if list[0].ID != items[4].ID {
+		t.Errorf("Expected newest Item %d first, got %d", items[4].ID, list[0].ID)
+	} else if err = db.ItemMarkRead(items[1]); err != nil {
+		t.Fatalf("Failed to mark Item as read: %s", err.Error())
+	} else if unread, err = other.FeedGetUnreadCnt(); err != nil {
+		t.Fatalf("Failed to count unread Items: %s", err.Error())
+	} else if unread[f.ID] != int64(len(items)-1) {
+		t.Errorf("Expected %d unread Items, got %d", len(items)-1, unread[f.ID])
+	} else if err = db.FeedDelete(f); !errors.Is(err, ErrConstraint) {
+		t.Errorf("Deleting a Feed with Items should fail, got %v", err)
+	} else if err = db.ItemDeleteByFeed(f); err != nil {
+		t.Fatalf("Failed to delete Items: %s", err.Error())
+	} else if err = db.FeedDelete(f); err != nil {
+		t.Errorf("Failed to delete Feed: %s", err.Error())
+	}
+} // func TestFeedItem(t *testing.T)
+
+func TestTransaction(t *testing.T) {
+	var (
+		err   error
+		feeds []model.Feed
+		mem   = New()
+		db    = openStore(t, mem)
+	)
+
+	if err = db.Commit(); !errors.Is(err, database.ErrNoTxInProgress) {
+		t.Errorf("Commit without transaction should fail, got %v", err)
+	} else if err = db.Begin(); err != nil {
+		t.Fatalf("Failed to begin transaction: %s", err.Error())
+	} else if err = db.Begin(); !errors.Is(err, database.ErrTxInProgress) {
+		t.Errorf("Nested transaction should fail, got %v", err)
+	}
+
+	addFeed(t, db, "rollback")
+
+	if err = db.Rollback(); err != nil {
+		t.Fatalf("Failed to roll back transaction: %s", err.Error())
+	} else if feeds, err = db.FeedGetAll(); err != nil {
+		t.Fatalf("Failed to load Feeds: %s", err.Error())
+	} else if len(feeds) != 0 {
+		t.Errorf("Expected no Feeds after Rollback, got %d", len(feeds))
+	} else if err = db.Begin(); err != nil {
+		t.Fatalf("Failed to begin transaction: %s", err.Error())
+	}
+
+	addFeed(t, db, "commit")
+
+	if err = db.Commit(); err != nil {
+		t.Fatalf("Failed to commit transaction: %s", err.Error())
+	} else if feeds, err = openStore(t, mem).FeedGetAll(); err != nil {
+		t.Fatalf("Failed to load Feeds: %s", err.Error())
+	} else if len(feeds) != 1 {
+		t.Errorf("Expected 1 Feed after Commit, got %d", len(feeds))
+	}
+} // func TestTransaction(t *testing.T)
+
+func TestTagSearch(t *testing.T) {
+	var (
+		err    error
+		tags   []*model.Tag
+		linked []*model.Tag
+		db     = openStore(t, New())
+		f      = addFeed(t, db, "tags")
+		items  = addItems(t, db, f, 4)
+		parent = &model.Tag{Name: "News"}
+		child  = &model.Tag{Name: "Politics"}
+		search = &model.Search{Title: "Both Tags", TagsAll: true}
+	)
+
+	if err = db.TagAdd(parent); err != nil {
+		t.Fatalf("Failed to add Tag %s: %s", parent.Name, err.Error())
+	}
+
+	child.Parent = parent.ID
+
+	if err = db.TagAdd(child); err != nil {
+		t.Fatalf("Failed to add Tag %s: %s", child.Name, err.Error())
+	} else if err = db.TagAdd(&model.Tag{Name: child.Name, Parent: parent.ID}); !errors.Is(err, ErrConstraint) {
+		t.Errorf("Adding a duplicate Tag should fail, got %v", err)
+	} else if tags, err = db.TagGetSorted(); err != nil {
+		t.Fatalf("Failed to load sorted Tags: %s", err.Error())
+	} else if len(tags) != 2 || tags[1].FullName != "News/Politics" || tags[1].Level != 1 {
+		t.Errorf("Unexpected Tag hierarchy: %v", tags)
+	}
+
+	for _, i := range items[:3] {
+		if err = db.TagLinkAddAuto(i, parent); err != nil {
+			t.Fatalf("Failed to link Item %d: %s", i.ID, err.Error())
+		}
+	}
+
+	for _, i := range items[1:] {
+		if err = db.TagLinkAdd(i, child); err != nil {
+			t.Fatalf("Failed to link Item %d: %s", i.ID, err.Error())
+		}
+	}
+
+	if linked, err = db.TagLinkGetByItem(items[1]); err != nil {
+		t.Fatalf("Failed to load Tags of Item %d: %s", items[1].ID, err.Error())
+	} else if len(linked) != 2 || !linked[0].Auto || linked[1].Auto {
+		t.Errorf("Unexpected Tags on Item %d: %v", items[1].ID, linked)
+	}
+
+	search.Tags = []int64{parent.ID, child.ID}
+
+	if err = db.SearchAdd(search); err != nil {
+		t.Fatalf("Failed to add Search: %s", err.Error())
+	} else if err = db.SearchStart(search); err != nil {
+		t.Fatalf("Failed to start Search: %s", err.Error())
+	} else if err = db.SearchExecute(search); err != nil {
+		t.Fatalf("Failed to execute Search: %s", err.Error())
+	} else if search, err = db.SearchGetByID(search.ID); err != nil {
+		t.Fatalf("Failed to load Search: %s", err.Error())
+	} else if search.TimeFinished.IsZero() || !search.Status {
+		t.Errorf("Search %d should have finished successfully", search.ID)
+	} else if len(search.Results) != 2 ||
+		search.Results[0].ID != items[2].ID ||
+		search.Results[1].ID != items[1].ID {
+		t.Errorf("Expected Items %d and %d as results, got %d Items",
+			items[2].ID,
+			items[1].ID,
+			len(search.Results))
+	}
+} // func TestTagSearch(t *testing.T)
+
+func TestPool(t *testing.T) {
+	var (
+		err  error
+		pool *database.Pool
+		mem  = New()
+	)
+
+	if pool, err = database.NewPoolFrom(2, mem.Open); err != nil {
+		t.Fatalf("Failed to create Pool: %s", err.Error())
+	}
+
+	defer pool.Close() // nolint: errcheck
+
+	var db = pool.Get()
+	addFeed(t, db, "pool")
+
+	if err = db.Begin(); err != nil {
+		t.Fatalf("Failed to begin transaction: %s", err.Error())
+	}
+
+	addFeed(t, db, "pending")
+
+	// Put rolls back the pending transaction.
+	pool.Put(db)
+
+	var feeds []model.Feed
+
+	db = pool.Get()
+	defer pool.Put(db)
+
+	if feeds, err = db.FeedGetAll(); err != nil {
+		t.Fatalf("Failed to load Feeds: %s", err.Error())
+	} else if len(feeds) != 1 {
+		t.Errorf("Expected 1 Feed, got %d", len(feeds))
+	}
+} // func TestPool(t *testing.T)
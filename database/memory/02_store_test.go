@@ -0,0 +1,22 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/memory/02_store_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 01:11:02 krylon>
+
+package memory
+
+import (
+	"testing"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/database/storetest"
+)
+
+// TestStore runs the test cases every Store has to pass, the same ones the
+// SQLite backend is tested with.
+func TestStore(t *testing.T) {
+	storetest.Run(t, func(*testing.T) database.Opener {
+		return New().Open
+	})
+} // func TestStore(t *testing.T)
@@ -0,0 +1,734 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/memory/feed.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 21:40:03 krylon>
+
+package memory
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/model"
+)
+
+// loadFeed returns a copy of the stored Feed with the given ID, or nil if
+// there is no such Feed.
+func (t *tables) loadFeed(id int64) *model.Feed {
+	var (
+		f  model.Feed
+		ok bool
+	)
+
+	if f, ok = t.feeds[id]; !ok {
+		return nil
+	}
+
+	f.Headers = maps.Clone(f.Headers)
+	return &f
+} // func (t *tables) loadFeed(id int64) *model.Feed
+
+// updateFeed applies the given function to the stored Feed with the given ID.
+// As with an UPDATE statement, it is not an error if the Feed does not exist.
+func (t *tables) updateFeed(id int64, fn func(f *model.Feed)) {
+	var (
+		f  model.Feed
+		ok bool
+	)
+
+	if f, ok = t.feeds[id]; ok {
+		fn(&f)
+		t.feeds[id] = f
+	}
+} // func (t *tables) updateFeed(id int64, fn func(f *model.Feed))
+
+// FeedAdd enters a Feed into the database.
+func (db *DB) FeedAdd(f *model.Feed) error {
+	var (
+		t        = db.lock()
+		interval = time.Second * time.Duration(f.UpdateInterval.Seconds())
+	)
+	defer db.unlock()
+
+	if interval <= 0 {
+		return fmt.Errorf("%w: Update interval of Feed %s must be positive",
+			ErrConstraint,
+			f.Title)
+	}
+
+	for _, other := range t.feeds {
+		if other.Title == f.Title {
+			return fmt.Errorf("%w: Feed title %q is not unique",
+				ErrConstraint,
+				f.Title)
+		} else if other.URL.String() == f.URL.String() {
+			return fmt.Errorf("%w: Feed URL %s is not unique",
+				ErrConstraint,
+				f.URL)
+		}
+	}
+
+	var rec = model.Feed{
+		ID:             t.nextID(),
+		Title:          f.Title,
+		URL:            copyURL(f.URL),
+		Homepage:       copyURL(f.Homepage),
+		UpdateInterval: interval,
+		LastRefresh:    time.Unix(0, 0),
+		Active:         true,
+		Folder:         f.Folder,
+	}
+
+	t.feeds[rec.ID] = rec
+	f.ID = rec.ID
+	return nil
+} // func (db *DB) FeedAdd(f *model.Feed) error
+
+// FeedGetByID loads a Feed by its ID.
+func (db *DB) FeedGetByID(id int64) (*model.Feed, error) {
+	var t = db.lock()
+	defer db.unlock()
+
+	return t.loadFeed(id), nil
+} // func (db *DB) FeedGetByID(id int64) (*model.Feed, error)
+
+// FeedGetAll loads all Feeds, ordered by folder and title.
+func (db *DB) FeedGetAll() ([]model.Feed, error) {
+	var t = db.lock()
+	defer db.unlock()
+
+	return t.feedList(func(*model.Feed) bool { return true }), nil
+} // func (db *DB) FeedGetAll() ([]model.Feed, error)
+
+// FeedGetPending loads all Feeds that need to be refreshed.
+func (db *DB) FeedGetPending() ([]model.Feed, error) {
+	var (
+		t   = db.lock()
+		now = time.Now().Unix()
+	)
+	defer db.unlock()
+
+	return t.feedList(func(f *model.Feed) bool {
+		var next = f.LastRefresh.Add(f.UpdateInterval)
+
+		if !f.Schedule.Next.IsZero() {
+			next = f.Schedule.Next
+		}
+
+		if !f.Active || next.Unix() >= now || f.NextAttempt.Unix() >= now {
+			return false
+		}
+
+		// A Feed with an active WebSub subscription is refreshed once a
+		// day, the hub tells us about updates in the meantime.
+		for _, s := range t.subs {
+			if s.FeedID == f.ID &&
+				s.State == model.SubActive &&
+				s.LeaseExpires.Unix() > now &&
+				f.LastRefresh.Unix()+86400 > now {
+				return false
+			}
+		}
+
+		return true
+	}), nil
+} // func (db *DB) FeedGetPending() ([]model.Feed, error)
+
+// feedList returns all Feeds the given function accepts, ordered by folder
+// and title.
+func (t *tables) feedList(accept func(f *model.Feed) bool) []model.Feed {
+	var feeds = make([]model.Feed, 0, len(t.feeds))
+
+	for id := range t.feeds {
+		var f = t.loadFeed(id)
+
+		if accept(f) {
+			feeds = append(feeds, *f)
+		}
+	}
+
+	slices.SortFunc(feeds, func(a, b model.Feed) int {
+		if a.Folder != b.Folder {
+			return strings.Compare(a.Folder, b.Folder)
+		}
+
+		return strings.Compare(a.Title, b.Title)
+	})
+
+	return feeds
+} // func (t *tables) feedList(accept func(f *model.Feed) bool) []model.Feed
+
+// FeedGetUnreadCnt returns a map of all Feed IDs and the number of unread
+// Items in each Feed.
+func (db *DB) FeedGetUnreadCnt() (map[int64]int64, error) {
+	var (
+		t   = db.lock()
+		cnt = make(map[int64]int64, len(t.feeds))
+	)
+	defer db.unlock()
+
+	for id := range t.feeds {
+		cnt[id] = 0
+	}
+
+	for _, i := range t.items {
+		if i.ReadAt.IsZero() {
+			cnt[i.FeedID]++
+		}
+	}
+
+	return cnt, nil
+} // func (db *DB) FeedGetUnreadCnt() (map[int64]int64, error)
+
+// FeedUpdateRefresh updates the given Feed's LastRefresh timestamp
+func (db *DB) FeedUpdateRefresh(f *model.Feed, ts time.Time) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateFeed(f.ID, func(r *model.Feed) { r.LastRefresh = time.Unix(ts.Unix(), 0) })
+	f.LastRefresh = ts
+	return nil
+} // func (db *DB) FeedUpdateRefresh(f *model.Feed, ts time.Time) error
+
+// FeedUpdateHTTPState stores the ETag and Last-Modified values as well as
+// the status code of the last response.
+func (db *DB) FeedUpdateHTTPState(f *model.Feed, etag, lastMod string, code int) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateFeed(f.ID, func(r *model.Feed) {
+		r.ETag = etag
+		r.LastModified = lastMod
+		r.LastStatus = code
+	})
+
+	f.ETag = etag
+	f.LastModified = lastMod
+	f.LastStatus = code
+	return nil
+} // func (db *DB) FeedUpdateHTTPState(f *model.Feed, etag, lastMod string, code int) error
+
+// FeedRecordFailure increments the given Feed's failure counter and stores the
+// error message, along with the earliest time of the next attempt.
+// If active is false, the Feed is disabled.
+func (db *DB) FeedRecordFailure(f *model.Feed, errmsg string, next time.Time, active bool) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	if f.Failures+1 < 0 {
+		return fmt.Errorf("%w: Failure count of Feed %s must not be negative",
+			ErrConstraint,
+			f.Title)
+	}
+
+	t.updateFeed(f.ID, func(r *model.Feed) {
+		r.Failures = f.Failures + 1
+		r.LastError = errmsg
+		r.NextAttempt = stamp(next)
+		r.Active = active
+	})
+
+	f.Failures++
+	f.LastError = errmsg
+	f.NextAttempt = next
+	f.Active = active
+	return nil
+} // func (db *DB) FeedRecordFailure(f *model.Feed, errmsg string, next time.Time, active bool) error
+
+// FeedResetFailures clears the failure counter and error message of the given Feed.
+func (db *DB) FeedResetFailures(f *model.Feed) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateFeed(f.ID, func(r *model.Feed) {
+		r.Failures = 0
+		r.LastError = ""
+		r.NextAttempt = time.Time{}
+	})
+
+	f.Failures = 0
+	f.LastError = ""
+	f.NextAttempt = time.Time{}
+	return nil
+} // func (db *DB) FeedResetFailures(f *model.Feed) error
+
+// FeedPostpone sets the earliest time for the next attempt to fetch the Feed.
+func (db *DB) FeedPostpone(f *model.Feed, next time.Time) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateFeed(f.ID, func(r *model.Feed) { r.NextAttempt = stamp(next) })
+	f.NextAttempt = next
+	return nil
+} // func (db *DB) FeedPostpone(f *model.Feed, next time.Time) error
+
+// FeedSetMeta stores the description, language, image and generator the Feed
+// gives for itself.
+func (db *DB) FeedSetMeta(f *model.Feed, meta model.FeedMeta) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateFeed(f.ID, func(r *model.Feed) {
+		r.Meta.Description = meta.Description
+		r.Meta.Language = meta.Language
+		r.Meta.Image = nil
+		if meta.Image != nil {
+			r.Meta.Image = copyURL(meta.Image)
+		}
+		r.Meta.Generator = meta.Generator
+	})
+
+	f.Meta.Description = meta.Description
+	f.Meta.Language = meta.Language
+	f.Meta.Image = meta.Image
+	f.Meta.Generator = meta.Generator
+	return nil
+} // func (db *DB) FeedSetMeta(f *model.Feed, meta model.FeedMeta) error
+
+// FeedSetIcon stores the path and MIME type of the Feed's cached icon and
+// the time we last looked for one.
+func (db *DB) FeedSetIcon(f *model.Feed, icon, mimeType string, checked time.Time) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateFeed(f.ID, func(r *model.Feed) {
+		r.Meta.Icon = icon
+		r.Meta.IconType = mimeType
+		r.Meta.IconChecked = stamp(checked)
+	})
+
+	f.Meta.Icon = icon
+	f.Meta.IconType = mimeType
+	f.Meta.IconChecked = checked
+	return nil
+} // func (db *DB) FeedSetIcon(f *model.Feed, icon, mimeType string, checked time.Time) error
+
+// FeedUpdate changes the title, URL, homepage and update interval of a Feed.
+// If the URL changes, the Feed's state is reset, its WebSub subscription is
+// removed and the change is recorded in the Feed's history.
+func (db *DB) FeedUpdate(f *model.Feed, title string, addr, homepage *url.URL, interval time.Duration) error {
+	return db.feedChange(f, title, addr, homepage, interval, "Changed by user")
+} // func (db *DB) FeedUpdate(f *model.Feed, title string, addr, homepage *url.URL, interval time.Duration) error
+
+// FeedSetURL changes the URL of a Feed, e.g. because it has moved
+// permanently. Like FeedUpdate, it resets the Feed's state and records the
+// change, along with the given reason, in the Feed's history.
+func (db *DB) FeedSetURL(f *model.Feed, addr *url.URL, reason string) error {
+	return db.feedChange(f, f.Title, addr, database.ResolveHomepage(f, addr), f.UpdateInterval, reason)
+} // func (db *DB) FeedSetURL(f *model.Feed, addr *url.URL, reason string) error
+
+func (db *DB) feedChange(f *model.Feed, title string, addr, homepage *url.URL, interval time.Duration, reason string) error {
+	var (
+		t     = db.lock()
+		moved bool
+	)
+	defer db.unlock()
+
+	title = strings.TrimSpace(title)
+
+	if homepage == nil {
+		homepage = new(url.URL)
+	}
+
+	if title == "" {
+		return fmt.Errorf("%w: Feed title must not be empty", database.ErrInvalidValue)
+	} else if addr == nil || !addr.IsAbs() || (addr.Scheme != "http" && addr.Scheme != "https") || addr.Host == "" {
+		return fmt.Errorf("%w: Feed URL must be an absolute http(s) URL", database.ErrInvalidValue)
+	} else if homepage.String() != "" && !homepage.IsAbs() {
+		return fmt.Errorf("%w: Homepage must be an absolute URL", database.ErrInvalidValue)
+	} else if interval < time.Minute {
+		return fmt.Errorf("%w: Update interval must be at least one minute", database.ErrInvalidValue)
+	}
+
+	for id, other := range t.feeds {
+		if id == f.ID {
+			continue
+		} else if other.Title == title {
+			return fmt.Errorf("%w: Feed title %q is not unique",
+				ErrConstraint,
+				title)
+		} else if other.URL.String() == addr.String() {
+			return fmt.Errorf("%w: Feed URL %s is not unique",
+				ErrConstraint,
+				addr)
+		}
+	}
+
+	moved = f.URL == nil || f.URL.String() != addr.String()
+
+	t.updateFeed(f.ID, func(r *model.Feed) {
+		r.Title = title
+		r.URL = copyURL(addr)
+		r.Homepage = copyURL(homepage)
+		r.UpdateInterval = time.Second * time.Duration(interval.Seconds())
+
+		if moved {
+			r.ETag = ""
+			r.LastModified = ""
+			r.LastStatus = 0
+			r.LastRefresh = time.Unix(0, 0)
+			r.Failures = 0
+			r.LastError = ""
+			r.NextAttempt = time.Time{}
+			r.Schedule = model.Schedule{}
+		}
+	})
+
+	if moved {
+		var m = model.FeedMove{
+			ID:        t.nextID(),
+			FeedID:    f.ID,
+			Timestamp: stamp(time.Now()),
+			OldURL:    copyURL(f.URL),
+			NewURL:    copyURL(addr),
+			Reason:    reason,
+		}
+
+		for id, s := range t.subs {
+			if s.FeedID == f.ID {
+				delete(t.subs, id)
+			}
+		}
+
+		t.history = append(t.history, m)
+
+		f.ETag = ""
+		f.LastModified = ""
+		f.LastStatus = 0
+		f.LastRefresh = time.Unix(0, 0)
+		f.Failures = 0
+		f.LastError = ""
+		f.NextAttempt = time.Unix(0, 0)
+		f.Schedule = model.Schedule{}
+	}
+
+	f.Title = title
+	f.URL = addr
+	f.Homepage = homepage
+	f.UpdateInterval = interval
+	return nil
+} // func (db *DB) feedChange(f *model.Feed, title string, addr, homepage *url.URL, interval time.Duration, reason string) error
+
+// FeedHistoryGetByFeed returns the changes of the given Feed's URL, most
+// recent first.
+func (db *DB) FeedHistoryGetByFeed(f *model.Feed) ([]model.FeedMove, error) {
+	var (
+		t    = db.lock()
+		list = make([]model.FeedMove, 0)
+	)
+	defer db.unlock()
+
+	for _, m := range t.history {
+		if m.FeedID == f.ID {
+			list = append(list, m)
+		}
+	}
+
+	slices.SortFunc(list, func(a, b model.FeedMove) int {
+		if c := b.Timestamp.Compare(a.Timestamp); c != 0 {
+			return c
+		}
+
+		return int(b.ID - a.ID)
+	})
+
+	return list, nil
+} // func (db *DB) FeedHistoryGetByFeed(f *model.Feed) ([]model.FeedMove, error)
+
+// FeedSetRetention sets the number of days we keep the Items of the given
+// Feed that the user has neither rated nor tagged.
+func (db *DB) FeedSetRetention(f *model.Feed, days int) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateFeed(f.ID, func(r *model.Feed) { r.Retention = days })
+	f.Retention = days
+	return nil
+} // func (db *DB) FeedSetRetention(f *model.Feed, days int) error
+
+// FeedSetActive sets the given Feed's Active flag
+func (db *DB) FeedSetActive(f *model.Feed, active bool) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateFeed(f.ID, func(r *model.Feed) { r.Active = active })
+	f.Active = active
+	return nil
+} // func (db *DB) FeedSetActive(f *model.Feed, active bool) error
+
+// FeedSetFetchFull sets the flag that tells the Reader to fetch the full
+// article text of the given Feed's Items.
+func (db *DB) FeedSetFetchFull(f *model.Feed, full bool) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateFeed(f.ID, func(r *model.Feed) { r.FetchFull = full })
+	f.FetchFull = full
+	return nil
+} // func (db *DB) FeedSetFetchFull(f *model.Feed, full bool) error
+
+// FeedSetDownload sets the flag that tells the download manager to fetch the
+// Enclosures of the given Feed's Items.
+func (db *DB) FeedSetDownload(f *model.Feed, download bool) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateFeed(f.ID, func(r *model.Feed) { r.Download = download })
+	f.Download = download
+	return nil
+} // func (db *DB) FeedSetDownload(f *model.Feed, download bool) error
+
+// FeedSetHTTPSettings sets the custom headers and the credentials we send when
+// fetching the given Feed. Unlike the Database, we keep the credentials in
+// plain text.
+func (db *DB) FeedSetHTTPSettings(f *model.Feed, headers map[string]string, auth model.Credentials) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	if auth.Kind > model.AuthBearer {
+		return fmt.Errorf("%w: Invalid authentication method %s",
+			ErrConstraint,
+			auth.Kind)
+	}
+
+	t.updateFeed(f.ID, func(r *model.Feed) {
+		r.Headers = nil
+		if len(headers) > 0 {
+			r.Headers = maps.Clone(headers)
+		}
+		r.Auth = auth
+	})
+
+	f.Headers = headers
+	f.Auth = auth
+	return nil
+} // func (db *DB) FeedSetHTTPSettings(f *model.Feed, headers map[string]string, auth model.Credentials) error
+
+// FeedSetIntervalBound sets whether the UpdateInterval of the given Feed is
+// a lower or an upper bound for its refresh schedule.
+func (db *DB) FeedSetIntervalBound(f *model.Feed, bound model.IntervalBound) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	if bound > model.BoundUpper {
+		return fmt.Errorf("%w: Invalid interval bound %s",
+			ErrConstraint,
+			bound)
+	}
+
+	t.updateFeed(f.ID, func(r *model.Feed) { r.Bound = bound })
+	f.Bound = bound
+	return nil
+} // func (db *DB) FeedSetIntervalBound(f *model.Feed, bound model.IntervalBound) error
+
+// FeedSetSchedule stores the refresh schedule of the given Feed.
+func (db *DB) FeedSetSchedule(f *model.Feed, sched model.Schedule) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	if sched.TTL < 0 || sched.Cadence < 0 {
+		return fmt.Errorf("%w: TTL and cadence must not be negative",
+			ErrConstraint)
+	}
+
+	t.updateFeed(f.ID, func(r *model.Feed) {
+		r.Schedule = model.Schedule{
+			TTL:       time.Second * time.Duration(sched.TTL.Seconds()),
+			SkipHours: sched.SkipHours,
+			SkipDays:  sched.SkipDays,
+			Cadence:   time.Second * time.Duration(sched.Cadence.Seconds()),
+			Next:      stamp(sched.Next),
+		}
+	})
+
+	f.Schedule = sched
+	return nil
+} // func (db *DB) FeedSetSchedule(f *model.Feed, sched model.Schedule) error
+
+// FeedDelete removes the given Feed. Its Items have to be deleted first.
+func (db *DB) FeedDelete(f *model.Feed) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	for _, i := range t.items {
+		if i.FeedID == f.ID {
+			return fmt.Errorf("%w: Feed %s still has Items",
+				ErrConstraint,
+				f.Title)
+		}
+	}
+
+	delete(t.feeds, f.ID)
+
+	for id, s := range t.subs {
+		if s.FeedID == f.ID {
+			delete(t.subs, id)
+		}
+	}
+
+	for id, m := range t.catmaps {
+		if m.FeedID == f.ID {
+			delete(t.catmaps, id)
+		}
+	}
+
+	t.history = slices.DeleteFunc(t.history, func(m model.FeedMove) bool {
+		return m.FeedID == f.ID
+	})
+
+	return nil
+} // func (db *DB) FeedDelete(f *model.Feed) error
+
+// WebSubAdd adds a Subscription. If the Feed already has one, it is
+// replaced, but it keeps its state unless the hub or the topic have changed.
+func (db *DB) WebSubAdd(s *model.Subscription) error {
+	var (
+		t     = db.lock()
+		rec   model.Subscription
+		found bool
+	)
+	defer db.unlock()
+
+	if _, ok := t.feeds[s.FeedID]; !ok {
+		return fmt.Errorf("%w: Feed %d does not exist",
+			ErrConstraint,
+			s.FeedID)
+	}
+
+	for _, other := range t.subs {
+		if other.FeedID == s.FeedID {
+			rec, found = other, true
+			break
+		}
+	}
+
+	if !found {
+		rec = model.Subscription{
+			ID:     t.nextID(),
+			FeedID: s.FeedID,
+			State:  model.SubPending,
+		}
+	} else if rec.Hub.String() != s.Hub.String() || rec.Topic.String() != s.Topic.String() {
+		rec.State = model.SubPending
+	}
+
+	rec.Hub = copyURL(s.Hub)
+	rec.Topic = copyURL(s.Topic)
+	rec.Secret = s.Secret
+	rec.Requested = time.Unix(s.Requested.Unix(), 0)
+	rec.LeaseExpires = stamp(s.LeaseExpires)
+
+	t.subs[rec.ID] = rec
+
+	s.ID = rec.ID
+	s.State = rec.State
+	return nil
+} // func (db *DB) WebSubAdd(s *model.Subscription) error
+
+// WebSubGetByFeed loads the Subscription for the given Feed, if there is one.
+func (db *DB) WebSubGetByFeed(f *model.Feed) (*model.Subscription, error) {
+	var t = db.lock()
+	defer db.unlock()
+
+	for _, s := range t.subs {
+		if s.FeedID == f.ID {
+			return &s, nil
+		}
+	}
+
+	return nil, nil
+} // func (db *DB) WebSubGetByFeed(f *model.Feed) (*model.Subscription, error)
+
+// WebSubGetRenewable returns all active Subscriptions whose lease expires
+// before the given time, as well as all pending ones, that were requested
+// before the deadline.
+func (db *DB) WebSubGetRenewable(expires, deadline time.Time) ([]*model.Subscription, error) {
+	var (
+		t    = db.lock()
+		list = make([]*model.Subscription, 0)
+	)
+	defer db.unlock()
+
+	for _, id := range slices.Sorted(maps.Keys(t.subs)) {
+		var s = t.subs[id]
+
+		if ((s.State == model.SubActive && s.LeaseExpires.Unix() < expires.Unix()) ||
+			s.State == model.SubPending) &&
+			s.Requested.Unix() < deadline.Unix() {
+			list = append(list, &s)
+		}
+	}
+
+	return list, nil
+} // func (db *DB) WebSubGetRenewable(expires, deadline time.Time) ([]*model.Subscription, error)
+
+// updateSub applies the given function to the stored Subscription with the
+// given ID, if it exists.
+func (t *tables) updateSub(id int64, fn func(s *model.Subscription)) {
+	var (
+		s  model.Subscription
+		ok bool
+	)
+
+	if s, ok = t.subs[id]; ok {
+		fn(&s)
+		t.subs[id] = s
+	}
+} // func (t *tables) updateSub(id int64, fn func(s *model.Subscription))
+
+// WebSubSetActive marks a Subscription as confirmed by the hub, with the lease
+// expiring at the given time.
+func (db *DB) WebSubSetActive(s *model.Subscription, expires time.Time) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateSub(s.ID, func(r *model.Subscription) {
+		r.State = model.SubActive
+		r.LeaseExpires = stamp(expires)
+	})
+
+	s.State = model.SubActive
+	s.LeaseExpires = expires
+	return nil
+} // func (db *DB) WebSubSetActive(s *model.Subscription, expires time.Time) error
+
+// WebSubSetState sets the state of a Subscription.
+func (db *DB) WebSubSetState(s *model.Subscription, state model.SubState) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	if state > model.SubUnsubscribing {
+		return fmt.Errorf("%w: Invalid Subscription state %s",
+			ErrConstraint,
+			state)
+	}
+
+	t.updateSub(s.ID, func(r *model.Subscription) { r.State = state })
+	s.State = state
+	return nil
+} // func (db *DB) WebSubSetState(s *model.Subscription, state model.SubState) error
+
+// WebSubSetLastPush records the time the hub last pushed an update to us.
+func (db *DB) WebSubSetLastPush(s *model.Subscription, ts time.Time) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateSub(s.ID, func(r *model.Subscription) { r.LastPush = stamp(ts) })
+	s.LastPush = ts
+	return nil
+} // func (db *DB) WebSubSetLastPush(s *model.Subscription, ts time.Time) error
+
+// WebSubDelete removes a Subscription.
+func (db *DB) WebSubDelete(s *model.Subscription) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	delete(t.subs, s.ID)
+	return nil
+} // func (db *DB) WebSubDelete(s *model.Subscription) error
@@ -0,0 +1,796 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/memory/item.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 22:26:18 krylon>
+
+package memory
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/blicero/badnews/common"
+	"github.com/blicero/badnews/model"
+)
+
+// loadItem returns a copy of the given Item as the database would load it,
+// including its content and number of revisions.
+func (t *tables) loadItem(rec *item) *model.Item {
+	var i = &model.Item{
+		ID:          rec.ID,
+		FeedID:      rec.FeedID,
+		URL:         copyURL(rec.URL),
+		Timestamp:   rec.Timestamp,
+		Headline:    rec.Headline,
+		Description: rec.Description,
+		Categories:  slices.Clone(rec.Categories),
+		Authors:     slices.Clone(rec.Authors),
+		Content:     t.content[rec.ID],
+		Rating:      rec.Rating,
+		ReadAt:      rec.ReadAt,
+		Starred:     rec.Starred,
+	}
+
+	for _, r := range t.revisions {
+		if r.ItemID == rec.ID {
+			i.Revisions++
+		}
+	}
+
+	return i
+} // func (t *tables) loadItem(rec *item) *model.Item
+
+// itemList returns all Items the given function accepts, newest first.
+func (t *tables) itemList(accept func(i *item) bool) []*model.Item {
+	var items = make([]*model.Item, 0)
+
+	for _, id := range t.itemsByTime() {
+		var rec = t.items[id]
+
+		if accept(&rec) {
+			items = append(items, t.loadItem(&rec))
+		}
+	}
+
+	return items
+} // func (t *tables) itemList(accept func(i *item) bool) []*model.Item
+
+// itemsByTime returns the IDs of all Items, newest first.
+func (t *tables) itemsByTime() []int64 {
+	var ids = slices.Collect(maps.Keys(t.items))
+
+	slices.SortFunc(ids, func(a, b int64) int {
+		if c := t.items[b].Timestamp.Compare(t.items[a].Timestamp); c != 0 {
+			return c
+		}
+
+		return int(b - a)
+	})
+
+	return ids
+} // func (t *tables) itemsByTime() []int64
+
+// page returns the part of the list that LIMIT and OFFSET select. Like in
+// SQLite, a negative limit means there is none.
+func page[T any](list []T, limit, offset int64) []T {
+	if offset >= int64(len(list)) {
+		return list[:0]
+	} else if offset > 0 {
+		list = list[offset:]
+	}
+
+	if limit >= 0 && limit < int64(len(list)) {
+		list = list[:limit]
+	}
+
+	return list
+} // func page[T any](list []T, limit, offset int64) []T
+
+// matchFilter returns true if the Item matches the given ItemFilter.
+func matchFilter(i *item, filter model.ItemFilter) bool {
+	return (!filter.Unread || i.ReadAt.IsZero()) && (!filter.Starred || i.Starred)
+} // func matchFilter(i *item, filter model.ItemFilter) bool
+
+// updateItem applies the given function to the stored Item with the given
+// ID. It returns false if there is no such Item.
+func (t *tables) updateItem(id int64, fn func(i *item)) bool {
+	var (
+		rec item
+		ok  bool
+	)
+
+	if rec, ok = t.items[id]; ok {
+		fn(&rec)
+		t.items[id] = rec
+	}
+
+	return ok
+} // func (t *tables) updateItem(id int64, fn func(i *item)) bool
+
+// insertItem stores a new Item. It returns false if an Item with the same
+// URL exists already.
+func (t *tables) insertItem(i *model.Item) (bool, error) {
+	if _, ok := t.feeds[i.FeedID]; !ok {
+		return false, fmt.Errorf("%w: Feed %d of Item %s does not exist",
+			ErrConstraint,
+			i.FeedID,
+			i.Headline)
+	}
+
+	for _, other := range t.items {
+		if other.URL.String() == i.URL.String() {
+			return false, nil
+		}
+	}
+
+	var rec = item{
+		Item: model.Item{
+			ID:          t.nextID(),
+			FeedID:      i.FeedID,
+			URL:         copyURL(i.URL),
+			Timestamp:   time.Unix(i.Timestamp.Unix(), 0),
+			Headline:    i.Headline,
+			Description: i.Description,
+			Categories:  slices.Clone(i.Categories),
+			Authors:     slices.Clone(i.Authors),
+			GUID:        i.GUID,
+		},
+		canonical: common.CanonicalURL(i.URL),
+	}
+
+	t.items[rec.ID] = rec
+	i.ID = rec.ID
+	return true, nil
+} // func (t *tables) insertItem(i *model.Item) (bool, error)
+
+// deleteItem removes an Item along with its content, revisions, Enclosures
+// and Tag links.
+func (t *tables) deleteItem(id int64) {
+	delete(t.items, id)
+	delete(t.content, id)
+
+	t.revisions = slices.DeleteFunc(t.revisions, func(r model.Revision) bool {
+		return r.ItemID == id
+	})
+
+	for eid, e := range t.enclosures {
+		if e.ItemID == id {
+			delete(t.enclosures, eid)
+		}
+	}
+
+	for k := range t.links {
+		if k.item == id {
+			delete(t.links, k)
+		}
+	}
+} // func (t *tables) deleteItem(id int64)
+
+// ItemAdd adds a news item.
+func (db *DB) ItemAdd(i *model.Item) error {
+	var (
+		err   error
+		added bool
+		t     = db.lock()
+	)
+	defer db.unlock()
+
+	if added, err = t.insertItem(i); err != nil {
+		return err
+	} else if !added {
+		return fmt.Errorf("%w: Item URL %s is not unique",
+			ErrConstraint,
+			i.URL)
+	}
+
+	return nil
+} // func (db *DB) ItemAdd(i *model.Item) error
+
+// ItemUpsert adds a news item, unless an Item with the same URL exists
+// already. It returns true if the Item was added.
+func (db *DB) ItemUpsert(i *model.Item) (bool, error) {
+	var t = db.lock()
+	defer db.unlock()
+
+	return t.insertItem(i)
+} // func (db *DB) ItemUpsert(i *model.Item) (bool, error)
+
+// purgeable returns true if the Item has neither been rated, starred nor
+// tagged by the user, and is not among the results of a Search.
+func (t *tables) purgeable(i *item) bool {
+	if i.Rating != 0 || i.Starred {
+		return false
+	}
+
+	for k, auto := range t.links {
+		if k.item == i.ID && !auto {
+			return false
+		}
+	}
+
+	for _, s := range t.searches {
+		if slices.Contains(s.results, i.ID) {
+			return false
+		}
+	}
+
+	return true
+} // func (t *tables) purgeable(i *item) bool
+
+// ItemGetPurgeable returns the Items that the retention policy says we can
+// delete. The Items are loaded without Description and Content.
+func (db *DB) ItemGetPurgeable(global int, now time.Time) ([]*model.Item, error) {
+	var (
+		t     = db.lock()
+		items = make([]*model.Item, 0)
+	)
+	defer db.unlock()
+
+	for _, rec := range t.items {
+		var days = t.feeds[rec.FeedID].Retention
+
+		if days == 0 {
+			days = global
+		}
+
+		if days <= 0 ||
+			rec.Timestamp.Unix() >= now.Unix()-86400*int64(days) ||
+			!t.purgeable(&rec) {
+			continue
+		}
+
+		items = append(items, &model.Item{
+			ID:        rec.ID,
+			FeedID:    rec.FeedID,
+			URL:       copyURL(rec.URL),
+			Timestamp: rec.Timestamp,
+			Headline:  rec.Headline,
+		})
+	}
+
+	slices.SortFunc(items, func(a, b *model.Item) int {
+		if a.FeedID != b.FeedID {
+			return int(a.FeedID - b.FeedID)
+		}
+
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	return items, nil
+} // func (db *DB) ItemGetPurgeable(global int, now time.Time) ([]*model.Item, error)
+
+// ItemPurge deletes an Item the retention policy has selected for removal.
+// If the user has rated, starred or tagged the Item in the meantime, it is
+// kept and ItemPurge returns false.
+func (db *DB) ItemPurge(i *model.Item) (bool, error) {
+	var (
+		t   = db.lock()
+		rec item
+		ok  bool
+	)
+	defer db.unlock()
+
+	if rec, ok = t.items[i.ID]; !ok || !t.purgeable(&rec) {
+		return false, nil
+	}
+
+	t.deleteItem(i.ID)
+	return true, nil
+} // func (db *DB) ItemPurge(i *model.Item) (bool, error)
+
+// ItemDeleteByFeed removes all Items that belong to the given Feed.
+func (db *DB) ItemDeleteByFeed(f *model.Feed) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	for id, rec := range t.items {
+		if rec.FeedID == f.ID {
+			t.deleteItem(id)
+		}
+	}
+
+	return nil
+} // func (db *DB) ItemDeleteByFeed(f *model.Feed) error
+
+// ItemGetByKey looks up the Item that the given Item is a duplicate of, that
+// is the oldest Item with the same URL, canonical URL, or GUID within the
+// same Feed. If no such Item exists, it returns nil.
+func (db *DB) ItemGetByKey(i *model.Item) (*model.Item, error) {
+	var (
+		t     = db.lock()
+		addr  = i.URL.String()
+		canon = common.CanonicalURL(i.URL)
+	)
+	defer db.unlock()
+
+	for _, id := range slices.Sorted(maps.Keys(t.items)) {
+		var rec = t.items[id]
+
+		if rec.URL.String() == addr ||
+			rec.canonical == canon ||
+			(rec.FeedID == i.FeedID && rec.GUID != "" && rec.GUID == i.GUID) {
+			return &model.Item{
+				ID:          rec.ID,
+				FeedID:      rec.FeedID,
+				URL:         copyURL(rec.URL),
+				Timestamp:   rec.Timestamp,
+				Headline:    rec.Headline,
+				Description: rec.Description,
+				Rating:      rec.Rating,
+			}, nil
+		}
+	}
+
+	return nil, nil
+} // func (db *DB) ItemGetByKey(i *model.Item) (*model.Item, error)
+
+// ItemUpdate sets the Headline and Description of the given Item.
+func (db *DB) ItemUpdate(i *model.Item, headline, description string) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateItem(i.ID, func(r *item) {
+		r.Headline = headline
+		r.Description = description
+	})
+
+	i.Headline = headline
+	i.Description = description
+	return nil
+} // func (db *DB) ItemUpdate(i *model.Item, headline, description string) error
+
+// ItemRevisionAdd saves the current Headline and Description of the given Item
+// as a Revision.
+func (db *DB) ItemRevisionAdd(i *model.Item, ts time.Time) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	if _, ok := t.items[i.ID]; !ok {
+		return fmt.Errorf("%w: Item %d does not exist",
+			ErrConstraint,
+			i.ID)
+	}
+
+	t.revisions = append(t.revisions, model.Revision{
+		ID:          t.nextID(),
+		ItemID:      i.ID,
+		Timestamp:   time.Unix(ts.Unix(), 0),
+		Headline:    i.Headline,
+		Description: i.Description,
+	})
+
+	return nil
+} // func (db *DB) ItemRevisionAdd(i *model.Item, ts time.Time) error
+
+// ItemRevisionGetByItem loads all Revisions of the given Item, oldest first.
+func (db *DB) ItemRevisionGetByItem(i *model.Item) ([]model.Revision, error) {
+	var (
+		t    = db.lock()
+		revs = make([]model.Revision, 0)
+	)
+	defer db.unlock()
+
+	for _, r := range t.revisions {
+		if r.ItemID == i.ID {
+			revs = append(revs, r)
+		}
+	}
+
+	slices.SortFunc(revs, func(a, b model.Revision) int {
+		if c := a.Timestamp.Compare(b.Timestamp); c != 0 {
+			return c
+		}
+
+		return int(a.ID - b.ID)
+	})
+
+	return revs, nil
+} // func (db *DB) ItemRevisionGetByItem(i *model.Item) ([]model.Revision, error)
+
+// ItemGetRecent loads all items newer than the given timestamp.
+func (db *DB) ItemGetRecent(begin time.Time) ([]*model.Item, error) {
+	var t = db.lock()
+	defer db.unlock()
+
+	return t.itemList(func(i *item) bool {
+		return i.Timestamp.Unix() > begin.Unix()
+	}), nil
+} // func (db *DB) ItemGetRecent(begin time.Time) ([]*model.Item, error)
+
+// ItemGetRecentPaged fetches up to cnt of the most recent news items, skipping
+// the first offset items, restricted by the filter.
+func (db *DB) ItemGetRecentPaged(cnt, offset int64, filter model.ItemFilter) ([]*model.Item, error) {
+	var t = db.lock()
+	defer db.unlock()
+
+	return page(t.itemList(func(i *item) bool {
+		return matchFilter(i, filter)
+	}), cnt, offset), nil
+} // func (db *DB) ItemGetRecentPaged(cnt, offset int64, filter model.ItemFilter) ([]*model.Item, error)
+
+// ItemGetByID loads an Item by its ID
+func (db *DB) ItemGetByID(id int64) (*model.Item, error) {
+	var (
+		t   = db.lock()
+		rec item
+		ok  bool
+	)
+	defer db.unlock()
+
+	if rec, ok = t.items[id]; !ok {
+		return nil, nil
+	}
+
+	return t.loadItem(&rec), nil
+} // func (db *DB) ItemGetByID(id int64) (*model.Item, error)
+
+// ItemGetByFeed loads items from the given Feed, restricted by the filter.
+func (db *DB) ItemGetByFeed(f *model.Feed, limit, offset int64, filter model.ItemFilter) ([]*model.Item, error) {
+	var t = db.lock()
+	defer db.unlock()
+
+	return page(t.itemList(func(i *item) bool {
+		return i.FeedID == f.ID && matchFilter(i, filter)
+	}), limit, offset), nil
+} // func (db *DB) ItemGetByFeed(f *model.Feed, limit, offset int64, filter model.ItemFilter) ([]*model.Item, error)
+
+// ItemGetRated loads all items that have been manually rated.
+func (db *DB) ItemGetRated() ([]model.Item, error) {
+	var (
+		t     = db.lock()
+		items []model.Item
+	)
+	defer db.unlock()
+
+	for _, i := range t.itemList(func(i *item) bool { return i.Rating != 0 }) {
+		items = append(items, *i)
+	}
+
+	return items, nil
+} // func (db *DB) ItemGetRated() ([]model.Item, error)
+
+// ItemContentAdd stores the full text of the article the given Item links to.
+// If content for the Item already exists, it is replaced.
+func (db *DB) ItemContentAdd(i *model.Item, content string) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	if _, ok := t.items[i.ID]; !ok {
+		return fmt.Errorf("%w: Item %d does not exist",
+			ErrConstraint,
+			i.ID)
+	}
+
+	t.content[i.ID] = content
+	i.Content = content
+	return nil
+} // func (db *DB) ItemContentAdd(i *model.Item, content string) error
+
+// ItemRate sets an Item's rating to the given value
+func (db *DB) ItemRate(i *model.Item, r int8) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	if r < -1 || r > 1 {
+		return fmt.Errorf("%w: Invalid rating %d",
+			ErrConstraint,
+			r)
+	}
+
+	t.updateItem(i.ID, func(rec *item) { rec.Rating = r })
+	i.Rating = r
+	return nil
+} // func (db *DB) ItemRate(i *model.Item, r int8) error
+
+// ItemUnrate resets an Item's rating to zero.
+func (db *DB) ItemUnrate(i *model.Item) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateItem(i.ID, func(rec *item) { rec.Rating = 0 })
+	i.Rating = 0
+	return nil
+} // func (db *DB) ItemUnrate(i *model.Item) error
+
+// markRead marks the Items the given function accepts as read, unless they
+// have been read before. It returns the number of Items it has marked.
+func (t *tables) markRead(accept func(i *item) bool) int64 {
+	var (
+		cnt int64
+		now = time.Unix(time.Now().Unix(), 0)
+	)
+
+	for id, rec := range t.items {
+		if rec.ReadAt.IsZero() && accept(&rec) {
+			rec.ReadAt = now
+			t.items[id] = rec
+			cnt++
+		}
+	}
+
+	return cnt
+} // func (t *tables) markRead(accept func(i *item) bool) int64
+
+// ItemMarkRead marks an Item as read. If the Item has been read before, the
+// time it was first read is kept.
+func (db *DB) ItemMarkRead(i *model.Item) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	if t.markRead(func(rec *item) bool { return rec.ID == i.ID }) > 0 {
+		i.ReadAt = t.items[i.ID].ReadAt
+	}
+
+	return nil
+} // func (db *DB) ItemMarkRead(i *model.Item) error
+
+// ItemMarkUnread marks an Item as not read.
+func (db *DB) ItemMarkUnread(i *model.Item) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateItem(i.ID, func(rec *item) { rec.ReadAt = time.Time{} })
+	i.ReadAt = time.Time{}
+	return nil
+} // func (db *DB) ItemMarkUnread(i *model.Item) error
+
+// ItemMarkReadList marks the Items with the given IDs as read. It returns the
+// number of Items that had not been read before.
+func (db *DB) ItemMarkReadList(ids []int64) (int64, error) {
+	var t = db.lock()
+	defer db.unlock()
+
+	return t.markRead(func(rec *item) bool {
+		return slices.Contains(ids, rec.ID)
+	}), nil
+} // func (db *DB) ItemMarkReadList(ids []int64) (int64, error)
+
+// ItemMarkReadAbove marks the given Item and all Items that are newer than it
+// as read. If f is not nil, only Items from that Feed are marked. It returns
+// the number of Items that had not been read before.
+func (db *DB) ItemMarkReadAbove(i *model.Item, f *model.Feed) (int64, error) {
+	var (
+		t      = db.lock()
+		rec    item
+		ok     bool
+		feedID int64
+	)
+	defer db.unlock()
+
+	if f != nil {
+		feedID = f.ID
+	}
+
+	if rec, ok = t.items[i.ID]; !ok {
+		return 0, nil
+	}
+
+	return t.markRead(func(other *item) bool {
+		return !other.Timestamp.Before(rec.Timestamp) &&
+			(feedID == 0 || other.FeedID == feedID)
+	}), nil
+} // func (db *DB) ItemMarkReadAbove(i *model.Item, f *model.Feed) (int64, error)
+
+// ItemSetStarred sets or clears the Starred flag of an Item.
+func (db *DB) ItemSetStarred(i *model.Item, starred bool) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateItem(i.ID, func(rec *item) { rec.Starred = starred })
+	i.Starred = starred
+	return nil
+} // func (db *DB) ItemSetStarred(i *model.Item, starred bool) error
+
+// EnclosureAdd adds an Enclosure.
+func (db *DB) EnclosureAdd(e *model.Enclosure) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	if _, ok := t.items[e.ItemID]; !ok {
+		return fmt.Errorf("%w: Item %d of Enclosure %s does not exist",
+			ErrConstraint,
+			e.ItemID,
+			e.URL)
+	} else if e.Length < 0 {
+		return fmt.Errorf("%w: Length of Enclosure %s must not be negative",
+			ErrConstraint,
+			e.URL)
+	}
+
+	for _, other := range t.enclosures {
+		if other.ItemID == e.ItemID && other.URL.String() == e.URL.String() {
+			return fmt.Errorf("%w: Item %d already has an Enclosure %s",
+				ErrConstraint,
+				e.ItemID,
+				e.URL)
+		}
+	}
+
+	var rec = model.Enclosure{
+		ID:       t.nextID(),
+		ItemID:   e.ItemID,
+		URL:      copyURL(e.URL),
+		MimeType: e.MimeType,
+		Length:   e.Length,
+	}
+
+	t.enclosures[rec.ID] = rec
+	e.ID = rec.ID
+	return nil
+} // func (db *DB) EnclosureAdd(e *model.Enclosure) error
+
+// EnclosureGetByID loads an Enclosure by its ID.
+func (db *DB) EnclosureGetByID(id int64) (*model.Enclosure, error) {
+	var (
+		t  = db.lock()
+		e  model.Enclosure
+		ok bool
+	)
+	defer db.unlock()
+
+	if e, ok = t.enclosures[id]; !ok {
+		return nil, nil
+	}
+
+	return &e, nil
+} // func (db *DB) EnclosureGetByID(id int64) (*model.Enclosure, error)
+
+// enclosureList returns all Enclosures the given function accepts, ordered
+// by ID.
+func (t *tables) enclosureList(accept func(e *model.Enclosure) bool) []*model.Enclosure {
+	var list = make([]*model.Enclosure, 0)
+
+	for _, id := range slices.Sorted(maps.Keys(t.enclosures)) {
+		var e = t.enclosures[id]
+
+		if accept(&e) {
+			list = append(list, &e)
+		}
+	}
+
+	return list
+} // func (t *tables) enclosureList(accept func(e *model.Enclosure) bool) []*model.Enclosure
+
+// EnclosureGetByItem loads all Enclosures of the given Item.
+func (db *DB) EnclosureGetByItem(i *model.Item) ([]*model.Enclosure, error) {
+	var t = db.lock()
+	defer db.unlock()
+
+	return t.enclosureList(func(e *model.Enclosure) bool {
+		return e.ItemID == i.ID
+	}), nil
+} // func (db *DB) EnclosureGetByItem(i *model.Item) ([]*model.Enclosure, error)
+
+// EnclosureGetPending returns up to max Enclosures of Feeds with downloads
+// enabled that have not been downloaded yet and have failed fewer than
+// maxAttempts times, newest Items first.
+func (db *DB) EnclosureGetPending(maxAttempts, max int) ([]*model.Enclosure, error) {
+	var (
+		t    = db.lock()
+		list []*model.Enclosure
+	)
+	defer db.unlock()
+
+	list = t.enclosureList(func(e *model.Enclosure) bool {
+		var rec, ok = t.items[e.ItemID]
+
+		return ok &&
+			t.feeds[rec.FeedID].Download &&
+			e.Downloaded.IsZero() &&
+			!e.Expired &&
+			e.Attempts < maxAttempts
+	})
+
+	slices.SortStableFunc(list, func(a, b *model.Enclosure) int {
+		return t.items[b.ItemID].Timestamp.Compare(t.items[a.ItemID].Timestamp)
+	})
+
+	return page(list, int64(max), 0), nil
+} // func (db *DB) EnclosureGetPending(maxAttempts, max int) ([]*model.Enclosure, error)
+
+// EnclosureGetDownloaded returns all Enclosures that are currently stored
+// locally, oldest download first.
+func (db *DB) EnclosureGetDownloaded() ([]*model.Enclosure, error) {
+	var (
+		t    = db.lock()
+		list []*model.Enclosure
+	)
+	defer db.unlock()
+
+	list = t.enclosureList(func(e *model.Enclosure) bool {
+		return !e.Downloaded.IsZero() && !e.Expired
+	})
+
+	slices.SortStableFunc(list, func(a, b *model.Enclosure) int {
+		return a.Downloaded.Compare(b.Downloaded)
+	})
+
+	return list, nil
+} // func (db *DB) EnclosureGetDownloaded() ([]*model.Enclosure, error)
+
+// EnclosureGetTotalSize returns the number of bytes used by all downloaded
+// Enclosures.
+func (db *DB) EnclosureGetTotalSize() (int64, error) {
+	var (
+		t    = db.lock()
+		size int64
+	)
+	defer db.unlock()
+
+	for _, e := range t.enclosures {
+		if !e.Downloaded.IsZero() && !e.Expired {
+			size += e.Size
+		}
+	}
+
+	return size, nil
+} // func (db *DB) EnclosureGetTotalSize() (int64, error)
+
+// updateEnclosure applies the given function to the stored Enclosure with the
+// given ID, if it exists.
+func (t *tables) updateEnclosure(id int64, fn func(e *model.Enclosure)) {
+	var (
+		e  model.Enclosure
+		ok bool
+	)
+
+	if e, ok = t.enclosures[id]; ok {
+		fn(&e)
+		t.enclosures[id] = e
+	}
+} // func (t *tables) updateEnclosure(id int64, fn func(e *model.Enclosure))
+
+// EnclosureSetDownloaded records that the given Enclosure has been saved
+// to the given path.
+func (db *DB) EnclosureSetDownloaded(e *model.Enclosure, path string, size int64, ts time.Time) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	if size < 0 {
+		return fmt.Errorf("%w: Size of Enclosure %s must not be negative",
+			ErrConstraint,
+			e.URL)
+	}
+
+	t.updateEnclosure(e.ID, func(r *model.Enclosure) {
+		r.Path = path
+		r.Size = size
+		r.Downloaded = stamp(ts)
+	})
+
+	e.Path = path
+	e.Size = size
+	e.Downloaded = ts
+	return nil
+} // func (db *DB) EnclosureSetDownloaded(e *model.Enclosure, path string, size int64, ts time.Time) error
+
+// EnclosureRecordFailure increments the number of failed attempts to download
+// the given Enclosure.
+func (db *DB) EnclosureRecordFailure(e *model.Enclosure) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateEnclosure(e.ID, func(r *model.Enclosure) { r.Attempts++ })
+	return nil
+} // func (db *DB) EnclosureRecordFailure(e *model.Enclosure) error
+
+// EnclosureExpire marks the given Enclosure as expired, i.e. its file has
+// been removed to make room for newer ones.
+func (db *DB) EnclosureExpire(e *model.Enclosure) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	t.updateEnclosure(e.ID, func(r *model.Enclosure) {
+		r.Expired = true
+		r.Path = ""
+		r.Size = 0
+	})
+
+	e.Expired = true
+	e.Path = ""
+	e.Size = 0
+	return nil
+} // func (db *DB) EnclosureExpire(e *model.Enclosure) error
@@ -0,0 +1,219 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/memory/memory.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 21:14:52 krylon>
+
+// Package memory provides an implementation of database.Store that keeps all
+// data in RAM. It is meant for tests that do not care about the database
+// itself and would rather not wait for SQLite.
+//
+// The Stores behave like the SQLite backend as far as the rest of the
+// application can tell, that includes the constraints of the schema and the
+// order in which lists are returned. Timestamps are stored with a resolution
+// of one second, too.
+package memory
+
+import (
+	"errors"
+	"maps"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/model"
+)
+
+// ErrConstraint is returned when an operation would violate a constraint of
+// the database schema, e.g. by adding a second Feed with the same URL.
+var ErrConstraint = errors.New("constraint violation")
+
+type linkKey struct {
+	tag, item int64
+}
+
+// item is an Item as it is stored, along with the columns model.Item does
+// not have a field for.
+type item struct {
+	model.Item
+	canonical string
+}
+
+// search is a Search as it is stored. Its results are stored by ID.
+type search struct {
+	model.Search
+	results []int64
+	scores  []float64
+}
+
+// tables holds the actual data.
+type tables struct {
+	seq        int64
+	feeds      map[int64]model.Feed
+	history    []model.FeedMove
+	subs       map[int64]model.Subscription
+	items      map[int64]item
+	content    map[int64]string
+	revisions  []model.Revision
+	enclosures map[int64]model.Enclosure
+	tags       map[int64]model.Tag
+	links      map[linkKey]bool
+	catmaps    map[int64]model.CategoryMapping
+	searches   map[int64]search
+}
+
+func newTables() *tables {
+	return &tables{
+		feeds:      make(map[int64]model.Feed),
+		subs:       make(map[int64]model.Subscription),
+		items:      make(map[int64]item),
+		content:    make(map[int64]string),
+		enclosures: make(map[int64]model.Enclosure),
+		tags:       make(map[int64]model.Tag),
+		links:      make(map[linkKey]bool),
+		catmaps:    make(map[int64]model.CategoryMapping),
+		searches:   make(map[int64]search),
+	}
+} // func newTables() *tables
+
+// clone returns a copy of the tables that is not affected by any changes
+// made to the original. Records are copied by value, the slices and maps
+// they refer to are never modified in place.
+func (t *tables) clone() *tables {
+	return &tables{
+		seq:        t.seq,
+		feeds:      maps.Clone(t.feeds),
+		history:    slices.Clone(t.history),
+		subs:       maps.Clone(t.subs),
+		items:      maps.Clone(t.items),
+		content:    maps.Clone(t.content),
+		revisions:  slices.Clone(t.revisions),
+		enclosures: maps.Clone(t.enclosures),
+		tags:       maps.Clone(t.tags),
+		links:      maps.Clone(t.links),
+		catmaps:    maps.Clone(t.catmaps),
+		searches:   maps.Clone(t.searches),
+	}
+} // func (t *tables) clone() *tables
+
+// nextID returns a fresh ID. All tables share a single sequence.
+func (t *tables) nextID() int64 {
+	t.seq++
+	return t.seq
+} // func (t *tables) nextID() int64
+
+// Memory holds the data that all Stores opened from it share.
+type Memory struct {
+	lock sync.Mutex
+	data *tables
+}
+
+// New creates an empty Memory.
+func New() *Memory {
+	return &Memory{data: newTables()}
+} // func New() *Memory
+
+// Open returns a new Store that works on the Memory's data. Its signature
+// matches database.Opener.
+func (m *Memory) Open() (database.Store, error) {
+	return &DB{mem: m}, nil
+} // func (m *Memory) Open() (database.Store, error)
+
+// DB is a handle on a Memory. Like a database.Database, a DB must not be used
+// by more than one goroutine at a time.
+//
+// While a DB has a transaction in progress, it holds the Memory's lock, so
+// other DBs opened from the same Memory block until the transaction is
+// finished. The snapshot taken at the start of the transaction is restored on
+// Rollback.
+type DB struct {
+	mem  *Memory
+	snap *tables
+}
+
+var _ database.Store = (*DB)(nil)
+
+// lock acquires the lock on the shared data, unless we hold it already
+// because a transaction is in progress.
+func (db *DB) lock() *tables {
+	if db.snap == nil {
+		db.mem.lock.Lock()
+	}
+
+	return db.mem.data
+} // func (db *DB) lock() *tables
+
+func (db *DB) unlock() {
+	if db.snap == nil {
+		db.mem.lock.Unlock()
+	}
+} // func (db *DB) unlock()
+
+// Close closes the DB. If a transaction is in progress, it is rolled back.
+// The data remains available to other DBs opened from the same Memory.
+func (db *DB) Close() error {
+	if db.snap != nil {
+		return db.Rollback()
+	}
+
+	return nil
+} // func (db *DB) Close() error
+
+// Begin starts a transaction.
+func (db *DB) Begin() error {
+	if db.snap != nil {
+		return database.ErrTxInProgress
+	}
+
+	db.mem.lock.Lock()
+	db.snap = db.mem.data.clone()
+	return nil
+} // func (db *DB) Begin() error
+
+// Commit finishes the pending transaction, keeping all changes made during
+// the transaction.
+func (db *DB) Commit() error {
+	if db.snap == nil {
+		return database.ErrNoTxInProgress
+	}
+
+	db.snap = nil
+	db.mem.lock.Unlock()
+	return nil
+} // func (db *DB) Commit() error
+
+// Rollback finishes the pending transaction, undoing all changes made during
+// the transaction.
+func (db *DB) Rollback() error {
+	if db.snap == nil {
+		return database.ErrNoTxInProgress
+	}
+
+	db.mem.data = db.snap
+	db.snap = nil
+	db.mem.lock.Unlock()
+	return nil
+} // func (db *DB) Rollback() error
+
+// stamp truncates the given time to a full second, which is the resolution
+// the database stores timestamps with. The zero Time stays zero.
+func stamp(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Time{}
+	}
+
+	return time.Unix(t.Unix(), 0)
+} // func stamp(t time.Time) time.Time
+
+// copyURL returns a copy of the given URL, so the caller cannot modify what
+// we store. The database stores a nil URL as an empty string.
+func copyURL(u *url.URL) *url.URL {
+	if u == nil {
+		return new(url.URL)
+	}
+
+	var c = *u
+	return &c
+} // func copyURL(u *url.URL) *url.URL
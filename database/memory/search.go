@@ -0,0 +1,264 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/memory/search.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 23:08:12 krylon>
+
+package memory
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/blicero/badnews/model"
+)
+
+// loadSearch returns a copy of the given Search. If withResults is true, the
+// result Items are loaded as well. Like in the database, Items that have been
+// removed in the meantime show up as nil.
+func (t *tables) loadSearch(rec *search, withResults bool) *model.Search {
+	var s = rec.Search
+
+	s.Tags = slices.Clone(rec.Tags)
+	s.Results = nil
+
+	if !withResults || len(rec.results) == 0 {
+		return &s
+	}
+
+	s.Results = make([]*model.Item, len(rec.results))
+
+	for idx, id := range rec.results {
+		if i, ok := t.items[id]; ok {
+			s.Results[idx] = t.loadItem(&i)
+			s.Results[idx].Score = rec.scores[idx]
+		}
+	}
+
+	return &s
+} // func (t *tables) loadSearch(rec *search, withResults bool) *model.Search
+
+// searchList returns all Searches the given function accepts, ordered by the
+// given function.
+func (t *tables) searchList(accept func(s *search) bool, order func(a, b *model.Search) int, withResults bool) []*model.Search {
+	var list = make([]*model.Search, 0)
+
+	for _, id := range slices.Sorted(maps.Keys(t.searches)) {
+		var rec = t.searches[id]
+
+		if accept(&rec) {
+			list = append(list, t.loadSearch(&rec, withResults))
+		}
+	}
+
+	slices.SortStableFunc(list, order)
+	return list
+} // func (t *tables) searchList(accept func(s *search) bool, order func(a, b *model.Search) int, withResults bool) []*model.Search
+
+// SearchAdd adds a Search query to the database. Like the database, it does
+// not store the period filter.
+func (db *DB) SearchAdd(s *model.Search) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	var rec = search{
+		Search: model.Search{
+			ID:          t.nextID(),
+			Title:       s.Title,
+			TimeCreated: time.Unix(s.TimeCreated.Unix(), 0),
+			Tags:        slices.Clone(s.Tags),
+			TagsAll:     s.TagsAll,
+			QueryString: s.QueryString,
+			Regex:       s.Regex,
+		},
+	}
+
+	t.searches[rec.ID] = rec
+	s.ID = rec.ID
+	return nil
+} // func (db *DB) SearchAdd(s *model.Search) error
+
+// SearchDelete removes a Search query from the database.
+func (db *DB) SearchDelete(s *model.Search) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	delete(t.searches, s.ID)
+	return nil
+} // func (db *DB) SearchDelete(s *model.Search) error
+
+// SearchGetByID loads a Search by its ID, including its results.
+func (db *DB) SearchGetByID(id int64) (*model.Search, error) {
+	var (
+		t   = db.lock()
+		rec search
+		ok  bool
+	)
+	defer db.unlock()
+
+	if rec, ok = t.searches[id]; !ok {
+		return nil, nil
+	}
+
+	return t.loadSearch(&rec, true), nil
+} // func (db *DB) SearchGetByID(id int64) (*model.Search, error)
+
+// SearchGetNextPending returns the oldest Search Query in the database that
+// has not been started, yet.
+func (db *DB) SearchGetNextPending() (*model.Search, error) {
+	var (
+		t    = db.lock()
+		list []*model.Search
+	)
+	defer db.unlock()
+
+	list = t.searchList(
+		func(s *search) bool { return s.TimeStarted.IsZero() },
+		func(a, b *model.Search) int { return a.TimeCreated.Compare(b.TimeCreated) },
+		false)
+
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	return list[0], nil
+} // func (db *DB) SearchGetNextPending() (*model.Search, error)
+
+// SearchGetActive returns all Searches that have been started but have not
+// finished, yet.
+func (db *DB) SearchGetActive() ([]*model.Search, error) {
+	var t = db.lock()
+	defer db.unlock()
+
+	return t.searchList(
+		func(s *search) bool { return !s.TimeStarted.IsZero() && s.TimeFinished.IsZero() },
+		func(a, b *model.Search) int { return a.TimeStarted.Compare(b.TimeStarted) },
+		false), nil
+} // func (db *DB) SearchGetActive() ([]*model.Search, error)
+
+// SearchGetAll loads all Searches, including their results.
+func (db *DB) SearchGetAll() ([]*model.Search, error) {
+	var t = db.lock()
+	defer db.unlock()
+
+	return t.searchList(
+		func(*search) bool { return true },
+		func(a, b *model.Search) int { return a.TimeCreated.Compare(b.TimeCreated) },
+		true), nil
+} // func (db *DB) SearchGetAll() ([]*model.Search, error)
+
+// SearchStart sets the start time of the given Search query to the current
+// time.
+func (db *DB) SearchStart(s *model.Search) error {
+	var (
+		t   = db.lock()
+		rec search
+		ok  bool
+		now = time.Unix(time.Now().Unix(), 0)
+	)
+	defer db.unlock()
+
+	if rec, ok = t.searches[s.ID]; ok {
+		rec.TimeStarted = now
+		rec.TimeFinished = time.Time{}
+		t.searches[s.ID] = rec
+	}
+
+	s.TimeStarted = now
+	return nil
+} // func (db *DB) SearchStart(s *model.Search) error
+
+// searchCandidates returns the IDs of the Items linked to the Search's Tags,
+// or of all Items if the Search has no Tags.
+func (t *tables) searchCandidates(s *model.Search) (map[int64]bool, error) {
+	var ids = make(map[int64]bool)
+
+	if len(s.Tags) == 0 {
+		for id := range t.items {
+			ids[id] = true
+		}
+
+		return ids, nil
+	}
+
+	for idx, tid := range s.Tags {
+		if _, ok := t.tags[tid]; !ok {
+			return nil, fmt.Errorf("No Tag with ID = %d was found in the database",
+				tid)
+		}
+
+		var linked = make(map[int64]bool)
+
+		for k := range t.links {
+			if k.tag == tid {
+				linked[k.item] = true
+			}
+		}
+
+		if s.TagsAll && idx > 0 {
+			maps.DeleteFunc(ids, func(id int64, _ bool) bool { return !linked[id] })
+		} else {
+			maps.Copy(ids, linked)
+		}
+	}
+
+	return ids, nil
+} // func (t *tables) searchCandidates(s *model.Search) (map[int64]bool, error)
+
+// SearchExecute runs a Search. If everything goes well, it fills in the
+// results, newest Item first, and sets the Status and TimeFinished fields,
+// both in the Search object and the database. There is no full-text index,
+// so every Score is zero.
+func (db *DB) SearchExecute(s *model.Search) error {
+	var (
+		err   error
+		ids   map[int64]bool
+		t     = db.lock()
+		now   = time.Unix(time.Now().Unix(), 0)
+		items = make([]*model.Item, 0)
+	)
+	defer db.unlock()
+
+	if ids, err = t.searchCandidates(s); err != nil {
+		s.TimeFinished = now
+		s.Status = false
+		s.Message = err.Error()
+		return err
+	}
+
+	for _, id := range t.itemsByTime() {
+		if !ids[id] {
+			continue
+		}
+
+		var rec = t.items[id]
+		var i = t.loadItem(&rec)
+
+		if s.Match(i) {
+			items = append(items, i)
+		}
+	}
+
+	s.Results = items
+	s.Status = true
+	s.TimeFinished = now
+
+	if rec, ok := t.searches[s.ID]; ok {
+		rec.TimeFinished = now
+		rec.Status = s.Status
+		rec.Message = s.Message
+		rec.results = make([]int64, len(items))
+		rec.scores = make([]float64, len(items))
+
+		for idx, i := range items {
+			rec.results[idx] = i.ID
+			rec.scores[idx] = i.Score
+		}
+
+		t.searches[s.ID] = rec
+	}
+
+	return nil
+} // func (db *DB) SearchExecute(s *model.Search) error
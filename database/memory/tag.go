@@ -0,0 +1,438 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/memory/tag.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 22:51:40 krylon>
+
+package memory
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/model"
+)
+
+// checkTag makes sure the given name and parent are acceptable for the Tag
+// with the given ID.
+func (t *tables) checkTag(id int64, name string, parent int64) error {
+	if name == "" {
+		return fmt.Errorf("%w: Tag name must not be empty", ErrConstraint)
+	} else if parent == 0 {
+		return nil
+	} else if parent == id {
+		return fmt.Errorf("%w: Tag %s cannot be its own parent",
+			ErrConstraint,
+			name)
+	} else if _, ok := t.tags[parent]; !ok {
+		return fmt.Errorf("%w: Parent %d of Tag %s does not exist",
+			ErrConstraint,
+			parent,
+			name)
+	}
+
+	for _, other := range t.tags {
+		if other.ID != id && other.Name == name && other.Parent == parent {
+			return fmt.Errorf("%w: Tag %s already exists below %d",
+				ErrConstraint,
+				name,
+				parent)
+		}
+	}
+
+	return nil
+} // func (t *tables) checkTag(id int64, name string, parent int64) error
+
+// TagAdd adds a new Tag to the database.
+func (db *DB) TagAdd(tag *model.Tag) error {
+	var (
+		err error
+		t   = db.lock()
+	)
+	defer db.unlock()
+
+	if err = t.checkTag(0, tag.Name, tag.Parent); err != nil {
+		return err
+	}
+
+	var rec = model.Tag{
+		ID:     t.nextID(),
+		Parent: tag.Parent,
+		Name:   tag.Name,
+	}
+
+	t.tags[rec.ID] = rec
+	tag.ID = rec.ID
+	return nil
+} // func (db *DB) TagAdd(tag *model.Tag) error
+
+// TagGetByID loads a Tag by its ID
+func (db *DB) TagGetByID(id int64) (*model.Tag, error) {
+	var (
+		t   = db.lock()
+		tag model.Tag
+		ok  bool
+	)
+	defer db.unlock()
+
+	if tag, ok = t.tags[id]; !ok {
+		return nil, nil
+	}
+
+	return &tag, nil
+} // func (db *DB) TagGetByID(id int64) (*model.Tag, error)
+
+// TagGetAll loads all Tags, top-level Tags first.
+func (db *DB) TagGetAll() ([]*model.Tag, error) {
+	var (
+		t    = db.lock()
+		tags = make([]*model.Tag, 0, len(t.tags))
+	)
+	defer db.unlock()
+
+	for _, tag := range t.tags {
+		tags = append(tags, &tag)
+	}
+
+	slices.SortFunc(tags, func(a, b *model.Tag) int {
+		if c := cmp.Compare(a.Parent, b.Parent); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return tags, nil
+} // func (db *DB) TagGetAll() ([]*model.Tag, error)
+
+// TagGetSorted loads all Tags that can be reached from a top-level Tag, with
+// their Level and FullName filled in, ordered by their FullName, so that
+// every Tag is followed by its children.
+func (db *DB) TagGetSorted() ([]*model.Tag, error) {
+	var (
+		t    = db.lock()
+		tags = make([]*model.Tag, 0, len(t.tags))
+		walk func(parent int64, level int64, prefix string)
+	)
+	defer db.unlock()
+
+	walk = func(parent int64, level int64, prefix string) {
+		for _, id := range slices.Sorted(maps.Keys(t.tags)) {
+			var tag = t.tags[id]
+
+			if tag.Parent != parent {
+				continue
+			}
+
+			tag.Level = level
+			tag.FullName = prefix + tag.Name
+			tags = append(tags, &tag)
+			walk(tag.ID, level+1, tag.FullName+"/")
+		}
+	}
+
+	walk(0, 0, "")
+
+	slices.SortStableFunc(tags, func(a, b *model.Tag) int {
+		return strings.Compare(a.FullName, b.FullName)
+	})
+
+	return tags, nil
+} // func (db *DB) TagGetSorted() ([]*model.Tag, error)
+
+// tagCount returns the number of links the given function accepts for every
+// Tag, including those that have none.
+func (t *tables) tagCount(accept func(k linkKey) bool) map[int64]int64 {
+	var cnt = make(map[int64]int64, len(t.tags))
+
+	for id := range t.tags {
+		cnt[id] = 0
+	}
+
+	for k := range t.links {
+		if accept(k) {
+			cnt[k.tag]++
+		}
+	}
+
+	return cnt
+} // func (t *tables) tagCount(accept func(k linkKey) bool) map[int64]int64
+
+// TagGetItemCnt returns a map of all Tag IDs and the number of Items that have
+// been linked to them.
+func (db *DB) TagGetItemCnt() (map[int64]int64, error) {
+	var t = db.lock()
+	defer db.unlock()
+
+	return t.tagCount(func(linkKey) bool { return true }), nil
+} // func (db *DB) TagGetItemCnt() (map[int64]int64, error)
+
+// TagGetUnreadCnt returns a map of all Tag IDs and the number of unread Items
+// that have been linked to them.
+func (db *DB) TagGetUnreadCnt() (map[int64]int64, error) {
+	var t = db.lock()
+	defer db.unlock()
+
+	return t.tagCount(func(k linkKey) bool {
+		return t.items[k.item].ReadAt.IsZero()
+	}), nil
+} // func (db *DB) TagGetUnreadCnt() (map[int64]int64, error)
+
+// TagUpdate sets the name and parent of a Tag.
+func (db *DB) TagUpdate(tag *model.Tag, name string, parent int64) error {
+	var (
+		err error
+		t   = db.lock()
+		rec model.Tag
+		ok  bool
+	)
+	defer db.unlock()
+
+	if err = t.checkTag(tag.ID, name, parent); err != nil {
+		return err
+	} else if rec, ok = t.tags[tag.ID]; ok {
+		rec.Name = name
+		rec.Parent = parent
+		t.tags[tag.ID] = rec
+	}
+
+	tag.Name = name
+	tag.Parent = parent
+	return nil
+} // func (db *DB) TagUpdate(tag *model.Tag, name string, parent int64) error
+
+// addLink links an Item to a Tag. If the link exists already, it is made
+// manual, unless auto is true.
+func (t *tables) addLink(itemID, tagID int64, auto bool) error {
+	var k = linkKey{tag: tagID, item: itemID}
+
+	if _, ok := t.items[itemID]; !ok {
+		return fmt.Errorf("%w: Item %d does not exist",
+			ErrConstraint,
+			itemID)
+	} else if _, ok = t.tags[tagID]; !ok {
+		return fmt.Errorf("%w: Tag %d does not exist",
+			ErrConstraint,
+			tagID)
+	}
+
+	if _, ok := t.links[k]; !ok || !auto {
+		t.links[k] = auto
+	}
+
+	return nil
+} // func (t *tables) addLink(itemID, tagID int64, auto bool) error
+
+// TagLinkAdd attaches a Tag to an Item. If the Tag has been attached
+// automatically before, the link becomes a manual one.
+func (db *DB) TagLinkAdd(item *model.Item, tag *model.Tag) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	return t.addLink(item.ID, tag.ID, false)
+} // func (db *DB) TagLinkAdd(item *model.Item, tag *model.Tag) error
+
+// TagLinkAddAuto attaches a Tag to an Item on behalf of the Advisor or a
+// CategoryMapping. If the Tag is attached to the Item already, nothing
+// changes.
+func (db *DB) TagLinkAddAuto(item *model.Item, tag *model.Tag) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	return t.addLink(item.ID, tag.ID, true)
+} // func (db *DB) TagLinkAddAuto(item *model.Item, tag *model.Tag) error
+
+// TagLinkDelete removes a Tag from an Item.
+func (db *DB) TagLinkDelete(item *model.Item, tag *model.Tag) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	delete(t.links, linkKey{tag: tag.ID, item: item.ID})
+	return nil
+} // func (db *DB) TagLinkDelete(item *model.Item, tag *model.Tag) error
+
+// TagLinkDeleteByFeed removes all Tags from the Items of the given Feed.
+func (db *DB) TagLinkDeleteByFeed(f *model.Feed) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	for k := range t.links {
+		if t.items[k.item].FeedID == f.ID {
+			delete(t.links, k)
+		}
+	}
+
+	return nil
+} // func (db *DB) TagLinkDeleteByFeed(f *model.Feed) error
+
+// TagLinkGetByItem returns all Tags attached to the given Item.
+func (db *DB) TagLinkGetByItem(item *model.Item) ([]*model.Tag, error) {
+	var (
+		t    = db.lock()
+		tags = make([]*model.Tag, 0)
+	)
+	defer db.unlock()
+
+	for _, id := range slices.Sorted(maps.Keys(t.tags)) {
+		var (
+			tag  = t.tags[id]
+			auto bool
+			ok   bool
+		)
+
+		if auto, ok = t.links[linkKey{tag: id, item: item.ID}]; ok {
+			tag.Auto = auto
+			tags = append(tags, &tag)
+		}
+	}
+
+	return tags, nil
+} // func (db *DB) TagLinkGetByItem(item *model.Item) ([]*model.Tag, error)
+
+// TagLinkGetByTagManual returns the Items the user has attached the given Tag
+// to by hand.
+func (db *DB) TagLinkGetByTagManual(tag *model.Tag) ([]*model.Item, error) {
+	var (
+		t     = db.lock()
+		items = make([]*model.Item, 0)
+	)
+	defer db.unlock()
+
+	for _, id := range slices.Sorted(maps.Keys(t.items)) {
+		var rec = t.items[id]
+
+		if auto, ok := t.links[linkKey{tag: tag.ID, item: id}]; ok && !auto {
+			items = append(items, t.loadItem(&rec))
+		}
+	}
+
+	return items, nil
+} // func (db *DB) TagLinkGetByTagManual(tag *model.Tag) ([]*model.Item, error)
+
+// CategoryMapAdd adds a CategoryMapping and attaches its Tag to those Items
+// of the Feed that are already in the database and have the category.
+func (db *DB) CategoryMapAdd(m *model.CategoryMapping) error {
+	var t *tables
+
+	m.Category = strings.TrimSpace(m.Category)
+
+	if m.Category == "" {
+		return fmt.Errorf("%w: Category must not be empty", database.ErrInvalidValue)
+	} else if m.FeedID == 0 || m.TagID == 0 {
+		return fmt.Errorf("%w: Mapping needs a Feed and a Tag", database.ErrInvalidValue)
+	}
+
+	t = db.lock()
+	defer db.unlock()
+
+	if _, ok := t.feeds[m.FeedID]; !ok {
+		return fmt.Errorf("%w: Feed %d does not exist",
+			ErrConstraint,
+			m.FeedID)
+	} else if _, ok = t.tags[m.TagID]; !ok {
+		return fmt.Errorf("%w: Tag %d does not exist",
+			ErrConstraint,
+			m.TagID)
+	}
+
+	for _, other := range t.catmaps {
+		if other.FeedID == m.FeedID && other.Category == m.Category && other.TagID == m.TagID {
+			return fmt.Errorf("%w: Category %q of Feed %d is already mapped to Tag %d",
+				ErrConstraint,
+				m.Category,
+				m.FeedID,
+				m.TagID)
+		}
+	}
+
+	m.ID = t.nextID()
+	t.catmaps[m.ID] = *m
+
+	for id, rec := range t.items {
+		if rec.FeedID != m.FeedID {
+			continue
+		}
+
+		for _, c := range rec.Categories {
+			if strings.EqualFold(c, m.Category) {
+				t.addLink(id, m.TagID, true) // nolint: errcheck
+				break
+			}
+		}
+	}
+
+	return nil
+} // func (db *DB) CategoryMapAdd(m *model.CategoryMapping) error
+
+// CategoryMapDelete removes a CategoryMapping. Tags it has already attached
+// to Items stay where they are.
+func (db *DB) CategoryMapDelete(m *model.CategoryMapping) error {
+	var t = db.lock()
+	defer db.unlock()
+
+	delete(t.catmaps, m.ID)
+	return nil
+} // func (db *DB) CategoryMapDelete(m *model.CategoryMapping) error
+
+// CategoryMapGetByFeed returns all CategoryMappings of the given Feed.
+func (db *DB) CategoryMapGetByFeed(f *model.Feed) ([]model.CategoryMapping, error) {
+	var (
+		t    = db.lock()
+		list = make([]model.CategoryMapping, 0)
+	)
+	defer db.unlock()
+
+	for _, m := range t.catmaps {
+		if m.FeedID == f.ID {
+			list = append(list, m)
+		}
+	}
+
+	slices.SortFunc(list, func(a, b model.CategoryMapping) int {
+		if c := strings.Compare(strings.ToLower(a.Category), strings.ToLower(b.Category)); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a.TagID, b.TagID)
+	})
+
+	return list, nil
+} // func (db *DB) CategoryMapGetByFeed(f *model.Feed) ([]model.CategoryMapping, error)
+
+// CategoryGetByFeed returns the categories the publisher of the given Feed
+// has put its Items in.
+func (db *DB) CategoryGetByFeed(f *model.Feed) ([]string, error) {
+	var (
+		t    = db.lock()
+		seen = make(map[string]bool)
+		list = make([]string, 0)
+	)
+	defer db.unlock()
+
+	for _, rec := range t.items {
+		if rec.FeedID != f.ID {
+			continue
+		}
+
+		for _, c := range rec.Categories {
+			if !seen[c] {
+				seen[c] = true
+				list = append(list, c)
+			}
+		}
+	}
+
+	slices.SortFunc(list, func(a, b string) int {
+		if c := strings.Compare(strings.ToLower(a), strings.ToLower(b)); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a, b)
+	})
+
+	return list, nil
+} // func (db *DB) CategoryGetByFeed(f *model.Feed) ([]string, error)
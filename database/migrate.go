@@ -0,0 +1,226 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/migrate.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 18:20:37 krylon>
+
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blicero/badnews/common"
+	"github.com/blicero/krylib"
+)
+
+// ErrSchemaTooNew indicates that the database has been created or upgraded by
+// a newer version of the application than the one trying to open it.
+var ErrSchemaTooNew = errors.New("database schema is newer than this version of the application")
+
+// Migration is a change to the database schema that brings it from the
+// previous version to Version. The schema version is stored in the
+// database's user_version.
+type Migration struct {
+	Version     int
+	Description string
+	Queries     []string
+}
+
+func (m *Migration) String() string {
+	return fmt.Sprintf("%3d  %s", m.Version, m.Description)
+} // func (m *Migration) String() string
+
+// SchemaVersion returns the version of the database schema this version of
+// the application uses.
+func SchemaVersion() int {
+	if len(migrations) == 0 {
+		return 0
+	}
+
+	return migrations[len(migrations)-1].Version
+} // func SchemaVersion() int
+
+// pendingMigrations returns the Migrations that have to be applied to a
+// database at the given schema version.
+func pendingMigrations(version int) []Migration {
+	var pending = make([]Migration, 0, len(migrations))
+
+	for _, m := range migrations {
+		if m.Version > version {
+			pending = append(pending, m)
+		}
+	}
+
+	return pending
+} // func pendingMigrations(version int) []Migration
+
+func getSchemaVersion(q interface {
+	QueryRow(string, ...any) *sql.Row
+}) (int, error) {
+	var (
+		err     error
+		version int
+	)
+
+GET_VERSION:
+	if err = q.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto GET_VERSION
+		}
+
+		return 0, err
+	}
+
+	return version, nil
+} // func getSchemaVersion(...) (int, error)
+
+// SchemaStatus returns the schema version of the database at the given path
+// and the Migrations that would be applied to it when it is opened. Unlike
+// Open, it does not change the database.
+func SchemaStatus(path string) (int, []Migration, error) {
+	var (
+		err     error
+		exists  bool
+		version int
+		sdb     *sql.DB
+	)
+
+	if exists, err = krylib.Fexists(path); err != nil {
+		return 0, nil, err
+	} else if !exists {
+		return 0, nil, fmt.Errorf("database %s does not exist", path)
+	} else if sdb, err = sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro", path)); err != nil {
+		return 0, nil, err
+	}
+
+	defer sdb.Close() // nolint: errcheck
+
+	if version, err = getSchemaVersion(sdb); err != nil {
+		return 0, nil, err
+	}
+
+	return version, pendingMigrations(version), nil
+} // func SchemaStatus(path string) (int, []Migration, error)
+
+// migrate brings the schema of an existing database up to date. Before it
+// applies any Migrations, it saves a copy of the database next to the
+// original. Each Migration is applied in a transaction of its own, so if one
+// fails, the database stays at the last version that succeeded.
+func (db *Database) migrate() error {
+	var (
+		err     error
+		version int
+		pending []Migration
+		bakPath string
+	)
+
+	if version, err = getSchemaVersion(db.db); err != nil {
+		db.log.Printf("[ERROR] Cannot query schema version of %s: %s\n",
+			db.path,
+			err.Error())
+		return err
+	} else if version > SchemaVersion() {
+		db.log.Printf("[ERROR] Database %s has schema version %d, we only know up to %d\n",
+			db.path,
+			version,
+			SchemaVersion())
+		return ErrSchemaTooNew
+	} else if pending = pendingMigrations(version); len(pending) == 0 {
+		return nil
+	}
+
+	bakPath = fmt.Sprintf("%s.v%d.%s.bak",
+		db.path,
+		version,
+		time.Now().Format("20060102_150405"))
+
+	db.log.Printf("[INFO] Upgrading schema of %s from version %d to %d, saving a backup to %s\n",
+		db.path,
+		version,
+		SchemaVersion(),
+		bakPath)
+
+	if err = db.Backup(bakPath); err != nil {
+		db.log.Printf("[ERROR] Cannot save backup of %s to %s: %s\n",
+			db.path,
+			bakPath,
+			err.Error())
+		return err
+	}
+
+	for _, m := range pending {
+		if err = db.applyMigration(&m); err != nil {
+			return err
+		}
+	}
+
+	return nil
+} // func (db *Database) migrate() error
+
+// applyMigration executes the queries of a Migration and updates the schema
+// version in a single transaction.
+func (db *Database) applyMigration(m *Migration) error {
+	var (
+		err error
+		tx  *sql.Tx
+	)
+
+	db.log.Printf("[INFO] Apply migration %d: %s\n",
+		m.Version,
+		m.Description)
+
+BEGIN_TX:
+	if tx, err = db.db.Begin(); err != nil {
+		if worthARetry(err) {
+			waitForRetry()
+			goto BEGIN_TX
+		}
+
+		db.log.Printf("[ERROR] Cannot begin transaction: %s\n",
+			err.Error())
+		return err
+	}
+
+	for _, q := range m.Queries {
+		if common.Debug {
+			db.log.Printf("[TRACE] Execute migration query:\n%s\n",
+				q)
+		}
+
+		if _, err = tx.Exec(q); err != nil {
+			db.log.Printf("[ERROR] Migration %d failed: %s\n%s\n",
+				m.Version,
+				err.Error(),
+				q)
+			if rbErr := tx.Rollback(); rbErr != nil {
+				db.log.Printf("[CANTHAPPEN] Cannot rollback transaction: %s\n",
+					rbErr.Error())
+				return rbErr
+			}
+			return fmt.Errorf("Migration %d failed: %w", m.Version, err)
+		}
+	}
+
+	if _, err = tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", m.Version)); err != nil {
+		db.log.Printf("[ERROR] Cannot set schema version to %d: %s\n",
+			m.Version,
+			err.Error())
+		if rbErr := tx.Rollback(); rbErr != nil {
+			db.log.Printf("[CANTHAPPEN] Cannot rollback transaction: %s\n",
+				rbErr.Error())
+			return rbErr
+		}
+		return err
+	} else if err = tx.Commit(); err != nil {
+		db.log.Printf("[ERROR] Failed to commit migration %d: %s\n",
+			m.Version,
+			err.Error())
+		return err
+	}
+
+	return nil
+} // func (db *Database) applyMigration(m *Migration) error
@@ -7,23 +7,24 @@
 package database
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 
 	"github.com/blicero/badnews/common"
-	"github.com/blicero/badnews/common/path"
 	"github.com/blicero/badnews/logdomain"
 )
 
 type dblink struct {
-	db   *Database
+	db   Store
 	next *dblink
 }
 
 // Pool is a pool of database connections
 type Pool struct {
 	cnt   int
+	open  Opener
 	log   *log.Logger
 	link  *dblink
 	lock  sync.RWMutex
@@ -34,9 +35,15 @@ type Pool struct {
 // The number of connections to use is given by the
 // parameter cnt.
 func NewPool(cnt int) (*Pool, error) {
+	return NewPoolFrom(cnt, OpenDefault)
+} // func NewPool(cnt int) (*Pool, error)
+
+// NewPoolFrom creates a Pool of cnt Stores, which are obtained by calling
+// open.
+func NewPoolFrom(cnt int, open Opener) (*Pool, error) {
 	var (
 		err  error
-		pool = &Pool{cnt: cnt}
+		pool = &Pool{cnt: cnt, open: open}
 	)
 
 	pool.empty = sync.NewCond(&pool.lock)
@@ -52,7 +59,7 @@ func NewPool(cnt int) (*Pool, error) {
 	for i := 0; i < cnt; i++ {
 		var link = &dblink{next: pool.link}
 
-		if link.db, err = Open(common.Path(path.Database)); err != nil {
+		if link.db, err = open(); err != nil {
 			pool.log.Printf("[ERROR] Cannot open database: %s\n",
 				err.Error())
 			return nil, err
@@ -62,7 +69,7 @@ func NewPool(cnt int) (*Pool, error) {
 	}
 
 	return pool, nil
-} // func NewPool(cnt int) (*Pool, error)
+} // func NewPoolFrom(cnt int, open Opener) (*Pool, error)
 
 // Close closes all open database connections currently in the pool and empties
 // the pool. Any connections retrieved from the pool that are in use at the
@@ -85,7 +92,7 @@ func (pool *Pool) Close() error {
 
 // Get returns a DB connection from the pool.
 // If the pool is empty, it waits for a connection to be returned.
-func (pool *Pool) Get() *Database {
+func (pool *Pool) Get() Store {
 	var link *dblink
 
 	pool.lock.Lock()
@@ -105,12 +112,12 @@ WAIT_FOR_LINK:
 	// Wait for it!!!
 	pool.empty.Wait()
 	goto WAIT_FOR_LINK
-} // func (pool *Pool) Get() *DB
+} // func (pool *Pool) Get() Store
 
 // GetNoWait returns a DB connection from the pool.
 // If the pool is empty, it creates a new one.
-func (pool *Pool) GetNoWait() (*Database, error) {
-	var db *Database
+func (pool *Pool) GetNoWait() (Store, error) {
+	var db Store
 	var err error
 
 	pool.lock.Lock()
@@ -122,27 +129,27 @@ func (pool *Pool) GetNoWait() (*Database, error) {
 		pool.link = link.next
 		pool.cnt--
 		return link.db, nil
-	} else if db, err = Open(common.Path(path.Database)); err != nil {
+	} else if db, err = pool.open(); err != nil {
 		pool.log.Printf("[ERROR] Error opening new database connection: %s",
 			err.Error())
 		return nil, err
 	}
 
 	return db, nil
-} // func (pool *Pool) GetNoWait() *Database
+} // func (pool *Pool) GetNoWait() Store
 
 // Put returns a DB connection to the pool.
-func (pool *Pool) Put(db *Database) {
+// If it has a pending transaction, it is rolled back.
+func (pool *Pool) Put(db Store) {
 	link := &dblink{
 		db: db,
 	}
 
-	if db.tx != nil {
-		pool.log.Println("[INFO] DB has pending transaction, rolling back.")
-		if err := db.Rollback(); err != nil {
-			pool.log.Printf("[ERROR] Cannot roll back transaction: %s\n",
-				err.Error())
-		}
+	if err := db.Rollback(); err == nil {
+		pool.log.Println("[INFO] DB had pending transaction, rolled back.")
+	} else if !errors.Is(err, ErrNoTxInProgress) {
+		pool.log.Printf("[ERROR] Cannot roll back transaction: %s\n",
+			err.Error())
 	}
 
 	pool.lock.Lock()
@@ -152,7 +159,7 @@ func (pool *Pool) Put(db *Database) {
 	pool.cnt++
 	pool.lock.Unlock()
 	pool.empty.Signal()
-} // func (pool *Pool) Put(db *Database)
+} // func (pool *Pool) Put(db Store)
 
 // IsEmpty returns true if the pool is currently empty.
 func (pool *Pool) IsEmpty() bool {
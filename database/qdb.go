@@ -10,8 +10,8 @@ import "github.com/blicero/badnews/database/query"
 
 var dbQueries = map[query.ID]string{
 	query.FeedAdd: `
-INSERT INTO feed (title, url, homepage, interval)
-          VALUES (    ?,   ?,        ?,        ?)
+INSERT INTO feed (title, url, homepage, interval, folder)
+          VALUES (    ?,   ?,        ?,        ?,      ?)
 RETURNING id
 `,
 	query.FeedGetByID: `
@@ -21,7 +21,34 @@ SELECT
     homepage,
     interval,
     last_refresh,
-    active
+    active,
+    folder,
+    fetch_full,
+    download,
+    etag,
+    last_modified,
+    last_status,
+    consecutive_failures,
+    last_error,
+    next_attempt,
+    headers,
+    auth_kind,
+    auth_user,
+    auth_secret,
+    interval_bound,
+    ttl,
+    skip_hours,
+    skip_days,
+    cadence,
+    next_refresh,
+    description,
+    language,
+    image,
+    generator,
+    icon,
+    icon_type,
+    icon_checked,
+    retention
 FROM feed
 WHERE id = ?
 `,
@@ -33,9 +60,36 @@ SELECT
     homepage,
     interval,
     last_refresh,
-    active
+    active,
+    folder,
+    fetch_full,
+    download,
+    etag,
+    last_modified,
+    last_status,
+    consecutive_failures,
+    last_error,
+    next_attempt,
+    headers,
+    auth_kind,
+    auth_user,
+    auth_secret,
+    interval_bound,
+    ttl,
+    skip_hours,
+    skip_days,
+    cadence,
+    next_refresh,
+    description,
+    language,
+    image,
+    generator,
+    icon,
+    icon_type,
+    icon_checked,
+    retention
 FROM feed
-ORDER BY title
+ORDER BY folder, title
 `,
 	query.FeedGetPending: `
 SELECT
@@ -45,29 +99,277 @@ SELECT
     homepage,
     interval,
     last_refresh,
-    active
+    active,
+    folder,
+    fetch_full,
+    download,
+    etag,
+    last_modified,
+    last_status,
+    consecutive_failures,
+    last_error,
+    next_attempt,
+    headers,
+    auth_kind,
+    auth_user,
+    auth_secret,
+    interval_bound,
+    ttl,
+    skip_hours,
+    skip_days,
+    cadence,
+    next_refresh,
+    description,
+    language,
+    image,
+    generator,
+    icon,
+    icon_type,
+    icon_checked,
+    retention
 FROM feed
-WHERE (active <> 0) AND (last_refresh + interval < unixepoch())
+WHERE (active <> 0)
+  AND (CASE next_refresh
+       WHEN 0 THEN last_refresh + interval
+       ELSE next_refresh
+       END < unixepoch())
+  AND (next_attempt < unixepoch())
+  AND NOT EXISTS (SELECT s.id
+                  FROM websub s
+                  WHERE s.feed_id = feed.id
+                    AND s.state = 1
+                    AND s.lease_expires > unixepoch()
+                    AND feed.last_refresh + 86400 > unixepoch())
+`,
+	query.FeedGetUnreadCnt: `
+SELECT
+    f.id,
+    (SELECT COUNT(i.id) FROM item i WHERE i.feed_id = f.id AND i.read_at = 0) AS unread
+FROM feed f
 `,
 	query.FeedUpdateRefresh: `
 UPDATE feed
 SET last_refresh = ?
 WHERE id = ?
 `,
+	query.FeedUpdateHTTPState: `
+UPDATE feed
+SET etag = ?,
+    last_modified = ?,
+    last_status = ?
+WHERE id = ?
+`,
+	query.FeedRecordFailure: `
+UPDATE feed
+SET consecutive_failures = ?,
+    last_error = ?,
+    next_attempt = ?,
+    active = ?
+WHERE id = ?
+`,
+	query.FeedResetFailures: `
+UPDATE feed
+SET consecutive_failures = 0,
+    last_error = '',
+    next_attempt = 0
+WHERE id = ?
+`,
+	query.FeedPostpone: "UPDATE feed SET next_attempt = ? WHERE id = ?",
+	query.FeedSetMeta: `
+UPDATE feed
+SET description = ?,
+    language = ?,
+    image = ?,
+    generator = ?
+WHERE id = ?
+`,
+	query.FeedSetIcon: `
+UPDATE feed
+SET icon = ?,
+    icon_type = ?,
+    icon_checked = ?
+WHERE id = ?
+`,
+	query.FeedSetRetention: "UPDATE feed SET retention = ? WHERE id = ?",
 	query.FeedSetActive: `
 UPDATE feed
 SET active = ?
 WHERE id = ?
+`,
+	query.FeedSetFetchFull: `
+UPDATE feed
+SET fetch_full = ?
+WHERE id = ?
+`,
+	query.FeedSetHTTPSettings: `
+UPDATE feed
+SET headers = ?,
+    auth_kind = ?,
+    auth_user = ?,
+    auth_secret = ?
+WHERE id = ?
+`,
+	query.FeedSetDownload: `
+UPDATE feed
+SET download = ?
+WHERE id = ?
+`,
+	query.FeedSetIntervalBound: `
+UPDATE feed
+SET interval_bound = ?
+WHERE id = ?
+`,
+	query.FeedSetSchedule: `
+UPDATE feed
+SET ttl = ?,
+    skip_hours = ?,
+    skip_days = ?,
+    cadence = ?,
+    next_refresh = ?
+WHERE id = ?
+`,
+	query.FeedUpdate: `
+UPDATE feed
+SET title = ?,
+    url = ?,
+    homepage = ?,
+    interval = ?
+WHERE id = ?
+`,
+	query.FeedResetState: `
+UPDATE feed
+SET etag = '',
+    last_modified = '',
+    last_status = 0,
+    last_refresh = 0,
+    consecutive_failures = 0,
+    last_error = '',
+    next_attempt = 0,
+    ttl = 0,
+    skip_hours = 0,
+    skip_days = 0,
+    cadence = 0,
+    next_refresh = 0
+WHERE id = ?
+`,
+	query.FeedHistoryAdd: `
+INSERT INTO feed_history (feed_id, timestamp, old_url, new_url, reason)
+                  VALUES (      ?,         ?,       ?,       ?,      ?)
+`,
+	query.FeedHistoryGetByFeed: `
+SELECT
+    id,
+    timestamp,
+    old_url,
+    new_url,
+    reason
+FROM feed_history
+WHERE feed_id = ?
+ORDER BY timestamp DESC, id DESC
 `,
 	query.FeedDelete: "DELETE FROM feed WHERE id = ?",
+	query.WebSubAdd: `
+INSERT INTO websub (feed_id, hub, topic, secret, state, requested, lease_expires)
+            VALUES (      ?,   ?,     ?,      ?,     0,         ?,             ?)
+ON CONFLICT (feed_id) DO UPDATE
+SET state = CASE WHEN hub = excluded.hub AND topic = excluded.topic
+                 THEN state
+                 ELSE 0
+            END,
+    hub = excluded.hub,
+    topic = excluded.topic,
+    secret = excluded.secret,
+    requested = excluded.requested,
+    lease_expires = excluded.lease_expires
+RETURNING id, state
+`,
+	query.WebSubGetByFeed: `
+SELECT
+    id,
+    hub,
+    topic,
+    secret,
+    state,
+    requested,
+    lease_expires,
+    last_push
+FROM websub
+WHERE feed_id = ?
+`,
+	query.WebSubGetRenewable: `
+SELECT
+    id,
+    feed_id,
+    hub,
+    topic,
+    secret,
+    state,
+    requested,
+    lease_expires,
+    last_push
+FROM websub
+WHERE ((state = 1 AND lease_expires < ?) OR state = 0)
+  AND requested < ?
+`,
+	query.WebSubSetActive: `
+UPDATE websub
+SET state = 1,
+    lease_expires = ?
+WHERE id = ?
+`,
+	query.WebSubSetState:     "UPDATE websub SET state = ? WHERE id = ?",
+	query.WebSubSetLastPush:  "UPDATE websub SET last_push = ? WHERE id = ?",
+	query.WebSubDelete:       "DELETE FROM websub WHERE id = ?",
+	query.WebSubDeleteByFeed: "DELETE FROM websub WHERE feed_id = ?",
 	query.ItemAdd: `
-INSERT INTO item (feed_id, url, timestamp, headline, description)
-          VALUES (      ?,   ?,         ?,        ?,           ?)
+INSERT INTO item (feed_id, url, timestamp, headline, description, guid, url_canonical, categories, authors)
+          VALUES (      ?,   ?,         ?,        ?,           ?,    ?,             ?,          ?,       ?)
+RETURNING id
+`,
+	query.ItemUpsert: `
+INSERT INTO item (feed_id, url, timestamp, headline, description, guid, url_canonical, categories, authors)
+          VALUES (      ?,   ?,         ?,        ?,           ?,    ?,             ?,          ?,       ?)
+ON CONFLICT (url) DO NOTHING
 RETURNING id
 `,
 	query.ItemDeleteByFeed: "DELETE FROM item WHERE feed_id = ?",
-	query.ItemExists:       "SELECT COUNT(id) FROM item WHERE url = ?",
-	query.ItemGetRecent: `
+	query.ItemGetPurgeable: `
+SELECT
+    i.id,
+    i.feed_id,
+    i.url,
+    i.timestamp,
+    i.headline
+FROM item i
+INNER JOIN feed f ON i.feed_id = f.id
+WHERE i.rating = 0
+  AND i.starred = 0
+  AND (CASE f.retention WHEN 0 THEN ? ELSE f.retention END) > 0
+  AND i.timestamp < ? - 86400 * (CASE f.retention WHEN 0 THEN ? ELSE f.retention END)
+  AND NOT EXISTS (SELECT l.id FROM tag_link l WHERE l.item_id = i.id AND l.auto = 0)
+  AND NOT EXISTS (SELECT s.id
+                  FROM search s
+                  WHERE instr(',' || COALESCE(s.results, '') || ',', ',' || i.id || ',') > 0)
+ORDER BY i.feed_id, i.timestamp
+`,
+	query.ItemPurge: `
+DELETE FROM item
+WHERE id = ?
+  AND rating = 0
+  AND starred = 0
+  AND NOT EXISTS (SELECT l.id FROM tag_link l WHERE l.item_id = item.id AND l.auto = 0)
+  AND NOT EXISTS (SELECT s.id
+                  FROM search s
+                  WHERE instr(',' || COALESCE(s.results, '') || ',', ',' || item.id || ',') > 0)
+`,
+	query.ItemExists: `
+SELECT COUNT(id)
+FROM item
+WHERE url = ?
+   OR url_canonical = ?
+   OR (feed_id = ? AND guid <> '' AND guid = ?)
+`,
+	query.ItemGetByKey: `
 SELECT
     id,
     feed_id,
@@ -77,6 +379,90 @@ SELECT
     description,
     rating
 FROM item
+WHERE url = ?
+   OR url_canonical = ?
+   OR (feed_id = ? AND guid <> '' AND guid = ?)
+ORDER BY id
+LIMIT 1
+`,
+	query.ItemUpdate: "UPDATE item SET headline = ?, description = ? WHERE id = ?",
+	query.ItemRevisionAdd: `
+INSERT INTO item_revision (item_id, timestamp, headline, description)
+                   VALUES (      ?,         ?,        ?,           ?)
+`,
+	query.ItemRevisionGetByItem: `
+SELECT
+    id,
+    timestamp,
+    headline,
+    description
+FROM item_revision
+WHERE item_id = ?
+ORDER BY timestamp, id
+`,
+	query.ItemGetUncanonical: "SELECT id, url FROM item WHERE url_canonical = ''",
+	query.ItemSetCanonical:   "UPDATE item SET url_canonical = ? WHERE id = ?",
+	query.ItemMergeDuplicates: `
+WITH dup (id, keep) AS (
+    SELECT
+        id,
+        MIN(id) OVER (PARTITION BY url_canonical) AS keep
+    FROM item
+    WHERE url_canonical <> ''
+)
+
+INSERT OR IGNORE INTO tag_link (tag_id, item_id)
+SELECT
+    l.tag_id,
+    d.keep
+FROM tag_link l
+INNER JOIN dup d ON l.item_id = d.id
+WHERE d.id <> d.keep
+`,
+	query.ItemMergeRating: `
+UPDATE item
+SET rating = (SELECT d.rating
+              FROM item d
+              WHERE d.url_canonical = item.url_canonical
+                AND d.rating <> 0
+              ORDER BY d.id
+              LIMIT 1)
+WHERE rating = 0
+  AND url_canonical <> ''
+  AND EXISTS (SELECT 1
+              FROM item d
+              WHERE d.url_canonical = item.url_canonical
+                AND d.id > item.id
+                AND d.rating <> 0)
+  AND NOT EXISTS (SELECT 1
+                  FROM item d
+                  WHERE d.url_canonical = item.url_canonical
+                    AND d.id < item.id)
+`,
+	query.ItemDeleteDuplicates: `
+DELETE FROM item
+WHERE url_canonical <> ''
+  AND EXISTS (SELECT 1
+              FROM item d
+              WHERE d.url_canonical = item.url_canonical
+                AND d.id < item.id)
+`,
+	query.ItemGetRecent: `
+SELECT
+    id,
+    feed_id,
+    url,
+    timestamp,
+    headline,
+    description,
+    categories,
+    authors,
+    rating,
+    read_at,
+    starred,
+    COALESCE((SELECT c.content FROM item_content c WHERE c.item_id = item.id), '') AS content,
+    (SELECT COUNT(r.id) FROM item_revision r WHERE r.item_id = item.id) AS revisions
+FROM item
 WHERE timestamp > ?
 ORDER BY timestamp DESC
 `,
@@ -88,8 +474,16 @@ SELECT
     timestamp,
     headline,
     description,
-    rating
+    categories,
+    authors,
+    rating,
+    read_at,
+    starred,
+    COALESCE((SELECT c.content FROM item_content c WHERE c.item_id = item.id), '') AS content,
+    (SELECT COUNT(r.id) FROM item_revision r WHERE r.item_id = item.id) AS revisions
 FROM item
+WHERE (? = 0 OR read_at = 0)
+  AND (? = 0 OR starred = 1)
 ORDER BY timestamp DESC
 LIMIT ?
 OFFSET ?
@@ -101,7 +495,13 @@ SELECT
     timestamp,
     headline,
     description,
-    rating
+    categories,
+    authors,
+    rating,
+    read_at,
+    starred,
+    COALESCE((SELECT c.content FROM item_content c WHERE c.item_id = item.id), '') AS content,
+    (SELECT COUNT(r.id) FROM item_revision r WHERE r.item_id = item.id) AS revisions
 FROM item
 WHERE id = ?
 `,
@@ -112,9 +512,17 @@ SELECT
     timestamp,
     headline,
     description,
-    rating
+    categories,
+    authors,
+    rating,
+    read_at,
+    starred,
+    COALESCE((SELECT c.content FROM item_content c WHERE c.item_id = item.id), '') AS content,
+    (SELECT COUNT(r.id) FROM item_revision r WHERE r.item_id = item.id) AS revisions
 FROM item
 WHERE feed_id = ?
+  AND (? = 0 OR read_at = 0)
+  AND (? = 0 OR starred = 1)
 ORDER BY timestamp DESC
 LIMIT ?
 OFFSET ?
@@ -127,7 +535,13 @@ SELECT
     timestamp,
     headline,
     description,
-    rating
+    categories,
+    authors,
+    rating,
+    read_at,
+    starred,
+    COALESCE((SELECT c.content FROM item_content c WHERE c.item_id = item.id), '') AS content,
+    (SELECT COUNT(r.id) FROM item_revision r WHERE r.item_id = item.id) AS revisions
 FROM item
 WHERE timestamp BETWEEN ? AND ?
 `,
@@ -139,7 +553,13 @@ SELECT
     timestamp,
     headline,
     description,
-    rating
+    categories,
+    authors,
+    rating,
+    read_at,
+    starred,
+    COALESCE((SELECT c.content FROM item_content c WHERE c.item_id = item.id), '') AS content,
+    (SELECT COUNT(r.id) FROM item_revision r WHERE r.item_id = item.id) AS revisions
 FROM item
 WHERE rating <> 0
 ORDER BY timestamp DESC
@@ -152,12 +572,132 @@ SELECT
     timestamp,
     headline,
     description,
-    rating
+    categories,
+    authors,
+    rating,
+    read_at,
+    starred,
+    COALESCE((SELECT c.content FROM item_content c WHERE c.item_id = item.id), '') AS content,
+    (SELECT COUNT(r.id) FROM item_revision r WHERE r.item_id = item.id) AS revisions
 FROM item
 ORDER BY timestamp DESC
 `,
-	query.ItemRate:   "UPDATE item SET rating = ? WHERE id = ?",
-	query.ItemUnrate: "UPDATE item SET rating = 0 WHERE id = ?",
+	query.ItemContentAdd: `
+INSERT INTO item_content (item_id, timestamp, content)
+                  VALUES (      ?,         ?,       ?)
+ON CONFLICT (item_id) DO UPDATE
+SET timestamp = excluded.timestamp,
+    content = excluded.content
+`,
+	query.ItemContentGet: "SELECT content FROM item_content WHERE item_id = ?",
+	query.ItemRate:       "UPDATE item SET rating = ? WHERE id = ?",
+	query.ItemUnrate:     "UPDATE item SET rating = 0 WHERE id = ?",
+	query.ItemMarkRead:   "UPDATE item SET read_at = ? WHERE id = ? AND read_at = 0",
+	query.ItemMarkUnread: "UPDATE item SET read_at = 0 WHERE id = ?",
+	query.ItemMarkReadList: `
+UPDATE item SET read_at = ?
+WHERE read_at = 0
+  AND id IN (SELECT value FROM json_each(?))
+`,
+	query.ItemMarkReadAbove: `
+UPDATE item SET read_at = ?
+WHERE read_at = 0
+  AND timestamp >= (SELECT timestamp FROM item WHERE id = ?)
+  AND (? = 0 OR feed_id = ?)
+`,
+	query.ItemSetStarred: "UPDATE item SET starred = ? WHERE id = ?",
+	query.EnclosureAdd: `
+INSERT INTO enclosure (item_id, url, mime_type, length)
+               VALUES (      ?,   ?,         ?,      ?)
+RETURNING id
+`,
+	query.EnclosureGetByID: `
+SELECT
+    item_id,
+    url,
+    mime_type,
+    length,
+    path,
+    size,
+    downloaded,
+    attempts,
+    expired
+FROM enclosure
+WHERE id = ?
+`,
+	query.EnclosureGetByItem: `
+SELECT
+    id,
+    url,
+    mime_type,
+    length,
+    path,
+    size,
+    downloaded,
+    attempts,
+    expired
+FROM enclosure
+WHERE item_id = ?
+ORDER BY id
+`,
+	query.EnclosureGetPending: `
+SELECT
+    e.id,
+    e.item_id,
+    e.url,
+    e.mime_type,
+    e.length,
+    e.path,
+    e.size,
+    e.downloaded,
+    e.attempts,
+    e.expired
+FROM enclosure e
+INNER JOIN item i ON e.item_id = i.id
+INNER JOIN feed f ON i.feed_id = f.id
+WHERE f.download <> 0
+  AND e.downloaded = 0
+  AND e.expired = 0
+  AND e.attempts < ?
+ORDER BY i.timestamp DESC
+LIMIT ?
+`,
+	query.EnclosureGetDownloaded: `
+SELECT
+    id,
+    item_id,
+    url,
+    mime_type,
+    length,
+    path,
+    size,
+    downloaded,
+    attempts,
+    expired
+FROM enclosure
+WHERE downloaded <> 0 AND expired = 0
+ORDER BY downloaded, id
+`,
+	query.EnclosureGetTotalSize: `
+SELECT COALESCE(SUM(size), 0)
+FROM enclosure
+WHERE downloaded <> 0 AND expired = 0
+`,
+	query.EnclosureSetDownloaded: `
+UPDATE enclosure
+SET path = ?,
+    size = ?,
+    downloaded = ?
+WHERE id = ?
+`,
+	query.EnclosureRecordFailure: "UPDATE enclosure SET attempts = attempts + 1 WHERE id = ?",
+	query.EnclosureExpire: `
+UPDATE enclosure
+SET expired = 1,
+    path = '',
+    size = 0
+WHERE id = ?
+`,
 	query.TagAdd: `
 INSERT INTO tag (name, parent)
          VALUES (   ?,      ?)
@@ -221,6 +761,23 @@ WITH cnt_list (tag_id, cnt) AS (
     GROUP BY tag_id
 )
 
+SELECT
+  t.id,
+  COALESCE(c.cnt, 0)
+FROM tag t
+LEFT OUTER JOIN cnt_list c ON t.id = c.tag_id
+`,
+	query.TagGetUnreadCnt: `
+WITH cnt_list (tag_id, cnt) AS (
+    SELECT
+        l.tag_id,
+        COUNT(l.tag_id)
+    FROM tag_link l
+    INNER JOIN item i ON l.item_id = i.id
+    WHERE i.read_at = 0
+    GROUP BY l.tag_id
+)
+
 SELECT
   t.id,
   COALESCE(c.cnt, 0)
@@ -234,6 +791,12 @@ LEFT OUTER JOIN cnt_list c ON t.id = c.tag_id
 	query.TagLinkAdd: `
 INSERT INTO tag_link (tag_id, item_id)
               VALUES (     ?,       ?)
+ON CONFLICT (tag_id, item_id) DO UPDATE SET auto = 0
+`,
+	query.TagLinkAddAuto: `
+INSERT INTO tag_link (tag_id, item_id, auto)
+              VALUES (     ?,       ?,    1)
+ON CONFLICT (tag_id, item_id) DO NOTHING
 `,
 	query.TagLinkDelete: "DELETE FROM tag_link WHERE tag_id = ? AND item_id = ?",
 	query.TagLinkDeleteByFeed: `
@@ -255,7 +818,8 @@ WHERE item_id IN (SELECT item_id FROM links WHERE feed_id = ?)
 SELECT
     t.id,
     t.parent,
-    t.name
+    t.name,
+    l.auto
 FROM tag_link l
 INNER JOIN tag t ON l.tag_id = t.id
 WHERE l.item_id = ?
@@ -268,10 +832,35 @@ SELECT
     i.timestamp,
     i.headline,
     i.description,
-    i.rating
+    i.categories,
+    i.authors,
+    i.rating,
+    i.read_at,
+    i.starred,
+    COALESCE((SELECT c.content FROM item_content c WHERE c.item_id = i.id), '') AS content,
+    (SELECT COUNT(r.id) FROM item_revision r WHERE r.item_id = i.id) AS revisions
 FROM tag_link l
 INNER JOIN item i ON l.item_id = i.id
 WHERE tag_id = ?
+`,
+	query.TagLinkGetByTagManual: `
+SELECT
+    i.id,
+    i.feed_id,
+    i.url,
+    i.timestamp,
+    i.headline,
+    i.description,
+    i.categories,
+    i.authors,
+    i.rating,
+    i.read_at,
+    i.starred,
+    COALESCE((SELECT c.content FROM item_content c WHERE c.item_id = i.id), '') AS content,
+    (SELECT COUNT(r.id) FROM item_revision r WHERE r.item_id = i.id) AS revisions
+FROM tag_link l
+INNER JOIN item i ON l.item_id = i.id
+WHERE tag_id = ? AND auto = 0
 `,
 	query.TagLinkGetByTagHierarchy: `
 WITH RECURSIVE children(id, name, lvl, root, parent, full_name) AS (
@@ -302,11 +891,45 @@ SELECT DISTINCT
     i.timestamp,
     i.headline,
     i.description,
-    i.rating
+    i.categories,
+    i.authors,
+    i.rating,
+    i.read_at,
+    i.starred,
+    COALESCE((SELECT c.content FROM item_content c WHERE c.item_id = i.id), '') AS content,
+    (SELECT COUNT(r.id) FROM item_revision r WHERE r.item_id = i.id) AS revisions
 FROM tag_link l
 INNER JOIN item i ON l.item_id = i.id
 WHERE l.tag_id IN (SELECT id FROM children WHERE root = ?)
 ORDER BY i.timestamp;
+`,
+	query.CategoryMapAdd: `
+INSERT INTO category_map (feed_id, category, tag_id)
+                  VALUES (      ?,        ?,      ?)
+RETURNING id
+`,
+	query.CategoryMapApply: `
+INSERT INTO tag_link (tag_id, item_id, auto)
+SELECT ?, i.id, 1
+FROM item i, json_each(i.categories) c
+WHERE i.feed_id = ? AND lower(c.value) = lower(?)
+ON CONFLICT (tag_id, item_id) DO NOTHING
+`,
+	query.CategoryMapDelete: "DELETE FROM category_map WHERE id = ?",
+	query.CategoryMapGetByFeed: `
+SELECT
+    id,
+    category,
+    tag_id
+FROM category_map
+WHERE feed_id = ?
+ORDER BY lower(category), tag_id
+`,
+	query.CategoryGetByFeed: `
+SELECT DISTINCT c.value
+FROM item i, json_each(i.categories) c
+WHERE i.feed_id = ?
+ORDER BY lower(c.value)
 `,
 	query.SearchAdd: `
 INSERT INTO search (title, time_created, tags, tags_all, query_string, regex)
@@ -326,7 +949,8 @@ SELECT
     tags_all,
     query_string,
     regex,
-    results
+    results,
+    scores
 FROM search
 WHERE id = ?
 `,
@@ -340,8 +964,12 @@ SELECT
     msg,
     tags,
     tags_all,
+    filter_by_period,
+    filter_period_begin,
+    filter_period_end,
     query_string,
-    regex
+    regex,
+    results
 FROM search
 WHERE time_started IS NOT NULL AND time_finished IS NULL
 ORDER BY time_started
@@ -379,7 +1007,8 @@ SELECT
     filter_period_end,
     query_string,
     regex,
-    results
+    results,
+    scores
 FROM search
 ORDER BY time_created
 `,
@@ -389,7 +1018,8 @@ UPDATE search
 SET time_finished = ?,
     status = ?,
     msg = ?,
-    results = ?
+    results = ?,
+    scores = ?
 WHERE id = ?
 `,
 }
@@ -6,6 +6,8 @@
 
 package database
 
+// initQueries create the most recent version of the database schema, see
+// migrations.
 var initQueries = []string{
 	`
 CREATE TABLE feed (
@@ -16,11 +18,77 @@ CREATE TABLE feed (
     interval            INTEGER NOT NULL DEFAULT 1800,
     last_refresh        INTEGER NOT NULL DEFAULT 0,
     active              INTEGER NOT NULL DEFAULT 1,
-    CHECK (interval > 0)
+    folder              TEXT NOT NULL DEFAULT '',
+    fetch_full          INTEGER NOT NULL DEFAULT 0,
+    download            INTEGER NOT NULL DEFAULT 0,
+    etag                TEXT NOT NULL DEFAULT '',
+    last_modified       TEXT NOT NULL DEFAULT '',
+    last_status         INTEGER NOT NULL DEFAULT 0,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    last_error          TEXT NOT NULL DEFAULT '',
+    next_attempt        INTEGER NOT NULL DEFAULT 0,
+    headers             TEXT NOT NULL DEFAULT '',
+    auth_kind           INTEGER NOT NULL DEFAULT 0,
+    auth_user           TEXT NOT NULL DEFAULT '',
+    auth_secret         TEXT NOT NULL DEFAULT '',
+    interval_bound      INTEGER NOT NULL DEFAULT 0,
+    ttl                 INTEGER NOT NULL DEFAULT 0,
+    skip_hours          INTEGER NOT NULL DEFAULT 0,
+    skip_days           INTEGER NOT NULL DEFAULT 0,
+    cadence             INTEGER NOT NULL DEFAULT 0,
+    next_refresh        INTEGER NOT NULL DEFAULT 0,
+    description         TEXT NOT NULL DEFAULT '',
+    language            TEXT NOT NULL DEFAULT '',
+    image               TEXT NOT NULL DEFAULT '',
+    generator           TEXT NOT NULL DEFAULT '',
+    icon                TEXT NOT NULL DEFAULT '',
+    icon_type           TEXT NOT NULL DEFAULT '',
+    icon_checked        INTEGER NOT NULL DEFAULT 0,
+    retention           INTEGER NOT NULL DEFAULT 0,
+    CHECK (interval > 0),
+    CHECK (consecutive_failures >= 0),
+    CHECK (auth_kind IN (0, 1, 2)),
+    CHECK (interval_bound IN (0, 1)),
+    CHECK (ttl >= 0 AND cadence >= 0)
 ) STRICT
 `,
 	"CREATE INDEX feed_last_refresh_idx ON feed (last_refresh)",
 	"CREATE INDEX feed_active_idx ON feed (active <> 0)",
+	"CREATE INDEX feed_folder_idx ON feed (folder)",
+	`
+CREATE TABLE websub (
+    id                  INTEGER PRIMARY KEY,
+    feed_id             INTEGER UNIQUE NOT NULL,
+    hub                 TEXT NOT NULL,
+    topic               TEXT NOT NULL,
+    secret              TEXT NOT NULL DEFAULT '',
+    state               INTEGER NOT NULL DEFAULT 0,
+    requested           INTEGER NOT NULL,
+    lease_expires       INTEGER NOT NULL DEFAULT 0,
+    last_push           INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (feed_id) REFERENCES feed (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE,
+    CHECK (state IN (0, 1, 2, 3))
+) STRICT
+`,
+	"CREATE INDEX websub_lease_idx ON websub (state, lease_expires)",
+
+	`
+CREATE TABLE feed_history (
+    id                  INTEGER PRIMARY KEY,
+    feed_id             INTEGER NOT NULL,
+    timestamp           INTEGER NOT NULL,
+    old_url             TEXT NOT NULL,
+    new_url             TEXT NOT NULL,
+    reason              TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (feed_id) REFERENCES feed (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+	"CREATE INDEX feed_history_feed_idx ON feed_history (feed_id, timestamp)",
+
 	`
 CREATE TABLE item (
     id                  INTEGER PRIMARY KEY,
@@ -30,14 +98,74 @@ CREATE TABLE item (
     headline            TEXT NOT NULL,
     description         TEXT NOT NULL DEFAULT '',
     rating              INTEGER NOT NULL DEFAULT 0,
+    guid                TEXT NOT NULL DEFAULT '',
+    url_canonical       TEXT NOT NULL DEFAULT '',
+    categories          TEXT NOT NULL DEFAULT '[]',
+    authors             TEXT NOT NULL DEFAULT '[]',
+    read_at             INTEGER NOT NULL DEFAULT 0,
+    starred             INTEGER NOT NULL DEFAULT 0,
     FOREIGN KEY (feed_id) REFERENCES feed (id),
-    CHECK (rating IN (-1, 0, 1))
+    CHECK (rating IN (-1, 0, 1)),
+    CHECK (starred IN (0, 1))
 ) STRICT
 `,
 	"CREATE INDEX item_feed_idx ON item (feed_id)",
 	"CREATE INDEX item_time_idx ON item (timestamp)",
 	"CREATE INDEX item_headline_idx ON item (headline)",
 	"CREATE INDEX item_rating_idx ON item (rating)",
+	"CREATE INDEX item_guid_idx ON item (feed_id, guid)",
+	"CREATE INDEX item_canon_idx ON item (url_canonical)",
+	"CREATE INDEX item_read_idx ON item (feed_id, read_at)",
+	"CREATE INDEX item_starred_idx ON item (starred) WHERE starred = 1",
+
+	`
+CREATE TABLE item_content (
+    id                  INTEGER PRIMARY KEY,
+    item_id             INTEGER UNIQUE NOT NULL,
+    timestamp           INTEGER NOT NULL,
+    content             TEXT NOT NULL,
+    FOREIGN KEY (item_id) REFERENCES item (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+
+	`
+CREATE TABLE item_revision (
+    id                  INTEGER PRIMARY KEY,
+    item_id             INTEGER NOT NULL,
+    timestamp           INTEGER NOT NULL,
+    headline            TEXT NOT NULL,
+    description         TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (item_id) REFERENCES item (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+	"CREATE INDEX rev_item_idx ON item_revision (item_id, timestamp)",
+
+	`
+CREATE TABLE enclosure (
+    id                  INTEGER PRIMARY KEY,
+    item_id             INTEGER NOT NULL,
+    url                 TEXT NOT NULL,
+    mime_type           TEXT NOT NULL DEFAULT '',
+    length              INTEGER NOT NULL DEFAULT 0,
+    path                TEXT NOT NULL DEFAULT '',
+    size                INTEGER NOT NULL DEFAULT 0,
+    downloaded          INTEGER NOT NULL DEFAULT 0,
+    attempts            INTEGER NOT NULL DEFAULT 0,
+    expired             INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (item_id) REFERENCES item (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE,
+    UNIQUE (item_id, url),
+    CHECK (length >= 0),
+    CHECK (size >= 0)
+) STRICT
+`,
+	"CREATE INDEX encl_item_idx ON enclosure (item_id)",
+	"CREATE INDEX encl_downloaded_idx ON enclosure (downloaded)",
 
 	`
 CREATE TABLE tag (
@@ -58,18 +186,38 @@ CREATE TABLE tag_link (
     id		INTEGER PRIMARY KEY,
     tag_id	INTEGER NOT NULL,
     item_id	INTEGER NOT NULL,
+    auto	INTEGER NOT NULL DEFAULT 0,
     FOREIGN KEY (tag_id) REFERENCES tag (id)
         ON UPDATE RESTRICT
         ON DELETE CASCADE,
     FOREIGN KEY (item_id) REFERENCES item (id)
         ON UPDATE RESTRICT
         ON DELETE CASCADE,
-    UNIQUE (tag_id, item_id)
+    UNIQUE (tag_id, item_id),
+    CHECK (auto IN (0, 1))
 ) STRICT
 `,
 	"CREATE INDEX tl_tag_idx ON tag_link (tag_id)",
 	"CREATE INDEX tl_item_idx ON tag_link (item_id)",
 
+	`
+CREATE TABLE category_map (
+    id		INTEGER PRIMARY KEY,
+    feed_id	INTEGER NOT NULL,
+    category	TEXT NOT NULL,
+    tag_id	INTEGER NOT NULL,
+    FOREIGN KEY (feed_id) REFERENCES feed (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tag (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE,
+    UNIQUE (feed_id, category, tag_id),
+    CHECK (category <> '')
+) STRICT
+`,
+	"CREATE INDEX catmap_feed_idx ON category_map (feed_id)",
+
 	`
 CREATE TABLE search (
     id			INTEGER PRIMARY KEY,
@@ -87,6 +235,7 @@ CREATE TABLE search (
     query_string	TEXT NOT NULL,
     regex		INTEGER NOT NULL DEFAULT 0,
     results		TEXT,
+    scores		TEXT,
     CHECK (time_started IS NULL OR time_started >= time_created),
     CHECK (time_finished IS NULL OR (time_started IS NOT NULL AND time_finished >= time_started)),
     CHECK ((filter_by_period = 0 AND filter_period_begin = 0 AND filter_period_end = 0) OR
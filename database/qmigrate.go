@@ -0,0 +1,196 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/qmigrate.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 18:02:11 krylon>
+
+package database
+
+// migrations lists the changes to the database schema, ordered by version.
+// initQueries always create the schema of the most recent version, so when
+// you add a Migration, make the same change to initQueries.
+// Version 0 is the schema from before we kept track of versions.
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "Fetch state, scheduling, credentials and metadata of Feeds; GUIDs, content, revisions, enclosures and categories of Items",
+		Queries: []string{
+			"ALTER TABLE feed ADD COLUMN folder TEXT NOT NULL DEFAULT ''",
+			"ALTER TABLE feed ADD COLUMN fetch_full INTEGER NOT NULL DEFAULT 0",
+			"ALTER TABLE feed ADD COLUMN download INTEGER NOT NULL DEFAULT 0",
+			"ALTER TABLE feed ADD COLUMN etag TEXT NOT NULL DEFAULT ''",
+			"ALTER TABLE feed ADD COLUMN last_modified TEXT NOT NULL DEFAULT ''",
+			"ALTER TABLE feed ADD COLUMN last_status INTEGER NOT NULL DEFAULT 0",
+			"ALTER TABLE feed ADD COLUMN consecutive_failures INTEGER NOT NULL DEFAULT 0 CHECK (consecutive_failures >= 0)",
+			"ALTER TABLE feed ADD COLUMN last_error TEXT NOT NULL DEFAULT ''",
+			"ALTER TABLE feed ADD COLUMN next_attempt INTEGER NOT NULL DEFAULT 0",
+			"ALTER TABLE feed ADD COLUMN headers TEXT NOT NULL DEFAULT ''",
+			"ALTER TABLE feed ADD COLUMN auth_kind INTEGER NOT NULL DEFAULT 0 CHECK (auth_kind IN (0, 1, 2))",
+			"ALTER TABLE feed ADD COLUMN auth_user TEXT NOT NULL DEFAULT ''",
+			"ALTER TABLE feed ADD COLUMN auth_secret TEXT NOT NULL DEFAULT ''",
+			"ALTER TABLE feed ADD COLUMN interval_bound INTEGER NOT NULL DEFAULT 0 CHECK (interval_bound IN (0, 1))",
+			"ALTER TABLE feed ADD COLUMN ttl INTEGER NOT NULL DEFAULT 0 CHECK (ttl >= 0)",
+			"ALTER TABLE feed ADD COLUMN skip_hours INTEGER NOT NULL DEFAULT 0",
+			"ALTER TABLE feed ADD COLUMN skip_days INTEGER NOT NULL DEFAULT 0",
+			"ALTER TABLE feed ADD COLUMN cadence INTEGER NOT NULL DEFAULT 0 CHECK (cadence >= 0)",
+			"ALTER TABLE feed ADD COLUMN next_refresh INTEGER NOT NULL DEFAULT 0",
+			"ALTER TABLE feed ADD COLUMN description TEXT NOT NULL DEFAULT ''",
+			"ALTER TABLE feed ADD COLUMN language TEXT NOT NULL DEFAULT ''",
+			"ALTER TABLE feed ADD COLUMN image TEXT NOT NULL DEFAULT ''",
+			"ALTER TABLE feed ADD COLUMN generator TEXT NOT NULL DEFAULT ''",
+			"ALTER TABLE feed ADD COLUMN icon TEXT NOT NULL DEFAULT ''",
+			"ALTER TABLE feed ADD COLUMN icon_type TEXT NOT NULL DEFAULT ''",
+			"ALTER TABLE feed ADD COLUMN icon_checked INTEGER NOT NULL DEFAULT 0",
+			"ALTER TABLE feed ADD COLUMN retention INTEGER NOT NULL DEFAULT 0",
+			"CREATE INDEX feed_folder_idx ON feed (folder)",
+			`
+CREATE TABLE websub (
+    id                  INTEGER PRIMARY KEY,
+    feed_id             INTEGER UNIQUE NOT NULL,
+    hub                 TEXT NOT NULL,
+    topic               TEXT NOT NULL,
+    secret              TEXT NOT NULL DEFAULT '',
+    state               INTEGER NOT NULL DEFAULT 0,
+    requested           INTEGER NOT NULL,
+    lease_expires       INTEGER NOT NULL DEFAULT 0,
+    last_push           INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (feed_id) REFERENCES feed (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE,
+    CHECK (state IN (0, 1, 2))
+) STRICT
+`,
+			"CREATE INDEX websub_lease_idx ON websub (state, lease_expires)",
+			`
+CREATE TABLE feed_history (
+    id                  INTEGER PRIMARY KEY,
+    feed_id             INTEGER NOT NULL,
+    timestamp           INTEGER NOT NULL,
+    old_url             TEXT NOT NULL,
+    new_url             TEXT NOT NULL,
+    reason              TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (feed_id) REFERENCES feed (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+			"CREATE INDEX feed_history_feed_idx ON feed_history (feed_id, timestamp)",
+			"ALTER TABLE item ADD COLUMN guid TEXT NOT NULL DEFAULT ''",
+			"ALTER TABLE item ADD COLUMN url_canonical TEXT NOT NULL DEFAULT ''",
+			"ALTER TABLE item ADD COLUMN categories TEXT NOT NULL DEFAULT '[]'",
+			"ALTER TABLE item ADD COLUMN authors TEXT NOT NULL DEFAULT '[]'",
+			"CREATE INDEX item_guid_idx ON item (feed_id, guid)",
+			"CREATE INDEX item_canon_idx ON item (url_canonical)",
+			`
+CREATE TABLE item_content (
+    id                  INTEGER PRIMARY KEY,
+    item_id             INTEGER UNIQUE NOT NULL,
+    timestamp           INTEGER NOT NULL,
+    content             TEXT NOT NULL,
+    FOREIGN KEY (item_id) REFERENCES item (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+			`
+CREATE TABLE item_revision (
+    id                  INTEGER PRIMARY KEY,
+    item_id             INTEGER NOT NULL,
+    timestamp           INTEGER NOT NULL,
+    headline            TEXT NOT NULL,
+    description         TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (item_id) REFERENCES item (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE
+) STRICT
+`,
+			"CREATE INDEX rev_item_idx ON item_revision (item_id, timestamp)",
+			`
+CREATE TABLE enclosure (
+    id                  INTEGER PRIMARY KEY,
+    item_id             INTEGER NOT NULL,
+    url                 TEXT NOT NULL,
+    mime_type           TEXT NOT NULL DEFAULT '',
+    length              INTEGER NOT NULL DEFAULT 0,
+    path                TEXT NOT NULL DEFAULT '',
+    size                INTEGER NOT NULL DEFAULT 0,
+    downloaded          INTEGER NOT NULL DEFAULT 0,
+    attempts            INTEGER NOT NULL DEFAULT 0,
+    expired             INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (item_id) REFERENCES item (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE,
+    UNIQUE (item_id, url),
+    CHECK (length >= 0),
+    CHECK (size >= 0)
+) STRICT
+`,
+			"CREATE INDEX encl_item_idx ON enclosure (item_id)",
+			"CREATE INDEX encl_downloaded_idx ON enclosure (downloaded)",
+			"ALTER TABLE tag_link ADD COLUMN auto INTEGER NOT NULL DEFAULT 0 CHECK (auto IN (0, 1))",
+			`
+CREATE TABLE category_map (
+    id		INTEGER PRIMARY KEY,
+    feed_id	INTEGER NOT NULL,
+    category	TEXT NOT NULL,
+    tag_id	INTEGER NOT NULL,
+    FOREIGN KEY (feed_id) REFERENCES feed (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tag (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE,
+    UNIQUE (feed_id, category, tag_id),
+    CHECK (category <> '')
+) STRICT
+`,
+			"CREATE INDEX catmap_feed_idx ON category_map (feed_id)",
+		},
+	},
+	{
+		Version:     2,
+		Description: "Relevance scores of Search results",
+		Queries: []string{
+			"ALTER TABLE search ADD COLUMN scores TEXT",
+		},
+	},
+	{
+		Version:     3,
+		Description: "Read and starred state of Items",
+		Queries: []string{
+			"ALTER TABLE item ADD COLUMN read_at INTEGER NOT NULL DEFAULT 0",
+			"ALTER TABLE item ADD COLUMN starred INTEGER NOT NULL DEFAULT 0 CHECK (starred IN (0, 1))",
+			"CREATE INDEX item_read_idx ON item (feed_id, read_at)",
+			"CREATE INDEX item_starred_idx ON item (starred) WHERE starred = 1",
+		},
+	},
+	{
+		Version:     4,
+		Description: "Pending requests to unsubscribe from WebSub hubs",
+		// SQLite cannot change a CHECK constraint in place, so we have
+		// to rebuild the table.
+		Queries: []string{
+			`
+CREATE TABLE websub_new (
+    id                  INTEGER PRIMARY KEY,
+    feed_id             INTEGER UNIQUE NOT NULL,
+    hub                 TEXT NOT NULL,
+    topic               TEXT NOT NULL,
+    secret              TEXT NOT NULL DEFAULT '',
+    state               INTEGER NOT NULL DEFAULT 0,
+    requested           INTEGER NOT NULL,
+    lease_expires       INTEGER NOT NULL DEFAULT 0,
+    last_push           INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (feed_id) REFERENCES feed (id)
+        ON UPDATE RESTRICT
+        ON DELETE CASCADE,
+    CHECK (state IN (0, 1, 2, 3))
+) STRICT
+`,
+			"INSERT INTO websub_new SELECT id, feed_id, hub, topic, secret, state, requested, lease_expires, last_push FROM websub",
+			"DROP TABLE websub",
+			"ALTER TABLE websub_new RENAME TO websub",
+			"CREATE INDEX websub_lease_idx ON websub (state, lease_expires)",
+		},
+	},
+}
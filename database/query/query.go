@@ -17,12 +17,49 @@ const (
 	FeedGetByID
 	FeedGetAll
 	FeedGetPending
+	FeedGetUnreadCnt
 	FeedUpdateRefresh
+	FeedUpdateHTTPState
+	FeedRecordFailure
+	FeedResetFailures
+	FeedPostpone
+	FeedSetMeta
+	FeedSetIcon
+	FeedSetRetention
 	FeedSetActive
+	FeedSetFetchFull
+	FeedSetDownload
+	FeedSetHTTPSettings
+	FeedSetIntervalBound
+	FeedSetSchedule
+	FeedUpdate
+	FeedResetState
+	FeedHistoryAdd
+	FeedHistoryGetByFeed
 	FeedDelete
+	WebSubAdd
+	WebSubGetByFeed
+	WebSubGetRenewable
+	WebSubSetActive
+	WebSubSetState
+	WebSubSetLastPush
+	WebSubDelete
+	WebSubDeleteByFeed
 	ItemAdd
+	ItemUpsert
 	ItemDeleteByFeed
+	ItemGetPurgeable
+	ItemPurge
 	ItemExists
+	ItemGetByKey
+	ItemUpdate
+	ItemRevisionAdd
+	ItemRevisionGetByItem
+	ItemGetUncanonical
+	ItemSetCanonical
+	ItemMergeDuplicates
+	ItemMergeRating
+	ItemDeleteDuplicates
 	ItemGetRecent
 	ItemGetRecentPaged
 	ItemGetByID
@@ -30,24 +67,48 @@ const (
 	ItemGetByPeriod
 	ItemGetRated
 	ItemGetAll
+	ItemContentAdd
+	ItemContentGet
 	ItemRate
 	ItemUnrate
+	ItemMarkRead
+	ItemMarkUnread
+	ItemMarkReadList
+	ItemMarkReadAbove
+	ItemSetStarred
+	EnclosureAdd
+	EnclosureGetByID
+	EnclosureGetByItem
+	EnclosureGetPending
+	EnclosureGetDownloaded
+	EnclosureGetTotalSize
+	EnclosureSetDownloaded
+	EnclosureRecordFailure
+	EnclosureExpire
 	TagAdd
 	TagGetByID
 	TagGetChildren
 	TagGetAll
 	TagGetSorted
 	TagGetItemCnt
+	TagGetUnreadCnt
 	TagRename
 	TagSetParent
 	TagUpdate
 	TagDelete
 	TagLinkAdd
+	TagLinkAddAuto
 	TagLinkDelete
 	TagLinkDeleteByFeed
 	TagLinkGetByItem
 	TagLinkGetByTag
+	TagLinkGetByTagManual
 	TagLinkGetByTagHierarchy
+	CategoryMapAdd
+	CategoryMapApply
+	CategoryMapDelete
+	CategoryMapGetByFeed
+	CategoryGetByFeed
 	SearchAdd
 	SearchDelete
 	SearchGetByID
@@ -65,12 +126,44 @@ func AllQueries() []ID {
 		FeedGetByID,
 		FeedGetAll,
 		FeedGetPending,
+		FeedGetUnreadCnt,
 		FeedUpdateRefresh,
+		FeedUpdateHTTPState,
+		FeedRecordFailure,
+		FeedResetFailures,
+		FeedPostpone,
+		FeedSetMeta,
+		FeedSetIcon,
+		FeedSetRetention,
 		FeedSetActive,
+		FeedSetFetchFull,
+		FeedSetDownload,
+		FeedSetHTTPSettings,
+		FeedSetIntervalBound,
+		FeedSetSchedule,
 		FeedDelete,
+		WebSubAdd,
+		WebSubGetByFeed,
+		WebSubGetRenewable,
+		WebSubSetActive,
+		WebSubSetState,
+		WebSubSetLastPush,
+		WebSubDelete,
 		ItemAdd,
+		ItemUpsert,
 		ItemDeleteByFeed,
+		ItemGetPurgeable,
+		ItemPurge,
 		ItemExists,
+		ItemGetByKey,
+		ItemUpdate,
+		ItemRevisionAdd,
+		ItemRevisionGetByItem,
+		ItemGetUncanonical,
+		ItemSetCanonical,
+		ItemMergeDuplicates,
+		ItemMergeRating,
+		ItemDeleteDuplicates,
 		ItemGetRecent,
 		ItemGetRecentPaged,
 		ItemGetByID,
@@ -78,23 +171,47 @@ func AllQueries() []ID {
 		ItemGetByPeriod,
 		ItemGetRated,
 		ItemGetAll,
+		ItemContentAdd,
+		ItemContentGet,
 		ItemRate,
 		ItemUnrate,
+		ItemMarkRead,
+		ItemMarkUnread,
+		ItemMarkReadList,
+		ItemMarkReadAbove,
+		ItemSetStarred,
+		EnclosureAdd,
+		EnclosureGetByID,
+		EnclosureGetByItem,
+		EnclosureGetPending,
+		EnclosureGetDownloaded,
+		EnclosureGetTotalSize,
+		EnclosureSetDownloaded,
+		EnclosureRecordFailure,
+		EnclosureExpire,
 		TagAdd,
 		TagGetByID,
 		TagGetChildren,
 		TagGetAll,
 		TagGetSorted,
+		TagGetUnreadCnt,
 		TagRename,
 		TagSetParent,
 		TagUpdate,
 		TagDelete,
 		TagLinkAdd,
+		TagLinkAddAuto,
 		TagLinkDelete,
 		TagLinkDeleteByFeed,
 		TagLinkGetByItem,
 		TagLinkGetByTag,
+		TagLinkGetByTagManual,
 		TagLinkGetByTagHierarchy,
+		CategoryMapAdd,
+		CategoryMapApply,
+		CategoryMapDelete,
+		CategoryMapGetByFeed,
+		CategoryGetByFeed,
 		SearchAdd,
 		SearchDelete,
 		SearchGetByID,
@@ -7,7 +7,11 @@
 package database
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -24,6 +28,8 @@ import (
 // both in the Search object AND the database.
 // If something goes wrong, it stores the relevant error message in the
 // object and the database record.
+// Unless the query string is a regular expression, the full-text index is
+// used if it is available, and the results are ordered by relevance.
 func (db *Database) SearchExecute(s *model.Search) error {
 	var (
 		err   error
@@ -37,6 +43,12 @@ func (db *Database) SearchExecute(s *model.Search) error {
 				err.Error())
 			return err
 		}
+	} else if db.useFTS(s) {
+		if items, err = db.searchFTS(s); err != nil {
+			db.log.Printf("[ERROR] Failed to search full-text index: %s\n",
+				err.Error())
+			return err
+		}
 	} else if s.FilterByPeriod {
 		if items, err = db.ItemGetByPeriod(s.FilterPeriod[0], s.FilterPeriod[1]); err != nil {
 			db.log.Printf("[ERROR] Failed to load Items by Period: %s\n",
@@ -258,6 +270,8 @@ func (db *Database) searchLoadByTags(s *model.Search) ([]*model.Item, error) {
 
 	if len(items) == 0 {
 		return nil, nil
+	} else if db.useFTS(s) {
+		return db.searchFilterFTS(s, items)
 	}
 
 	var results = make([]*model.Item, 0, len(items))
@@ -268,6 +282,88 @@ func (db *Database) searchLoadByTags(s *model.Search) ([]*model.Item, error) {
 		}
 	}
 
+	// The Items were collected in maps, so their order is random. We
+	// return them newest first, like everywhere else.
+	slices.SortFunc(results, func(a, b *model.Item) int {
+		if c := b.Timestamp.Compare(a.Timestamp); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(b.ID, a.ID)
+	})
+
 	// At long last:
 	return results, nil
 } // func (db *Database) searchLoadByTags(s *model.Search) ([]*model.Item, error)
+
+// useFTS returns true if we can use the full-text index for the given Search.
+// Regular expressions have to be matched by hand.
+func (db *Database) useFTS(s *model.Search) bool {
+	return db.fts && !s.Regex && strings.TrimSpace(s.QueryString) != ""
+} // func (db *Database) useFTS(s *model.Search) bool
+
+// searchFilterFTS returns those of the given Items that match the Search's
+// query string according to the full-text index, best match first.
+func (db *Database) searchFilterFTS(s *model.Search, items []*model.Item) ([]*model.Item, error) {
+	var (
+		err     error
+		matches []*model.Item
+		ids     = make(map[int64]bool, len(items))
+	)
+
+	if matches, err = db.searchFTS(s); err != nil {
+		db.log.Printf("[ERROR] Failed to search full-text index: %s\n",
+			err.Error())
+		return nil, err
+	}
+
+	for _, i := range items {
+		ids[i.ID] = true
+	}
+
+	var results = make([]*model.Item, 0, min(len(items), len(matches)))
+
+	for _, i := range matches {
+		if ids[i.ID] {
+			results = append(results, i)
+		}
+	}
+
+	return results, nil
+} // func (db *Database) searchFilterFTS(s *model.Search, items []*model.Item) ([]*model.Item, error)
+
+// searchSetScores fills in the relevance scores of a Search's results, as
+// stored in the database.
+func (db *Database) searchSetScores(s *model.Search, scoreStr *string) error {
+	if scoreStr == nil || *scoreStr == "" {
+		return nil
+	}
+
+	var scores = strings.Split(*scoreStr, ",")
+
+	if len(scores) != len(s.Results) {
+		db.log.Printf("[ERROR] Search %d has %d results, but %d scores\n",
+			s.ID,
+			len(s.Results),
+			len(scores))
+		return nil
+	}
+
+	for idx, str := range scores {
+		var (
+			err   error
+			score float64
+		)
+
+		if score, err = strconv.ParseFloat(str, 64); err != nil {
+			db.log.Printf("[ERROR] Cannot parse score %q: %s\n",
+				str,
+				err.Error())
+			return err
+		} else if s.Results[idx] != nil {
+			s.Results[idx].Score = score
+		}
+	}
+
+	return nil
+} // func (db *Database) searchSetScores(s *model.Search, scoreStr *string) error
@@ -0,0 +1,147 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/store.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 19:02:37 krylon>
+
+package database
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/blicero/badnews/common"
+	"github.com/blicero/badnews/common/path"
+	"github.com/blicero/badnews/model"
+)
+
+// Store is the set of operations on Feeds, Items, Tags and Searches the
+// rest of the application uses. Database implements it on top of SQLite,
+// the memory package provides an implementation that keeps everything in
+// RAM, which is meant for tests.
+//
+// A Store is not safe for concurrent use, every goroutine needs one of its
+// own, e.g. from a Pool. Like a Database, a Store supports one explicit
+// transaction at a time, methods that are called while no transaction is in
+// progress take effect immediately.
+type Store interface {
+	Close() error
+	Begin() error
+	Commit() error
+	Rollback() error
+
+	FeedAdd(f *model.Feed) error
+	FeedGetByID(id int64) (*model.Feed, error)
+	FeedGetAll() ([]model.Feed, error)
+	FeedGetPending() ([]model.Feed, error)
+	FeedGetUnreadCnt() (map[int64]int64, error)
+	FeedUpdateRefresh(f *model.Feed, stamp time.Time) error
+	FeedUpdateHTTPState(f *model.Feed, etag, lastMod string, code int) error
+	FeedRecordFailure(f *model.Feed, errmsg string, next time.Time, active bool) error
+	FeedResetFailures(f *model.Feed) error
+	FeedPostpone(f *model.Feed, next time.Time) error
+	FeedSetMeta(f *model.Feed, meta model.FeedMeta) error
+	FeedSetIcon(f *model.Feed, icon, mimeType string, checked time.Time) error
+	FeedUpdate(f *model.Feed, title string, addr, homepage *url.URL, interval time.Duration) error
+	FeedSetURL(f *model.Feed, addr *url.URL, reason string) error
+	FeedSetRetention(f *model.Feed, days int) error
+	FeedSetActive(f *model.Feed, active bool) error
+	FeedSetFetchFull(f *model.Feed, full bool) error
+	FeedSetDownload(f *model.Feed, download bool) error
+	FeedSetHTTPSettings(f *model.Feed, headers map[string]string, auth model.Credentials) error
+	FeedSetIntervalBound(f *model.Feed, bound model.IntervalBound) error
+	FeedSetSchedule(f *model.Feed, sched model.Schedule) error
+	FeedHistoryGetByFeed(f *model.Feed) ([]model.FeedMove, error)
+	FeedDelete(f *model.Feed) error
+
+	ItemAdd(i *model.Item) error
+	ItemUpsert(i *model.Item) (bool, error)
+	ItemGetPurgeable(global int, now time.Time) ([]*model.Item, error)
+	ItemPurge(i *model.Item) (bool, error)
+	ItemDeleteByFeed(f *model.Feed) error
+	ItemGetByKey(i *model.Item) (*model.Item, error)
+	ItemUpdate(i *model.Item, headline, description string) error
+	ItemRevisionAdd(i *model.Item, stamp time.Time) error
+	ItemRevisionGetByItem(i *model.Item) ([]model.Revision, error)
+	ItemGetRecent(begin time.Time) ([]*model.Item, error)
+	ItemGetRecentPaged(cnt, offset int64, filter model.ItemFilter) ([]*model.Item, error)
+	ItemGetByID(id int64) (*model.Item, error)
+	ItemGetByFeed(f *model.Feed, limit, offset int64, filter model.ItemFilter) ([]*model.Item, error)
+	ItemGetRated() ([]model.Item, error)
+	ItemContentAdd(i *model.Item, content string) error
+	ItemRate(i *model.Item, r int8) error
+	ItemUnrate(i *model.Item) error
+	ItemMarkRead(i *model.Item) error
+	ItemMarkUnread(i *model.Item) error
+	ItemMarkReadList(ids []int64) (int64, error)
+	ItemMarkReadAbove(i *model.Item, f *model.Feed) (int64, error)
+	ItemSetStarred(i *model.Item, starred bool) error
+
+	EnclosureAdd(e *model.Enclosure) error
+	EnclosureGetByID(id int64) (*model.Enclosure, error)
+	EnclosureGetByItem(i *model.Item) ([]*model.Enclosure, error)
+	EnclosureGetPending(maxAttempts, max int) ([]*model.Enclosure, error)
+	EnclosureGetDownloaded() ([]*model.Enclosure, error)
+	EnclosureGetTotalSize() (int64, error)
+	EnclosureSetDownloaded(e *model.Enclosure, path string, size int64, stamp time.Time) error
+	EnclosureRecordFailure(e *model.Enclosure) error
+	EnclosureExpire(e *model.Enclosure) error
+
+	WebSubAdd(s *model.Subscription) error
+	WebSubGetByFeed(f *model.Feed) (*model.Subscription, error)
+	WebSubGetRenewable(expires, deadline time.Time) ([]*model.Subscription, error)
+	WebSubSetActive(s *model.Subscription, expires time.Time) error
+	WebSubSetState(s *model.Subscription, state model.SubState) error
+	WebSubSetLastPush(s *model.Subscription, stamp time.Time) error
+	WebSubDelete(s *model.Subscription) error
+
+	TagAdd(t *model.Tag) error
+	TagGetByID(id int64) (*model.Tag, error)
+	TagGetAll() ([]*model.Tag, error)
+	TagGetSorted() ([]*model.Tag, error)
+	TagGetItemCnt() (map[int64]int64, error)
+	TagGetUnreadCnt() (map[int64]int64, error)
+	TagUpdate(t *model.Tag, name string, parent int64) error
+
+	TagLinkAdd(item *model.Item, tag *model.Tag) error
+	TagLinkAddAuto(item *model.Item, tag *model.Tag) error
+	TagLinkDelete(item *model.Item, tag *model.Tag) error
+	TagLinkDeleteByFeed(f *model.Feed) error
+	TagLinkGetByItem(item *model.Item) ([]*model.Tag, error)
+	TagLinkGetByTagManual(tag *model.Tag) ([]*model.Item, error)
+
+	CategoryMapAdd(m *model.CategoryMapping) error
+	CategoryMapDelete(m *model.CategoryMapping) error
+	CategoryMapGetByFeed(f *model.Feed) ([]model.CategoryMapping, error)
+	CategoryGetByFeed(f *model.Feed) ([]string, error)
+
+	SearchAdd(s *model.Search) error
+	SearchDelete(s *model.Search) error
+	SearchGetByID(id int64) (*model.Search, error)
+	SearchGetNextPending() (*model.Search, error)
+	SearchGetActive() ([]*model.Search, error)
+	SearchGetAll() ([]*model.Search, error)
+	SearchStart(s *model.Search) error
+	SearchExecute(s *model.Search) error
+}
+
+var _ Store = (*Database)(nil)
+
+// Opener returns a new Store. Components that need more than one Store, e.g.
+// for a Pool, are given an Opener instead of a Store.
+type Opener func() (Store, error)
+
+// OpenDefault opens the Database at its usual location. It is the Opener the
+// application uses outside of tests.
+func OpenDefault() (Store, error) {
+	var (
+		err error
+		db  *Database
+	)
+
+	if db, err = Open(common.Path(path.Database)); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+} // func OpenDefault() (Store, error)
@@ -0,0 +1,374 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/storetest/feed.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 00:14:37 krylon>
+
+package storetest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/model"
+)
+
+func testTransaction(t *testing.T, open database.Opener) {
+	var (
+		err   error
+		feeds []model.Feed
+		db    = openStore(t, open)
+	)
+
+	if err = db.Commit(); !errors.Is(err, database.ErrNoTxInProgress) {
+		t.Errorf("Commit without transaction should fail, got %v", err)
+	} else if err = db.Rollback(); !errors.Is(err, database.ErrNoTxInProgress) {
+		t.Errorf("Rollback without transaction should fail, got %v", err)
+	} else if err = db.Begin(); err != nil {
+		t.Fatalf("Failed to begin transaction: %s", err.Error())
+	} else if err = db.Begin(); !errors.Is(err, database.ErrTxInProgress) {
+		t.Errorf("Nested transaction should fail, got %v", err)
+	}
+
+	addFeed(t, db, "rollback")
+
+	if err = db.Rollback(); err != nil {
+		t.Fatalf("Failed to roll back transaction: %s", err.Error())
+	} else if feeds, err = db.FeedGetAll(); err != nil {
+		t.Fatalf("Failed to load Feeds: %s", err.Error())
+	} else if len(feeds) != 0 {
+		t.Errorf("Expected no Feeds after Rollback, got %d", len(feeds))
+	} else if err = db.Begin(); err != nil {
+		t.Fatalf("Failed to begin transaction: %s", err.Error())
+	}
+
+	addFeed(t, db, "commit")
+
+	if err = db.Commit(); err != nil {
+		t.Fatalf("Failed to commit transaction: %s", err.Error())
+	} else if feeds, err = openStore(t, open).FeedGetAll(); err != nil {
+		t.Fatalf("Failed to load Feeds: %s", err.Error())
+	} else if len(feeds) != 1 || feeds[0].Title != "commit" {
+		t.Errorf("Expected Feed commit after Commit, got %d Feeds", len(feeds))
+	}
+} // func testTransaction(t *testing.T, open database.Opener)
+
+func testFeed(t *testing.T, open database.Opener) {
+	var (
+		err    error
+		f      *model.Feed
+		feeds  []model.Feed
+		db     = openStore(t, open)
+		other  = openStore(t, open)
+		beta   = addFeed(t, db, "beta")
+		alpha  = addFeed(t, db, "alpha")
+		folder = &model.Feed{
+			Title:          "aardvark",
+			URL:            purl("https://aardvark.example.com/feed.rss"),
+			Homepage:       purl("https://aardvark.example.com/"),
+			UpdateInterval: 90 * time.Minute,
+			Folder:         "zoo",
+		}
+		headers = map[string]string{"X-Api-Key": "abc123"}
+		auth    = model.Credentials{Kind: model.AuthBasic, Username: "user", Secret: "secret"}
+	)
+
+	if err = db.FeedAdd(folder); err != nil {
+		t.Fatalf("Failed to add Feed %s: %s", folder.Title, err.Error())
+	} else if err = db.FeedAdd(&model.Feed{Title: "dup", URL: alpha.URL, Homepage: alpha.Homepage, UpdateInterval: time.Hour}); err == nil {
+		t.Error("Adding a Feed with a duplicate URL should fail")
+	} else if err = db.FeedAdd(&model.Feed{Title: alpha.Title, URL: purl("https://dup.example.com/"), Homepage: alpha.Homepage, UpdateInterval: time.Hour}); err == nil {
+		t.Error("Adding a Feed with a duplicate title should fail")
+	} else if feeds, err = other.FeedGetAll(); err != nil {
+		t.Fatalf("Failed to load Feeds: %s", err.Error())
+	} else if len(feeds) != 3 {
+		t.Fatalf("Expected 3 Feeds, got %d", len(feeds))
+	} else if feeds[0].ID != alpha.ID || feeds[1].ID != beta.ID || feeds[2].ID != folder.ID {
+		t.Errorf("Feeds should be ordered by folder and title: %s, %s, %s",
+			feeds[0].Title,
+			feeds[1].Title,
+			feeds[2].Title)
+	} else if f, err = other.FeedGetByID(folder.ID); err != nil {
+		t.Fatalf("Failed to load Feed %d: %s", folder.ID, err.Error())
+	} else if f == nil {
+		t.Fatalf("Feed %d was not found", folder.ID)
+	} else if f.Title != folder.Title ||
+		f.URL.String() != folder.URL.String() ||
+		f.Homepage.String() != folder.Homepage.String() ||
+		f.UpdateInterval != folder.UpdateInterval ||
+		f.Folder != folder.Folder ||
+		!f.Active {
+		t.Errorf("Feed %d differs from what was added: %#v", f.ID, f)
+	} else if f, err = other.FeedGetByID(folder.ID + 1000); err != nil {
+		t.Fatalf("Failed to look for a missing Feed: %s", err.Error())
+	} else if f != nil {
+		t.Errorf("Expected no Feed for a missing ID, got %s", f.Title)
+	}
+
+	if err = db.FeedSetActive(beta, false); err != nil {
+		t.Fatalf("Failed to deactivate Feed: %s", err.Error())
+	} else if err = db.FeedSetRetention(beta, 7); err != nil {
+		t.Fatalf("Failed to set retention: %s", err.Error())
+	} else if err = db.FeedSetFetchFull(beta, true); err != nil {
+		t.Fatalf("Failed to set FetchFull: %s", err.Error())
+	} else if err = db.FeedSetDownload(beta, true); err != nil {
+		t.Fatalf("Failed to set Download: %s", err.Error())
+	} else if err = db.FeedSetHTTPSettings(beta, headers, auth); err != nil {
+		t.Fatalf("Failed to set HTTP settings: %s", err.Error())
+	} else if err = db.FeedSetIntervalBound(beta, model.BoundUpper); err != nil {
+		t.Fatalf("Failed to set interval bound: %s", err.Error())
+	} else if err = db.FeedUpdateHTTPState(beta, `"etag"`, "Mon, 02 Jan 2006 15:04:05 GMT", 304); err != nil {
+		t.Fatalf("Failed to update HTTP state: %s", err.Error())
+	} else if err = db.FeedSetMeta(beta, model.FeedMeta{Description: "Beta news", Language: "de"}); err != nil {
+		t.Fatalf("Failed to set meta data: %s", err.Error())
+	} else if f, err = other.FeedGetByID(beta.ID); err != nil {
+		t.Fatalf("Failed to load Feed %d: %s", beta.ID, err.Error())
+	} else if f.Active ||
+		f.Retention != 7 ||
+		!f.FetchFull ||
+		!f.Download ||
+		f.Headers["X-Api-Key"] != headers["X-Api-Key"] ||
+		f.Auth != auth ||
+		f.Bound != model.BoundUpper ||
+		f.ETag != `"etag"` ||
+		f.LastStatus != 304 ||
+		f.Meta.Description != "Beta news" ||
+		f.Meta.Language != "de" {
+		t.Errorf("Feed %d was not updated: %#v", f.ID, f)
+	}
+
+	addItems(t, db, alpha, 1)
+
+	if err = db.FeedDelete(alpha); err == nil {
+		t.Error("Deleting a Feed with Items should fail")
+	} else if err = db.ItemDeleteByFeed(alpha); err != nil {
+		t.Fatalf("Failed to delete Items: %s", err.Error())
+	} else if err = db.FeedDelete(alpha); err != nil {
+		t.Fatalf("Failed to delete Feed: %s", err.Error())
+	} else if f, err = other.FeedGetByID(alpha.ID); err != nil {
+		t.Fatalf("Failed to look for deleted Feed: %s", err.Error())
+	} else if f != nil {
+		t.Errorf("Feed %d should have been deleted", alpha.ID)
+	}
+} // func testFeed(t *testing.T, open database.Opener)
+
+func testFeedChange(t *testing.T, open database.Opener) {
+	var (
+		err     error
+		f       *model.Feed
+		history []model.FeedMove
+		sub     *model.Subscription
+		db      = openStore(t, open)
+		feed    = addFeed(t, db, "change")
+		taken   = addFeed(t, db, "taken")
+		oldURL  = feed.URL
+		newURL  = purl("https://moved.example.com/feed.atom")
+	)
+
+	if err = db.WebSubAdd(&model.Subscription{
+		FeedID:    feed.ID,
+		Hub:       purl("https://hub.example.com/"),
+		Topic:     feed.URL,
+		Requested: time.Now(),
+	}); err != nil {
+		t.Fatalf("Failed to add Subscription: %s", err.Error())
+	} else if err = db.FeedRecordFailure(feed, "Timeout", time.Now().Add(time.Hour), true); err != nil {
+		t.Fatalf("Failed to record failure: %s", err.Error())
+	} else if err = db.FeedUpdate(feed, " ", newURL, feed.Homepage, time.Hour); !errors.Is(err, database.ErrInvalidValue) {
+		t.Errorf("An empty title should be rejected, got %v", err)
+	} else if err = db.FeedUpdate(feed, feed.Title, purl("/relative"), feed.Homepage, time.Hour); !errors.Is(err, database.ErrInvalidValue) {
+		t.Errorf("A relative URL should be rejected, got %v", err)
+	} else if err = db.FeedUpdate(feed, feed.Title, newURL, feed.Homepage, time.Second); !errors.Is(err, database.ErrInvalidValue) {
+		t.Errorf("An interval below one minute should be rejected, got %v", err)
+	} else if err = db.FeedUpdate(feed, feed.Title, taken.URL, feed.Homepage, time.Hour); err == nil {
+		t.Error("Taking another Feed's URL should fail")
+	} else if err = db.FeedUpdate(feed, "changed", feed.URL, feed.Homepage, 2*time.Hour); err != nil {
+		t.Fatalf("Failed to update Feed: %s", err.Error())
+	} else if history, err = db.FeedHistoryGetByFeed(feed); err != nil {
+		t.Fatalf("Failed to load history: %s", err.Error())
+	} else if len(history) != 0 {
+		t.Errorf("Keeping the URL should not be recorded, got %d changes", len(history))
+	} else if err = db.FeedSetURL(feed, newURL, "Moved permanently"); err != nil {
+		t.Fatalf("Failed to set URL: %s", err.Error())
+	} else if f, err = db.FeedGetByID(feed.ID); err != nil {
+		t.Fatalf("Failed to load Feed: %s", err.Error())
+	} else if f.Title != "changed" ||
+		f.URL.String() != newURL.String() ||
+		f.UpdateInterval != 2*time.Hour ||
+		f.Failures != 0 ||
+		f.LastError != "" {
+		t.Errorf("Feed was not changed as expected: %#v", f)
+	} else if history, err = db.FeedHistoryGetByFeed(feed); err != nil {
+		t.Fatalf("Failed to load history: %s", err.Error())
+	} else if len(history) != 1 {
+		t.Fatalf("Expected 1 change in history, got %d", len(history))
+	} else if history[0].OldURL.String() != oldURL.String() ||
+		history[0].NewURL.String() != newURL.String() ||
+		history[0].Reason != "Moved permanently" {
+		t.Errorf("Unexpected change in history: %#v", history[0])
+	} else if sub, err = db.WebSubGetByFeed(feed); err != nil {
+		t.Fatalf("Failed to load Subscription: %s", err.Error())
+	} else if sub != nil {
+		t.Error("Moving the Feed should have removed its Subscription")
+	}
+
+	// Feeds added before we checked homepages may have a relative one,
+	// that must not keep us from recording a move.
+	var legacy = &model.Feed{
+		Title:          "legacy",
+		URL:            purl("https://legacy.example.com/feed.rss"),
+		Homepage:       purl("/blog/"),
+		UpdateInterval: time.Hour,
+		Active:         true,
+	}
+
+	if err = db.FeedAdd(legacy); err != nil {
+		t.Fatalf("Failed to add Feed: %s", err.Error())
+	} else if err = db.FeedSetURL(legacy, purl("https://new.example.com/feed.rss"), "Moved permanently"); err != nil {
+		t.Fatalf("Failed to set URL of Feed with relative Homepage: %s", err.Error())
+	} else if f, err = db.FeedGetByID(legacy.ID); err != nil {
+		t.Fatalf("Failed to load Feed: %s", err.Error())
+	} else if f.Homepage.String() != "https://legacy.example.com/blog/" {
+		t.Errorf("Homepage should have been resolved against the old URL, is %s", f.Homepage)
+	}
+} // func testFeedChange(t *testing.T, open database.Opener)
+
+func testFeedPending(t *testing.T, open database.Opener) {
+	var (
+		err      error
+		pending  []model.Feed
+		db       = openStore(t, open)
+		fresh    = addFeed(t, db, "fresh")
+		recent   = addFeed(t, db, "recent")
+		inactive = addFeed(t, db, "inactive")
+		failed   = addFeed(t, db, "failed")
+		now      = time.Now()
+	)
+
+	if err = db.FeedUpdateRefresh(recent, now); err != nil {
+		t.Fatalf("Failed to update refresh: %s", err.Error())
+	} else if err = db.FeedSetActive(inactive, false); err != nil {
+		t.Fatalf("Failed to deactivate Feed: %s", err.Error())
+	} else if err = db.FeedRecordFailure(failed, "Not found", now.Add(time.Hour), true); err != nil {
+		t.Fatalf("Failed to record failure: %s", err.Error())
+	} else if failed.Failures != 1 {
+		t.Errorf("Expected 1 failure, got %d", failed.Failures)
+	} else if pending, err = db.FeedGetPending(); err != nil {
+		t.Fatalf("Failed to load pending Feeds: %s", err.Error())
+	} else if len(pending) != 1 || pending[0].ID != fresh.ID {
+		t.Errorf("Expected only Feed %d to be pending, got %d Feeds", fresh.ID, len(pending))
+	} else if err = db.FeedResetFailures(failed); err != nil {
+		t.Fatalf("Failed to reset failures: %s", err.Error())
+	} else if err = db.FeedPostpone(fresh, now.Add(time.Hour)); err != nil {
+		t.Fatalf("Failed to postpone Feed: %s", err.Error())
+	} else if pending, err = db.FeedGetPending(); err != nil {
+		t.Fatalf("Failed to load pending Feeds: %s", err.Error())
+	} else if len(pending) != 1 || pending[0].ID != failed.ID {
+		t.Errorf("Expected only Feed %d to be pending, got %d Feeds", failed.ID, len(pending))
+	} else if err = db.FeedSetSchedule(failed, model.Schedule{Next: now.Add(time.Hour)}); err != nil {
+		t.Fatalf("Failed to set schedule: %s", err.Error())
+	} else if pending, err = db.FeedGetPending(); err != nil {
+		t.Fatalf("Failed to load pending Feeds: %s", err.Error())
+	} else if len(pending) != 0 {
+		t.Errorf("Expected no pending Feeds, got %d", len(pending))
+	}
+} // func testFeedPending(t *testing.T, open database.Opener)
+
+func testWebSub(t *testing.T, open database.Opener) {
+	var (
+		err   error
+		list  []*model.Subscription
+		sub   *model.Subscription
+		db    = openStore(t, open)
+		feed  = addFeed(t, db, "websub")
+		hub   = purl("https://hub.example.com/")
+		now   = time.Now().Truncate(time.Second)
+		added = &model.Subscription{
+			FeedID:    feed.ID,
+			Hub:       hub,
+			Topic:     feed.URL,
+			Secret:    "secret",
+			Requested: now.Add(-time.Hour),
+		}
+	)
+
+	if err = db.WebSubAdd(&model.Subscription{FeedID: feed.ID + 1000, Hub: hub, Topic: feed.URL}); err == nil {
+		t.Error("Adding a Subscription for a missing Feed should fail")
+	} else if err = db.WebSubAdd(added); err != nil {
+		t.Fatalf("Failed to add Subscription: %s", err.Error())
+	} else if added.State != model.SubPending {
+		t.Errorf("New Subscription should be pending, is %s", added.State)
+	} else if list, err = db.WebSubGetRenewable(now, now); err != nil {
+		t.Fatalf("Failed to load renewable Subscriptions: %s", err.Error())
+	} else if len(list) != 1 || list[0].ID != added.ID || list[0].FeedID != feed.ID {
+		t.Errorf("Pending Subscription should be renewable, got %d", len(list))
+	} else if err = db.WebSubSetActive(added, now.Add(24*time.Hour)); err != nil {
+		t.Fatalf("Failed to activate Subscription: %s", err.Error())
+	} else if list, err = db.WebSubGetRenewable(now, now); err != nil {
+		t.Fatalf("Failed to load renewable Subscriptions: %s", err.Error())
+	} else if len(list) != 0 {
+		t.Errorf("Active Subscription should not be renewable yet, got %d", len(list))
+	} else if list, err = db.WebSubGetRenewable(now.Add(48*time.Hour), now); err != nil {
+		t.Fatalf("Failed to load renewable Subscriptions: %s", err.Error())
+	} else if len(list) != 1 {
+		t.Errorf("Expiring Subscription should be renewable, got %d", len(list))
+	}
+
+	// Renewing the lease at the same hub keeps the Subscription active.
+	var renewed = *added
+	renewed.Requested = now
+
+	if err = db.WebSubAdd(&renewed); err != nil {
+		t.Fatalf("Failed to renew Subscription: %s", err.Error())
+	} else if renewed.ID != added.ID || renewed.State != model.SubActive {
+		t.Errorf("Renewed Subscription should be %d and active, is %d and %s",
+			added.ID,
+			renewed.ID,
+			renewed.State)
+	} else if err = db.WebSubSetLastPush(added, now); err != nil {
+		t.Fatalf("Failed to set last push: %s", err.Error())
+	} else if sub, err = db.WebSubGetByFeed(feed); err != nil {
+		t.Fatalf("Failed to load Subscription: %s", err.Error())
+	} else if sub == nil {
+		t.Fatal("Subscription was not found")
+	} else if sub.ID != added.ID ||
+		sub.Hub.String() != hub.String() ||
+		sub.Topic.String() != feed.URL.String() ||
+		sub.Secret != added.Secret ||
+		sub.State != model.SubActive ||
+		!sub.Requested.Equal(now) ||
+		!sub.LastPush.Equal(now) {
+		t.Errorf("Subscription differs from what was stored: %#v", sub)
+	}
+
+	// Moving to another hub needs a new confirmation.
+	renewed.Hub = purl("https://other-hub.example.com/")
+
+	if err = db.WebSubAdd(&renewed); err != nil {
+		t.Fatalf("Failed to move Subscription: %s", err.Error())
+	} else if renewed.State != model.SubPending {
+		t.Errorf("Subscription at a new hub should be pending, is %s", renewed.State)
+	} else if err = db.WebSubSetState(&renewed, model.SubDenied); err != nil {
+		t.Fatalf("Failed to set state: %s", err.Error())
+	} else if sub, err = db.WebSubGetByFeed(feed); err != nil {
+		t.Fatalf("Failed to load Subscription: %s", err.Error())
+	} else if sub.State != model.SubDenied {
+		t.Errorf("Subscription should be denied, is %s", sub.State)
+	} else if err = db.WebSubSetState(sub, model.SubUnsubscribing); err != nil {
+		t.Fatalf("Failed to set state: %s", err.Error())
+	} else if sub, err = db.WebSubGetByFeed(feed); err != nil {
+		t.Fatalf("Failed to load Subscription: %s", err.Error())
+	} else if sub.State != model.SubUnsubscribing {
+		t.Errorf("Subscription should be unsubscribing, is %s", sub.State)
+	} else if err = db.WebSubSetState(sub, model.SubUnsubscribing+1); err == nil {
+		t.Error("Setting an invalid state should fail")
+	} else if err = db.WebSubDelete(sub); err != nil {
+		t.Fatalf("Failed to delete Subscription: %s", err.Error())
+	} else if sub, err = db.WebSubGetByFeed(feed); err != nil {
+		t.Fatalf("Failed to look for deleted Subscription: %s", err.Error())
+	} else if sub != nil {
+		t.Error("Subscription should have been deleted")
+	}
+} // func testWebSub(t *testing.T, open database.Opener)
@@ -0,0 +1,348 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/storetest/item.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 00:37:52 krylon>
+
+package storetest
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/model"
+)
+
+func testItem(t *testing.T, open database.Opener) {
+	var (
+		err   error
+		added bool
+		i     *model.Item
+		list  []*model.Item
+		rated []model.Item
+		revs  []model.Revision
+		db    = openStore(t, open)
+		other = openStore(t, open)
+		feed  = addFeed(t, db, "items")
+		items = addItems(t, db, feed, 5)
+		now   = time.Now().Truncate(time.Second)
+		dup   = &model.Item{
+			FeedID:    feed.ID,
+			URL:       items[0].URL,
+			Timestamp: now,
+			Headline:  "Duplicate",
+		}
+	)
+
+	if err = db.ItemAdd(dup); err == nil {
+		t.Error("Adding an Item with a duplicate URL should fail")
+	} else if added, err = db.ItemUpsert(dup); err != nil {
+		t.Fatalf("Failed to upsert Item: %s", err.Error())
+	} else if added {
+		t.Error("Item with a duplicate URL should not have been added")
+	} else if _, err = db.ItemUpsert(&model.Item{FeedID: feed.ID + 1000, URL: purl("https://nowhere.example.com/"), Timestamp: now}); err == nil {
+		t.Error("Adding an Item to a missing Feed should fail")
+	} else if i, err = other.ItemGetByKey(dup); err != nil {
+		t.Fatalf("Failed to look up Item by key: %s", err.Error())
+	} else if i == nil || i.ID != items[0].ID {
+		t.Errorf("Expected Item %d for duplicate key, got %v", items[0].ID, i)
+	} else if list, err = other.ItemGetByFeed(feed, -1, 0, model.ItemFilter{}); err != nil {
+		t.Fatalf("Failed to load Items: %s", err.Error())
+	} else if !slices.Equal(itemIDs(list), []int64{items[4].ID, items[3].ID, items[2].ID, items[1].ID, items[0].ID}) {
+		t.Errorf("Items should be loaded newest first, got %v", itemIDs(list))
+	} else if list, err = other.ItemGetByFeed(feed, 2, 1, model.ItemFilter{}); err != nil {
+		t.Fatalf("Failed to load page of Items: %s", err.Error())
+	} else if !slices.Equal(itemIDs(list), []int64{items[3].ID, items[2].ID}) {
+		t.Errorf("Unexpected page of Items: %v", itemIDs(list))
+	} else if list, err = other.ItemGetRecentPaged(10, 4, model.ItemFilter{}); err != nil {
+		t.Fatalf("Failed to load recent Items: %s", err.Error())
+	} else if !slices.Equal(itemIDs(list), []int64{items[0].ID}) {
+		t.Errorf("Unexpected page of recent Items: %v", itemIDs(list))
+	} else if list, err = other.ItemGetRecent(items[2].Timestamp); err != nil {
+		t.Fatalf("Failed to load recent Items: %s", err.Error())
+	} else if !slices.Equal(itemIDs(list), []int64{items[4].ID, items[3].ID}) {
+		t.Errorf("Expected Items newer than %d, got %v", items[2].ID, itemIDs(list))
+	}
+
+	if err = db.ItemRevisionAdd(items[1], now.Add(-time.Minute)); err != nil {
+		t.Fatalf("Failed to add revision: %s", err.Error())
+	} else if err = db.ItemUpdate(items[1], "Updated", "New description"); err != nil {
+		t.Fatalf("Failed to update Item: %s", err.Error())
+	} else if err = db.ItemContentAdd(items[1], "Full text"); err != nil {
+		t.Fatalf("Failed to add content: %s", err.Error())
+	} else if err = db.ItemContentAdd(items[1], "Replaced full text"); err != nil {
+		t.Fatalf("Failed to replace content: %s", err.Error())
+	} else if i, err = other.ItemGetByID(items[1].ID); err != nil {
+		t.Fatalf("Failed to load Item: %s", err.Error())
+	} else if i == nil {
+		t.Fatalf("Item %d was not found", items[1].ID)
+	} else if i.Headline != "Updated" ||
+		i.Description != "New description" ||
+		i.Content != "Replaced full text" ||
+		i.Revisions != 1 ||
+		!i.Timestamp.Equal(items[1].Timestamp) ||
+		i.URL.String() != items[1].URL.String() {
+		t.Errorf("Item %d differs from what was stored: %#v", i.ID, i)
+	} else if revs, err = other.ItemRevisionGetByItem(items[1]); err != nil {
+		t.Fatalf("Failed to load revisions: %s", err.Error())
+	} else if len(revs) != 1 || revs[0].Headline != "items 01" {
+		t.Errorf("Revision should keep the old headline: %v", revs)
+	} else if i, err = other.ItemGetByID(items[4].ID + 1000); err != nil {
+		t.Fatalf("Failed to look for a missing Item: %s", err.Error())
+	} else if i != nil {
+		t.Errorf("Expected no Item for a missing ID, got %d", i.ID)
+	}
+
+	if err = db.ItemRate(items[2], 2); err == nil {
+		t.Error("A rating out of range should be rejected")
+	} else if err = db.ItemRate(items[2], -1); err != nil {
+		t.Fatalf("Failed to rate Item: %s", err.Error())
+	} else if err = db.ItemRate(items[3], 1); err != nil {
+		t.Fatalf("Failed to rate Item: %s", err.Error())
+	} else if err = db.ItemSetStarred(items[3], true); err != nil {
+		t.Fatalf("Failed to star Item: %s", err.Error())
+	} else if rated, err = other.ItemGetRated(); err != nil {
+		t.Fatalf("Failed to load rated Items: %s", err.Error())
+	} else if len(rated) != 2 || rated[0].ID != items[3].ID || rated[1].ID != items[2].ID {
+		t.Errorf("Expected Items %d and %d to be rated, got %d Items",
+			items[3].ID,
+			items[2].ID,
+			len(rated))
+	} else if list, err = other.ItemGetByFeed(feed, -1, 0, model.ItemFilter{Starred: true}); err != nil {
+		t.Fatalf("Failed to load starred Items: %s", err.Error())
+	} else if !slices.Equal(itemIDs(list), []int64{items[3].ID}) || list[0].Rating != 1 || !list[0].Starred {
+		t.Errorf("Expected Item %d to be starred, got %v", items[3].ID, itemIDs(list))
+	} else if err = db.ItemUnrate(items[2]); err != nil {
+		t.Fatalf("Failed to unrate Item: %s", err.Error())
+	} else if rated, err = other.ItemGetRated(); err != nil {
+		t.Fatalf("Failed to load rated Items: %s", err.Error())
+	} else if len(rated) != 1 {
+		t.Errorf("Expected 1 rated Item, got %d", len(rated))
+	}
+} // func testItem(t *testing.T, open database.Opener)
+
+func testItemReadState(t *testing.T, open database.Opener) {
+	var (
+		err    error
+		cnt    int64
+		unread map[int64]int64
+		list   []*model.Item
+		i      *model.Item
+		db     = openStore(t, open)
+		feed   = addFeed(t, db, "read")
+		second = addFeed(t, db, "other")
+		items  = addItems(t, db, feed, 5)
+		others = addItems(t, db, second, 2)
+	)
+
+	if err = db.ItemMarkRead(items[0]); err != nil {
+		t.Fatalf("Failed to mark Item as read: %s", err.Error())
+	} else if !items[0].IsRead() {
+		t.Errorf("Item %d is not marked as read", items[0].ID)
+	} else if i, err = db.ItemGetByID(items[0].ID); err != nil {
+		t.Fatalf("Failed to load Item: %s", err.Error())
+	} else if !i.IsRead() {
+		t.Errorf("Item %d was not stored as read", i.ID)
+	} else if unread, err = db.FeedGetUnreadCnt(); err != nil {
+		t.Fatalf("Failed to count unread Items: %s", err.Error())
+	} else if unread[feed.ID] != 4 || unread[second.ID] != 2 {
+		t.Errorf("Expected 4 and 2 unread Items, got %d and %d",
+			unread[feed.ID],
+			unread[second.ID])
+	} else if cnt, err = db.ItemMarkReadAbove(items[2], feed); err != nil {
+		t.Fatalf("Failed to mark Items as read: %s", err.Error())
+	} else if cnt != 3 {
+		t.Errorf("Expected 3 Items to be marked as read, got %d", cnt)
+	} else if list, err = db.ItemGetByFeed(feed, -1, 0, model.ItemFilter{Unread: true}); err != nil {
+		t.Fatalf("Failed to load unread Items: %s", err.Error())
+	} else if !slices.Equal(itemIDs(list), []int64{items[1].ID}) {
+		t.Errorf("Expected only Item %d to be unread, got %v", items[1].ID, itemIDs(list))
+	} else if cnt, err = db.ItemMarkReadList([]int64{items[0].ID, items[1].ID, others[0].ID}); err != nil {
+		t.Fatalf("Failed to mark list of Items as read: %s", err.Error())
+	} else if cnt != 2 {
+		t.Errorf("Expected 2 Items to be marked as read, got %d", cnt)
+	} else if err = db.ItemMarkUnread(items[4]); err != nil {
+		t.Fatalf("Failed to mark Item as unread: %s", err.Error())
+	} else if items[4].IsRead() {
+		t.Errorf("Item %d is still marked as read", items[4].ID)
+	} else if list, err = db.ItemGetRecentPaged(-1, 0, model.ItemFilter{Unread: true}); err != nil {
+		t.Fatalf("Failed to load unread Items: %s", err.Error())
+	} else if len(list) != 2 ||
+		// Both Items are one minute old, their order is unspecified.
+		!slices.Contains(itemIDs(list), others[1].ID) ||
+		!slices.Contains(itemIDs(list), items[4].ID) {
+		t.Errorf("Expected Items %d and %d to be unread, got %v",
+			others[1].ID,
+			items[4].ID,
+			itemIDs(list))
+	} else if cnt, err = db.ItemMarkReadAbove(items[0], nil); err != nil {
+		t.Fatalf("Failed to mark all Items as read: %s", err.Error())
+	} else if cnt != 2 {
+		t.Errorf("Expected 2 Items to be marked as read, got %d", cnt)
+	}
+} // func testItemReadState(t *testing.T, open database.Opener)
+
+func testItemPurge(t *testing.T, open database.Opener) {
+	var (
+		err    error
+		purged bool
+		list   []*model.Item
+		db     = openStore(t, open)
+		feed   = addFeed(t, db, "purge")
+		short  = addFeed(t, db, "short")
+		items  = addItems(t, db, feed, 6)
+		kept   = addItems(t, db, short, 1)
+		tag    = addTag(t, db, "Keep", nil)
+		future = time.Now().Add(72 * time.Hour)
+	)
+
+	if err = db.FeedSetRetention(short, -1); err != nil {
+		t.Fatalf("Failed to disable retention: %s", err.Error())
+	} else if err = db.ItemRate(items[0], 1); err != nil {
+		t.Fatalf("Failed to rate Item: %s", err.Error())
+	} else if err = db.ItemSetStarred(items[1], true); err != nil {
+		t.Fatalf("Failed to star Item: %s", err.Error())
+	} else if err = db.TagLinkAdd(items[2], tag); err != nil {
+		t.Fatalf("Failed to tag Item: %s", err.Error())
+	} else if err = db.TagLinkAddAuto(items[3], tag); err != nil {
+		t.Fatalf("Failed to tag Item: %s", err.Error())
+	} else if list, err = db.ItemGetPurgeable(30, future); err != nil {
+		t.Fatalf("Failed to load purgeable Items: %s", err.Error())
+	} else if len(list) != 0 {
+		t.Errorf("Items younger than the retention period should be kept, got %d", len(list))
+	} else if list, err = db.ItemGetPurgeable(2, future); err != nil {
+		t.Fatalf("Failed to load purgeable Items: %s", err.Error())
+	} else if !slices.Equal(itemIDs(list), []int64{items[3].ID, items[4].ID, items[5].ID}) {
+		// Tags added by a CategoryMapping do not show any interest
+		// of the user.
+		t.Errorf("Expected Items %d, %d and %d to be purgeable, got %v",
+			items[3].ID,
+			items[4].ID,
+			items[5].ID,
+			itemIDs(list))
+	} else if list, err = db.ItemGetPurgeable(0, future); err != nil {
+		t.Fatalf("Failed to load purgeable Items: %s", err.Error())
+	} else if len(list) != 0 {
+		t.Errorf("Without a retention period, nothing should be purgeable, got %d", len(list))
+	} else if err = db.ItemSetStarred(items[5], true); err != nil {
+		t.Fatalf("Failed to star Item: %s", err.Error())
+	} else if purged, err = db.ItemPurge(items[5]); err != nil {
+		t.Fatalf("Failed to purge Item: %s", err.Error())
+	} else if purged {
+		t.Errorf("Starred Item %d should not have been purged", items[5].ID)
+	} else if purged, err = db.ItemPurge(items[2]); err != nil {
+		t.Fatalf("Failed to purge Item: %s", err.Error())
+	} else if purged {
+		t.Errorf("Tagged Item %d should not have been purged", items[2].ID)
+	} else if purged, err = db.ItemPurge(items[3]); err != nil {
+		t.Fatalf("Failed to purge Item: %s", err.Error())
+	} else if !purged {
+		t.Errorf("Automatically tagged Item %d should have been purged", items[3].ID)
+	} else if purged, err = db.ItemPurge(items[4]); err != nil {
+		t.Fatalf("Failed to purge Item: %s", err.Error())
+	} else if !purged {
+		t.Errorf("Item %d should have been purged", items[4].ID)
+	} else if list, err = db.ItemGetByFeed(feed, -1, 0, model.ItemFilter{}); err != nil {
+		t.Fatalf("Failed to load Items: %s", err.Error())
+	} else if len(list) != 4 {
+		t.Errorf("Expected 4 Items after purge, got %d", len(list))
+	} else if list, err = db.ItemGetByFeed(short, -1, 0, model.ItemFilter{}); err != nil {
+		t.Fatalf("Failed to load Items: %s", err.Error())
+	} else if !slices.Equal(itemIDs(list), itemIDs(kept)) {
+		t.Errorf("Item of Feed %s should have been kept", short.Title)
+	}
+} // func testItemPurge(t *testing.T, open database.Opener)
+
+func testEnclosure(t *testing.T, open database.Opener) {
+	var (
+		err   error
+		size  int64
+		e     *model.Enclosure
+		list  []*model.Enclosure
+		db    = openStore(t, open)
+		feed  = addFeed(t, db, "podcast")
+		items = addItems(t, db, feed, 3)
+		now   = time.Now().Truncate(time.Second)
+		encs  = make([]*model.Enclosure, len(items))
+	)
+
+	for idx, i := range items {
+		encs[idx] = &model.Enclosure{
+			ItemID:   i.ID,
+			URL:      purl(i.URL.String() + ".mp3"),
+			MimeType: "audio/mpeg",
+			Length:   int64(idx+1) * 1024,
+		}
+
+		if err = db.EnclosureAdd(encs[idx]); err != nil {
+			t.Fatalf("Failed to add Enclosure %s: %s", encs[idx].URL, err.Error())
+		}
+	}
+
+	if err = db.EnclosureAdd(&model.Enclosure{ItemID: items[0].ID, URL: encs[0].URL}); err == nil {
+		t.Error("Adding a duplicate Enclosure should fail")
+	} else if err = db.EnclosureAdd(&model.Enclosure{ItemID: items[2].ID + 1000, URL: encs[0].URL}); err == nil {
+		t.Error("Adding an Enclosure to a missing Item should fail")
+	} else if list, err = db.EnclosureGetPending(3, 10); err != nil {
+		t.Fatalf("Failed to load pending Enclosures: %s", err.Error())
+	} else if len(list) != 0 {
+		t.Errorf("Without downloads enabled, nothing should be pending, got %d", len(list))
+	} else if err = db.FeedSetDownload(feed, true); err != nil {
+		t.Fatalf("Failed to enable downloads: %s", err.Error())
+	} else if list, err = db.EnclosureGetPending(3, 2); err != nil {
+		t.Fatalf("Failed to load pending Enclosures: %s", err.Error())
+	} else if len(list) != 2 || list[0].ID != encs[2].ID || list[1].ID != encs[1].ID {
+		t.Errorf("Expected the Enclosures of the newest Items to be pending, got %d", len(list))
+	} else if list, err = db.EnclosureGetByItem(items[1]); err != nil {
+		t.Fatalf("Failed to load Enclosures of Item: %s", err.Error())
+	} else if len(list) != 1 ||
+		list[0].URL.String() != encs[1].URL.String() ||
+		list[0].MimeType != encs[1].MimeType ||
+		list[0].Length != encs[1].Length {
+		t.Errorf("Unexpected Enclosures of Item %d: %v", items[1].ID, list)
+	}
+
+	if err = db.EnclosureSetDownloaded(encs[2], "/tmp/enc2.mp3", 3000, now); err != nil {
+		t.Fatalf("Failed to mark Enclosure as downloaded: %s", err.Error())
+	} else if err = db.EnclosureSetDownloaded(encs[0], "/tmp/enc0.mp3", 1000, now.Add(-time.Minute)); err != nil {
+		t.Fatalf("Failed to mark Enclosure as downloaded: %s", err.Error())
+	} else if err = db.EnclosureRecordFailure(encs[1]); err != nil {
+		t.Fatalf("Failed to record failure: %s", err.Error())
+	} else if list, err = db.EnclosureGetPending(1, 10); err != nil {
+		t.Fatalf("Failed to load pending Enclosures: %s", err.Error())
+	} else if len(list) != 0 {
+		t.Errorf("Enclosure that failed too often should not be pending, got %d", len(list))
+	} else if list, err = db.EnclosureGetPending(2, 10); err != nil {
+		t.Fatalf("Failed to load pending Enclosures: %s", err.Error())
+	} else if len(list) != 1 || list[0].ID != encs[1].ID || list[0].Attempts != 1 {
+		t.Errorf("Expected Enclosure %d with 1 attempt to be pending, got %v", encs[1].ID, list)
+	} else if size, err = db.EnclosureGetTotalSize(); err != nil {
+		t.Fatalf("Failed to get total size: %s", err.Error())
+	} else if size != 4000 {
+		t.Errorf("Expected 4000 bytes in total, got %d", size)
+	} else if list, err = db.EnclosureGetDownloaded(); err != nil {
+		t.Fatalf("Failed to load downloaded Enclosures: %s", err.Error())
+	} else if len(list) != 2 || list[0].ID != encs[0].ID || list[1].ID != encs[2].ID {
+		t.Errorf("Expected downloaded Enclosures, oldest first, got %d", len(list))
+	} else if err = db.EnclosureExpire(encs[0]); err != nil {
+		t.Fatalf("Failed to expire Enclosure: %s", err.Error())
+	} else if e, err = db.EnclosureGetByID(encs[0].ID); err != nil {
+		t.Fatalf("Failed to load Enclosure: %s", err.Error())
+	} else if e == nil || !e.Expired || e.Path != "" || e.Size != 0 {
+		t.Errorf("Enclosure %d was not expired: %v", encs[0].ID, e)
+	} else if size, err = db.EnclosureGetTotalSize(); err != nil {
+		t.Fatalf("Failed to get total size: %s", err.Error())
+	} else if size != 3000 {
+		t.Errorf("Expected 3000 bytes in total, got %d", size)
+	} else if e, err = db.EnclosureGetByID(encs[2].ID); err != nil {
+		t.Fatalf("Failed to load Enclosure: %s", err.Error())
+	} else if e.Path != "/tmp/enc2.mp3" || !e.Downloaded.Equal(now) {
+		t.Errorf("Enclosure %d differs from what was stored: %v", encs[2].ID, e)
+	} else if e, err = db.EnclosureGetByID(encs[2].ID + 1000); err != nil {
+		t.Fatalf("Failed to look for a missing Enclosure: %s", err.Error())
+	} else if e != nil {
+		t.Errorf("Expected no Enclosure for a missing ID, got %d", e.ID)
+	}
+} // func testEnclosure(t *testing.T, open database.Opener)
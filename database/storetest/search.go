@@ -0,0 +1,122 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/storetest/search.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 01:03:44 krylon>
+
+package storetest
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/model"
+)
+
+func testSearch(t *testing.T, open database.Opener) {
+	var (
+		err     error
+		s       *model.Search
+		list    []*model.Search
+		db      = openStore(t, open)
+		other   = openStore(t, open)
+		feed    = addFeed(t, db, "search")
+		items   = addItems(t, db, feed, 4)
+		parent  = addTag(t, db, "News", nil)
+		child   = addTag(t, db, "Politics", parent)
+		created = time.Now().Truncate(time.Second)
+		byTags  = &model.Search{
+			Title:       "Both Tags",
+			TimeCreated: created.Add(-time.Minute),
+			Tags:        []int64{parent.ID, child.ID},
+			TagsAll:     true,
+		}
+		byText = &model.Search{
+			Title:       "Regex",
+			TimeCreated: created,
+			QueryString: "Item 0[13]",
+			Regex:       true,
+		}
+	)
+
+	for _, i := range items[:3] {
+		if err = db.TagLinkAddAuto(i, parent); err != nil {
+			t.Fatalf("Failed to link Item %d: %s", i.ID, err.Error())
+		}
+	}
+
+	for _, i := range items[1:] {
+		if err = db.TagLinkAdd(i, child); err != nil {
+			t.Fatalf("Failed to link Item %d: %s", i.ID, err.Error())
+		}
+	}
+
+	if err = db.SearchAdd(byText); err != nil {
+		t.Fatalf("Failed to add Search: %s", err.Error())
+	} else if err = db.SearchAdd(byTags); err != nil {
+		t.Fatalf("Failed to add Search: %s", err.Error())
+	} else if s, err = other.SearchGetNextPending(); err != nil {
+		t.Fatalf("Failed to load pending Search: %s", err.Error())
+	} else if s == nil || s.ID != byTags.ID {
+		t.Fatalf("Expected the oldest Search %d to be pending, got %v", byTags.ID, s)
+	} else if err = db.SearchStart(byTags); err != nil {
+		t.Fatalf("Failed to start Search: %s", err.Error())
+	} else if list, err = other.SearchGetActive(); err != nil {
+		t.Fatalf("Failed to load active Searches: %s", err.Error())
+	} else if len(list) != 1 || list[0].ID != byTags.ID {
+		t.Errorf("Expected Search %d to be active, got %d Searches", byTags.ID, len(list))
+	} else if s, err = other.SearchGetNextPending(); err != nil {
+		t.Fatalf("Failed to load pending Search: %s", err.Error())
+	} else if s == nil || s.ID != byText.ID {
+		t.Fatalf("Expected Search %d to be pending, got %v", byText.ID, s)
+	} else if err = db.SearchExecute(byTags); err != nil {
+		t.Fatalf("Failed to execute Search: %s", err.Error())
+	} else if !byTags.Status || byTags.TimeFinished.IsZero() {
+		t.Errorf("Search %d should have finished successfully", byTags.ID)
+	} else if err = db.SearchStart(byText); err != nil {
+		t.Fatalf("Failed to start Search: %s", err.Error())
+	} else if err = db.SearchExecute(byText); err != nil {
+		t.Fatalf("Failed to execute Search: %s", err.Error())
+	} else if list, err = other.SearchGetActive(); err != nil {
+		t.Fatalf("Failed to load active Searches: %s", err.Error())
+	} else if len(list) != 0 {
+		t.Errorf("Expected no active Searches, got %d", len(list))
+	} else if s, err = other.SearchGetNextPending(); err != nil {
+		t.Fatalf("Failed to load pending Search: %s", err.Error())
+	} else if s != nil {
+		t.Errorf("Expected no pending Search, got %d", s.ID)
+	}
+
+	if s, err = other.SearchGetByID(byTags.ID); err != nil {
+		t.Fatalf("Failed to load Search: %s", err.Error())
+	} else if s == nil {
+		t.Fatalf("Search %d was not found", byTags.ID)
+	} else if !s.Status || s.TimeFinished.IsZero() || !s.TagsAll || !slices.Equal(s.Tags, byTags.Tags) {
+		t.Errorf("Search %d differs from what was stored: %#v", s.ID, s)
+	} else if !slices.Equal(itemIDs(s.Results), []int64{items[2].ID, items[1].ID}) {
+		t.Errorf("Expected Items %d and %d as results, got %v",
+			items[2].ID,
+			items[1].ID,
+			itemIDs(s.Results))
+	} else if list, err = other.SearchGetAll(); err != nil {
+		t.Fatalf("Failed to load Searches: %s", err.Error())
+	} else if len(list) != 2 || list[0].ID != byTags.ID || list[1].ID != byText.ID {
+		t.Fatalf("Expected Searches %d and %d, oldest first, got %d",
+			byTags.ID,
+			byText.ID,
+			len(list))
+	} else if !slices.Equal(itemIDs(list[1].Results), []int64{items[3].ID, items[1].ID}) {
+		t.Errorf("Expected Items %d and %d as results, got %v",
+			items[3].ID,
+			items[1].ID,
+			itemIDs(list[1].Results))
+	} else if err = db.SearchDelete(byText); err != nil {
+		t.Fatalf("Failed to delete Search: %s", err.Error())
+	} else if s, err = other.SearchGetByID(byText.ID); err != nil {
+		t.Fatalf("Failed to look for deleted Search: %s", err.Error())
+	} else if s != nil {
+		t.Errorf("Search %d should have been deleted", byText.ID)
+	}
+} // func testSearch(t *testing.T, open database.Opener)
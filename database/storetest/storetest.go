@@ -0,0 +1,156 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/storetest/storetest.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 23:58:21 krylon>
+
+// Package storetest provides the test cases every implementation of
+// database.Store has to pass. Running the same cases against the SQLite
+// backend and the in-memory one makes sure the two behave the same as far as
+// the rest of the application can tell.
+//
+// The cases only check that a constraint violation causes an error, not
+// which one, since every backend has errors of its own. Errors the database
+// package defines, like ErrTxInProgress, have to be the same, though.
+package storetest
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/model"
+)
+
+// Factory returns an Opener for a new, empty backend. Every test case gets
+// a backend of its own.
+type Factory func(t *testing.T) database.Opener
+
+var cases = []struct {
+	name string
+	fn   func(t *testing.T, open database.Opener)
+}{
+	{"Transaction", testTransaction},
+	{"Feed", testFeed},
+	{"FeedChange", testFeedChange},
+	{"FeedPending", testFeedPending},
+	{"WebSub", testWebSub},
+	{"Item", testItem},
+	{"ItemReadState", testItemReadState},
+	{"ItemPurge", testItemPurge},
+	{"Enclosure", testEnclosure},
+	{"Tag", testTag},
+	{"TagLink", testTagLink},
+	{"CategoryMap", testCategoryMap},
+	{"Search", testSearch},
+}
+
+// Run runs all test cases as subtests of t, each against a Store opened from
+// a fresh backend the Factory provides.
+func Run(t *testing.T, fresh Factory) {
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.fn(t, fresh(t))
+		})
+	}
+} // func Run(t *testing.T, fresh Factory)
+
+// Helpers
+
+func purl(s string) *url.URL {
+	var (
+		err error
+		u   *url.URL
+	)
+
+	if u, err = url.Parse(s); err != nil {
+		panic(err)
+	}
+
+	return u
+} // func purl(s string) *url.URL
+
+// openStore opens a Store that is closed when the test is finished.
+func openStore(t *testing.T, open database.Opener) database.Store {
+	var (
+		err error
+		db  database.Store
+	)
+
+	if db, err = open(); err != nil {
+		t.Fatalf("Cannot open Store: %s", err.Error())
+	}
+
+	t.Cleanup(func() { db.Close() }) // nolint: errcheck
+	return db
+} // func openStore(t *testing.T, open database.Opener) database.Store
+
+func addFeed(t *testing.T, db database.Store, name string) *model.Feed {
+	var f = &model.Feed{
+		Title:          name,
+		URL:            purl(fmt.Sprintf("https://%s.example.com/feed.rss", name)),
+		Homepage:       purl(fmt.Sprintf("https://%s.example.com/", name)),
+		UpdateInterval: time.Hour,
+		Active:         true,
+	}
+
+	if err := db.FeedAdd(f); err != nil {
+		t.Fatalf("Failed to add Feed %s: %s", name, err.Error())
+	}
+
+	return f
+} // func addFeed(t *testing.T, db database.Store, name string) *model.Feed
+
+// addItems adds cnt Items to the given Feed, one minute apart. items[0] is
+// the oldest, items[cnt-1] the most recent Item, it is one minute old.
+func addItems(t *testing.T, db database.Store, f *model.Feed, cnt int) []*model.Item {
+	var (
+		items = make([]*model.Item, cnt)
+		now   = time.Now().Truncate(time.Second)
+	)
+
+	for idx := range items {
+		items[idx] = &model.Item{
+			FeedID:      f.ID,
+			URL:         purl(fmt.Sprintf("%sitem%02d.html", f.Homepage, idx)),
+			Timestamp:   now.Add(time.Duration(idx-cnt) * time.Minute),
+			Headline:    fmt.Sprintf("%s %02d", f.Title, idx),
+			Description: fmt.Sprintf("Description of Item %02d", idx),
+		}
+
+		if err := db.ItemAdd(items[idx]); err != nil {
+			t.Fatalf("Failed to add Item %s: %s", items[idx].Headline, err.Error())
+		}
+	}
+
+	return items
+} // func addItems(t *testing.T, db database.Store, f *model.Feed, cnt int) []*model.Item
+
+func addTag(t *testing.T, db database.Store, name string, parent *model.Tag) *model.Tag {
+	var tag = &model.Tag{Name: name}
+
+	if parent != nil {
+		tag.Parent = parent.ID
+	}
+
+	if err := db.TagAdd(tag); err != nil {
+		t.Fatalf("Failed to add Tag %s: %s", name, err.Error())
+	}
+
+	return tag
+} // func addTag(t *testing.T, db database.Store, name string, parent *model.Tag) *model.Tag
+
+// itemIDs returns the IDs of the given Items, in the same order.
+func itemIDs(items []*model.Item) []int64 {
+	var ids = make([]int64, len(items))
+
+	for idx, i := range items {
+		if i != nil {
+			ids[idx] = i.ID
+		}
+	}
+
+	return ids
+} // func itemIDs(items []*model.Item) []int64
@@ -0,0 +1,199 @@
+// /home/krylon/go/src/github.com/blicero/badnews/database/storetest/tag.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 00:52:19 krylon>
+
+package storetest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/model"
+)
+
+func testTag(t *testing.T, open database.Opener) {
+	var (
+		err    error
+		tag    *model.Tag
+		tags   []*model.Tag
+		db     = openStore(t, open)
+		other  = openStore(t, open)
+		news   = addTag(t, db, "News", nil)
+		tech   = addTag(t, db, "Tech", nil)
+		pol    = addTag(t, db, "Politics", news)
+		europe = addTag(t, db, "Europe", pol)
+	)
+
+	if err = db.TagAdd(&model.Tag{Name: pol.Name, Parent: news.ID}); err == nil {
+		t.Error("Adding a duplicate Tag should fail")
+	} else if err = db.TagAdd(&model.Tag{Name: "Orphan", Parent: europe.ID + 1000}); err == nil {
+		t.Error("Adding a Tag with a missing parent should fail")
+	} else if tag, err = other.TagGetByID(pol.ID); err != nil {
+		t.Fatalf("Failed to load Tag: %s", err.Error())
+	} else if tag == nil || tag.Name != pol.Name || tag.Parent != news.ID {
+		t.Errorf("Tag %d differs from what was added: %v", pol.ID, tag)
+	} else if tag, err = other.TagGetByID(europe.ID + 1000); err != nil {
+		t.Fatalf("Failed to look for a missing Tag: %s", err.Error())
+	} else if tag != nil {
+		t.Errorf("Expected no Tag for a missing ID, got %s", tag.Name)
+	} else if tags, err = other.TagGetAll(); err != nil {
+		t.Fatalf("Failed to load Tags: %s", err.Error())
+	} else if len(tags) != 4 || tags[0].Parent != 0 || tags[1].Parent != 0 {
+		t.Errorf("Expected 4 Tags, top-level Tags first, got %v", tags)
+	} else if tags, err = other.TagGetSorted(); err != nil {
+		t.Fatalf("Failed to load sorted Tags: %s", err.Error())
+	} else if len(tags) != 4 ||
+		tags[0].ID != news.ID ||
+		tags[1].ID != pol.ID ||
+		tags[2].ID != europe.ID ||
+		tags[3].ID != tech.ID {
+		t.Errorf("Every Tag should be followed by its children: %v", tags)
+	} else if tags[2].FullName != "News/Politics/Europe" || tags[2].Level != 2 {
+		t.Errorf("Unexpected FullName %q or Level %d of Tag %s",
+			tags[2].FullName,
+			tags[2].Level,
+			europe.Name)
+	} else if err = db.TagUpdate(europe, "EU", tech.ID); err != nil {
+		t.Fatalf("Failed to update Tag: %s", err.Error())
+	} else if err = db.TagUpdate(tech, "EU", tech.ID); err == nil {
+		t.Error("A Tag should not be its own parent")
+	} else if tags, err = other.TagGetSorted(); err != nil {
+		t.Fatalf("Failed to load sorted Tags: %s", err.Error())
+	} else if tags[3].ID != europe.ID || tags[3].FullName != "Tech/EU" {
+		t.Errorf("Tag %d was not moved: %v", europe.ID, tags)
+	}
+} // func testTag(t *testing.T, open database.Opener)
+
+func testTagLink(t *testing.T, open database.Opener) {
+	var (
+		err    error
+		linked []*model.Tag
+		manual []*model.Item
+		cnt    map[int64]int64
+		db     = openStore(t, open)
+		feed   = addFeed(t, db, "tagged")
+		second = addFeed(t, db, "untouched")
+		items  = addItems(t, db, feed, 3)
+		others = addItems(t, db, second, 1)
+		auto   = addTag(t, db, "Auto", nil)
+		hand   = addTag(t, db, "Manual", nil)
+		unused = addTag(t, db, "Unused", nil)
+	)
+
+	for _, i := range append(items, others...) {
+		if err = db.TagLinkAddAuto(i, auto); err != nil {
+			t.Fatalf("Failed to link Item %d: %s", i.ID, err.Error())
+		}
+	}
+
+	if err = db.TagLinkAdd(items[0], hand); err != nil {
+		t.Fatalf("Failed to link Item %d: %s", items[0].ID, err.Error())
+	} else if err = db.TagLinkAdd(items[1], auto); err != nil {
+		t.Fatalf("Failed to link Item %d: %s", items[1].ID, err.Error())
+	} else if err = db.TagLinkAddAuto(items[0], hand); err != nil {
+		t.Fatalf("Failed to link Item %d: %s", items[0].ID, err.Error())
+	} else if err = db.TagLinkAdd(items[0], &model.Tag{ID: unused.ID + 1000}); err == nil {
+		t.Error("Linking a missing Tag should fail")
+	} else if linked, err = db.TagLinkGetByItem(items[0]); err != nil {
+		t.Fatalf("Failed to load Tags of Item: %s", err.Error())
+	} else if len(linked) != 2 ||
+		linked[0].ID != auto.ID || !linked[0].Auto ||
+		linked[1].ID != hand.ID || linked[1].Auto {
+		t.Errorf("An automatic link must not replace a manual one: %v", linked)
+	} else if manual, err = db.TagLinkGetByTagManual(auto); err != nil {
+		t.Fatalf("Failed to load manually tagged Items: %s", err.Error())
+	} else if !slices.Equal(itemIDs(manual), []int64{items[1].ID}) {
+		t.Errorf("A manual link should replace an automatic one, got %v", itemIDs(manual))
+	} else if err = db.ItemMarkRead(items[2]); err != nil {
+		t.Fatalf("Failed to mark Item as read: %s", err.Error())
+	} else if cnt, err = db.TagGetItemCnt(); err != nil {
+		t.Fatalf("Failed to count Items: %s", err.Error())
+	} else if cnt[auto.ID] != 4 || cnt[hand.ID] != 1 || cnt[unused.ID] != 0 {
+		t.Errorf("Unexpected Item counts: %v", cnt)
+	} else if cnt, err = db.TagGetUnreadCnt(); err != nil {
+		t.Fatalf("Failed to count unread Items: %s", err.Error())
+	} else if cnt[auto.ID] != 3 || cnt[hand.ID] != 1 {
+		t.Errorf("Unexpected unread counts: %v", cnt)
+	} else if err = db.TagLinkDelete(items[0], hand); err != nil {
+		t.Fatalf("Failed to unlink Item: %s", err.Error())
+	} else if err = db.TagLinkDeleteByFeed(feed); err != nil {
+		t.Fatalf("Failed to unlink Items of Feed: %s", err.Error())
+	} else if cnt, err = db.TagGetItemCnt(); err != nil {
+		t.Fatalf("Failed to count Items: %s", err.Error())
+	} else if cnt[auto.ID] != 1 || cnt[hand.ID] != 0 {
+		t.Errorf("Only the Item of Feed %s should be tagged, got %v", second.Title, cnt)
+	}
+} // func testTagLink(t *testing.T, open database.Opener)
+
+func testCategoryMap(t *testing.T, open database.Opener) {
+	var (
+		err     error
+		cats    []string
+		maps    []model.CategoryMapping
+		linked  []*model.Tag
+		db      = openStore(t, open)
+		feed    = addFeed(t, db, "categories")
+		tag     = addTag(t, db, "Sports", nil)
+		items   = make([]*model.Item, 3)
+		now     = time.Now().Truncate(time.Second)
+		mapping = &model.CategoryMapping{FeedID: feed.ID, Category: " football ", TagID: tag.ID}
+	)
+
+	for idx, cats := range [][]string{{"Football", "Europe"}, {"politics"}, {"Basketball", "europe"}} {
+		items[idx] = &model.Item{
+			FeedID:     feed.ID,
+			URL:        purl(fmt.Sprintf("%scategory%02d.html", feed.Homepage, idx)),
+			Timestamp:  now.Add(time.Duration(idx) * time.Minute),
+			Headline:   fmt.Sprintf("Category %02d", idx),
+			Categories: cats,
+		}
+
+		if err = db.ItemAdd(items[idx]); err != nil {
+			t.Fatalf("Failed to add Item %s: %s", items[idx].Headline, err.Error())
+		}
+	}
+
+	if err = db.CategoryMapAdd(&model.CategoryMapping{FeedID: feed.ID, Category: " ", TagID: tag.ID}); !errors.Is(err, database.ErrInvalidValue) {
+		t.Errorf("An empty category should be rejected, got %v", err)
+	} else if err = db.CategoryMapAdd(mapping); err != nil {
+		t.Fatalf("Failed to add CategoryMapping: %s", err.Error())
+	} else if mapping.Category != "football" {
+		t.Errorf("Category should have been trimmed, is %q", mapping.Category)
+	} else if err = db.CategoryMapAdd(&model.CategoryMapping{FeedID: feed.ID, Category: "football", TagID: tag.ID}); err == nil {
+		t.Error("Adding a duplicate CategoryMapping should fail")
+	} else if err = db.CategoryMapAdd(&model.CategoryMapping{FeedID: feed.ID, Category: "basketball", TagID: tag.ID}); err != nil {
+		t.Fatalf("Failed to add CategoryMapping: %s", err.Error())
+	} else if linked, err = db.TagLinkGetByItem(items[0]); err != nil {
+		t.Fatalf("Failed to load Tags of Item: %s", err.Error())
+	} else if len(linked) != 1 || linked[0].ID != tag.ID || !linked[0].Auto {
+		t.Errorf("Existing Item should have been tagged automatically: %v", linked)
+	} else if linked, err = db.TagLinkGetByItem(items[1]); err != nil {
+		t.Fatalf("Failed to load Tags of Item: %s", err.Error())
+	} else if len(linked) != 0 {
+		t.Errorf("Item without the category should not be tagged: %v", linked)
+	} else if maps, err = db.CategoryMapGetByFeed(feed); err != nil {
+		t.Fatalf("Failed to load CategoryMappings: %s", err.Error())
+	} else if len(maps) != 2 || maps[0].Category != "basketball" || maps[1].Category != "football" {
+		t.Errorf("Unexpected CategoryMappings: %v", maps)
+	} else if cats, err = db.CategoryGetByFeed(feed); err != nil {
+		t.Fatalf("Failed to load categories: %s", err.Error())
+	} else if !slices.Equal(cats, []string{"Basketball", "Europe", "europe", "Football", "politics"}) {
+		t.Errorf("Unexpected categories: %v", cats)
+	} else if err = db.CategoryMapDelete(&maps[0]); err != nil {
+		t.Fatalf("Failed to delete CategoryMapping: %s", err.Error())
+	} else if linked, err = db.TagLinkGetByItem(items[2]); err != nil {
+		t.Fatalf("Failed to load Tags of Item: %s", err.Error())
+	} else if len(linked) != 1 {
+		t.Errorf("Deleting a CategoryMapping should keep its Tags: %v", linked)
+	} else if maps, err = db.CategoryMapGetByFeed(feed); err != nil {
+		t.Fatalf("Failed to load CategoryMappings: %s", err.Error())
+	} else if len(maps) != 1 {
+		t.Errorf("Expected 1 CategoryMapping, got %d", len(maps))
+	}
+} // func testCategoryMap(t *testing.T, open database.Opener)
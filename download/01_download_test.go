@@ -0,0 +1,155 @@
+// /home/krylon/go/src/github.com/blicero/badnews/download/01_download_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 17:02:14 krylon>
+
+package download
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/database/memory"
+	"github.com/blicero/badnews/model"
+)
+
+const testSize = 64 * 1024
+
+var (
+	mgr     *Manager
+	payload = bytes.Repeat([]byte("0123456789abcdef"), testSize/16)
+)
+
+func purl(s string) *url.URL {
+	var u, _ = url.Parse(s)
+	return u
+} // func purl(s string) *url.URL
+
+func TestManagerCreate(t *testing.T) {
+	var err error
+
+	if mgr, err = Create(testSize*3/2, DefaultRetention, memory.New().Open); err != nil {
+		mgr = nil
+		t.Fatalf("Failed to create Manager: %s", err.Error())
+	}
+} // func TestManagerCreate(t *testing.T)
+
+func TestManagerDownload(t *testing.T) {
+	if mgr == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err      error
+		db       database.Store
+		data     []byte
+		ranged   atomic.Int64
+		stamp    = time.Now()
+		encl     = make([]*model.Enclosure, 2)
+		feed     *model.Feed
+		item     *model.Item
+		received *model.Enclosure
+	)
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			ranged.Add(1)
+		}
+		http.ServeContent(w, r, "episode.mp3", stamp, bytes.NewReader(payload))
+	}))
+	defer srv.Close()
+
+	db = mgr.pool.Get()
+	defer mgr.pool.Put(db)
+
+	feed = &model.Feed{
+		Title:          "Podcast",
+		URL:            purl(srv.URL + "/podcast.rss"),
+		Homepage:       purl(srv.URL),
+		UpdateInterval: time.Hour,
+		Active:         true,
+	}
+
+	if err = db.FeedAdd(feed); err != nil {
+		t.Fatalf("Failed to add Feed: %s", err.Error())
+	} else if err = db.FeedSetDownload(feed, true); err != nil {
+		t.Fatalf("Failed to enable downloads for Feed: %s", err.Error())
+	}
+
+	for idx := range encl {
+		item = &model.Item{
+			FeedID:    feed.ID,
+			URL:       purl(srv.URL + "/episode/" + string(rune('a'+idx))),
+			Timestamp: time.Now().Add(time.Duration(idx) * time.Minute),
+			Headline:  "Episode " + string(rune('A'+idx)),
+		}
+		encl[idx] = &model.Enclosure{
+			URL:      purl(srv.URL + "/media/episode.mp3"),
+			MimeType: "audio/mpeg",
+			Length:   testSize,
+		}
+
+		if err = db.ItemAdd(item); err != nil {
+			t.Fatalf("Failed to add Item: %s", err.Error())
+		}
+
+		encl[idx].ItemID = item.ID
+
+		if err = db.EnclosureAdd(encl[idx]); err != nil {
+			t.Fatalf("Failed to add Enclosure: %s", err.Error())
+		}
+	}
+
+	// Simulate an interrupted download of the older episode, which has to
+	// be resumed.
+	var part = mgr.LocalPath(encl[0]) + partSuffix
+
+	if err = os.MkdirAll(filepath.Dir(part), 0755); err != nil {
+		t.Fatalf("Cannot create folder: %s", err.Error())
+	} else if err = os.WriteFile(part, payload[:testSize/4], 0644); err != nil {
+		t.Fatalf("Cannot write partial download: %s", err.Error())
+	} else if err = mgr.RunOnce(context.Background()); err != nil {
+		t.Fatalf("Failed to run download manager: %s", err.Error())
+	} else if ranged.Load() != 1 {
+		t.Errorf("Expected 1 ranged request, got %d", ranged.Load())
+	}
+
+	// The newer episode is fetched first. There is only room for one
+	// of them, so it has to make room for the older one.
+	if received, err = db.EnclosureGetByID(encl[1].ID); err != nil {
+		t.Fatalf("Failed to load Enclosure: %s", err.Error())
+	} else if !received.Expired {
+		t.Errorf("Enclosure %d should have been removed to stay within quota",
+			received.ID)
+	} else if received, err = db.EnclosureGetByID(encl[0].ID); err != nil {
+		t.Fatalf("Failed to load Enclosure: %s", err.Error())
+	} else if !received.IsDownloaded() {
+		t.Fatalf("Enclosure %d was not downloaded", received.ID)
+	} else if data, err = os.ReadFile(received.Path); err != nil {
+		t.Fatalf("Cannot read downloaded file: %s", err.Error())
+	} else if !bytes.Equal(data, payload) {
+		t.Errorf("Downloaded file is corrupt (%d bytes)", len(data))
+	}
+
+	mgr.retention = time.Nanosecond
+
+	if err = mgr.RunOnce(context.Background()); err != nil {
+		t.Fatalf("Failed to run download manager: %s", err.Error())
+	} else if received, err = db.EnclosureGetByID(encl[0].ID); err != nil {
+		t.Fatalf("Failed to load Enclosure: %s", err.Error())
+	} else if received.IsDownloaded() {
+		t.Error("Enclosure should have been removed after retention period")
+	} else if _, err = os.Stat(mgr.LocalPath(encl[0])); !os.IsNotExist(err) {
+		t.Error("File of expired Enclosure still exists")
+	}
+} // func TestManagerDownload(t *testing.T)
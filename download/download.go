@@ -0,0 +1,417 @@
+// /home/krylon/go/src/github.com/blicero/badnews/download/download.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 16:24:51 krylon>
+
+// Package download implements a download manager that fetches the Enclosures
+// of Feeds that have downloads enabled, e.g. podcast episodes, and stores
+// them locally.
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/blicero/badnews/common"
+	"github.com/blicero/badnews/common/path"
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/logdomain"
+	"github.com/blicero/badnews/model"
+	"github.com/blicero/badnews/reader"
+)
+
+const (
+	// DefaultQuota is the default limit for the disk space used by
+	// downloaded Enclosures, in bytes.
+	DefaultQuota int64 = 4 * 1024 * 1024 * 1024
+	// DefaultRetention is the default period after which downloaded
+	// Enclosures are deleted.
+	DefaultRetention = time.Hour * 24 * 30
+	runInterval      = time.Minute * 5
+	batchSize        = 8
+	maxAttempts      = 5
+	downloadTimeout  = time.Hour
+	partSuffix       = ".part"
+)
+
+// ErrQuotaExceeded is returned if an Enclosure does not fit into the quota.
+var ErrQuotaExceeded = errors.New("download would exceed quota")
+
+var unsafeChars = regexp.MustCompile(`[^-_.A-Za-z0-9]+`)
+
+// Manager downloads Enclosures in the background, deleting downloaded files
+// when they exceed the retention period or to stay within the disk quota.
+type Manager struct {
+	lock      sync.Mutex
+	active    atomic.Bool
+	log       *log.Logger
+	pool      *database.Pool
+	dir       string
+	quota     int64
+	retention time.Duration
+}
+
+// Create instantiates a new Manager. A quota or retention of zero or less
+// means no limit. The Manager gets its database connections from the given
+// Opener.
+func Create(quota int64, retention time.Duration, open database.Opener) (*Manager, error) {
+	var (
+		err error
+		m   = &Manager{
+			dir:       common.Path(path.Downloads),
+			quota:     quota,
+			retention: retention,
+		}
+	)
+
+	if m.log, err = common.GetLogger(logdomain.Download); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Failed to create Logger for download manager: %s\n",
+			err.Error())
+		return nil, err
+	} else if err = os.MkdirAll(m.dir, 0755); err != nil {
+		m.log.Printf("[ERROR] Cannot create download folder %s: %s\n",
+			m.dir,
+			err.Error())
+		return nil, err
+	} else if m.pool, err = database.NewPoolFrom(2, open); err != nil {
+		m.log.Printf("[ERROR] Failed to create database connection pool: %s\n",
+			err.Error())
+		return nil, err
+	}
+
+	return m, nil
+} // func Create(quota int64, retention time.Duration, open database.Opener) (*Manager, error)
+
+// IsActive returns the Manager's active flag.
+func (m *Manager) IsActive() bool {
+	return m.active.Load()
+} // func (m *Manager) IsActive() bool
+
+// Run executes the Manager's main loop until ctx is cancelled. A download
+// that is interrupted by this is resumed the next time around. When Run
+// returns, the Manager has closed its database connections.
+func (m *Manager) Run(ctx context.Context) {
+	var (
+		err    error
+		ticker = time.NewTicker(runInterval)
+	)
+
+	defer ticker.Stop()
+
+	m.active.Store(true)
+	defer m.active.Store(false)
+	defer m.pool.Close() // nolint: errcheck
+
+	for {
+		if err = m.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			m.log.Printf("[ERROR] Failed to process downloads: %s\n",
+				err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			m.log.Println("[INFO] Download manager is stopping.")
+			return
+		case <-ticker.C:
+		}
+	}
+} // func (m *Manager) Run(ctx context.Context)
+
+// RunOnce deletes expired downloads, then fetches pending Enclosures.
+// Cancelling ctx aborts the current download.
+func (m *Manager) RunOnce(ctx context.Context) error {
+	var (
+		err     error
+		db      database.Store
+		pending []*model.Enclosure
+	)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	db = m.pool.Get()
+	defer m.pool.Put(db)
+
+	if err = m.expire(db); err != nil {
+		return err
+	} else if pending, err = db.EnclosureGetPending(maxAttempts, batchSize); err != nil {
+		m.log.Printf("[ERROR] Failed to load pending Enclosures: %s\n",
+			err.Error())
+		return err
+	}
+
+	for _, e := range pending {
+		if err = ctx.Err(); err != nil {
+			return err
+		} else if err = m.makeRoom(db, e.Length); err != nil {
+			if errors.Is(err, ErrQuotaExceeded) {
+				m.log.Printf("[INFO] Enclosure %s (%d bytes) does not fit into quota of %d bytes\n",
+					e.URL,
+					e.Length,
+					m.quota)
+				if err = db.EnclosureExpire(e); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		} else if err = m.fetch(ctx, db, e); err != nil {
+			if ctx.Err() != nil {
+				// We were interrupted, that does not count as a
+				// failed attempt.
+				return ctx.Err()
+			}
+			m.log.Printf("[ERROR] Failed to download Enclosure %s: %s\n",
+				e.URL,
+				err.Error())
+			if err = db.EnclosureRecordFailure(e); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The Length advertised by a Feed is not always accurate.
+	return m.makeRoom(db, 0)
+} // func (m *Manager) RunOnce(ctx context.Context) error
+
+// LocalPath returns the path where the given Enclosure is stored. The file
+// name is derived from the Enclosure's ID and the last component of its URL.
+func (m *Manager) LocalPath(e *model.Enclosure) string {
+	var name = unsafeChars.ReplaceAllString(filepath.Base(e.URL.Path), "_")
+
+	if name == "" || name == "." || name == "_" {
+		name = "enclosure"
+	}
+
+	return filepath.Join(
+		m.dir,
+		strconv.FormatInt(e.ItemID, 10),
+		fmt.Sprintf("%d-%s", e.ID, name))
+} // func (m *Manager) LocalPath(e *model.Enclosure) string
+
+// expire deletes all downloads older than the retention period.
+func (m *Manager) expire(db database.Store) error {
+	var (
+		err    error
+		list   []*model.Enclosure
+		cutoff = time.Now().Add(-m.retention)
+	)
+
+	if m.retention <= 0 {
+		return nil
+	} else if list, err = db.EnclosureGetDownloaded(); err != nil {
+		m.log.Printf("[ERROR] Failed to load downloaded Enclosures: %s\n",
+			err.Error())
+		return err
+	}
+
+	for _, e := range list {
+		if e.Downloaded.After(cutoff) {
+			// The list is sorted by download time, so we are done.
+			break
+		}
+
+		m.log.Printf("[INFO] Retention period of Enclosure %s has expired\n",
+			e.Path)
+
+		if err = m.remove(db, e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+} // func (m *Manager) expire(db database.Store) error
+
+// makeRoom deletes the oldest downloads until the given number of bytes fits
+// into the quota.
+func (m *Manager) makeRoom(db database.Store, size int64) error {
+	var (
+		err  error
+		used int64
+		list []*model.Enclosure
+	)
+
+	if m.quota <= 0 {
+		return nil
+	} else if size > m.quota {
+		return ErrQuotaExceeded
+	} else if used, err = db.EnclosureGetTotalSize(); err != nil {
+		m.log.Printf("[ERROR] Failed to get disk usage of Enclosures: %s\n",
+			err.Error())
+		return err
+	} else if used+size <= m.quota {
+		return nil
+	} else if list, err = db.EnclosureGetDownloaded(); err != nil {
+		m.log.Printf("[ERROR] Failed to load downloaded Enclosures: %s\n",
+			err.Error())
+		return err
+	}
+
+	for _, e := range list {
+		if used+size <= m.quota {
+			break
+		}
+
+		m.log.Printf("[INFO] Remove Enclosure %s to stay within quota\n",
+			e.Path)
+
+		used -= e.Size
+		if err = m.remove(db, e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+} // func (m *Manager) makeRoom(db database.Store, size int64) error
+
+// remove deletes the file of a downloaded Enclosure and marks it as expired.
+func (m *Manager) remove(db database.Store, e *model.Enclosure) error {
+	var err error
+
+	if err = os.Remove(e.Path); err != nil && !os.IsNotExist(err) {
+		m.log.Printf("[ERROR] Cannot remove %s: %s\n",
+			e.Path,
+			err.Error())
+		return err
+	} else if err = db.EnclosureExpire(e); err != nil {
+		m.log.Printf("[ERROR] Failed to mark Enclosure %d as expired: %s\n",
+			e.ID,
+			err.Error())
+		return err
+	}
+
+	return nil
+} // func (m *Manager) remove(db database.Store, e *model.Enclosure) error
+
+// fetch downloads an Enclosure. Data is written to a temporary file first,
+// which is renamed once the download is complete. If a temporary file from an
+// earlier, interrupted attempt exists, we ask the server to resume where we
+// left off.
+func (m *Manager) fetch(ctx context.Context, db database.Store, e *model.Enclosure) error {
+	var (
+		err    error
+		dst    = m.LocalPath(e)
+		part   = dst + partSuffix
+		offset int64
+		fh     *os.File
+		req    *http.Request
+		res    *http.Response
+		info   os.FileInfo
+		flags  = os.O_WRONLY | os.O_CREATE
+		size   int64
+		client = &http.Client{
+			Transport: reader.Client().Transport,
+			Timeout:   downloadTimeout,
+		}
+	)
+
+	if err = os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	} else if info, err = os.Stat(part); err == nil {
+		offset = info.Size()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if req, err = reader.NewRequest(e.URL.String()); err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	m.log.Printf("[DEBUG] Download %s to %s (offset %d)\n",
+		e.URL,
+		dst,
+		offset)
+
+	if res, err = client.Do(req); err != nil {
+		return err
+	}
+
+	defer res.Body.Close() // nolint: errcheck
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		// The server ignored our Range header, or we did not send one.
+		offset = 0
+		flags |= os.O_TRUNC
+	case http.StatusPartialContent:
+		if !strings.HasPrefix(res.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+			os.Remove(part) // nolint: errcheck
+			return fmt.Errorf("Unexpected Content-Range %q resuming at offset %d",
+				res.Header.Get("Content-Range"),
+				offset)
+		}
+		flags |= os.O_APPEND
+	case http.StatusRequestedRangeNotSatisfiable:
+		// Most likely, the file is complete already.
+		if offset == 0 || (e.Length > 0 && offset != e.Length) {
+			os.Remove(part) // nolint: errcheck
+			return fmt.Errorf("Server rejected request to resume at offset %d",
+				offset)
+		}
+		goto FINISH
+	default:
+		return fmt.Errorf("Unexpected HTTP status: %s", res.Status)
+	}
+
+	if fh, err = os.OpenFile(part, flags, 0644); err != nil {
+		return err
+	}
+
+	if m.quota > 0 {
+		// Stop before we blow the quota completely. The next call to
+		// makeRoom will clean up.
+		_, err = io.Copy(fh, io.LimitReader(res.Body, m.quota-offset))
+	} else {
+		_, err = io.Copy(fh, res.Body)
+	}
+
+	if err != nil {
+		fh.Close() // nolint: errcheck
+		return err
+	} else if err = fh.Close(); err != nil {
+		return err
+	}
+
+FINISH:
+	if info, err = os.Stat(part); err != nil {
+		return err
+	}
+
+	size = info.Size()
+
+	if err = os.Rename(part, dst); err != nil {
+		return err
+	} else if err = db.EnclosureSetDownloaded(e, dst, size, time.Now()); err != nil {
+		m.log.Printf("[ERROR] Failed to mark Enclosure %d as downloaded: %s\n",
+			e.ID,
+			err.Error())
+		return err
+	}
+
+	m.log.Printf("[INFO] Downloaded %s (%d bytes)\n",
+		e.URL,
+		size)
+
+	return nil
+} // func (m *Manager) fetch(ctx context.Context, db database.Store, e *model.Enclosure) error
@@ -3,6 +3,7 @@ module github.com/blicero/badnews
 go 1.23.2
 
 require (
+	github.com/PuerkitoBio/goquery v1.8.0
 	github.com/blicero/cacheme v0.1.1
 	github.com/blicero/krylib v0.2.1
 	github.com/blicero/shield v0.0.0-20241004181537-05f336ba3f75
@@ -15,10 +16,11 @@ require (
 	github.com/mattn/go-sqlite3 v1.14.23
 	github.com/mborgerson/GoTruncateHtml v0.0.0-20150507032438-125d9154cd1e
 	github.com/mmcdole/gofeed v1.3.0
+	github.com/syndtr/goleveldb v1.0.0
+	golang.org/x/net v0.13.0
 )
 
 require (
-	github.com/PuerkitoBio/goquery v1.8.0 // indirect
 	github.com/andybalholm/cascadia v1.3.1 // indirect
 	github.com/garyburd/redigo v1.6.4 // indirect
 	github.com/golang/snappy v0.0.1 // indirect
@@ -31,8 +33,6 @@ require (
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/olekukonko/tablewriter v0.0.5 // indirect
 	github.com/ssor/bom v0.0.0-20170718123548-6386211fdfcf // indirect
-	github.com/syndtr/goleveldb v1.0.0 // indirect
-	golang.org/x/net v0.13.0 // indirect
 	golang.org/x/sys v0.15.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 )
@@ -0,0 +1,258 @@
+// /home/krylon/go/src/github.com/blicero/badnews/janitor/janitor.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 02:14:52 krylon>
+
+// Package janitor implements the retention policy for news Items: It
+// periodically deletes old Items the user has shown no interest in, i.e.
+// Items that have not been rated, starred, tagged by hand or found by a
+// search. Tags added automatically by a CategoryMapping do not count.
+package janitor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/blicero/badnews/advisor"
+	"github.com/blicero/badnews/common"
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/judge"
+	"github.com/blicero/badnews/logdomain"
+	"github.com/blicero/badnews/model"
+)
+
+const (
+	runInterval = time.Hour * 6
+	batchSize   = 256
+)
+
+// Report describes what a purge has deleted, or would delete in a dry run.
+type Report struct {
+	DryRun    bool
+	Timestamp time.Time
+	Items     []*model.Item
+	Deleted   int
+	Files     int
+}
+
+func (r *Report) String() string {
+	if r.DryRun {
+		return fmt.Sprintf("Would delete %d Item(s)", len(r.Items))
+	}
+
+	return fmt.Sprintf("Deleted %d of %d Item(s) and %d downloaded file(s)",
+		r.Deleted,
+		len(r.Items),
+		r.Files)
+} // func (r *Report) String() string
+
+// Janitor deletes Items that have exceeded their retention period.
+type Janitor struct {
+	active    atomic.Bool
+	log       *log.Logger
+	pool      *database.Pool
+	retention int
+}
+
+// Create instantiates a new Janitor. retention is the number of days we keep
+// Items of Feeds that do not have their own retention period, zero or less
+// means forever. The Janitor gets its database connection from the given
+// Opener.
+func Create(retention int, open database.Opener) (*Janitor, error) {
+	var (
+		err error
+		j   = &Janitor{retention: retention}
+	)
+
+	if j.log, err = common.GetLogger(logdomain.Janitor); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Failed to create Logger for Janitor: %s\n",
+			err.Error())
+		return nil, err
+	} else if j.pool, err = database.NewPoolFrom(1, open); err != nil {
+		j.log.Printf("[ERROR] Failed to create database connection pool: %s\n",
+			err.Error())
+		return nil, err
+	}
+
+	return j, nil
+} // func Create(retention int, open database.Opener) (*Janitor, error)
+
+// IsActive returns the Janitor's active flag.
+func (j *Janitor) IsActive() bool {
+	return j.active.Load()
+} // func (j *Janitor) IsActive() bool
+
+// Close closes the Janitor's database connections. There is no need to call
+// Close after Run has returned.
+func (j *Janitor) Close() error {
+	return j.pool.Close()
+} // func (j *Janitor) Close() error
+
+// Run purges expired Items periodically until ctx is cancelled. When it
+// returns, the Janitor has closed its database connections.
+func (j *Janitor) Run(ctx context.Context) {
+	var (
+		err    error
+		rep    *Report
+		ticker = time.NewTicker(runInterval)
+	)
+
+	defer ticker.Stop()
+
+	j.active.Store(true)
+	defer j.active.Store(false)
+	defer j.pool.Close() // nolint: errcheck
+
+	for {
+		if rep, err = j.Purge(ctx, false); err != nil && ctx.Err() == nil {
+			j.log.Printf("[ERROR] Failed to purge expired Items: %s\n",
+				err.Error())
+		} else if rep != nil && len(rep.Items) > 0 {
+			j.log.Printf("[INFO] %s\n", rep)
+		}
+
+		select {
+		case <-ctx.Done():
+			j.log.Println("[INFO] Janitor is stopping.")
+			return
+		case <-ticker.C:
+		}
+	}
+} // func (j *Janitor) Run(ctx context.Context)
+
+// Purge deletes the Items that have exceeded their retention period, along
+// with their downloaded Enclosures and the cached ratings and Tag
+// suggestions for them. If dryRun is true, Purge only reports which Items it
+// would delete.
+// Items are deleted in batches, if ctx is cancelled, Purge stops after the
+// current batch.
+func (j *Janitor) Purge(ctx context.Context, dryRun bool) (*Report, error) {
+	var (
+		err error
+		db  database.Store
+		rep = &Report{
+			DryRun:    dryRun,
+			Timestamp: time.Now(),
+		}
+	)
+
+	db = j.pool.Get()
+	defer j.pool.Put(db)
+
+	if rep.Items, err = db.ItemGetPurgeable(j.retention, rep.Timestamp); err != nil {
+		j.log.Printf("[ERROR] Cannot load expired Items: %s\n",
+			err.Error())
+		return nil, err
+	} else if dryRun {
+		return rep, nil
+	}
+
+	for start := 0; start < len(rep.Items) && ctx.Err() == nil; start += batchSize {
+		var (
+			deleted []*model.Item
+			files   []string
+			end     = min(start+batchSize, len(rep.Items))
+		)
+
+		if deleted, files, err = j.purgeBatch(db, rep.Items[start:end]); err != nil {
+			return rep, err
+		}
+
+		rep.Deleted += len(deleted)
+
+		for _, p := range files {
+			if err = os.Remove(p); err == nil {
+				rep.Files++
+			} else if !os.IsNotExist(err) {
+				j.log.Printf("[ERROR] Cannot remove downloaded file %s: %s\n",
+					p,
+					err.Error())
+			}
+		}
+
+		if len(deleted) == 0 {
+			continue
+		}
+
+		// The two caches are independent of each other, so we clean
+		// both, even if one of them fails.
+		if err = judge.Evict(deleted...); err != nil {
+			j.log.Printf("[WARN] Cannot remove purged Items from the rating cache: %s\n",
+				err.Error())
+		}
+
+		if err = advisor.Evict(deleted...); err != nil {
+			j.log.Printf("[WARN] Cannot remove purged Items from the advice cache: %s\n",
+				err.Error())
+		}
+	}
+
+	return rep, nil
+} // func (j *Janitor) Purge(ctx context.Context, dryRun bool) (*Report, error)
+
+// purgeBatch deletes the given Items in a single transaction. It returns
+// the Items that were actually deleted and the paths of their downloaded
+// Enclosures, which the caller should remove once the transaction is
+// committed.
+func (j *Janitor) purgeBatch(db database.Store, items []*model.Item) ([]*model.Item, []string, error) {
+	var (
+		err     error
+		deleted = make([]*model.Item, 0, len(items))
+		files   []string
+	)
+
+	if err = db.Begin(); err != nil {
+		j.log.Printf("[ERROR] Cannot start transaction: %s\n",
+			err.Error())
+		return nil, nil, err
+	}
+
+	for _, i := range items {
+		var (
+			ok   bool
+			encl []*model.Enclosure
+		)
+
+		if encl, err = db.EnclosureGetByItem(i); err != nil {
+			j.log.Printf("[ERROR] Cannot load Enclosures of Item %q (%d): %s\n",
+				i.Headline,
+				i.ID,
+				err.Error())
+			goto FAIL
+		} else if ok, err = db.ItemPurge(i); err != nil {
+			goto FAIL
+		} else if !ok {
+			j.log.Printf("[DEBUG] Item %q (%d) is no longer eligible for purging\n",
+				i.Headline,
+				i.ID)
+			continue
+		}
+
+		for _, e := range encl {
+			if e.IsDownloaded() {
+				files = append(files, e.Path)
+			}
+		}
+
+		deleted = append(deleted, i)
+	}
+
+	if err = db.Commit(); err != nil {
+		j.log.Printf("[ERROR] Cannot commit transaction: %s\n",
+			err.Error())
+		goto FAIL
+	}
+
+	return deleted, files, nil
+
+FAIL:
+	db.Rollback() // nolint: errcheck
+	return nil, nil, err
+} // func (j *Janitor) purgeBatch(db database.Store, items []*model.Item) ([]*model.Item, []string, error)
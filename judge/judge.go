@@ -36,6 +36,8 @@ const (
 var (
 	cache    cacheme.Backend
 	openLock sync.Mutex
+	judges   []*Judge
+	listLock sync.Mutex
 )
 
 func backOff() {
@@ -59,20 +61,45 @@ func getCache() (cacheme.Backend, error) {
 	return cache, nil
 } // func getCache() (cachego.Cache, error)
 
+// Evict removes the cached ratings for the given Items, e.g. because
+// the Items have been deleted. It works without loading the classifier.
+func Evict(items ...*model.Item) error {
+	var (
+		err error
+		c   cacheme.Backend
+	)
+
+	if c, err = getCache(); err != nil {
+		return err
+	}
+
+	for _, i := range items {
+		if err = c.Delete(i.IDString()); err != nil {
+			return fmt.Errorf("Cannot remove Item %d from cache: %w",
+				i.ID,
+				err)
+		}
+	}
+
+	return nil
+} // func Evict(items ...*model.Item) error
+
 // Judge is a classifier to rate News Items as boring or interesting.
 type Judge struct {
 	log   *log.Logger
 	jdg   map[string]shield.Shield
-	db    *database.Database
+	db    database.Store
 	cache cacheme.Backend
 	lock  sync.RWMutex
 }
 
-// New creates a new Judge
-func New() (*Judge, error) {
+// New creates a new Judge that loads its training data from the given Store.
+// The caller remains responsible for closing the Store.
+func New(db database.Store) (*Judge, error) {
 	var (
 		err error
 		j   = &Judge{
+			db: db,
 			jdg: map[string]shield.Shield{
 				"de": shield.New(
 					shield.NewGermanTokenizer(),
@@ -95,18 +122,42 @@ func New() (*Judge, error) {
 
 	if j.log, err = common.GetLogger(logdomain.Judge); err != nil {
 		return nil, err
-	} else if j.db, err = database.Open(common.Path(path.Database)); err != nil {
-		return nil, err
 	} else if j.cache, err = getCache(); err != nil {
 		j.log.Printf("[CRITICAL] Cannot open jcache db at %s: %s\n",
 			common.Path(path.JudgeCache),
 			err.Error())
-		j.db.Close() // nolint: errcheck
 		return nil, err
 	}
 
+	listLock.Lock()
+	judges = append(judges, j)
+	listLock.Unlock()
+
 	return j, nil
-} // func New() (*Judge, error)
+} // func New(db database.Store) (*Judge, error)
+
+// Freeze closes the LevelDB stores that hold the training data of all Judges
+// and blocks them until the returned function is called, so the stores can
+// be copied consistently while the application is running. New Judges cannot
+// be created while the stores are frozen. The stores are reopened the next
+// time they are used.
+func Freeze() func() {
+	listLock.Lock()
+
+	for _, j := range judges {
+		j.lock.Lock()
+		for _, s := range j.jdg {
+			s.Destroy()
+		}
+	}
+
+	return func() {
+		for _, j := range judges {
+			j.lock.Unlock()
+		}
+		listLock.Unlock()
+	}
+} // func Freeze() func()
 
 // InCache returns true if a Rating for the given Item is already stored in the Cache
 func (j *Judge) InCache(i *model.Item) bool {
@@ -20,6 +20,9 @@ const (
 	Blacklist
 	BusyBee
 	Search
+	Download
+	Janitor
+	Backup
 )
 
 func AllDomains() []ID {
@@ -33,5 +36,8 @@ func AllDomains() []ID {
 		Blacklist,
 		BusyBee,
 		Search,
+		Download,
+		Janitor,
+		Backup,
 	}
 } // func AllDomains() []ID
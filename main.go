@@ -7,22 +7,35 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/blicero/badnews/backup"
 	"github.com/blicero/badnews/busybee"
 	"github.com/blicero/badnews/common"
 	"github.com/blicero/badnews/common/path"
 	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/download"
+	"github.com/blicero/badnews/janitor"
+	"github.com/blicero/badnews/model"
+	"github.com/blicero/badnews/opml"
 	"github.com/blicero/badnews/reader"
 	"github.com/blicero/badnews/sleuth"
 	"github.com/blicero/badnews/web"
 )
 
+// shutdownTimeout is how long we wait for the daemons to finish after we
+// have been told to quit.
+const shutdownTimeout = time.Minute
+
 func main() {
 	fmt.Printf("%s %s built on %s\n",
 		common.AppName,
@@ -34,13 +47,40 @@ func main() {
 		rdr             *reader.Reader
 		srv             *web.Server
 		bee             *busybee.BusyBee
+		dlm             *download.Manager
+		jan             *janitor.Janitor
 		sigq            chan os.Signal
+		wg              sync.WaitGroup
+		webDone         = make(chan struct{})
+		ctx, cancel     = context.WithCancel(context.Background())
+		webCtx, webStop = context.WithCancel(context.Background())
 		flushCache      bool
 		startBee        bool
 		doSleuth        bool
 		minlog          = "TRACE"
 		baseDir         = common.Path(path.Base)
 		workerCntReader int
+		maxFailures     int
+		opmlImport      string
+		opmlExport      string
+		dedup           bool
+		schema          bool
+		quotaMB         int64
+		retentionDays   int
+		keepDays        int
+		purge           bool
+		dryRun          bool
+		websub          string
+		refresh         string
+		backupFile      string
+		restoreFile     string
+		verifyFile      string
+		backupDir       string
+		backupKeep      int
+		backupInterval  time.Duration
+		callback        *url.URL
+		httpCfg         = reader.ClientConfig{UserAgent: reader.DefaultUserAgent()}
+		hostLimits      = reader.DefaultHostLimits()
 		addr            = fmt.Sprintf("[::1]:%d", common.Port)
 	)
 
@@ -49,8 +89,32 @@ func main() {
 	flag.StringVar(&minlog, "loglevel", minlog, "Minimum level for log messages to be logged")
 	flag.BoolVar(&flushCache, "flush", false, "Flush cached ratings and tag suggestions")
 	flag.IntVar(&workerCntReader, "readercount", common.WorkerCntReader, "The number of workers for the Reader")
+	flag.IntVar(&maxFailures, "maxfailures", reader.DefaultMaxFailures, "Disable Feeds after this many consecutive failures (0 = never)")
 	flag.BoolVar(&startBee, "bee", false, "Precompute suggested Tags and Ratings for news Items")
 	flag.BoolVar(&doSleuth, "sleuth", false, "Run the Sleuth")
+	flag.StringVar(&opmlImport, "import", "", "Import subscriptions from the given OPML file and exit")
+	flag.StringVar(&opmlExport, "export", "", "Export subscriptions to the given OPML file and exit")
+	flag.BoolVar(&dedup, "dedup", false, "Merge duplicate news Items and exit")
+	flag.BoolVar(&schema, "schema", false, "Show the schema version of the database and pending migrations and exit")
+	flag.Int64Var(&quotaMB, "quota", download.DefaultQuota/(1024*1024), "Disk quota for downloaded enclosures in MB (0 = unlimited)")
+	flag.IntVar(&retentionDays, "retention", int(download.DefaultRetention/(time.Hour*24)), "Delete downloaded enclosures after this many days (0 = never)")
+	flag.IntVar(&keepDays, "keep", 0, "Delete Items older than this many days that have not been rated, tagged or found by a search (0 = never)")
+	flag.BoolVar(&purge, "purge", false, "Delete expired Items and exit")
+	flag.BoolVar(&dryRun, "dryrun", false, "With -purge, only show which Items would be deleted")
+	flag.StringVar(&httpCfg.UserAgent, "useragent", httpCfg.UserAgent, "User-Agent to send with HTTP requests")
+	flag.StringVar(&httpCfg.Proxy, "proxy", "", "URL of the HTTP proxy to use (default: from environment)")
+	flag.DurationVar(&httpCfg.Timeout, "timeout", reader.DefaultTimeout, "Timeout for HTTP requests")
+	flag.StringVar(&websub, "websub", "", "Public base URL of the web server for WebSub callbacks (default: WebSub is disabled)")
+	flag.IntVar(&hostLimits.Concurrency, "hostconns", hostLimits.Concurrency, "Maximum number of concurrent requests to the same host (0 = unlimited)")
+	flag.DurationVar(&hostLimits.Interval, "hostinterval", hostLimits.Interval, "Average time between requests to the same host (0 = unlimited)")
+	flag.IntVar(&hostLimits.Burst, "hostburst", hostLimits.Burst, "Number of requests to the same host we make at once before -hostinterval applies")
+	flag.StringVar(&refresh, "refresh", "", "Refresh the Feed with the given ID, or all active Feeds if \"all\", and exit")
+	flag.StringVar(&backupFile, "backup", "", "Save a backup of the application's state to the given file and exit")
+	flag.StringVar(&restoreFile, "restore", "", "Replace the application's state with the given backup and exit")
+	flag.StringVar(&verifyFile, "verify", "", "Check the given backup for damage and exit")
+	flag.StringVar(&backupDir, "backupdir", "", "Save backups to this directory periodically (default: no scheduled backups)")
+	flag.DurationVar(&backupInterval, "backupinterval", time.Hour*24, "Time between scheduled backups")
+	flag.IntVar(&backupKeep, "backupkeep", 7, "Number of scheduled backups to keep (0 = all)")
 	flag.Parse()
 
 	if baseDir != common.Path(path.Base) {
@@ -70,6 +134,14 @@ func main() {
 		os.Exit(2)
 	}
 
+	// We restore the backup before anything else gets to open the
+	// database or the training data.
+	if restoreFile != "" {
+		os.Exit(runRestore(restoreFile))
+	} else if verifyFile != "" {
+		os.Exit(runVerify(verifyFile))
+	}
+
 	if flushCache {
 		if err = os.Remove(common.Path(path.JudgeCache)); err != nil {
 			fmt.Fprintf(
@@ -88,20 +160,74 @@ func main() {
 		}
 	}
 
-	if rdr, err = reader.New(workerCntReader); err != nil {
+	if schema {
+		os.Exit(runSchema())
+	}
+
+	if opmlImport != "" || opmlExport != "" {
+		os.Exit(runOPML(opmlImport, opmlExport))
+	}
+
+	if dedup {
+		os.Exit(runDedup())
+	}
+
+	if purge {
+		os.Exit(runPurge(keepDays, dryRun))
+	}
+
+	if backupFile != "" {
+		os.Exit(runBackup(backupFile))
+	}
+
+	if websub != "" {
+		if callback, err = url.Parse(websub); err != nil || callback.Scheme == "" || callback.Host == "" {
+			fmt.Fprintf(
+				os.Stderr,
+				"Invalid WebSub callback URL %q\n",
+				websub)
+			os.Exit(1)
+		}
+	}
+
+	if err = reader.Configure(httpCfg); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Invalid HTTP client configuration: %s\n",
+			err.Error())
+		os.Exit(1)
+	}
+
+	if refresh != "" {
+		os.Exit(runRefresh(refresh, workerCntReader, hostLimits))
+	}
+
+	if rdr, err = reader.New(workerCntReader, database.OpenDefault); err != nil {
 		fmt.Fprintf(
 			os.Stderr,
 			"Error creating Reader: %s\n",
 			err.Error())
 		os.Exit(2)
-	} else if srv, err = web.Create(addr); err != nil {
+	} else if dlm, err = download.Create(quotaMB*1024*1024, time.Hour*24*time.Duration(retentionDays), database.OpenDefault); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Error creating download manager: %s\n",
+			err.Error())
+		os.Exit(2)
+	} else if jan, err = janitor.Create(keepDays, database.OpenDefault); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Error creating Janitor: %s\n",
+			err.Error())
+		os.Exit(2)
+	} else if srv, err = web.Create(addr, database.OpenDefault); err != nil {
 		fmt.Fprintf(
 			os.Stderr,
 			"Error creating Web server: %s\n",
 			err.Error())
 		os.Exit(2)
 	} else if startBee {
-		if bee, err = busybee.Create(); err != nil {
+		if bee, err = busybee.Create(database.OpenDefault); err != nil {
 			fmt.Fprintf(
 				os.Stderr,
 				"Failed to create BusyBee: %s\n",
@@ -110,15 +236,67 @@ func main() {
 			os.Exit(3)
 		}
 
-		go bee.Run()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bee.Run(ctx)
+		}()
 	}
 
 	if doSleuth {
-		go runSleuth()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runSleuth(ctx)
+		}()
+	}
+
+	if backupDir != "" {
+		var sched *backup.Scheduler
+
+		if sched, err = backup.Create(backupDir, backupKeep, backupInterval); err != nil {
+			fmt.Fprintf(
+				os.Stderr,
+				"Error creating backup Scheduler: %s\n",
+				err.Error())
+			os.Exit(2)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sched.Run(ctx)
+		}()
+	}
+
+	if callback != nil {
+		rdr.SetCallback(callback)
 	}
 
-	rdr.Start()
-	go srv.ListenAndServe()
+	srv.SetReader(rdr)
+
+	rdr.SetMaxFailures(maxFailures)
+	rdr.SetHostLimits(hostLimits)
+	rdr.Start(ctx)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		dlm.Run(ctx)
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		jan.Run(ctx)
+	}()
+	go func() {
+		defer close(webDone)
+		if err := srv.ListenAndServe(webCtx); err != nil {
+			fmt.Fprintf(
+				os.Stderr,
+				"Web server failed: %s\n",
+				err.Error())
+		}
+	}()
 
 	sigq = make(chan os.Signal, 2)
 	var ticker = time.NewTicker(time.Second * 5)
@@ -126,6 +304,7 @@ func main() {
 
 	signal.Notify(sigq, os.Interrupt, syscall.SIGHUP, syscall.SIGQUIT, syscall.SIGTERM)
 
+MAIN_LOOP:
 	for {
 		select {
 		case sig := <-sigq:
@@ -133,7 +312,7 @@ func main() {
 				os.Stderr,
 				"Received signal %s, quitting.\n",
 				sig)
-			os.Exit(0)
+			break MAIN_LOOP
 		case <-ticker.C:
 			var cnt = database.WaitCnt.Load()
 			if cnt > 0 {
@@ -144,15 +323,47 @@ func main() {
 			}
 		}
 	}
-}
 
-func runSleuth() {
+	go func() {
+		select {
+		case sig := <-sigq:
+			fmt.Fprintf(
+				os.Stderr,
+				"Received signal %s again, quitting immediately.\n",
+				sig)
+		case <-time.After(shutdownTimeout):
+			fmt.Fprintf(
+				os.Stderr,
+				"Shutdown did not finish within %s, quitting anyway.\n",
+				shutdownTimeout)
+		}
+		os.Exit(1)
+	}()
+
+	// The web server passes WebSub callbacks on to the Reader, so we stop
+	// it first.
+	webStop()
+	<-webDone
+
+	cancel()
+	rdr.Wait()
+	wg.Wait()
+} // func main()
+
+func runSleuth(ctx context.Context) {
 	var (
 		err error
+		db  database.Store
 		s   *sleuth.Sleuth
 	)
 
-	if s, err = sleuth.Create(); err != nil {
+	if db, err = database.OpenDefault(); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Failed to open database: %s\n",
+			err.Error())
+		os.Exit(2)
+	} else if s, err = sleuth.Create(db); err != nil {
 		fmt.Fprintf(
 			os.Stderr,
 			"Failed to create Sleuth: %s\n",
@@ -160,5 +371,364 @@ func runSleuth() {
 		os.Exit(2)
 	}
 
-	s.Run()
-} // func runSleuth()
+	s.Run(ctx)
+} // func runSleuth(ctx context.Context)
+
+func runRefresh(which string, workers int, limits reader.HostLimits) int {
+	var (
+		err         error
+		db          *database.Database
+		rdr         *reader.Reader
+		feeds       []model.Feed
+		results     <-chan reader.RefreshResult
+		newCnt      int
+		errCnt      int
+		start       = time.Now()
+		ctx, cancel = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	)
+
+	defer cancel()
+
+	if db, err = database.Open(common.Path(path.Database)); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Failed to open database: %s\n",
+			err.Error())
+		return 2
+	}
+
+	if which == "all" {
+		var all []model.Feed
+
+		if all, err = db.FeedGetAll(); err == nil {
+			for _, f := range all {
+				if f.Active {
+					feeds = append(feeds, f)
+				}
+			}
+		}
+	} else {
+		var (
+			id int64
+			f  *model.Feed
+		)
+
+		if id, err = strconv.ParseInt(which, 10, 64); err != nil {
+			err = fmt.Errorf("Invalid Feed ID %q, expected a number or \"all\"", which)
+		} else if f, err = db.FeedGetByID(id); err == nil && f == nil {
+			err = fmt.Errorf("Feed %d does not exist", id)
+		} else if err == nil {
+			feeds = append(feeds, *f)
+		}
+	}
+
+	db.Close() // nolint: errcheck
+
+	if err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Failed to load Feeds: %s\n",
+			err.Error())
+		return 1
+	} else if rdr, err = reader.New(max(min(workers, len(feeds)), 1), database.OpenDefault); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Error creating Reader: %s\n",
+			err.Error())
+		return 2
+	}
+
+	rdr.SetHostLimits(limits)
+
+	// We only fetch the Feeds we were asked for, so we do not need the
+	// feeder. Deferred calls run in reverse order, so we stop the Reader
+	// before we wait for it.
+	rdr.StartWorkers(ctx)
+	defer rdr.Wait()
+	defer cancel()
+
+	if results, err = rdr.Refresh(ctx, feeds...); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Cannot refresh Feeds: %s\n",
+			err.Error())
+		return 2
+	}
+
+	for i := range feeds {
+		var res = <-results
+
+		if res.Err != nil {
+			errCnt++
+			fmt.Printf("[%d/%d] %s: Error after %s: %s\n",
+				i+1,
+				len(feeds),
+				res.Feed.Title,
+				res.Duration.Round(time.Millisecond),
+				res.Err.Error())
+		} else {
+			newCnt += res.NewItems
+			fmt.Printf("[%d/%d] %s: %d new Item(s) in %s\n",
+				i+1,
+				len(feeds),
+				res.Feed.Title,
+				res.NewItems,
+				res.Duration.Round(time.Millisecond))
+		}
+	}
+
+	fmt.Printf("Refreshed %d Feed(s) in %s: %d new Item(s), %d error(s)\n",
+		len(feeds),
+		time.Since(start).Round(time.Millisecond),
+		newCnt,
+		errCnt)
+
+	if errCnt > 0 {
+		return 1
+	}
+
+	return 0
+} // func runRefresh(which string, workers int, limits reader.HostLimits) int
+
+func runSchema() int {
+	var (
+		err     error
+		version int
+		pending []database.Migration
+		dbpath  = common.Path(path.Database)
+	)
+
+	if version, pending, err = database.SchemaStatus(dbpath); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Cannot get schema version of %s: %s\n",
+			dbpath,
+			err.Error())
+		return 1
+	}
+
+	fmt.Printf("Database %s has schema version %d, current version is %d\n",
+		dbpath,
+		version,
+		database.SchemaVersion())
+
+	if version > database.SchemaVersion() {
+		fmt.Println("The database was upgraded by a newer version of the application.")
+		return 1
+	} else if len(pending) == 0 {
+		fmt.Println("No pending migrations.")
+		return 0
+	}
+
+	fmt.Printf("%d pending migration(s), applied the next time the database is opened:\n",
+		len(pending))
+	for _, m := range pending {
+		fmt.Println(m.String())
+	}
+
+	return 0
+} // func runSchema() int
+
+func runOPML(importPath, exportPath string) int {
+	var (
+		err error
+		db  *database.Database
+		fh  *os.File
+		res *opml.Result
+	)
+
+	if db, err = database.Open(common.Path(path.Database)); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Failed to open database: %s\n",
+			err.Error())
+		return 2
+	}
+
+	defer db.Close() // nolint: errcheck
+
+	if importPath != "" {
+		if fh, err = os.Open(importPath); err != nil {
+			fmt.Fprintf(
+				os.Stderr,
+				"Cannot open %s: %s\n",
+				importPath,
+				err.Error())
+			return 1
+		}
+
+		defer fh.Close() // nolint: errcheck
+
+		if res, err = opml.Import(db, fh); err != nil {
+			fmt.Fprintf(
+				os.Stderr,
+				"Failed to import %s: %s\n",
+				importPath,
+				err.Error())
+			return 1
+		}
+
+		for _, f := range res.Added {
+			fmt.Printf("Added     %s (%s)\n", f.Title, f.URL)
+		}
+		for _, f := range res.Duplicates {
+			fmt.Printf("Duplicate %s (%s)\n", f.Title, f.URL)
+		}
+		for _, e := range res.Errors {
+			fmt.Printf("Error     %s\n", e.Error())
+		}
+		fmt.Println(res)
+	}
+
+	if exportPath != "" {
+		if fh, err = os.Create(exportPath); err != nil {
+			fmt.Fprintf(
+				os.Stderr,
+				"Cannot create %s: %s\n",
+				exportPath,
+				err.Error())
+			return 1
+		}
+
+		defer fh.Close() // nolint: errcheck
+
+		if err = opml.Export(db, fh); err != nil {
+			fmt.Fprintf(
+				os.Stderr,
+				"Failed to export subscriptions to %s: %s\n",
+				exportPath,
+				err.Error())
+			return 1
+		}
+	}
+
+	return 0
+} // func runOPML(importPath, exportPath string) int
+
+func runDedup() int {
+	var (
+		err error
+		db  *database.Database
+		cnt int64
+	)
+
+	if db, err = database.Open(common.Path(path.Database)); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Failed to open database: %s\n",
+			err.Error())
+		return 2
+	}
+
+	defer db.Close() // nolint: errcheck
+
+	if cnt, err = db.ItemMergeDuplicates(); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Failed to merge duplicate Items: %s\n",
+			err.Error())
+		return 1
+	}
+
+	fmt.Printf("Removed %d duplicate Items\n", cnt)
+	return 0
+} // func runDedup() int
+
+func runPurge(keep int, dryRun bool) int {
+	var (
+		err         error
+		jan         *janitor.Janitor
+		rep         *janitor.Report
+		ctx, cancel = signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	)
+
+	defer cancel()
+
+	if jan, err = janitor.Create(keep, database.OpenDefault); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Error creating Janitor: %s\n",
+			err.Error())
+		return 2
+	}
+
+	defer jan.Close() // nolint: errcheck
+
+	if rep, err = jan.Purge(ctx, dryRun); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Failed to purge expired Items: %s\n",
+			err.Error())
+		return 1
+	}
+
+	for _, i := range rep.Items {
+		fmt.Printf("%6d  Feed %-4d  %s  %s\n",
+			i.ID,
+			i.FeedID,
+			i.Timestamp.Format(common.TimestampFormat),
+			i.Headline)
+	}
+
+	fmt.Println(rep)
+	return 0
+} // func runPurge(keep int, dryRun bool) int
+
+func runBackup(archive string) int {
+	var (
+		err error
+		m   *backup.Manifest
+	)
+
+	if m, err = backup.Save(archive); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Failed to save backup to %s: %s\n",
+			archive,
+			err.Error())
+		return 1
+	}
+
+	fmt.Printf("Saved backup to %s\n%s\n", archive, m)
+	return 0
+} // func runBackup(archive string) int
+
+func runVerify(archive string) int {
+	var (
+		err error
+		m   *backup.Manifest
+	)
+
+	if m, err = backup.Verify(archive); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"%s is damaged: %s\n",
+			archive,
+			err.Error())
+		return 1
+	}
+
+	fmt.Printf("%s is intact\n%s\n", archive, m)
+	return 0
+} // func runVerify(archive string) int
+
+func runRestore(archive string) int {
+	var (
+		err error
+		old string
+	)
+
+	if old, err = backup.Restore(archive); err != nil {
+		fmt.Fprintf(
+			os.Stderr,
+			"Failed to restore backup from %s: %s\n",
+			archive,
+			err.Error())
+		return 1
+	}
+
+	fmt.Printf("Restored backup from %s, the previous state has been moved to %s\n",
+		archive,
+		old)
+	return 0
+} // func runRestore(archive string) int
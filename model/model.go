@@ -8,7 +8,10 @@
 package model
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"net/url"
 	"regexp"
 	"strconv"
@@ -20,13 +23,28 @@ import (
 
 // Feed is an RSS feed. Duh.
 type Feed struct {
-	ID             int64         `json:"id,omitempty"`
-	Title          string        `json:"title"`
-	URL            *url.URL      `json:"url"`
-	Homepage       *url.URL      `json:"homepage"`
-	UpdateInterval time.Duration `json:"interval"`
-	LastRefresh    time.Time     `json:"last_refresh,omitempty"`
-	Active         bool          `json:"active,omitempty"`
+	ID             int64             `json:"id,omitempty"`
+	Title          string            `json:"title"`
+	URL            *url.URL          `json:"url"`
+	Homepage       *url.URL          `json:"homepage"`
+	UpdateInterval time.Duration     `json:"interval"`
+	LastRefresh    time.Time         `json:"last_refresh,omitempty"`
+	Active         bool              `json:"active,omitempty"`
+	Folder         string            `json:"folder,omitempty"`
+	FetchFull      bool              `json:"fetch_full,omitempty"`
+	Download       bool              `json:"download,omitempty"`
+	ETag           string            `json:"etag,omitempty"`
+	LastModified   string            `json:"last_modified,omitempty"`
+	LastStatus     int               `json:"last_status,omitempty"`
+	Failures       int               `json:"consecutive_failures,omitempty"`
+	LastError      string            `json:"last_error,omitempty"`
+	NextAttempt    time.Time         `json:"next_attempt,omitempty"`
+	Headers        map[string]string `json:"-"` // May contain API keys
+	Auth           Credentials       `json:"-"`
+	Bound          IntervalBound     `json:"interval_bound"`
+	Schedule       Schedule          `json:"schedule"`
+	Meta           FeedMeta          `json:"meta"`
+	Retention      int               `json:"retention,omitempty"`
 }
 
 func (f *Feed) String() string {
@@ -40,11 +58,39 @@ func (f *Feed) String() string {
 		f.Active)
 }
 
+// NextRefresh returns the time the Feed is due for its next refresh.
+func (f *Feed) NextRefresh() time.Time {
+	if f.Schedule.Next.IsZero() {
+		return f.LastRefresh.Add(f.UpdateInterval)
+	}
+
+	return f.Schedule.Next
+} // func (f *Feed) NextRefresh() time.Time
+
 // IsDue returns true if the Feed is due for a refresh.
 func (f *Feed) IsDue() bool {
-	return time.Now().After(f.LastRefresh.Add(f.UpdateInterval))
+	var now = time.Now()
+	return now.After(f.NextRefresh()) && now.After(f.NextAttempt)
 } // func (f *Feed) IsDue() bool
 
+// RetentionPeriod returns how long we keep the Feed's Items that the user
+// has neither rated nor tagged. A Retention of zero means the Feed uses the
+// global default, which is given in days, a negative one means we keep its
+// Items forever. A period of zero means forever.
+func (f *Feed) RetentionPeriod(global int) time.Duration {
+	var days = f.Retention
+
+	if days == 0 {
+		days = global
+	}
+
+	if days <= 0 {
+		return 0
+	}
+
+	return time.Hour * 24 * time.Duration(days)
+} // func (f *Feed) RetentionPeriod(global int) time.Duration
+
 // Clone returns a shallow copy of the Feed
 func (f *Feed) Clone() *Feed {
 	var c = &Feed{
@@ -55,26 +101,209 @@ func (f *Feed) Clone() *Feed {
 		UpdateInterval: f.UpdateInterval,
 		LastRefresh:    f.LastRefresh,
 		Active:         f.Active,
+		Folder:         f.Folder,
+		FetchFull:      f.FetchFull,
+		Download:       f.Download,
+		ETag:           f.ETag,
+		LastModified:   f.LastModified,
+		LastStatus:     f.LastStatus,
+		Failures:       f.Failures,
+		LastError:      f.LastError,
+		NextAttempt:    f.NextAttempt,
+		Headers:        maps.Clone(f.Headers),
+		Auth:           f.Auth,
+		Bound:          f.Bound,
+		Schedule:       f.Schedule,
+		Meta:           f.Meta,
+		Retention:      f.Retention,
 	}
 
 	return c
 }
 
+// IntervalBound determines how the UpdateInterval the user has set for a Feed
+// limits the adaptive refresh schedule.
+type IntervalBound uint8
+
+// BoundLower means we never refresh a Feed more often than its
+// UpdateInterval, BoundUpper means we never wait longer than its
+// UpdateInterval.
+const (
+	BoundLower IntervalBound = iota
+	BoundUpper
+)
+
+func (b IntervalBound) String() string {
+	switch b {
+	case BoundLower:
+		return "Lower"
+	case BoundUpper:
+		return "Upper"
+	default:
+		return fmt.Sprintf("IntervalBound(%d)", b)
+	}
+} // func (b IntervalBound) String() string
+
+// Schedule holds what we know about when a Feed publishes new Items and when
+// we should check it next.
+// SkipHours and SkipDays are bitmasks of the hours (0-23, UTC) and weekdays
+// (time.Sunday = bit 0) during which the Feed asks not to be checked.
+type Schedule struct {
+	TTL       time.Duration `json:"ttl,omitempty"`
+	SkipHours uint32        `json:"skip_hours,omitempty"`
+	SkipDays  uint8         `json:"skip_days,omitempty"`
+	Cadence   time.Duration `json:"cadence,omitempty"`
+	Next      time.Time     `json:"next,omitempty"`
+}
+
+// Skip returns true if the Feed asks not to be checked at the given time.
+func (s *Schedule) Skip(t time.Time) bool {
+	t = t.UTC()
+	return s.SkipHours&(1<<uint(t.Hour())) != 0 || s.SkipDays&(1<<uint(t.Weekday())) != 0
+} // func (s *Schedule) Skip(t time.Time) bool
+
+// FeedMeta holds what a Feed tells us about itself, along with the icon we
+// keep for it. Icon is the path of the cached icon file, it is empty if we
+// have not found one. IconChecked is when we last looked for one.
+type FeedMeta struct {
+	Description string    `json:"description,omitempty"`
+	Language    string    `json:"language,omitempty"`
+	Image       *url.URL  `json:"image,omitempty"`
+	Generator   string    `json:"generator,omitempty"`
+	Icon        string    `json:"-"`
+	IconType    string    `json:"-"`
+	IconChecked time.Time `json:"-"`
+}
+
+// HasIcon returns true if we have a cached icon for the Feed.
+func (m *FeedMeta) HasIcon() bool {
+	return m.Icon != ""
+} // func (m *FeedMeta) HasIcon() bool
+
+// ImageString returns the URL of the Feed's image, or an empty string if it
+// has none.
+func (m *FeedMeta) ImageString() string {
+	if m.Image == nil {
+		return ""
+	}
+
+	return m.Image.String()
+} // func (m *FeedMeta) ImageString() string
+
+// AuthKind identifies how we authenticate ourselves when fetching a Feed.
+type AuthKind uint8
+
+// AuthNone means we send no credentials, AuthBasic means HTTP basic auth with
+// a username and password, AuthBearer means the Secret is sent as a bearer
+// token in the Authorization header.
+const (
+	AuthNone AuthKind = iota
+	AuthBasic
+	AuthBearer
+)
+
+func (k AuthKind) String() string {
+	switch k {
+	case AuthNone:
+		return "None"
+	case AuthBasic:
+		return "Basic"
+	case AuthBearer:
+		return "Bearer"
+	default:
+		return fmt.Sprintf("AuthKind(%d)", k)
+	}
+} // func (k AuthKind) String() string
+
+// Credentials are used to access Feeds that require authentication. The
+// Secret is stored encrypted in the database.
+type Credentials struct {
+	Kind     AuthKind
+	Username string
+	Secret   string
+}
+
+// SubState is the state of a WebSub subscription.
+type SubState uint8
+
+// SubPending means we have asked the hub to subscribe us, but it has not
+// verified our intent, yet. SubActive means the hub has verified our intent
+// and pushes updates to us until the lease expires. SubDenied means the hub
+// has refused our request. SubUnsubscribing means we have asked the hub to
+// unsubscribe us, but it has not verified our intent, yet.
+const (
+	SubPending SubState = iota
+	SubActive
+	SubDenied
+	SubUnsubscribing
+)
+
+func (s SubState) String() string {
+	switch s {
+	case SubPending:
+		return "Pending"
+	case SubActive:
+		return "Active"
+	case SubDenied:
+		return "Denied"
+	case SubUnsubscribing:
+		return "Unsubscribing"
+	default:
+		return fmt.Sprintf("SubState(%d)", s)
+	}
+} // func (s SubState) String() string
+
+// Subscription is a WebSub subscription to a Feed, which allows the Feed's
+// hub to push updates to us instead of us polling the Feed.
+type Subscription struct {
+	ID           int64     `json:"id"`
+	FeedID       int64     `json:"feed_id"`
+	Hub          *url.URL  `json:"hub"`
+	Topic        *url.URL  `json:"topic"`
+	Secret       string    `json:"-"`
+	State        SubState  `json:"state"`
+	Requested    time.Time `json:"requested"`
+	LeaseExpires time.Time `json:"lease_expires,omitempty"`
+	LastPush     time.Time `json:"last_push,omitempty"`
+}
+
+// IsActive returns true if the hub has confirmed the Subscription and the
+// lease has not expired.
+func (s *Subscription) IsActive() bool {
+	return s.State == SubActive && time.Now().Before(s.LeaseExpires)
+} // func (s *Subscription) IsActive() bool
+
 // Item is a single news item
 type Item struct {
-	ID          int64     `json:"id"`
-	FeedID      int64     `json:"feed_id"`
-	URL         *url.URL  `json:"url"`
-	Timestamp   time.Time `json:"timestamp"`
-	Headline    string    `json:"headline"`
-	Description string    `json:"description"`
-	Rating      int8      `json:"rating"`
-	Guessed     int8      `json:"guessed"`
-	Tags        []*Tag    `json:"tags"`
+	ID          int64        `json:"id"`
+	FeedID      int64        `json:"feed_id"`
+	URL         *url.URL     `json:"url"`
+	Timestamp   time.Time    `json:"timestamp"`
+	Headline    string       `json:"headline"`
+	Description string       `json:"description"`
+	Categories  StringList   `json:"categories,omitempty"`
+	Authors     StringList   `json:"authors,omitempty"`
+	Content     string       `json:"-"`
+	GUID        string       `json:"guid,omitempty"`
+	Revisions   int          `json:"revisions,omitempty"`
+	Rating      int8         `json:"rating"`
+	Guessed     int8         `json:"guessed"`
+	ReadAt      time.Time    `json:"read_at"`
+	Starred     bool         `json:"starred"`
+	Score       float64      `json:"score,omitempty"`
+	Tags        []*Tag       `json:"tags"`
+	Enclosures  []*Enclosure `json:"enclosures,omitempty"`
 	_idstr      string
 	_plain      string
 }
 
+// ItemFilter restricts a list of Items to those that have not been read yet,
+// those that have been starred, or both. The zero value matches all Items.
+type ItemFilter struct {
+	Unread  bool
+	Starred bool
+}
+
 var whitespace *regexp.Regexp = regexp.MustCompile(`[\s\t\n\r]+`)
 
 // EffectiveRating returns the Item's Rating, *if* it has been rated, the guessed
@@ -89,7 +318,14 @@ func (i *Item) EffectiveRating() int8 {
 	return 0
 } // func (i *Item) EffectiveRating() int8
 
+// IsRead returns true if the Item has been marked as read.
+func (i *Item) IsRead() bool {
+	return !i.ReadAt.IsZero()
+} // func (i *Item) IsRead() bool
+
 // Plaintext returns the complete text of the Item, cleansed of any HTML.
+// If the full article text has been fetched, it is used instead of the
+// Description.
 func (i *Item) Plaintext() string {
 	var tmp = make([]string, 2)
 	var err error
@@ -102,7 +338,9 @@ func (i *Item) Plaintext() string {
 		tmp[0] = i.Headline
 	}
 
-	if tmp[1], err = html2text.FromString(i.Description); err != nil {
+	if i.Content != "" {
+		tmp[1] = i.Content
+	} else if tmp[1], err = html2text.FromString(i.Description); err != nil {
 		tmp[1] = i.Description
 	}
 
@@ -128,6 +366,18 @@ func (i *Item) HasTag(id int64) bool {
 	return false
 } // func (i *Item) HasTag(id int64) bool
 
+// HasAutoTag returns true if the Tag with the given id was attached to the
+// Item automatically, based on the Item's categories.
+func (i *Item) HasAutoTag(id int64) bool {
+	for _, t := range i.Tags {
+		if t.ID == id {
+			return t.Auto
+		}
+	}
+
+	return false
+} // func (i *Item) HasAutoTag(id int64) bool
+
 // IDString returns the ID as a string
 func (i *Item) IDString() string {
 	if i._idstr != "" {
@@ -138,6 +388,47 @@ func (i *Item) IDString() string {
 	return i._idstr
 } // func (i *Item) IDString() string
 
+// FeedMove records a change of a Feed's URL, either because the server told
+// us the Feed has moved permanently, or because the user changed it.
+type FeedMove struct {
+	ID        int64     `json:"id"`
+	FeedID    int64     `json:"feed_id"`
+	Timestamp time.Time `json:"timestamp"`
+	OldURL    *url.URL  `json:"old_url"`
+	NewURL    *url.URL  `json:"new_url"`
+	Reason    string    `json:"reason"`
+}
+
+// Revision is an earlier version of an Item, saved when the Feed changed the
+// Item's Headline or Description after we first saw it.
+type Revision struct {
+	ID          int64     `json:"id"`
+	ItemID      int64     `json:"item_id"`
+	Timestamp   time.Time `json:"timestamp"`
+	Headline    string    `json:"headline"`
+	Description string    `json:"description"`
+}
+
+// Enclosure is a media file attached to an Item, e.g. a podcast episode.
+// If the Item's Feed has downloads enabled, the file is stored locally at Path.
+type Enclosure struct {
+	ID         int64     `json:"id"`
+	ItemID     int64     `json:"item_id"`
+	URL        *url.URL  `json:"url"`
+	MimeType   string    `json:"mime_type"`
+	Length     int64     `json:"length"`
+	Path       string    `json:"path,omitempty"`
+	Size       int64     `json:"size,omitempty"`
+	Downloaded time.Time `json:"downloaded,omitempty"`
+	Attempts   int       `json:"attempts,omitempty"`
+	Expired    bool      `json:"expired,omitempty"`
+}
+
+// IsDownloaded returns true if the Enclosure's file is available locally.
+func (e *Enclosure) IsDownloaded() bool {
+	return !e.Downloaded.IsZero() && !e.Expired && e.Path != ""
+} // func (e *Enclosure) IsDownloaded() bool
+
 // Tag is a label that can be attached to an Item. A Tag can also have
 // a Parent Tag, which allows to organize them in a hierarchy.
 type Tag struct {
@@ -146,8 +437,64 @@ type Tag struct {
 	Name     string `json:"name"`
 	Level    int64  `json:"level"`
 	FullName string `json:"full_name"`
+	Auto     bool   `json:"auto,omitempty"`
 }
 
+// CategoryMapping tells the Reader to attach a Tag to every Item of a Feed
+// that the publisher has put in the given category. Categories are compared
+// case-insensitively.
+type CategoryMapping struct {
+	ID       int64  `json:"id"`
+	FeedID   int64  `json:"feed_id"`
+	Category string `json:"category"`
+	TagID    int64  `json:"tag_id"`
+}
+
+// StringList is a list of strings that is stored in the database as a JSON
+// array.
+type StringList []string
+
+// Scan implements the sql.Scanner interface.
+func (l *StringList) Scan(src any) error {
+	var raw []byte
+
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		return fmt.Errorf("Cannot scan %T into StringList", src)
+	}
+
+	*l = nil
+	return json.Unmarshal(raw, l)
+} // func (l *StringList) Scan(src any) error
+
+// Value implements the driver.Valuer interface.
+func (l StringList) Value() (driver.Value, error) {
+	var (
+		err error
+		buf []byte
+	)
+
+	if len(l) == 0 {
+		return "[]", nil
+	} else if buf, err = json.Marshal([]string(l)); err != nil {
+		return nil, err
+	}
+
+	return string(buf), nil
+} // func (l StringList) Value() (driver.Value, error)
+
+// String returns the list's elements, separated by commas.
+func (l StringList) String() string {
+	return strings.Join(l, ", ")
+} // func (l StringList) String() string
+
 // Search represents the parameters of a search query.
 // Regex, if true, indicates the Query text should be handled as a regular
 // expression.
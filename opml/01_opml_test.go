@@ -0,0 +1,116 @@
+// /home/krylon/go/src/github.com/blicero/badnews/opml/01_opml_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 13:44:51 krylon>
+
+package opml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testDocument = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head><title>Test</title></head>
+  <body>
+    <outline text="Nachrichten">
+      <outline text="Tagesschau" type="rss" xmlUrl="https://www.tagesschau.de/xml/rss2" htmlUrl="https://www.tagesschau.de/" refreshInterval="600" />
+      <outline text="Regional">
+        <outline text="WDR Bielefeld" type="rss" xmlUrl="https://www1.wdr.de/nachrichten/bielefeld-nachrichten-100.feed" />
+      </outline>
+    </outline>
+    <outline text="Hacker News" title="HN" type="rss" xmlUrl="https://news.ycombinator.com/rss" htmlUrl="/news" />
+  </body>
+</opml>
+`
+
+func TestParseFeeds(t *testing.T) {
+	type expect struct {
+		title    string
+		folder   string
+		interval time.Duration
+		homepage string
+	}
+
+	var expected = []expect{
+		{title: "Tagesschau", folder: "Nachrichten", interval: time.Minute * 10, homepage: "https://www.tagesschau.de/"},
+		{title: "WDR Bielefeld", folder: "Nachrichten/Regional", interval: defaultInterval, homepage: "https://www1.wdr.de/"},
+		// A relative htmlUrl is resolved against the xmlUrl.
+		{title: "HN", folder: "", interval: defaultInterval, homepage: "https://news.ycombinator.com/news"},
+	}
+
+	var (
+		err  error
+		doc  *Document
+		errs []error
+	)
+
+	if doc, err = Parse(strings.NewReader(testDocument)); err != nil {
+		t.Fatalf("Failed to parse OPML document: %s", err.Error())
+	}
+
+	feeds, errs := doc.Feeds()
+
+	if len(errs) != 0 {
+		t.Fatalf("Unexpected errors: %v", errs)
+	} else if len(feeds) != len(expected) {
+		t.Fatalf("Expected %d Feeds, got %d", len(expected), len(feeds))
+	}
+
+	for i, e := range expected {
+		var f = feeds[i]
+		if f.Title != e.title || f.Folder != e.folder || f.UpdateInterval != e.interval {
+			t.Errorf("Unexpected Feed #%d: %q / %q / %s (expected %q / %q / %s)",
+				i,
+				f.Title,
+				f.Folder,
+				f.UpdateInterval,
+				e.title,
+				e.folder,
+				e.interval)
+		} else if f.Homepage == nil {
+			t.Errorf("Feed %s has no Homepage", f.Title)
+		} else if f.Homepage.String() != e.homepage {
+			t.Errorf("Unexpected Homepage of Feed %s: %s (expected %s)",
+				f.Title,
+				f.Homepage,
+				e.homepage)
+		}
+	}
+
+	// Exporting and re-importing the Feeds should give us the same Feeds.
+	var buf bytes.Buffer
+
+	if err = FromFeeds("Test", feeds).Write(&buf); err != nil {
+		t.Fatalf("Failed to write OPML document: %s", err.Error())
+	} else if doc, err = Parse(&buf); err != nil {
+		t.Fatalf("Failed to parse exported OPML document: %s", err.Error())
+	}
+
+	again, errs := doc.Feeds()
+
+	if len(errs) != 0 {
+		t.Fatalf("Unexpected errors after round trip: %v", errs)
+	} else if len(again) != len(feeds) {
+		t.Fatalf("Expected %d Feeds after round trip, got %d",
+			len(feeds),
+			len(again))
+	}
+
+	for i, f := range feeds {
+		var g = again[i]
+		if f.Title != g.Title ||
+			f.Folder != g.Folder ||
+			f.URL.String() != g.URL.String() ||
+			f.UpdateInterval != g.UpdateInterval {
+			t.Errorf("Feed #%d changed in round trip: %s -> %s",
+				i,
+				&f,
+				&g)
+		}
+	}
+} // func TestParseFeeds(t *testing.T)
@@ -0,0 +1,99 @@
+// /home/krylon/go/src/github.com/blicero/badnews/opml/import.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 13:21:07 krylon>
+
+package opml
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/model"
+)
+
+// Result summarizes the outcome of an import.
+type Result struct {
+	Added      []model.Feed
+	Duplicates []model.Feed
+	Errors     []error
+}
+
+func (r *Result) String() string {
+	return fmt.Sprintf("%d Feed(s) added, %d duplicate(s) skipped, %d error(s)",
+		len(r.Added),
+		len(r.Duplicates),
+		len(r.Errors))
+} // func (r *Result) String() string
+
+// Import reads an OPML document and adds all Feeds to the database that are
+// not already subscribed to. A Feed counts as a duplicate if either its URL
+// or its title is already present in the database.
+func Import(db database.Store, r io.Reader) (*Result, error) {
+	var (
+		err    error
+		doc    *Document
+		feeds  []model.Feed
+		known  []model.Feed
+		urls   = make(map[string]bool)
+		titles = make(map[string]bool)
+		res    = new(Result)
+	)
+
+	if doc, err = Parse(r); err != nil {
+		return nil, err
+	} else if known, err = db.FeedGetAll(); err != nil {
+		return nil, err
+	}
+
+	for _, f := range known {
+		urls[f.URL.String()] = true
+		titles[strings.ToLower(f.Title)] = true
+	}
+
+	feeds, res.Errors = doc.Feeds()
+
+	if err = db.Begin(); err != nil {
+		return nil, err
+	}
+
+	for _, f := range feeds {
+		var ustr = f.URL.String()
+
+		if urls[ustr] || titles[strings.ToLower(f.Title)] {
+			res.Duplicates = append(res.Duplicates, f)
+			continue
+		} else if err = db.FeedAdd(&f); err != nil {
+			res.Errors = append(res.Errors, err)
+			continue
+		}
+
+		urls[ustr] = true
+		titles[strings.ToLower(f.Title)] = true
+		res.Added = append(res.Added, f)
+	}
+
+	if err = db.Commit(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+} // func Import(db database.Store, r io.Reader) (*Result, error)
+
+// Export writes all Feeds in the database to the given Writer as an OPML
+// document.
+func Export(db database.Store, w io.Writer) error {
+	var (
+		err   error
+		feeds []model.Feed
+	)
+
+	if feeds, err = db.FeedGetAll(); err != nil {
+		return err
+	}
+
+	return FromFeeds(DefaultTitle(), feeds).Write(w)
+} // func Export(db database.Store, w io.Writer) error
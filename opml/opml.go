@@ -0,0 +1,237 @@
+// /home/krylon/go/src/github.com/blicero/badnews/opml/opml.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 12:58:40 krylon>
+
+// Package opml implements importing and exporting subscriptions in the
+// OPML 2.0 format.
+package opml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blicero/badnews/common"
+	"github.com/blicero/badnews/model"
+)
+
+// FolderSeparator separates the components of a Feed's Folder.
+const FolderSeparator = "/"
+
+// defaultInterval is used for Feeds that do not specify a refresh interval.
+const defaultInterval = time.Minute * 30
+
+// Document is an OPML document.
+type Document struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+// Head is the head of an OPML document.
+type Head struct {
+	Title       string `xml:"title"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+// Body is the body of an OPML document.
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+// Outline is a single node in an OPML document. Outlines that have an XMLURL
+// are Feeds, Outlines without one are folders containing other Outlines.
+// The refresh interval (in seconds) is stored in the custom attribute
+// refreshInterval.
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Interval string    `xml:"refreshInterval,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// Parse reads an OPML document.
+func Parse(r io.Reader) (*Document, error) {
+	var (
+		err error
+		doc = new(Document)
+		dec = xml.NewDecoder(r)
+	)
+
+	dec.CharsetReader = charsetReader
+
+	if err = dec.Decode(doc); err != nil {
+		return nil, fmt.Errorf("Cannot parse OPML document: %w", err)
+	}
+
+	return doc, nil
+} // func Parse(r io.Reader) (*Document, error)
+
+// Most OPML files out there are UTF-8, but some claim to be ISO-8859-1, which
+// is close enough for the attributes we care about.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8", "us-ascii", "ascii", "iso-8859-1", "latin1":
+		return input, nil
+	default:
+		return nil, fmt.Errorf("Unsupported charset %q", charset)
+	}
+} // func charsetReader(charset string, input io.Reader) (io.Reader, error)
+
+// Feeds returns all the Feeds found in the Document. Nested Outlines are
+// flattened, the titles of their enclosing folders become the Feed's Folder.
+// Outlines that cannot be turned into a Feed are returned as errors.
+func (d *Document) Feeds() ([]model.Feed, []error) {
+	var (
+		feeds = make([]model.Feed, 0, 32)
+		errs  []error
+	)
+
+	walk(d.Body.Outlines, nil, &feeds, &errs)
+
+	return feeds, errs
+} // func (d *Document) Feeds() ([]model.Feed, []error)
+
+func walk(outlines []Outline, folder []string, feeds *[]model.Feed, errs *[]error) {
+	for _, o := range outlines {
+		var title = o.Title
+
+		if title == "" {
+			title = o.Text
+		}
+
+		if o.XMLURL == "" {
+			walk(o.Outlines, append(folder, title), feeds, errs)
+			continue
+		}
+
+		var (
+			err error
+			f   = model.Feed{
+				Title:          title,
+				UpdateInterval: defaultInterval,
+				Active:         true,
+				Folder:         strings.Join(folder, FolderSeparator),
+			}
+		)
+
+		if f.URL, err = url.Parse(o.XMLURL); err != nil {
+			*errs = append(*errs, fmt.Errorf("Cannot parse URL of Feed %q: %w", title, err))
+			continue
+		} else if f.Title == "" {
+			f.Title = f.URL.Host
+		}
+
+		if o.HTMLURL == "" {
+			f.Homepage = &url.URL{Scheme: f.URL.Scheme, Host: f.URL.Host, Path: "/"}
+		} else if f.Homepage, err = f.URL.Parse(o.HTMLURL); err != nil {
+			*errs = append(*errs, fmt.Errorf("Cannot parse Homepage of Feed %q: %w", title, err))
+			continue
+		}
+
+		if o.Interval != "" {
+			var secs int64
+
+			if secs, err = strconv.ParseInt(o.Interval, 10, 64); err != nil || secs <= 0 {
+				*errs = append(*errs, fmt.Errorf("Invalid refresh interval %q for Feed %q", o.Interval, title))
+			} else {
+				f.UpdateInterval = time.Second * time.Duration(secs)
+			}
+		}
+
+		*feeds = append(*feeds, f)
+
+		// Some exporters nest Outlines below Feeds. Strange, but we
+		// might as well pick them up.
+		if len(o.Outlines) > 0 {
+			walk(o.Outlines, append(folder, title), feeds, errs)
+		}
+	}
+} // func walk(outlines []Outline, folder []string, feeds *[]model.Feed, errs *[]error)
+
+// FromFeeds creates a Document from a list of Feeds. Feeds that have a Folder
+// are nested inside Outlines for each component of the Folder.
+func FromFeeds(title string, feeds []model.Feed) *Document {
+	var doc = &Document{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: time.Now().Format(time.RFC1123Z),
+		},
+	}
+
+	for _, f := range feeds {
+		var (
+			list = &doc.Body.Outlines
+			o    = Outline{
+				Text:     f.Title,
+				Title:    f.Title,
+				Type:     "rss",
+				XMLURL:   f.URL.String(),
+				Interval: strconv.FormatInt(int64(f.UpdateInterval.Seconds()), 10),
+			}
+		)
+
+		if f.Homepage != nil {
+			o.HTMLURL = f.Homepage.String()
+		}
+
+		if f.Folder != "" {
+			for _, name := range strings.Split(f.Folder, FolderSeparator) {
+				list = folderOutline(list, name)
+			}
+		}
+
+		*list = append(*list, o)
+	}
+
+	return doc
+} // func FromFeeds(title string, feeds []model.Feed) *Document
+
+// folderOutline returns the list of children of the folder with the given
+// name in the given list, creating the folder if it does not exist, yet.
+func folderOutline(list *[]Outline, name string) *[]Outline {
+	for i := range *list {
+		if (*list)[i].XMLURL == "" && (*list)[i].Text == name {
+			return &(*list)[i].Outlines
+		}
+	}
+
+	*list = append(*list, Outline{Text: name, Title: name})
+	return &(*list)[len(*list)-1].Outlines
+} // func folderOutline(list *[]Outline, name string) *[]Outline
+
+// Write serializes the Document to the given Writer.
+func (d *Document) Write(w io.Writer) error {
+	var (
+		err error
+		enc = xml.NewEncoder(w)
+	)
+
+	enc.Indent("", "  ")
+
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return err
+	} else if err = enc.Encode(d); err != nil {
+		return err
+	} else if _, err = io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+
+	return nil
+} // func (d *Document) Write(w io.Writer) error
+
+// DefaultTitle returns the title we use for exported Documents.
+func DefaultTitle() string {
+	return fmt.Sprintf("%s subscriptions", common.AppName)
+} // func DefaultTitle() string
@@ -10,14 +10,17 @@ import (
 	"net/url"
 	"time"
 
-	"github.com/blicero/badnews/common"
-	"github.com/blicero/badnews/common/path"
 	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/database/memory"
 	"github.com/blicero/badnews/model"
 )
 
 var rdr *Reader
 
+// testDB holds the data of all Readers the tests create, the tests do not
+// care about the database itself.
+var testDB = memory.New()
+
 func purl(s string) *url.URL {
 	var (
 		err error
@@ -58,10 +61,10 @@ var testFeeds = []*model.Feed{
 func prepare() error {
 	var (
 		err error
-		db  *database.Database
+		db  database.Store
 	)
 
-	if db, err = database.Open(common.Path(path.Database)); err != nil {
+	if db, err = testDB.Open(); err != nil {
 		return err
 	}
 
@@ -6,12 +6,15 @@
 
 package reader
 
-import "testing"
+import (
+	"context"
+	"testing"
+)
 
 func TestReaderNew(t *testing.T) {
 	var err error
 
-	if rdr, err = New(2); err != nil {
+	if rdr, err = New(2, testDB.Open); err != nil {
 		rdr = nil
 		t.Fatalf("Error creating new Reader: %s",
 			err.Error())
@@ -34,7 +37,7 @@ func TestReaderProcessFeed(t *testing.T) {
 				f.URL)
 		}
 
-		if err = rdr.process(*f); err != nil {
+		if _, err = rdr.process(context.Background(), *f); err != nil {
 			t.Errorf("Failed to process feed %s (%d): %s",
 				f.Title,
 				f.ID,
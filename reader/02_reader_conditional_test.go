@@ -0,0 +1,85 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/02_reader_conditional_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 10:12:40 krylon>
+
+package reader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/model"
+)
+
+const testETag = `"badnews-test-etag"`
+
+func TestReaderConditionalFetch(t *testing.T) {
+	if rdr == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err              error
+		body             []byte
+		fullCnt, condCnt atomic.Int64
+		f                *model.Feed
+	)
+
+	if body, err = os.ReadFile("testdata/nachrichten-100.rss"); err != nil {
+		t.Fatalf("Cannot read test feed: %s", err.Error())
+	}
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == testETag {
+			condCnt.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		fullCnt.Add(1)
+		w.Header().Set("ETag", testETag)
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write(body) // nolint: errcheck
+	}))
+	defer srv.Close()
+
+	f = &model.Feed{
+		Title:          "Conditional Test Feed",
+		URL:            purl(srv.URL + "/feed.rss"),
+		Homepage:       purl(srv.URL),
+		UpdateInterval: time.Minute * 10,
+		Active:         true,
+	}
+
+	var db = rdr.pool.Get()
+	defer rdr.pool.Put(db)
+
+	if err = db.FeedAdd(f); err != nil {
+		t.Fatalf("Cannot add Feed %s: %s", f.Title, err.Error())
+	} else if _, err = rdr.process(context.Background(), *f); err != nil {
+		t.Fatalf("Error processing Feed %s: %s", f.Title, err.Error())
+	} else if f, err = db.FeedGetByID(f.ID); err != nil {
+		t.Fatalf("Cannot reload Feed: %s", err.Error())
+	} else if f.ETag != testETag {
+		t.Errorf("Unexpected ETag: %q (expected %q)", f.ETag, testETag)
+	} else if f.LastStatus != http.StatusOK {
+		t.Errorf("Unexpected status after first fetch: %d", f.LastStatus)
+	} else if _, err = rdr.process(context.Background(), *f); err != nil {
+		t.Fatalf("Error processing Feed %s again: %s", f.Title, err.Error())
+	} else if f, err = db.FeedGetByID(f.ID); err != nil {
+		t.Fatalf("Cannot reload Feed: %s", err.Error())
+	} else if f.LastStatus != http.StatusNotModified {
+		t.Errorf("Unexpected status after second fetch: %d", f.LastStatus)
+	} else if fullCnt.Load() != 1 || condCnt.Load() != 1 {
+		t.Errorf("Expected one full and one conditional fetch, got %d / %d",
+			fullCnt.Load(),
+			condCnt.Load())
+	}
+} // func TestReaderConditionalFetch(t *testing.T)
@@ -0,0 +1,171 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/03_reader_failure_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 11:02:19 krylon>
+
+package reader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/model"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	type testCase struct {
+		cnt   int
+		delay time.Duration
+	}
+
+	var cases = []testCase{
+		{cnt: 1, delay: backoffBase},
+		{cnt: 2, delay: backoffBase * 2},
+		{cnt: 4, delay: backoffBase * 8},
+		{cnt: 64, delay: backoffMax},
+	}
+
+	for _, c := range cases {
+		var d = backoffDelay(c.cnt)
+		if d != c.delay {
+			t.Errorf("Unexpected delay for %d failures: %s (expected %s)",
+				c.cnt,
+				d,
+				c.delay)
+		}
+	}
+} // func TestBackoffDelay(t *testing.T)
+
+func TestReaderRecordFailure(t *testing.T) {
+	if rdr == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err error
+		f   *model.Feed
+		srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+	)
+	defer srv.Close()
+
+	f = &model.Feed{
+		Title:          "Broken Test Feed",
+		URL:            purl(srv.URL + "/feed.rss"),
+		Homepage:       purl(srv.URL),
+		UpdateInterval: time.Minute * 10,
+		Active:         true,
+	}
+
+	var db = rdr.pool.Get()
+	defer rdr.pool.Put(db)
+
+	rdr.SetMaxFailures(2)
+	defer rdr.SetMaxFailures(DefaultMaxFailures)
+
+	if err = db.FeedAdd(f); err != nil {
+		t.Fatalf("Cannot add Feed %s: %s", f.Title, err.Error())
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err = rdr.process(context.Background(), *f); err == nil {
+			t.Fatalf("Processing Feed %s should have failed", f.Title)
+		}
+
+		rdr.recordFailure(f, err)
+
+		if f, err = db.FeedGetByID(f.ID); err != nil {
+			t.Fatalf("Cannot reload Feed: %s", err.Error())
+		} else if f.Failures != i+1 {
+			t.Errorf("Unexpected failure count: %d (expected %d)",
+				f.Failures,
+				i+1)
+		} else if f.LastError == "" {
+			t.Error("Feed should have an error message")
+		} else if !f.NextAttempt.After(time.Now()) {
+			t.Errorf("Next attempt should be in the future: %s",
+				f.NextAttempt)
+		}
+	}
+
+	if f.Active {
+		t.Errorf("Feed %s should have been disabled after %d failures",
+			f.Title,
+			f.Failures)
+	}
+
+	rdr.resetFailures(f)
+
+	if f, err = db.FeedGetByID(f.ID); err != nil {
+		t.Fatalf("Cannot reload Feed: %s", err.Error())
+	} else if f.Failures != 0 || f.LastError != "" {
+		t.Errorf("Failure state was not reset: %d / %q",
+			f.Failures,
+			f.LastError)
+	}
+} // func TestReaderRecordFailure(t *testing.T)
+
+func TestReaderBrokenBody(t *testing.T) {
+	if rdr == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err   error
+		f     *model.Feed
+		feeds = make(map[string]*model.Feed)
+		srv   = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/rss+xml")
+
+			switch r.URL.Path {
+			case "/huge.rss":
+				w.Write(bytes.Repeat([]byte(" "), maxFeedSize+1)) // nolint: errcheck
+			case "/short.rss":
+				// We promise more than we deliver, so reading
+				// the body fails.
+				w.Header().Set("Content-Length", "1024")
+				w.Write([]byte("<rss>")) // nolint: errcheck
+			}
+		}))
+	)
+	defer srv.Close()
+
+	var db = rdr.pool.Get()
+	defer rdr.pool.Put(db)
+
+	for _, name := range []string{"huge", "short"} {
+		f = &model.Feed{
+			Title:          "Broken Body " + name,
+			URL:            purl(srv.URL + "/" + name + ".rss"),
+			Homepage:       purl(srv.URL),
+			UpdateInterval: time.Minute * 10,
+			Active:         true,
+		}
+
+		if err = db.FeedAdd(f); err != nil {
+			t.Fatalf("Cannot add Feed %s: %s", f.Title, err.Error())
+		}
+
+		feeds[name] = f
+	}
+
+	if _, err = rdr.process(context.Background(), *feeds["huge"]); !errors.Is(err, ErrFeedTooLarge) {
+		t.Errorf("Expected ErrFeedTooLarge, got %v", err)
+	}
+
+	if _, err = rdr.process(context.Background(), *feeds["short"]); err == nil {
+		t.Error("Processing a Feed with a truncated body should fail")
+	} else if f, err = db.FeedGetByID(feeds["short"].ID); err != nil {
+		t.Fatalf("Cannot reload Feed: %s", err.Error())
+	} else if f.LastStatus != 0 {
+		t.Errorf("A failure to read the body should not be stored as HTTP status %d",
+			f.LastStatus)
+	}
+} // func TestReaderBrokenBody(t *testing.T)
@@ -0,0 +1,128 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/04_reader_discover_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 12:10:33 krylon>
+
+package reader
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+const discoverPage = `<!DOCTYPE html>
+<html>
+  <head>
+    <title>Test Site</title>
+    <link rel="alternate" type="application/rss+xml" title="All News" href="/news.rss" />
+    <link rel="alternate" type="application/atom+xml" href="https://example.org/atom" />
+    <link rel="stylesheet" type="text/css" href="/style.css" />
+  </head>
+  <body><p>Hello</p></body>
+</html>
+`
+
+const relativeFeed = `<?xml version="1.0" encoding="utf-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Relative</title>
+    <link>/blog/</link>
+    <description>A Feed with a relative link</description>
+  </channel>
+</rss>
+`
+
+func TestDiscover(t *testing.T) {
+	var (
+		err        error
+		body       []byte
+		candidates []Candidate
+	)
+
+	if body, err = os.ReadFile("testdata/nachrichten-100.rss"); err != nil {
+		t.Fatalf("Cannot read test feed: %s", err.Error())
+	}
+
+	var mux = http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(discoverPage)) // nolint: errcheck
+	})
+	mux.HandleFunc("/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write(body) // nolint: errcheck
+	})
+	mux.HandleFunc("/relative.rss", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(relativeFeed)) // nolint: errcheck
+	})
+
+	var srv = httptest.NewServer(mux)
+	defer srv.Close()
+
+	if candidates, err = Discover(srv.URL + "/"); err != nil {
+		t.Fatalf("Discover failed: %s", err.Error())
+	} else if len(candidates) != 3 {
+		t.Fatalf("Expected 3 candidates, got %d: %v",
+			len(candidates),
+			candidates)
+	}
+
+	type expect struct {
+		title string
+		url   string
+	}
+
+	var expected = []expect{
+		{title: "All News", url: srv.URL + "/news.rss"},
+		{title: "Test Site", url: "https://example.org/atom"},
+		{
+			title: "Deutschlandfunk - Fortlaufende Nachrichten vom 25. September 2024",
+			url:   srv.URL + "/feed.xml",
+		},
+	}
+
+	for i, e := range expected {
+		var c = candidates[i]
+		if c.Title != e.title {
+			t.Errorf("Candidate %d: unexpected title %q (expected %q)",
+				i,
+				c.Title,
+				e.title)
+		} else if c.URL.String() != e.url {
+			t.Errorf("Candidate %d: unexpected URL %q (expected %q)",
+				i,
+				c.URL,
+				e.url)
+		} else if f := c.Feed(); f.Title != c.Title || f.URL != c.URL || f.Homepage == nil {
+			t.Errorf("Candidate %d: Feed was not prefilled correctly: %s",
+				i,
+				f)
+		}
+	}
+
+	// If the URL points directly at a Feed, we get only that one Feed.
+	if candidates, err = Discover(srv.URL + "/feed.xml"); err != nil {
+		t.Fatalf("Discover failed on Feed URL: %s", err.Error())
+	} else if len(candidates) != 1 {
+		t.Errorf("Expected 1 candidate for direct Feed URL, got %d",
+			len(candidates))
+	}
+
+	// A relative link in the Feed is resolved against the Feed's URL.
+	if candidates, err = Discover(srv.URL + "/relative.rss"); err != nil {
+		t.Fatalf("Discover failed on Feed URL: %s", err.Error())
+	} else if len(candidates) != 1 {
+		t.Fatalf("Expected 1 candidate for direct Feed URL, got %d",
+			len(candidates))
+	} else if h := candidates[0].Homepage.String(); h != srv.URL+"/blog/" {
+		t.Errorf("Unexpected Homepage %s (expected %s/blog/)", h, srv.URL)
+	}
+} // func TestDiscover(t *testing.T)
@@ -0,0 +1,61 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/05_reader_extract_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 15:02:44 krylon>
+
+package reader
+
+import (
+	"strings"
+	"testing"
+)
+
+const articlePage = `<!DOCTYPE html>
+<html>
+  <head><title>Big News</title></head>
+  <body>
+    <nav><a href="/">Home</a> <a href="/world">World</a></nav>
+    <div class="sidebar">
+      <p>Subscribe to our newsletter, it is great, really, you will love it.</p>
+    </div>
+    <div class="article-body" id="content">
+      <h1>Something happened</h1>
+      <p>Early this morning, something happened in a place not far from here, witnesses say.</p>
+      <p>Officials, who asked not to be named, confirmed the event, but declined to comment further.</p>
+      <p>More details are expected later today, according to a spokesperson.</p>
+    </div>
+    <div class="comments">
+      <p>First! This is a comment that is long enough to be scored, sadly.</p>
+    </div>
+    <footer><p>Copyright 2026 Example Newspaper, all rights reserved.</p></footer>
+  </body>
+</html>
+`
+
+func TestExtract(t *testing.T) {
+	var (
+		err  error
+		text string
+	)
+
+	if text, err = Extract(strings.NewReader(articlePage)); err != nil {
+		t.Fatalf("Failed to extract article: %s", err.Error())
+	}
+
+	for _, s := range []string{"Early this morning", "Officials", "spokesperson"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("Extracted text does not contain %q:\n%s", s, text)
+		}
+	}
+
+	for _, s := range []string{"newsletter", "First!", "Copyright"} {
+		if strings.Contains(text, s) {
+			t.Errorf("Extracted text should not contain %q:\n%s", s, text)
+		}
+	}
+
+	if _, err = Extract(strings.NewReader("<html><body></body></html>")); err != ErrNoContent {
+		t.Errorf("Expected ErrNoContent for empty document, got %v", err)
+	}
+} // func TestExtract(t *testing.T)
@@ -0,0 +1,99 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/06_reader_client_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 18:21:47 krylon>
+
+package reader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/model"
+)
+
+func TestConfigureInvalidProxy(t *testing.T) {
+	if err := Configure(ClientConfig{Proxy: "not a proxy"}); err == nil {
+		t.Error("Configure should reject invalid proxy URL")
+	}
+} // func TestConfigureInvalidProxy(t *testing.T)
+
+// TestReaderFeedSettings sends a request through a "proxy" that checks the
+// request carries our User-Agent as well as the Feed's headers and
+// credentials.
+func TestReaderFeedSettings(t *testing.T) {
+	if rdr == nil {
+		t.SkipNow()
+	}
+
+	const userAgent = "badnews-test/1.0"
+
+	var (
+		err  error
+		body []byte
+		hits atomic.Int64
+		f    *model.Feed
+	)
+
+	if body, err = os.ReadFile("testdata/nachrichten-100.rss"); err != nil {
+		t.Fatalf("Cannot read test feed: %s", err.Error())
+	}
+
+	var proxy = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var user, pass, ok = r.BasicAuth()
+
+		if r.URL.Host != "feeds.example.invalid" {
+			t.Errorf("Unexpected host in proxied request: %q", r.URL.Host)
+		} else if r.UserAgent() != userAgent {
+			t.Errorf("Unexpected User-Agent: %q", r.UserAgent())
+		} else if r.Header.Get("X-Api-Key") != "4711" {
+			t.Errorf("Custom header is missing: %v", r.Header)
+		} else if !ok || user != "krylon" || pass != "hunter2" {
+			t.Errorf("Unexpected credentials: %q / %q", user, pass)
+		}
+
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write(body) // nolint: errcheck
+	}))
+	defer proxy.Close()
+
+	if err = Configure(ClientConfig{UserAgent: userAgent, Proxy: proxy.URL}); err != nil {
+		t.Fatalf("Failed to configure HTTP client: %s", err.Error())
+	}
+
+	defer Configure(ClientConfig{}) // nolint: errcheck
+
+	f = &model.Feed{
+		Title:          "Proxied Test Feed",
+		URL:            purl("http://feeds.example.invalid/feed.rss"),
+		Homepage:       purl("http://feeds.example.invalid/"),
+		UpdateInterval: time.Minute * 10,
+		Active:         true,
+	}
+
+	var db = rdr.pool.Get()
+	defer rdr.pool.Put(db)
+
+	if err = db.FeedAdd(f); err != nil {
+		t.Fatalf("Cannot add Feed %s: %s", f.Title, err.Error())
+	} else if err = db.FeedSetHTTPSettings(
+		f,
+		map[string]string{"X-Api-Key": "4711"},
+		model.Credentials{Kind: model.AuthBasic, Username: "krylon", Secret: "hunter2"},
+	); err != nil {
+		t.Fatalf("Cannot set HTTP settings of Feed %s: %s", f.Title, err.Error())
+	} else if f, err = db.FeedGetByID(f.ID); err != nil {
+		t.Fatalf("Cannot reload Feed: %s", err.Error())
+	} else if _, err = rdr.process(context.Background(), *f); err != nil {
+		t.Fatalf("Error processing Feed %s: %s", f.Title, err.Error())
+	} else if hits.Load() != 1 {
+		t.Errorf("Expected 1 request via proxy, got %d", hits.Load())
+	}
+} // func TestReaderFeedSettings(t *testing.T)
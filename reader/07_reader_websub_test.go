@@ -0,0 +1,283 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/07_reader_websub_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 19:48:03 krylon>
+
+package reader
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/model"
+)
+
+const atomTemplate = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>WebSub Test Feed</title>
+  <link rel="hub" href="%s"/>
+  <link rel="self" href="%s"/>
+  <updated>2026-10-17T12:00:00Z</updated>
+  <id>urn:badnews:websub-test</id>
+  <entry>
+    <title>%s</title>
+    <link href="%s"/>
+    <id>%s</id>
+    <updated>2026-10-17T12:00:00Z</updated>
+    <summary>Nothing to see here.</summary>
+  </entry>
+</feed>
+`
+
+func TestDiscoverHub(t *testing.T) {
+	type testCase struct {
+		header http.Header
+		body   string
+		hub    string
+		self   string
+	}
+
+	var cases = []testCase{
+		{
+			header: http.Header{"Link": []string{
+				`<https://hub.example.com/>; rel="hub", <https://example.com/feed>; rel="self"`,
+			}},
+			hub:  "https://hub.example.com/",
+			self: "https://example.com/feed",
+		},
+		{
+			body: fmt.Sprintf(atomTemplate,
+				"https://hub.example.com/",
+				"https://example.com/feed.atom",
+				"Headline",
+				"https://example.com/1",
+				"urn:1"),
+			hub:  "https://hub.example.com/",
+			self: "https://example.com/feed.atom",
+		},
+		{
+			body: `<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom"><channel>
+<title>RSS</title><link>https://example.com/</link>
+<atom:link rel="hub" href="https://hub.example.com/"/>
+</channel></rss>`,
+			hub: "https://hub.example.com/",
+		},
+		{
+			body: `<rss version="2.0"><channel><title>RSS</title><link>https://example.com/</link>
+<item><title>Item</title><link>https://example.com/1</link></item></channel></rss>`,
+		},
+	}
+
+	for idx, c := range cases {
+		var hub, self = discoverHub(c.header, []byte(c.body))
+
+		if hub != c.hub || self != c.self {
+			t.Errorf("Test case %02d: expected %q / %q, got %q / %q",
+				idx,
+				c.hub,
+				c.self,
+				hub,
+				self)
+		}
+	}
+} // func TestDiscoverHub(t *testing.T)
+
+func sign(secret string, body []byte) string {
+	var mac = hmac.New(sha256.New, []byte(secret))
+	mac.Write(body) // nolint: errcheck
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+} // func sign(secret string, body []byte) string
+
+func TestReaderWebSub(t *testing.T) {
+	if rdr == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err     error
+		lock    sync.Mutex
+		form    url.Values
+		f       *model.Feed
+		sub     *model.Subscription
+		ok      bool
+		pending []model.Feed
+		items   []*model.Item
+		srv     *httptest.Server
+		body    []byte
+	)
+
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/hub":
+			lock.Lock()
+			defer lock.Unlock()
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("Hub cannot parse form: %s", err.Error())
+			}
+			form = r.PostForm
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			w.Header().Set("Content-Type", "application/atom+xml")
+			fmt.Fprintf(w, atomTemplate,
+				srv.URL+"/hub",
+				srv.URL+"/feed.atom",
+				"Polled Item",
+				srv.URL+"/item/1",
+				"urn:badnews:item:1")
+		}
+	}))
+	defer srv.Close()
+
+	rdr.SetCallback(purl("https://badnews.example.invalid/"))
+	defer rdr.SetCallback(nil)
+
+	f = &model.Feed{
+		Title:          "WebSub Test Feed",
+		URL:            purl(srv.URL + "/feed.atom"),
+		Homepage:       purl(srv.URL),
+		UpdateInterval: time.Minute * 10,
+		Active:         true,
+	}
+
+	var db = rdr.pool.Get()
+	defer rdr.pool.Put(db)
+
+	if err = db.FeedAdd(f); err != nil {
+		t.Fatalf("Cannot add Feed %s: %s", f.Title, err.Error())
+	} else if _, err = rdr.process(context.Background(), *f); err != nil {
+		t.Fatalf("Error processing Feed %s: %s", f.Title, err.Error())
+	}
+
+	lock.Lock()
+	if form == nil {
+		t.Fatal("Reader did not subscribe to hub")
+	} else if cb := form.Get("hub.callback"); cb != fmt.Sprintf("https://badnews.example.invalid/websub/%d", f.ID) {
+		t.Errorf("Unexpected callback URL %q", cb)
+	} else if form.Get("hub.topic") != f.URL.String() {
+		t.Errorf("Unexpected topic %q", form.Get("hub.topic"))
+	} else if form.Get("hub.secret") == "" {
+		t.Error("Reader did not send a secret")
+	}
+	lock.Unlock()
+
+	if sub, err = db.WebSubGetByFeed(f); err != nil {
+		t.Fatalf("Cannot load Subscription: %s", err.Error())
+	} else if sub == nil {
+		t.Fatal("Subscription was not saved")
+	} else if sub.State != model.SubPending {
+		t.Errorf("Unexpected state of new Subscription: %s", sub.State)
+	} else if sub.Secret != form.Get("hub.secret") {
+		t.Errorf("Secret was not saved correctly: %q", sub.Secret)
+	}
+
+	if ok, err = rdr.Verify(f.ID, "subscribe", "https://example.com/other", 3600); err != nil {
+		t.Fatalf("Error verifying Subscription: %s", err.Error())
+	} else if ok {
+		t.Error("Reader confirmed Subscription for wrong topic")
+	} else if ok, err = rdr.Verify(f.ID, "subscribe", sub.Topic.String(), 3600); err != nil {
+		t.Fatalf("Error verifying Subscription: %s", err.Error())
+	} else if !ok {
+		t.Error("Reader did not confirm Subscription")
+	} else if err = db.WebSubSetState(sub, model.SubDenied); err != nil {
+		t.Fatalf("Cannot set state of Subscription: %s", err.Error())
+	} else if ok, err = rdr.Verify(f.ID, "subscribe", sub.Topic.String(), 3600); err != nil {
+		t.Fatalf("Error verifying Subscription: %s", err.Error())
+	} else if ok {
+		t.Error("Reader confirmed Subscription the hub had denied")
+	} else if sub, err = db.WebSubGetByFeed(f); err != nil {
+		t.Fatalf("Cannot load Subscription: %s", err.Error())
+	} else if sub.State != model.SubDenied {
+		t.Errorf("Denied Subscription was reactivated, state is %s", sub.State)
+	} else if err = db.WebSubSetActive(sub, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Cannot activate Subscription: %s", err.Error())
+	}
+
+	// While the Subscription is active, the Feed does not get polled.
+	if err = db.FeedUpdateRefresh(f, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("Cannot update Feed: %s", err.Error())
+	} else if err = db.FeedSetSchedule(f, model.Schedule{Next: time.Now().Add(-time.Minute)}); err != nil {
+		t.Fatalf("Cannot update schedule of Feed: %s", err.Error())
+	} else if pending, err = db.FeedGetPending(); err != nil {
+		t.Fatalf("Cannot load pending Feeds: %s", err.Error())
+	}
+
+	for _, p := range pending {
+		if p.ID == f.ID {
+			t.Errorf("Feed %s is polled despite active Subscription", f.Title)
+		}
+	}
+
+	body = []byte(fmt.Sprintf(atomTemplate,
+		srv.URL+"/hub",
+		srv.URL+"/feed.atom",
+		"Pushed Item",
+		srv.URL+"/item/2",
+		"urn:badnews:item:2"))
+
+	if err = rdr.Push(f.ID, bytes.NewReader(body), sign("wrong secret", body)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Push with invalid signature should fail, got %v", err)
+	} else if err = rdr.Push(f.ID+1000, bytes.NewReader(body), sign(sub.Secret, body)); !errors.Is(err, ErrUnknownSubscription) {
+		t.Errorf("Push for unknown Feed should fail, got %v", err)
+	} else if err = rdr.Push(f.ID, bytes.NewReader(body), sign(sub.Secret, body)); err != nil {
+		t.Fatalf("Failed to process pushed update: %s", err.Error())
+	} else if items, err = db.ItemGetByFeed(f, 10, 0, model.ItemFilter{}); err != nil {
+		t.Fatalf("Cannot load Items of Feed: %s", err.Error())
+	} else if len(items) != 2 {
+		t.Errorf("Expected 2 Items, got %d", len(items))
+	}
+
+	// We only confirm an unsubscribe we have asked for.
+	if ok, err = rdr.Verify(f.ID+1000, "unsubscribe", sub.Topic.String(), 0); err != nil {
+		t.Fatalf("Error verifying unsubscribe: %s", err.Error())
+	} else if ok {
+		t.Error("Reader confirmed unsubscribe from unknown Feed")
+	} else if ok, err = rdr.Verify(f.ID, "unsubscribe", sub.Topic.String(), 0); err != nil {
+		t.Fatalf("Error verifying unsubscribe: %s", err.Error())
+	} else if ok {
+		t.Error("Reader confirmed unsubscribe it did not ask for")
+	} else if err = db.FeedSetActive(f, false); err != nil {
+		t.Fatalf("Cannot deactivate Feed %s: %s", f.Title, err.Error())
+	} else if err = rdr.unsubscribe(db, f, sub); err != nil {
+		t.Fatalf("Failed to unsubscribe: %s", err.Error())
+	}
+
+	if ok, err = rdr.Verify(f.ID, "subscribe", sub.Topic.String(), 3600); err != nil {
+		t.Fatalf("Error verifying Subscription: %s", err.Error())
+	} else if ok {
+		t.Error("Reader confirmed Subscription it is cancelling")
+	}
+
+	lock.Lock()
+	if form.Get("hub.mode") != "unsubscribe" {
+		t.Errorf("Unexpected mode %q", form.Get("hub.mode"))
+	} else if form.Get("hub.topic") != sub.Topic.String() {
+		t.Errorf("Unexpected topic %q", form.Get("hub.topic"))
+	}
+	lock.Unlock()
+
+	if ok, err = rdr.Verify(f.ID, "unsubscribe", "https://example.com/other", 0); err != nil {
+		t.Fatalf("Error verifying unsubscribe: %s", err.Error())
+	} else if ok {
+		t.Error("Reader confirmed unsubscribe for wrong topic")
+	} else if ok, err = rdr.Verify(f.ID, "unsubscribe", sub.Topic.String(), 0); err != nil {
+		t.Fatalf("Error verifying unsubscribe: %s", err.Error())
+	} else if !ok {
+		t.Error("Reader did not confirm unsubscribe")
+	} else if sub, err = db.WebSubGetByFeed(f); err != nil {
+		t.Fatalf("Cannot load Subscription: %s", err.Error())
+	} else if sub != nil {
+		t.Errorf("Subscription was not deleted, state is %s", sub.State)
+	}
+} // func TestReaderWebSub(t *testing.T)
@@ -0,0 +1,202 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/08_reader_schedule_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 20:58:22 krylon>
+
+package reader
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/model"
+	"github.com/mmcdole/gofeed"
+)
+
+const scheduleRSS = `<?xml version="1.0"?>
+<rss version="2.0">
+  <channel>
+    <title>Schedule Test</title>
+    <link>https://example.com/</link>
+    <ttl>90</ttl>
+    <skipHours><hour>0</hour><hour>1</hour><hour>24</hour></skipHours>
+    <skipDays><day>Sunday</day><day>Saturday</day></skipDays>
+    <item>
+      <title>Item</title>
+      <link>https://example.com/1</link>
+      <ttl>5</ttl>
+    </item>
+  </channel>
+</rss>
+`
+
+func TestScanSchedule(t *testing.T) {
+	var ttl, hours, days = scanSchedule([]byte(scheduleRSS))
+
+	if ttl != time.Minute*90 {
+		t.Errorf("Unexpected TTL: %s", ttl)
+	}
+
+	if hours != 0b11 {
+		t.Errorf("Unexpected skipHours: %b", hours)
+	}
+
+	if days != 1<<uint(time.Sunday)|1<<uint(time.Saturday) {
+		t.Errorf("Unexpected skipDays: %b", days)
+	}
+} // func TestScanSchedule(t *testing.T)
+
+func TestMaxAge(t *testing.T) {
+	type testCase struct {
+		header string
+		age    time.Duration
+	}
+
+	var cases = []testCase{
+		{"", 0},
+		{"no-cache", 0},
+		{"public, max-age=3600", time.Hour},
+		{"s-maxage=60, max-age=\"120\"", time.Minute * 2},
+		{"max-age=0", 0},
+	}
+
+	for _, c := range cases {
+		var h = http.Header{}
+
+		h.Set("Cache-Control", c.header)
+
+		if age := maxAge(h); age != c.age {
+			t.Errorf("Unexpected max-age for %q: %s (expected %s)",
+				c.header,
+				age,
+				c.age)
+		}
+	}
+} // func TestMaxAge(t *testing.T)
+
+func TestMeasureCadence(t *testing.T) {
+	var (
+		feed  = &gofeed.Feed{}
+		start = time.Now().Add(-time.Hour * 24)
+	)
+
+	for _, offset := range []time.Duration{0, time.Hour, time.Hour * 2, time.Hour * 3, time.Hour * 8} {
+		var stamp = start.Add(offset)
+		feed.Items = append(feed.Items, &gofeed.Item{PublishedParsed: &stamp})
+	}
+
+	if cadence := measureCadence(feed); cadence != time.Hour {
+		t.Errorf("Unexpected cadence: %s (expected %s)", cadence, time.Hour)
+	}
+
+	feed.Items = feed.Items[:2]
+
+	if cadence := measureCadence(feed); cadence != 0 {
+		t.Errorf("Cadence from 2 Items should be 0, got %s", cadence)
+	}
+} // func TestMeasureCadence(t *testing.T)
+
+func TestNextRefresh(t *testing.T) {
+	type testCase struct {
+		feed     model.Feed
+		cacheAge time.Duration
+		interval time.Duration
+	}
+
+	var (
+		now   = time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC) // a Wednesday
+		cases = []testCase{
+			// Without any other information, we use the UpdateInterval.
+			{
+				feed:     model.Feed{UpdateInterval: time.Hour},
+				interval: time.Hour,
+			},
+			// A Feed that publishes every 20 minutes is not checked more
+			// often than its UpdateInterval...
+			{
+				feed: model.Feed{
+					UpdateInterval: time.Hour,
+					Schedule:       model.Schedule{Cadence: time.Minute * 20},
+				},
+				interval: time.Hour,
+			},
+			// ... unless the UpdateInterval is an upper bound.
+			{
+				feed: model.Feed{
+					UpdateInterval: time.Hour,
+					Bound:          model.BoundUpper,
+					Schedule:       model.Schedule{Cadence: time.Minute * 20},
+				},
+				interval: time.Minute * 10,
+			},
+			// A Feed that publishes twice a day is checked less often.
+			{
+				feed: model.Feed{
+					UpdateInterval: time.Hour,
+					Schedule:       model.Schedule{Cadence: time.Hour * 12},
+				},
+				interval: time.Hour * 6,
+			},
+			{
+				feed: model.Feed{
+					UpdateInterval: time.Hour,
+					Bound:          model.BoundUpper,
+					Schedule:       model.Schedule{Cadence: time.Hour * 12},
+				},
+				interval: time.Hour,
+			},
+			// TTL and Cache-Control are honored.
+			{
+				feed: model.Feed{
+					UpdateInterval: time.Minute * 30,
+					Schedule:       model.Schedule{TTL: time.Hour * 2},
+				},
+				interval: time.Hour * 2,
+			},
+			{
+				feed:     model.Feed{UpdateInterval: time.Minute * 30},
+				cacheAge: time.Hour * 3,
+				interval: time.Hour * 3,
+			},
+		}
+	)
+
+	for idx, c := range cases {
+		var (
+			next     = nextRefresh(&c.feed, c.cacheAge, now)
+			interval = next.Sub(now)
+			slack    = time.Duration(float64(c.interval) * jitterFactor)
+		)
+
+		if interval < c.interval-slack || interval > c.interval+slack {
+			t.Errorf("Test case %02d: Unexpected interval %s (expected %s +/- %s)",
+				idx,
+				interval,
+				c.interval,
+				slack)
+		}
+	}
+
+	// The Feed asks not to be checked from 12:00 to 17:59 UTC, so the
+	// next check has to wait until 18:00.
+	var f = model.Feed{
+		UpdateInterval: time.Hour,
+		Schedule:       model.Schedule{SkipHours: 0b111111 << 12},
+	}
+
+	if next := nextRefresh(&f, 0, now); !next.Equal(now.Add(time.Hour * 6)) {
+		t.Errorf("Feed should be skipped until 18:00 UTC, next refresh is at %s",
+			next.UTC())
+	}
+
+	// The same with skipDays: Thursday is skipped entirely.
+	f.Schedule = model.Schedule{SkipDays: 1 << uint(time.Thursday)}
+	f.UpdateInterval = time.Hour * 24
+
+	if next := nextRefresh(&f, 0, now); next.UTC().Weekday() != time.Friday {
+		t.Errorf("Feed should be skipped on Thursday, next refresh is at %s",
+			next.UTC())
+	}
+} // func TestNextRefresh(t *testing.T)
@@ -0,0 +1,267 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/09_reader_ingest_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 21:34:50 krylon>
+
+package reader
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/model"
+	"github.com/mmcdole/gofeed"
+)
+
+const (
+	benchWorkers  = 4
+	benchItemsPer = 25
+)
+
+var benchSeq atomic.Int64
+
+// benchFeed returns a parsed Feed with benchItemsPer Items we have not seen
+// before.
+func benchFeed(worker int) *gofeed.Feed {
+	var (
+		feed  = &gofeed.Feed{Title: fmt.Sprintf("Benchmark Feed %d", worker)}
+		stamp = time.Now()
+	)
+
+	for i := 0; i < benchItemsPer; i++ {
+		var n = benchSeq.Add(1)
+
+		feed.Items = append(feed.Items, &gofeed.Item{
+			Title:           fmt.Sprintf("Item %d", n),
+			Description:     "Lorem ipsum dolor sit amet",
+			Link:            fmt.Sprintf("https://bench.example.com/%d/item/%d", worker, n),
+			GUID:            fmt.Sprintf("urn:bench:%d", n),
+			PublishedParsed: &stamp,
+		})
+	}
+
+	return feed
+} // func benchFeed(worker int) *gofeed.Feed
+
+// writeStats counts the write transactions the Stores of a benchmark have
+// run, and how much time they spent in them.
+type writeStats struct {
+	tx   atomic.Int64
+	held atomic.Int64
+}
+
+// countingStore wraps a Store and records in its writeStats each transaction
+// that changes the database. Outside an explicit transaction, every write is
+// a transaction of its own.
+type countingStore struct {
+	database.Store
+	stats *writeStats
+	begin time.Time
+}
+
+func (c *countingStore) write(fn func() error) error {
+	if !c.begin.IsZero() {
+		return fn()
+	}
+
+	var start = time.Now()
+	defer func() {
+		c.stats.tx.Add(1)
+		c.stats.held.Add(int64(time.Since(start)))
+	}()
+
+	return fn()
+} // func (c *countingStore) write(fn func() error) error
+
+func (c *countingStore) finish() {
+	c.stats.tx.Add(1)
+	c.stats.held.Add(int64(time.Since(c.begin)))
+	c.begin = time.Time{}
+} // func (c *countingStore) finish()
+
+func (c *countingStore) Begin() error {
+	var err error
+
+	if err = c.Store.Begin(); err == nil {
+		c.begin = time.Now()
+	}
+
+	return err
+} // func (c *countingStore) Begin() error
+
+func (c *countingStore) Commit() error {
+	defer c.finish()
+	return c.Store.Commit()
+} // func (c *countingStore) Commit() error
+
+func (c *countingStore) Rollback() error {
+	defer c.finish()
+	return c.Store.Rollback()
+} // func (c *countingStore) Rollback() error
+
+func (c *countingStore) ItemAdd(i *model.Item) error {
+	return c.write(func() error { return c.Store.ItemAdd(i) })
+} // func (c *countingStore) ItemAdd(i *model.Item) error
+
+func (c *countingStore) ItemUpsert(i *model.Item) (bool, error) {
+	var ok bool
+
+	var err = c.write(func() error {
+		var err error
+		ok, err = c.Store.ItemUpsert(i)
+		return err
+	})
+
+	return ok, err
+} // func (c *countingStore) ItemUpsert(i *model.Item) (bool, error)
+
+func (c *countingStore) ItemUpdate(i *model.Item, headline, description string) error {
+	return c.write(func() error { return c.Store.ItemUpdate(i, headline, description) })
+} // func (c *countingStore) ItemUpdate(i *model.Item, headline, description string) error
+
+func (c *countingStore) ItemRevisionAdd(i *model.Item, stamp time.Time) error {
+	return c.write(func() error { return c.Store.ItemRevisionAdd(i, stamp) })
+} // func (c *countingStore) ItemRevisionAdd(i *model.Item, stamp time.Time) error
+
+func (c *countingStore) EnclosureAdd(e *model.Enclosure) error {
+	return c.write(func() error { return c.Store.EnclosureAdd(e) })
+} // func (c *countingStore) EnclosureAdd(e *model.Enclosure) error
+
+func (c *countingStore) TagLinkAddAuto(i *model.Item, t *model.Tag) error {
+	return c.write(func() error { return c.Store.TagLinkAddAuto(i, t) })
+} // func (c *countingStore) TagLinkAddAuto(i *model.Item, t *model.Tag) error
+
+// ingestPerItem adds Items the way the Reader used to, one ad-hoc
+// transaction per Item.
+func ingestPerItem(db database.Store, f *model.Feed, feed *gofeed.Feed) error {
+	for _, fitem := range feed.Items {
+		var (
+			err      error
+			existing *model.Item
+			item     = &model.Item{
+				FeedID:      f.ID,
+				Headline:    fitem.Title,
+				Description: fitem.Description,
+				GUID:        fitem.GUID,
+				Timestamp:   *fitem.PublishedParsed,
+			}
+		)
+
+		if item.URL, err = url.Parse(fitem.Link); err != nil {
+			return err
+		} else if existing, err = db.ItemGetByKey(item); err != nil {
+			return err
+		} else if existing != nil {
+			continue
+		} else if err = db.ItemAdd(item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+} // func ingestPerItem(db database.Store, f *model.Feed, feed *gofeed.Feed) error
+
+func benchmarkIngest(b *testing.B, batched bool) {
+	var (
+		err   error
+		r     = rdr
+		pool  *database.Pool
+		feeds = make([]*model.Feed, benchWorkers)
+	)
+
+	if r == nil {
+		// We are only running the benchmarks.
+		if r, err = New(1, database.OpenDefault); err != nil {
+			b.Fatalf("Cannot create Reader: %s", err.Error())
+		}
+	}
+
+	if pool, err = database.NewPool(benchWorkers); err != nil {
+		b.Fatalf("Cannot create database pool: %s", err.Error())
+	}
+
+	defer pool.Close() // nolint: errcheck
+
+	var db = pool.Get()
+
+	for i := range feeds {
+		feeds[i] = &model.Feed{
+			Title:          fmt.Sprintf("Benchmark Feed %d/%t/%d", i, batched, b.N),
+			URL:            purl(fmt.Sprintf("https://bench.example.com/%d/%t/%d/feed.rss", i, batched, b.N)),
+			Homepage:       purl("https://bench.example.com/"),
+			UpdateInterval: time.Hour,
+			Active:         true,
+		}
+
+		if err = db.FeedAdd(feeds[i]); err != nil {
+			b.Fatalf("Cannot add Feed: %s", err.Error())
+		}
+	}
+
+	pool.Put(db)
+
+	var stats writeStats
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		var wg sync.WaitGroup
+
+		for i, f := range feeds {
+			wg.Add(1)
+			go func(worker int, f *model.Feed) {
+				defer wg.Done()
+
+				var (
+					err  error
+					db   = &countingStore{Store: pool.Get(), stats: &stats}
+					feed = benchFeed(worker)
+				)
+
+				defer pool.Put(db.Store)
+
+				if batched {
+					_, err = r.ingest(context.Background(), db, f, feed)
+				} else {
+					err = ingestPerItem(db, f, feed)
+				}
+
+				if err != nil {
+					b.Errorf("Failed to ingest Items: %s", err.Error())
+				}
+			}(i, f)
+		}
+
+		wg.Wait()
+	}
+
+	b.StopTimer()
+	b.ReportMetric(float64(stats.tx.Load())/float64(b.N), "writetx/op")
+	b.ReportMetric(float64(stats.held.Load())/float64(b.N), "lock-ns/op")
+} // func benchmarkIngest(b *testing.B, batched bool)
+
+// BenchmarkIngestPerItem and BenchmarkIngestBatched let benchWorkers workers
+// add benchItemsPer new Items each, concurrently:
+//
+//	go test -run '^$' -bench Ingest ./reader
+//
+// SQLite's busy timeout absorbs the waits for the write lock before we ever
+// see an error, so counting retries shows nothing. Instead, writetx/op counts
+// the write transactions, each of which has to take the write lock and sync
+// the WAL, and lock-ns/op adds up the time the workers spent in those
+// transactions, waiting for the lock included. While one of them holds the
+// lock, the other workers and the web interface cannot write.
+func BenchmarkIngestPerItem(b *testing.B) {
+	benchmarkIngest(b, false)
+} // func BenchmarkIngestPerItem(b *testing.B)
+
+func BenchmarkIngestBatched(b *testing.B) {
+	benchmarkIngest(b, true)
+} // func BenchmarkIngestBatched(b *testing.B)
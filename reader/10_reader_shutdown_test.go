@@ -0,0 +1,117 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/10_reader_shutdown_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 22:14:37 krylon>
+
+package reader
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/model"
+)
+
+// TestReaderShutdown checks that a Reader whose Context is cancelled while it
+// is fetching a Feed finishes processing that Feed before it stops.
+func TestReaderShutdown(t *testing.T) {
+	var (
+		err         error
+		r           *Reader
+		db          database.Store
+		f           *model.Feed
+		items       []*model.Item
+		requested   = make(chan struct{})
+		release     = make(chan struct{})
+		stopped     = make(chan struct{})
+		ctx, cancel = context.WithCancel(context.Background())
+		srv         *httptest.Server
+	)
+
+	defer cancel()
+
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		close(requested)
+		<-release
+		w.Header().Set("Content-Type", "application/atom+xml")
+		fmt.Fprintf(w, atomTemplate,
+			srv.URL+"/hub",
+			srv.URL+"/feed.atom",
+			"Slow Item",
+			srv.URL+"/item/1",
+			"urn:badnews:slow:1")
+	}))
+	defer srv.Close()
+
+	if r, err = New(1, testDB.Open); err != nil {
+		t.Fatalf("Cannot create Reader: %s", err.Error())
+	}
+
+	f = &model.Feed{
+		Title:          "Slow Feed",
+		URL:            purl(srv.URL + "/feed.atom"),
+		Homepage:       purl(srv.URL),
+		UpdateInterval: time.Hour,
+		Active:         true,
+	}
+
+	db = r.pool.Get()
+	err = db.FeedAdd(f)
+	r.pool.Put(db)
+
+	if err != nil {
+		t.Fatalf("Cannot add Feed %s: %s", f.Title, err.Error())
+	}
+
+	r.Start(ctx)
+	r.q <- *f
+
+	select {
+	case <-requested:
+	case <-time.After(time.Second * 10):
+		t.Fatal("Reader did not fetch Feed")
+	}
+
+	cancel()
+
+	go func() {
+		r.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("Reader stopped before it finished processing the Feed")
+	case <-time.After(time.Millisecond * 250):
+	}
+
+	close(release)
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second * 10):
+		t.Fatal("Reader did not stop")
+	}
+
+	if r.IsActive() {
+		t.Error("Reader is still active after it stopped")
+	}
+
+	if db, err = testDB.Open(); err != nil {
+		t.Fatalf("Cannot open database: %s", err.Error())
+	}
+
+	defer db.Close() // nolint: errcheck
+
+	if items, err = db.ItemGetByFeed(f, 10, 0, model.ItemFilter{}); err != nil {
+		t.Fatalf("Cannot load Items of Feed: %s", err.Error())
+	} else if len(items) != 1 {
+		t.Errorf("Expected 1 Item, got %d", len(items))
+	}
+} // func TestReaderShutdown(t *testing.T)
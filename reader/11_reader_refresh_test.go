@@ -0,0 +1,126 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/11_reader_refresh_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 23:02:51 krylon>
+
+package reader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/model"
+)
+
+func TestReaderRefresh(t *testing.T) {
+	var (
+		err         error
+		r           *Reader
+		srv         *httptest.Server
+		results     <-chan RefreshResult
+		res         RefreshResult
+		feeds       = make([]model.Feed, 2)
+		ctx, cancel = context.WithCancel(context.Background())
+	)
+
+	defer cancel()
+
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/broken.atom" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/atom+xml")
+		fmt.Fprintf(w, atomTemplate,
+			srv.URL+"/hub",
+			srv.URL+"/feed.atom",
+			"Breaking News",
+			srv.URL+"/item/breaking",
+			"urn:badnews:breaking:1")
+	}))
+	defer srv.Close()
+
+	if r, err = New(2, testDB.Open); err != nil {
+		t.Fatalf("Cannot create Reader: %s", err.Error())
+	} else if _, err = r.Refresh(ctx); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Refresh on stopped Reader should fail, got %v", err)
+	}
+
+	var db = r.pool.Get()
+
+	for idx, name := range []string{"feed", "broken"} {
+		feeds[idx] = model.Feed{
+			Title:          "Refresh Test " + name,
+			URL:            purl(srv.URL + "/" + name + ".atom"),
+			Homepage:       purl(srv.URL),
+			UpdateInterval: time.Hour * 24,
+			Active:         true,
+		}
+
+		if err = db.FeedAdd(&feeds[idx]); err != nil {
+			r.pool.Put(db)
+			t.Fatalf("Cannot add Feed %s: %s", feeds[idx].Title, err.Error())
+		}
+	}
+
+	r.pool.Put(db)
+
+	// Like the refresh command, we do not need the feeder.
+	r.StartWorkers(ctx)
+	defer r.Wait()
+	defer cancel()
+
+	if results, err = r.Refresh(ctx, feeds...); err != nil {
+		t.Fatalf("Cannot refresh Feeds: %s", err.Error())
+	}
+
+	for range feeds {
+		select {
+		case res = <-results:
+		case <-time.After(time.Second * 10):
+			t.Fatal("Timed out waiting for Feeds to be refreshed")
+		}
+
+		switch res.Feed.ID {
+		case feeds[0].ID:
+			if res.Err != nil {
+				t.Errorf("Failed to refresh Feed %s: %s", res.Feed.Title, res.Err.Error())
+			} else if res.NewItems != 1 {
+				t.Errorf("Expected 1 new Item, got %d", res.NewItems)
+			}
+		case feeds[1].ID:
+			if res.Err == nil {
+				t.Errorf("Refreshing Feed %s should have failed", res.Feed.Title)
+			}
+		default:
+			t.Errorf("Unexpected result for Feed %s (%d)", res.Feed.Title, res.Feed.ID)
+		}
+
+		if res.Duration <= 0 {
+			t.Errorf("Duration of refresh was not recorded for Feed %s", res.Feed.Title)
+		}
+	}
+
+	// The second time around, there is nothing new.
+	if results, err = r.Refresh(ctx, feeds[0]); err != nil {
+		t.Fatalf("Cannot refresh Feed: %s", err.Error())
+	}
+
+	select {
+	case res = <-results:
+		if res.Err != nil {
+			t.Errorf("Failed to refresh Feed %s: %s", res.Feed.Title, res.Err.Error())
+		} else if res.NewItems != 0 {
+			t.Errorf("Expected no new Items, got %d", res.NewItems)
+		}
+	case <-time.After(time.Second * 10):
+		t.Fatal("Timed out waiting for Feed to be refreshed")
+	}
+} // func TestReaderRefresh(t *testing.T)
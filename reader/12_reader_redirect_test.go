@@ -0,0 +1,110 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/12_reader_redirect_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 23:44:12 krylon>
+
+package reader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/model"
+)
+
+func TestReaderRedirect(t *testing.T) {
+	if rdr == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err  error
+		body []byte
+	)
+
+	if body, err = os.ReadFile("testdata/nachrichten-100.rss"); err != nil {
+		t.Fatalf("Cannot read test feed: %s", err.Error())
+	}
+
+	var redirects = map[string]struct {
+		target string
+		code   int
+	}{
+		"/moved.rss":     {"/moved-new.rss", http.StatusMovedPermanently},
+		"/permanent.rss": {"/permanent-new.rss", http.StatusPermanentRedirect},
+		"/temporary.rss": {"/temporary-new.rss", http.StatusFound},
+		"/chain.rss":     {"/hop.rss", http.StatusMovedPermanently},
+		"/hop.rss":       {"/chain-new.rss", http.StatusTemporaryRedirect},
+	}
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rd, ok := redirects[r.URL.Path]; ok {
+			http.Redirect(w, r, rd.target, rd.code)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write(body) // nolint: errcheck
+	}))
+	defer srv.Close()
+
+	type testCase struct {
+		path     string
+		expected string
+	}
+
+	var cases = []testCase{
+		{"/moved.rss", "/moved-new.rss"},
+		{"/permanent.rss", "/permanent-new.rss"},
+		{"/temporary.rss", "/temporary.rss"},
+		{"/chain.rss", "/hop.rss"},
+	}
+
+	var db = rdr.pool.Get()
+	defer rdr.pool.Put(db)
+
+	for _, c := range cases {
+		var (
+			hist []model.FeedMove
+			f    = &model.Feed{
+				Title:          "Redirect Test " + c.path,
+				URL:            purl(srv.URL + c.path),
+				Homepage:       purl(srv.URL),
+				UpdateInterval: time.Minute * 10,
+				Active:         true,
+			}
+		)
+
+		if err = db.FeedAdd(f); err != nil {
+			t.Fatalf("Cannot add Feed %s: %s", f.Title, err.Error())
+		} else if _, err = rdr.process(context.Background(), *f); err != nil {
+			t.Fatalf("Error processing Feed %s: %s", f.Title, err.Error())
+		} else if f, err = db.FeedGetByID(f.ID); err != nil {
+			t.Fatalf("Cannot reload Feed: %s", err.Error())
+		} else if f.URL.String() != srv.URL+c.expected {
+			t.Errorf("Unexpected URL for Feed %s: %s (expected %s)",
+				f.Title,
+				f.URL,
+				srv.URL+c.expected)
+		} else if hist, err = db.FeedHistoryGetByFeed(f); err != nil {
+			t.Fatalf("Cannot load history of Feed %s: %s", f.Title, err.Error())
+		} else if c.path == c.expected && len(hist) != 0 {
+			t.Errorf("Feed %s should not have moved, history has %d entries",
+				f.Title,
+				len(hist))
+		} else if c.path != c.expected {
+			if len(hist) != 1 {
+				t.Errorf("Expected 1 history entry for Feed %s, got %d",
+					f.Title,
+					len(hist))
+			} else if hist[0].OldURL.String() != srv.URL+c.path {
+				t.Errorf("Unexpected old URL in history: %s", hist[0].OldURL)
+			}
+		}
+	}
+} // func TestReaderRedirect(t *testing.T)
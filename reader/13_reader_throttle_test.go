@@ -0,0 +1,206 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/13_reader_throttle_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 00:12:09 krylon>
+
+package reader
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/model"
+)
+
+func TestRetryAfter(t *testing.T) {
+	type testCase struct {
+		value    string
+		ok       bool
+		expected time.Duration
+	}
+
+	var (
+		now   = time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+		cases = []testCase{
+			{"", false, 0},
+			{"120", true, time.Minute * 2},
+			{"-5", false, 0},
+			{"whenever", false, 0},
+			{"9999999", true, backoffMax},
+			{now.Add(time.Hour).Format(http.TimeFormat), true, time.Hour},
+			{now.Add(-time.Hour).Format(http.TimeFormat), true, 0},
+		}
+	)
+
+	for _, c := range cases {
+		var (
+			until time.Time
+			ok    bool
+			h     = make(http.Header)
+		)
+
+		h.Set("Retry-After", c.value)
+
+		if until, ok = retryAfter(h, now); ok != c.ok {
+			t.Errorf("Unexpected result for Retry-After %q: %t (expected %t)",
+				c.value,
+				ok,
+				c.ok)
+		} else if ok && until.Sub(now) != c.expected {
+			t.Errorf("Unexpected delay for Retry-After %q: %s (expected %s)",
+				c.value,
+				until.Sub(now),
+				c.expected)
+		}
+	}
+} // func TestRetryAfter(t *testing.T)
+
+func TestThrottleConcurrency(t *testing.T) {
+	var (
+		err         error
+		th          = newThrottle(HostLimits{Concurrency: 1})
+		ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*100)
+	)
+
+	defer cancel()
+
+	if err = th.acquire(context.Background(), "example.org"); err != nil {
+		t.Fatalf("Cannot acquire slot: %s", err.Error())
+	} else if err = th.acquire(context.Background(), "example.com"); err != nil {
+		t.Fatalf("Other hosts should not be affected: %s", err.Error())
+	} else if st := th.status("example.org"); st.InFlight != 1 {
+		t.Errorf("Expected 1 request in flight, got %d", st.InFlight)
+	}
+
+	if err = th.acquire(ctx, "example.org"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Second request to the same host should have to wait, got %v", err)
+	}
+
+	th.release("example.org")
+
+	if err = th.acquire(context.Background(), "example.org"); err != nil {
+		t.Errorf("Cannot acquire slot after it was released: %s", err.Error())
+	}
+} // func TestThrottleConcurrency(t *testing.T)
+
+func TestThrottleRate(t *testing.T) {
+	const interval = time.Millisecond * 100
+	var (
+		err   error
+		th    = newThrottle(HostLimits{Interval: interval, Burst: 2})
+		start = time.Now()
+	)
+
+	for i := 0; i < 4; i++ {
+		if err = th.acquire(context.Background(), "example.org"); err != nil {
+			t.Fatalf("Cannot acquire token: %s", err.Error())
+		}
+		th.release("example.org")
+	}
+
+	// The first two requests go through right away, the other two have
+	// to wait for a token each.
+	if d := time.Since(start); d < interval*2-interval/10 {
+		t.Errorf("Requests were not throttled, 4 requests took only %s", d)
+	}
+} // func TestThrottleRate(t *testing.T)
+
+func TestReaderTooManyRequests(t *testing.T) {
+	if rdr == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err   error
+		te    *ThrottledError
+		body  []byte
+		cnt   atomic.Int64
+		feeds = make([]*model.Feed, 2)
+	)
+
+	if body, err = os.ReadFile("testdata/nachrichten-100.rss"); err != nil {
+		t.Fatalf("Cannot read test feed: %s", err.Error())
+	}
+
+	var srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cnt.Add(1)
+		if r.URL.Path == "/limited.rss" {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write(body) // nolint: errcheck
+	}))
+	defer srv.Close()
+
+	var db = rdr.pool.Get()
+	defer rdr.pool.Put(db)
+
+	for i, name := range []string{"limited", "other"} {
+		feeds[i] = &model.Feed{
+			Title:          "Throttle Test " + name,
+			URL:            purl(srv.URL + "/" + name + ".rss"),
+			Homepage:       purl(srv.URL),
+			UpdateInterval: time.Minute * 10,
+			Active:         true,
+		}
+
+		if err = db.FeedAdd(feeds[i]); err != nil {
+			t.Fatalf("Cannot add Feed %s: %s", feeds[i].Title, err.Error())
+		}
+	}
+
+	if _, err = rdr.process(context.Background(), *feeds[0]); !errors.As(err, &te) {
+		t.Fatalf("Expected ThrottledError, got %v", err)
+	} else if te.Status != http.StatusTooManyRequests {
+		t.Errorf("Unexpected status in ThrottledError: %d", te.Status)
+	} else if d := time.Until(te.Until); d < time.Minute || d > time.Minute*2 {
+		t.Errorf("Retry-After was not honored: %s", d)
+	}
+
+	var st = rdr.HostStatus(feeds[0].URL.Host)
+
+	if !st.Blocked() || st.Status != http.StatusTooManyRequests {
+		t.Errorf("Host should be blocked after HTTP 429: %#v", st)
+	}
+
+	rdr.recordFailure(feeds[0], err)
+
+	if feeds[0], err = db.FeedGetByID(feeds[0].ID); err != nil {
+		t.Fatalf("Cannot reload Feed: %s", err.Error())
+	} else if feeds[0].NextAttempt.Before(te.Until.Truncate(time.Second)) {
+		t.Errorf("Next attempt %s is before Retry-After %s",
+			feeds[0].NextAttempt,
+			te.Until)
+	} else if feeds[0].LastStatus != http.StatusTooManyRequests {
+		t.Errorf("Unexpected HTTP status: %d", feeds[0].LastStatus)
+	}
+
+	// Other Feeds from the same host have to wait, too.
+	if _, err = rdr.process(context.Background(), *feeds[1]); !errors.As(err, &te) {
+		t.Fatalf("Expected ThrottledError, got %v", err)
+	} else if te.Status != 0 {
+		t.Errorf("Unexpected status in ThrottledError: %d", te.Status)
+	} else if cnt.Load() != 1 {
+		t.Errorf("Expected 1 request, server got %d", cnt.Load())
+	}
+
+	rdr.postpone(feeds[1], te.Until)
+
+	if feeds[1], err = db.FeedGetByID(feeds[1].ID); err != nil {
+		t.Fatalf("Cannot reload Feed: %s", err.Error())
+	} else if feeds[1].Failures != 0 {
+		t.Errorf("Postponing a Feed should not count as a failure: %d", feeds[1].Failures)
+	} else if feeds[1].IsDue() {
+		t.Error("Postponed Feed should not be due")
+	}
+} // func TestReaderTooManyRequests(t *testing.T)
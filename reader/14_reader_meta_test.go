@@ -0,0 +1,139 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/14_reader_meta_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 01:02:33 krylon>
+
+package reader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/model"
+)
+
+const metaRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Meta Test</title>
+    <link>%s/</link>
+    <description>All the news that fit</description>
+    <language>de-DE</language>
+    <generator>Handwritten</generator>
+    <image>
+      <url>/logo.png</url>
+      <title>Meta Test</title>
+      <link>%s/</link>
+    </image>
+    <item>
+      <title>Meta Item</title>
+      <link>%s/item/meta</link>
+      <guid>urn:badnews:meta:1</guid>
+    </item>
+  </channel>
+</rss>
+`
+
+const metaHomepage = `<!DOCTYPE html>
+<html>
+  <head>
+    <title>Meta Test</title>
+    <link rel="shortcut icon" href="/static/icon.png" />
+  </head>
+  <body></body>
+</html>
+`
+
+func TestReaderFeedMeta(t *testing.T) {
+	if rdr == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err      error
+		buf      bytes.Buffer
+		f        *model.Feed
+		data     []byte
+		iconHits atomic.Int64
+		srv      *httptest.Server
+	)
+
+	if err = png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 16, 16))); err != nil {
+		t.Fatalf("Cannot create icon: %s", err.Error())
+	}
+
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed.rss":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprintf(w, metaRSS, srv.URL, srv.URL, srv.URL)
+		case "/":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(metaHomepage)) // nolint: errcheck
+		case "/static/icon.png":
+			iconHits.Add(1)
+			// Servers often get this wrong.
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write(buf.Bytes()) // nolint: errcheck
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	f = &model.Feed{
+		Title:          "Meta Test Feed",
+		URL:            purl(srv.URL + "/feed.rss"),
+		Homepage:       purl(srv.URL + "/"),
+		UpdateInterval: time.Minute * 10,
+		Active:         true,
+	}
+
+	var db = rdr.pool.Get()
+	defer rdr.pool.Put(db)
+
+	if err = db.FeedAdd(f); err != nil {
+		t.Fatalf("Cannot add Feed %s: %s", f.Title, err.Error())
+	} else if _, err = rdr.process(context.Background(), *f); err != nil {
+		t.Fatalf("Error processing Feed %s: %s", f.Title, err.Error())
+	} else if f, err = db.FeedGetByID(f.ID); err != nil {
+		t.Fatalf("Cannot reload Feed: %s", err.Error())
+	} else if f.Meta.Description != "All the news that fit" ||
+		f.Meta.Language != "de-DE" ||
+		f.Meta.Generator != "Handwritten" {
+		t.Errorf("Unexpected metadata: %#v", f.Meta)
+	} else if f.Meta.ImageString() != srv.URL+"/logo.png" {
+		t.Errorf("Unexpected image URL: %q", f.Meta.ImageString())
+	}
+
+	rdr.checkIcon(context.Background(), *f)
+
+	if f, err = db.FeedGetByID(f.ID); err != nil {
+		t.Fatalf("Cannot reload Feed: %s", err.Error())
+	} else if !f.Meta.HasIcon() {
+		t.Fatal("Feed has no icon")
+	} else if f.Meta.IconType != "image/png" {
+		t.Errorf("Unexpected icon type: %q", f.Meta.IconType)
+	} else if data, err = os.ReadFile(f.Meta.Icon); err != nil {
+		t.Fatalf("Cannot read icon: %s", err.Error())
+	} else if !bytes.Equal(data, buf.Bytes()) {
+		t.Error("Cached icon differs from the original")
+	}
+
+	// We do not look for a new icon until the old one is stale.
+	rdr.checkIcon(context.Background(), *f)
+
+	if iconHits.Load() != 1 {
+		t.Errorf("Expected icon to be fetched once, got %d", iconHits.Load())
+	}
+} // func TestReaderFeedMeta(t *testing.T)
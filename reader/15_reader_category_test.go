@@ -0,0 +1,127 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/15_reader_category_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 01:41:09 krylon>
+
+package reader
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/blicero/badnews/model"
+)
+
+const categoryRSS = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel>
+    <title>Category Test</title>
+    <link>%s/</link>
+    <description>Categories and authors</description>
+    <item>
+      <title>Football</title>
+      <link>%s/item/football</link>
+      <guid>urn:badnews:category:1</guid>
+      <category>SPORT</category>
+      <category>Fußball</category>
+      <category>Sport</category>
+      <dc:creator>Jane Doe</dc:creator>
+    </item>
+    <item>
+      <title>Elections</title>
+      <link>%s/item/elections</link>
+      <guid>urn:badnews:category:2</guid>
+      <category>Politik</category>
+      <author>editor@example.com</author>
+    </item>
+  </channel>
+</rss>
+`
+
+func TestReaderCategories(t *testing.T) {
+	if rdr == nil {
+		t.SkipNow()
+	}
+
+	var (
+		err   error
+		items []*model.Item
+		srv   *httptest.Server
+		f     *model.Feed
+		tag   = &model.Tag{Name: "sports"}
+	)
+
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprintf(w, categoryRSS, srv.URL, srv.URL, srv.URL)
+	}))
+	defer srv.Close()
+
+	f = &model.Feed{
+		Title:          "Category Test Feed",
+		URL:            purl(srv.URL + "/feed.rss"),
+		Homepage:       purl(srv.URL + "/"),
+		UpdateInterval: time.Minute * 10,
+		Active:         true,
+	}
+
+	var db = rdr.pool.Get()
+	defer rdr.pool.Put(db)
+
+	if err = db.FeedAdd(f); err != nil {
+		t.Fatalf("Cannot add Feed %s: %s", f.Title, err.Error())
+	} else if err = db.TagAdd(tag); err != nil {
+		t.Fatalf("Cannot add Tag %s: %s", tag.Name, err.Error())
+	} else if err = db.CategoryMapAdd(&model.CategoryMapping{FeedID: f.ID, Category: "sport", TagID: tag.ID}); err != nil {
+		t.Fatalf("Cannot add category mapping: %s", err.Error())
+	} else if _, err = rdr.process(context.Background(), *f); err != nil {
+		t.Fatalf("Error processing Feed %s: %s", f.Title, err.Error())
+	} else if items, err = db.ItemGetByFeed(f, 10, 0, model.ItemFilter{}); err != nil {
+		t.Fatalf("Cannot load Items of Feed %s: %s", f.Title, err.Error())
+	} else if len(items) != 2 {
+		t.Fatalf("Unexpected number of Items: %d", len(items))
+	}
+
+	for _, item := range items {
+		var (
+			linked []*model.Tag
+			expect struct {
+				categories, authors model.StringList
+				tagged              bool
+			}
+		)
+
+		switch item.Headline {
+		case "Football":
+			expect.categories = model.StringList{"SPORT", "Fußball"}
+			expect.authors = model.StringList{"Jane Doe"}
+			expect.tagged = true
+		case "Elections":
+			expect.categories = model.StringList{"Politik"}
+			expect.authors = model.StringList{"editor@example.com"}
+		default:
+			t.Fatalf("Unexpected Item %q", item.Headline)
+		}
+
+		if !slices.Equal(item.Categories, expect.categories) {
+			t.Errorf("Unexpected categories of Item %q: %v", item.Headline, item.Categories)
+		} else if !slices.Equal(item.Authors, expect.authors) {
+			t.Errorf("Unexpected authors of Item %q: %v", item.Headline, item.Authors)
+		} else if linked, err = db.TagLinkGetByItem(item); err != nil {
+			t.Fatalf("Cannot load Tags of Item %q: %s", item.Headline, err.Error())
+		} else if !expect.tagged && len(linked) != 0 {
+			t.Errorf("Item %q should not have any Tags: %v", item.Headline, linked)
+		} else if expect.tagged && (len(linked) != 1 || linked[0].ID != tag.ID || !linked[0].Auto) {
+			t.Errorf("Item %q should have Tag %s attached automatically: %#v",
+				item.Headline,
+				tag.Name,
+				linked)
+		}
+	}
+} // func TestReaderCategories(t *testing.T)
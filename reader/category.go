@@ -0,0 +1,134 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/category.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 01:27:40 krylon>
+
+package reader
+
+import (
+	"strings"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/model"
+	"github.com/mmcdole/gofeed"
+)
+
+// categoryRule is a CategoryMapping along with the Tag it attaches.
+type categoryRule struct {
+	category string
+	tag      *model.Tag
+}
+
+// itemCategories returns the categories of a Feed's Item, without blanks and
+// duplicates.
+func itemCategories(fitem *gofeed.Item) model.StringList {
+	var list = make(model.StringList, 0, len(fitem.Categories))
+
+	for _, c := range fitem.Categories {
+		list = appendUnique(list, c)
+	}
+
+	return list
+} // func itemCategories(fitem *gofeed.Item) model.StringList
+
+// itemAuthors returns the authors of a Feed's Item. For authors that only
+// give their email address, we use that instead of the name.
+func itemAuthors(fitem *gofeed.Item) model.StringList {
+	var (
+		people = fitem.Authors
+		list   = make(model.StringList, 0, len(people))
+	)
+
+	if len(people) == 0 && fitem.Author != nil { // nolint: staticcheck
+		people = []*gofeed.Person{fitem.Author} // nolint: staticcheck
+	}
+
+	for _, p := range people {
+		if p == nil {
+			continue
+		} else if strings.TrimSpace(p.Name) != "" {
+			list = appendUnique(list, p.Name)
+		} else {
+			list = appendUnique(list, p.Email)
+		}
+	}
+
+	return list
+} // func itemAuthors(fitem *gofeed.Item) model.StringList
+
+func appendUnique(list model.StringList, s string) model.StringList {
+	if s = strings.TrimSpace(s); s == "" {
+		return list
+	}
+
+	for _, x := range list {
+		if strings.EqualFold(x, s) {
+			return list
+		}
+	}
+
+	return append(list, s)
+} // func appendUnique(list model.StringList, s string) model.StringList
+
+// categoryRules loads the CategoryMappings of a Feed along with their Tags.
+func (r *Reader) categoryRules(db database.Store, f *model.Feed) []categoryRule {
+	var (
+		err      error
+		mappings []model.CategoryMapping
+		tags     = make(map[int64]*model.Tag)
+		rules    []categoryRule
+	)
+
+	if mappings, err = db.CategoryMapGetByFeed(f); err != nil {
+		r.log.Printf("[ERROR] Cannot load category mappings of Feed %s (%d): %s\n",
+			f.Title,
+			f.ID,
+			err.Error())
+		return nil
+	}
+
+	rules = make([]categoryRule, 0, len(mappings))
+
+	for _, m := range mappings {
+		var t = tags[m.TagID]
+
+		if t == nil {
+			if t, err = db.TagGetByID(m.TagID); err != nil || t == nil {
+				r.log.Printf("[ERROR] Cannot load Tag %d for category %q of Feed %s (%d): %v\n",
+					m.TagID,
+					m.Category,
+					f.Title,
+					f.ID,
+					err)
+				continue
+			}
+			tags[m.TagID] = t
+		}
+
+		rules = append(rules, categoryRule{category: m.Category, tag: t})
+	}
+
+	return rules
+} // func (r *Reader) categoryRules(db database.Store, f *model.Feed) []categoryRule
+
+// applyCategories attaches the Tags the Feed's CategoryMappings call for to a
+// new Item.
+func (r *Reader) applyCategories(db database.Store, item *model.Item, rules []categoryRule) {
+	var err error
+
+	for _, rule := range rules {
+		for _, c := range item.Categories {
+			if !strings.EqualFold(c, rule.category) {
+				continue
+			} else if err = db.TagLinkAddAuto(item, rule.tag); err != nil {
+				r.log.Printf("[ERROR] Cannot attach Tag %s to Item %q (%d): %s\n",
+					rule.tag.Name,
+					item.Headline,
+					item.ID,
+					err.Error())
+			}
+			break
+		}
+	}
+} // func (r *Reader) applyCategories(db database.Store, item *model.Item, rules []categoryRule)
@@ -0,0 +1,132 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/client.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 17:38:05 krylon>
+
+package reader
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blicero/badnews/common"
+	"github.com/blicero/badnews/model"
+)
+
+// ClientConfig holds the settings for the HTTP client that is shared by all
+// parts of the application that fetch stuff from the web.
+// If Proxy is empty, the proxy is taken from the environment variables
+// HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+type ClientConfig struct {
+	UserAgent string
+	Proxy     string
+	Timeout   time.Duration
+}
+
+// DefaultTimeout is the timeout for HTTP requests unless configured
+// otherwise.
+const DefaultTimeout = fetchTimeout
+
+// DefaultUserAgent returns the User-Agent we send unless configured otherwise.
+func DefaultUserAgent() string {
+	return fmt.Sprintf("%s/%s", common.AppName, common.Version)
+} // func DefaultUserAgent() string
+
+var (
+	clientLock sync.RWMutex
+	clientCfg  = ClientConfig{
+		UserAgent: DefaultUserAgent(),
+		Timeout:   fetchTimeout,
+	}
+	sharedClient = &http.Client{
+		Transport: &http.Transport{Proxy: http.ProxyFromEnvironment},
+		Timeout:   fetchTimeout,
+	}
+)
+
+// Configure replaces the shared HTTP client with one using the given
+// settings. Empty fields are replaced with the defaults.
+func Configure(cfg ClientConfig) error {
+	var (
+		err   error
+		proxy *url.URL
+		tr    = &http.Transport{Proxy: http.ProxyFromEnvironment}
+	)
+
+	if cfg.UserAgent = strings.TrimSpace(cfg.UserAgent); cfg.UserAgent == "" {
+		cfg.UserAgent = DefaultUserAgent()
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = fetchTimeout
+	}
+
+	if cfg.Proxy != "" {
+		if proxy, err = url.Parse(cfg.Proxy); err != nil {
+			return fmt.Errorf("Cannot parse proxy URL %q: %w", cfg.Proxy, err)
+		} else if proxy.Scheme == "" || proxy.Host == "" {
+			return fmt.Errorf("Invalid proxy URL %q", cfg.Proxy)
+		}
+
+		tr.Proxy = http.ProxyURL(proxy)
+	}
+
+	clientLock.Lock()
+	clientCfg = cfg
+	sharedClient = &http.Client{
+		Transport: tr,
+		Timeout:   cfg.Timeout,
+	}
+	clientLock.Unlock()
+
+	return nil
+} // func Configure(cfg ClientConfig) error
+
+// Client returns the shared HTTP client.
+func Client() *http.Client {
+	clientLock.RLock()
+	defer clientLock.RUnlock()
+	return sharedClient
+} // func Client() *http.Client
+
+// UserAgent returns the User-Agent of the shared HTTP client.
+func UserAgent() string {
+	clientLock.RLock()
+	defer clientLock.RUnlock()
+	return clientCfg.UserAgent
+} // func UserAgent() string
+
+// NewRequest creates a GET request for the given URL with our User-Agent set.
+func NewRequest(addr string) (*http.Request, error) {
+	var (
+		err error
+		req *http.Request
+	)
+
+	if req, err = http.NewRequest(http.MethodGet, addr, nil); err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", UserAgent())
+	return req, nil
+} // func NewRequest(addr string) (*http.Request, error)
+
+// applyFeedSettings adds the Feed's custom headers and credentials to a
+// request. They take precedence over our defaults.
+func applyFeedSettings(req *http.Request, f *model.Feed) {
+	for name, value := range f.Headers {
+		req.Header.Set(name, value)
+	}
+
+	switch f.Auth.Kind {
+	case model.AuthBasic:
+		req.SetBasicAuth(f.Auth.Username, f.Auth.Secret)
+	case model.AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+f.Auth.Secret)
+	}
+} // func applyFeedSettings(req *http.Request, f *model.Feed)
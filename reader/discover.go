@@ -0,0 +1,203 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/discover.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 11:48:02 krylon>
+
+package reader
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/blicero/badnews/model"
+	"github.com/mmcdole/gofeed"
+)
+
+// maxDiscoverSize is the maximum number of bytes we read from a page or feed
+// during autodiscovery.
+const maxDiscoverSize = 4 * 1024 * 1024
+
+// defaultInterval is the UpdateInterval we suggest for discovered Feeds.
+const defaultInterval = time.Minute * 30
+
+// commonFeedPaths are paths where many sites put their feeds without
+// advertising them in the page header.
+var commonFeedPaths = []string{
+	"/feed",
+	"/feed/",
+	"/rss",
+	"/rss.xml",
+	"/feed.xml",
+	"/atom.xml",
+	"/index.xml",
+	"/index.rss",
+}
+
+var feedTypes = map[string]bool{
+	"application/rss+xml":  true,
+	"application/atom+xml": true,
+	"application/rdf+xml":  true,
+	"application/xml":      true,
+	"text/xml":             true,
+}
+
+// Candidate is a Feed found during autodiscovery.
+type Candidate struct {
+	Title    string
+	URL      *url.URL
+	Homepage *url.URL
+	Type     string
+}
+
+// Feed returns a Feed prefilled with the Candidate's Title, URL and Homepage.
+func (c *Candidate) Feed() *model.Feed {
+	return &model.Feed{
+		Title:          c.Title,
+		URL:            c.URL,
+		Homepage:       c.Homepage,
+		UpdateInterval: defaultInterval,
+		Active:         true,
+	}
+} // func (c *Candidate) Feed() *model.Feed
+
+// Discover fetches the page at the given address and returns all the Feeds
+// it could find. If the address already points to a Feed, that Feed is the
+// only Candidate.
+// Otherwise, we look for <link rel="alternate"> elements advertising an RSS or
+// Atom feed and also probe a few common locations.
+func Discover(addr string) ([]Candidate, error) {
+	var (
+		err        error
+		page       *url.URL
+		body       []byte
+		ctype      string
+		doc        *goquery.Document
+		feed       *gofeed.Feed
+		pageTitle  string
+		seen       = make(map[string]bool)
+		candidates = make([]Candidate, 0, 4)
+	)
+
+	if page, err = url.Parse(strings.TrimSpace(addr)); err != nil {
+		return nil, err
+	} else if page.Scheme == "" {
+		if page, err = url.Parse("https://" + strings.TrimSpace(addr)); err != nil {
+			return nil, err
+		}
+	}
+
+	if body, ctype, err = discoverFetch(page); err != nil {
+		return nil, err
+	} else if feed, err = gofeed.NewParser().Parse(bytes.NewReader(body)); err == nil {
+		var c = Candidate{
+			Title: feed.Title,
+			URL:   page,
+			Type:  ctype,
+		}
+
+		if c.Homepage, err = page.Parse(feed.Link); err != nil || feed.Link == "" {
+			c.Homepage = &url.URL{Scheme: page.Scheme, Host: page.Host, Path: "/"}
+		}
+
+		candidates = append(candidates, c)
+		return candidates, nil
+	} else if doc, err = goquery.NewDocumentFromReader(bytes.NewReader(body)); err != nil {
+		return nil, fmt.Errorf("Cannot parse page %s: %w", page, err)
+	}
+
+	pageTitle = strings.TrimSpace(doc.Find("head title").First().Text())
+
+	doc.Find("link[rel~=alternate][href]").Each(func(_ int, s *goquery.Selection) {
+		var (
+			ltype, _ = s.Attr("type")
+			href, _  = s.Attr("href")
+			title, _ = s.Attr("title")
+			u        *url.URL
+			e        error
+		)
+
+		ltype = strings.ToLower(strings.TrimSpace(ltype))
+		if !feedTypes[ltype] {
+			return
+		} else if u, e = page.Parse(strings.TrimSpace(href)); e != nil || seen[u.String()] {
+			return
+		}
+
+		if title = strings.TrimSpace(title); title == "" {
+			title = pageTitle
+		}
+
+		seen[u.String()] = true
+		candidates = append(candidates, Candidate{
+			Title:    title,
+			URL:      u,
+			Homepage: page,
+			Type:     ltype,
+		})
+	})
+
+	for _, p := range commonFeedPaths {
+		var (
+			u = &url.URL{Scheme: page.Scheme, Host: page.Host, Path: p}
+			c Candidate
+		)
+
+		if seen[u.String()] {
+			continue
+		} else if body, ctype, err = discoverFetch(u); err != nil {
+			continue
+		} else if feed, err = gofeed.NewParser().Parse(bytes.NewReader(body)); err != nil {
+			continue
+		}
+
+		seen[u.String()] = true
+		c = Candidate{
+			Title:    feed.Title,
+			URL:      u,
+			Homepage: page,
+			Type:     ctype,
+		}
+
+		if c.Title == "" {
+			c.Title = pageTitle
+		}
+
+		candidates = append(candidates, c)
+	}
+
+	return candidates, nil
+} // func Discover(addr string) ([]Candidate, error)
+
+func discoverFetch(u *url.URL) ([]byte, string, error) {
+	var (
+		err  error
+		req  *http.Request
+		res  *http.Response
+		body []byte
+	)
+
+	if req, err = NewRequest(u.String()); err != nil {
+		return nil, "", err
+	} else if res, err = Client().Do(req); err != nil {
+		return nil, "", err
+	}
+
+	defer res.Body.Close() // nolint: errcheck
+
+	if res.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("Unexpected HTTP status fetching %s: %s",
+			u,
+			res.Status)
+	} else if body, err = io.ReadAll(io.LimitReader(res.Body, maxDiscoverSize)); err != nil {
+		return nil, "", err
+	}
+
+	return body, res.Header.Get("Content-Type"), nil
+} // func discoverFetch(u *url.URL) ([]byte, string, error)
@@ -0,0 +1,206 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/extract.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 14:37:12 krylon>
+
+package reader
+
+import (
+	"errors"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// The content extraction below is a much simplified version of the algorithm
+// used by Arc90's Readability: Paragraphs are scored by their length and
+// number of commas, their scores are propagated to their parent and
+// grandparent, which get a bonus or malus depending on their tag name and
+// their class and id attributes. The element with the highest score, adjusted
+// for the density of links in it, is assumed to contain the article.
+
+// minParagraphLength is the minimum length of a paragraph to be considered
+// for scoring.
+const minParagraphLength = 25
+
+// ErrNoContent is returned if no article could be found in a document.
+var ErrNoContent = errors.New("no article content found in document")
+
+var (
+	unlikelyPat = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|disqus|extra|footer|header|legends|menu|modal|nav|popup|promo|related|remark|share|shoutbox|sidebar|social|sponsor|teaser-list|tweet|twitter|consent`)
+	likelyPat   = regexp.MustCompile(`(?i)and|article|body|column|main|shadow|content|entry|story|text`)
+	positivePat = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story`)
+	negativePat = regexp.MustCompile(`(?i)hidden|^hid$|\shid$|\shid\s|^hid\s|banner|combx|comment|com-|contact|foot|footer|footnote|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget|ad-break|ads`)
+)
+
+const removeSelector = "script, style, noscript, nav, header, footer, aside, form, iframe, svg, button, figure figcaption"
+
+const textSelector = "p, pre, h2, h3, h4, li, blockquote"
+
+type candidate struct {
+	node  *goquery.Selection
+	score float64
+}
+
+// Extract attempts to find the main article in an HTML document and returns
+// its text.
+func Extract(r io.Reader) (string, error) {
+	var (
+		err        error
+		doc        *goquery.Document
+		candidates = make(map[*html.Node]*candidate)
+		best       *candidate
+	)
+
+	if doc, err = goquery.NewDocumentFromReader(r); err != nil {
+		return "", err
+	}
+
+	doc.Find(removeSelector).Remove()
+
+	doc.Find("body *").Each(func(_ int, s *goquery.Selection) {
+		var match = classAndID(s)
+
+		if match == "" || s.Is("body, article, main") {
+			return
+		} else if unlikelyPat.MatchString(match) && !likelyPat.MatchString(match) {
+			s.Remove()
+		}
+	})
+
+	doc.Find("p, pre, td").Each(func(_ int, s *goquery.Selection) {
+		var (
+			text        = strings.TrimSpace(s.Text())
+			parent      = s.Parent()
+			grandparent = parent.Parent()
+			score       float64
+		)
+
+		if len(text) < minParagraphLength || parent.Length() == 0 {
+			return
+		}
+
+		score = 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+
+		addScore(candidates, parent, score)
+		if grandparent.Length() > 0 {
+			addScore(candidates, grandparent, score/2)
+		}
+	})
+
+	for _, c := range candidates {
+		c.score *= 1 - linkDensity(c.node)
+		if best == nil || c.score > best.score {
+			best = c
+		}
+	}
+
+	if best == nil {
+		return "", ErrNoContent
+	}
+
+	return nodeText(best.node), nil
+} // func Extract(r io.Reader) (string, error)
+
+func addScore(candidates map[*html.Node]*candidate, s *goquery.Selection, score float64) {
+	var (
+		n  = s.Get(0)
+		c  *candidate
+		ok bool
+	)
+
+	if c, ok = candidates[n]; !ok {
+		c = &candidate{node: s, score: initialScore(s)}
+		candidates[n] = c
+	}
+
+	c.score += score
+} // func addScore(candidates map[*html.Node]*candidate, s *goquery.Selection, score float64)
+
+func initialScore(s *goquery.Selection) float64 {
+	var (
+		score float64
+		match = classAndID(s)
+	)
+
+	switch goquery.NodeName(s) {
+	case "article":
+		score = 10
+	case "div", "main", "section":
+		score = 5
+	case "pre", "td", "blockquote":
+		score = 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		score = -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		score = -5
+	}
+
+	if match != "" {
+		if negativePat.MatchString(match) {
+			score -= 25
+		}
+		if positivePat.MatchString(match) {
+			score += 25
+		}
+	}
+
+	return score
+} // func initialScore(s *goquery.Selection) float64
+
+func classAndID(s *goquery.Selection) string {
+	var (
+		class, _ = s.Attr("class")
+		id, _    = s.Attr("id")
+	)
+
+	return strings.TrimSpace(class + " " + id)
+} // func classAndID(s *goquery.Selection) string
+
+func linkDensity(s *goquery.Selection) float64 {
+	var (
+		textLen = len(strings.TrimSpace(s.Text()))
+		linkLen int
+	)
+
+	if textLen == 0 {
+		return 0
+	}
+
+	s.Find("a").Each(func(_ int, a *goquery.Selection) {
+		linkLen += len(strings.TrimSpace(a.Text()))
+	})
+
+	return float64(linkLen) / float64(textLen)
+} // func linkDensity(s *goquery.Selection) float64
+
+// nodeText renders the text of the given element, one paragraph per line.
+func nodeText(s *goquery.Selection) string {
+	var parts = make([]string, 0, 16)
+
+	s.Find(textSelector).Each(func(_ int, p *goquery.Selection) {
+		// Avoid duplicates from nested elements, e.g. a paragraph
+		// inside a blockquote.
+		if p.ParentsUntilSelection(s).Filter(textSelector).Length() > 0 {
+			return
+		}
+
+		var text = whitespace.ReplaceAllString(strings.TrimSpace(p.Text()), " ")
+		if text != "" {
+			parts = append(parts, text)
+		}
+	})
+
+	if len(parts) == 0 {
+		return whitespace.ReplaceAllString(strings.TrimSpace(s.Text()), " ")
+	}
+
+	return strings.Join(parts, "\n\n")
+} // func nodeText(s *goquery.Selection) string
+
+var whitespace = regexp.MustCompile(`\s+`)
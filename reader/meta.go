@@ -0,0 +1,255 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/meta.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-18 00:41:17 krylon>
+
+package reader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/blicero/badnews/common"
+	"github.com/blicero/badnews/common/path"
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/model"
+	"github.com/mmcdole/gofeed"
+)
+
+// iconMaxAge is how long we keep a Feed's icon before we look for a new one.
+const iconMaxAge = time.Hour * 24 * 7
+
+// maxIconSize is the maximum number of bytes we accept for an icon.
+const maxIconSize = 512 * 1024
+
+var iconExtensions = map[string]string{
+	"image/png":                ".png",
+	"image/gif":                ".gif",
+	"image/jpeg":               ".jpg",
+	"image/webp":               ".webp",
+	"image/svg+xml":            ".svg",
+	"image/x-icon":             ".ico",
+	"image/vnd.microsoft.icon": ".ico",
+}
+
+// updateMeta stores the description, language, image and generator a Feed
+// reports about itself, if they have changed.
+func (r *Reader) updateMeta(db database.Store, f *model.Feed, feed *gofeed.Feed) {
+	var (
+		err  error
+		meta = model.FeedMeta{
+			Description: strings.TrimSpace(feed.Description),
+			Language:    strings.TrimSpace(feed.Language),
+			Generator:   strings.TrimSpace(feed.Generator),
+		}
+	)
+
+	if feed.Image != nil && strings.TrimSpace(feed.Image.URL) != "" {
+		if meta.Image, err = f.URL.Parse(strings.TrimSpace(feed.Image.URL)); err != nil {
+			r.log.Printf("[DEBUG] Cannot parse image URL %q of Feed %s (%d): %s\n",
+				feed.Image.URL,
+				f.Title,
+				f.ID,
+				err.Error())
+			meta.Image = nil
+		} else if meta.Image.Scheme != "http" && meta.Image.Scheme != "https" {
+			meta.Image = nil
+		}
+	}
+
+	if meta.Description == f.Meta.Description &&
+		meta.Language == f.Meta.Language &&
+		meta.Generator == f.Meta.Generator &&
+		meta.ImageString() == f.Meta.ImageString() {
+		return
+	} else if err = db.FeedSetMeta(f, meta); err != nil {
+		r.log.Printf("[ERROR] Failed to store metadata of Feed %s (%d): %s\n",
+			f.Title,
+			f.ID,
+			err.Error())
+	}
+} // func (r *Reader) updateMeta(db database.Store, f *model.Feed, feed *gofeed.Feed)
+
+// checkIcon looks for an icon for the Feed if we have none or ours is older
+// than iconMaxAge. If we cannot find a new icon, we keep the old one.
+func (r *Reader) checkIcon(ctx context.Context, f model.Feed) {
+	if ctx.Err() != nil || time.Since(f.Meta.IconChecked) < iconMaxAge {
+		return
+	}
+
+	var (
+		err      error
+		feed     *model.Feed
+		data     []byte
+		mimeType string
+		icon     string
+		db       = r.pool.Get()
+	)
+
+	defer r.pool.Put(db)
+
+	// The Feed we got from the queue may predate the latest fetch.
+	if feed, err = db.FeedGetByID(f.ID); err != nil || feed == nil {
+		r.log.Printf("[ERROR] Cannot reload Feed %s (%d): %v\n",
+			f.Title,
+			f.ID,
+			err)
+		return
+	}
+
+	for _, u := range r.iconCandidates(ctx, feed) {
+		if data, mimeType, err = r.fetchIcon(ctx, u); err == nil {
+			break
+		}
+
+		r.log.Printf("[TRACE] No icon for Feed %s (%d) at %s: %s\n",
+			feed.Title,
+			feed.ID,
+			u,
+			err.Error())
+	}
+
+	if ctx.Err() != nil {
+		return
+	} else if data == nil {
+		icon, mimeType = feed.Meta.Icon, feed.Meta.IconType
+	} else if icon, err = saveIcon(feed, mimeType, data); err != nil {
+		r.log.Printf("[ERROR] Failed to save icon of Feed %s (%d): %s\n",
+			feed.Title,
+			feed.ID,
+			err.Error())
+		icon, mimeType = feed.Meta.Icon, feed.Meta.IconType
+	}
+
+	if err = db.FeedSetIcon(feed, icon, mimeType, time.Now()); err != nil {
+		r.log.Printf("[ERROR] Failed to store icon of Feed %s (%d): %s\n",
+			feed.Title,
+			feed.ID,
+			err.Error())
+	}
+} // func (r *Reader) checkIcon(ctx context.Context, f model.Feed)
+
+// iconCandidates returns the URLs where we look for a Feed's icon: The icons
+// the homepage advertises, the favicon.ico at the root of the homepage's
+// host, and the image the Feed reports.
+func (r *Reader) iconCandidates(ctx context.Context, f *model.Feed) []*url.URL {
+	var (
+		err        error
+		req        *http.Request
+		res        *http.Response
+		body       []byte
+		doc        *goquery.Document
+		seen       = make(map[string]bool)
+		candidates = make([]*url.URL, 0, 4)
+	)
+
+	var add = func(u *url.URL) {
+		if u != nil && (u.Scheme == "http" || u.Scheme == "https") && !seen[u.String()] {
+			seen[u.String()] = true
+			candidates = append(candidates, u)
+		}
+	}
+
+	if f.Homepage != nil && f.Homepage.IsAbs() {
+		if req, err = NewRequest(f.Homepage.String()); err != nil {
+			r.log.Printf("[DEBUG] Cannot create request for %s: %s\n",
+				f.Homepage,
+				err.Error())
+		} else if res, body, err = r.fetch(ctx, req, maxDiscoverSize); err != nil {
+			r.log.Printf("[DEBUG] Failed to fetch homepage %s: %s\n",
+				f.Homepage,
+				err.Error())
+		} else if res.StatusCode == http.StatusOK &&
+			strings.Contains(res.Header.Get("Content-Type"), "html") {
+			if doc, err = goquery.NewDocumentFromReader(bytes.NewReader(body)); err == nil {
+				doc.Find("link[rel~=icon][href], link[rel=apple-touch-icon][href]").Each(func(_ int, s *goquery.Selection) {
+					var (
+						href, _ = s.Attr("href")
+						u, e    = res.Request.URL.Parse(strings.TrimSpace(href))
+					)
+
+					if e == nil {
+						add(u)
+					}
+				})
+			}
+		}
+
+		add(&url.URL{Scheme: f.Homepage.Scheme, Host: f.Homepage.Host, Path: "/favicon.ico"})
+	}
+
+	add(f.Meta.Image)
+
+	return candidates
+} // func (r *Reader) iconCandidates(ctx context.Context, f *model.Feed) []*url.URL
+
+// fetchIcon downloads an icon and returns its content and MIME type.
+func (r *Reader) fetchIcon(ctx context.Context, u *url.URL) ([]byte, string, error) {
+	var (
+		err      error
+		req      *http.Request
+		res      *http.Response
+		body     []byte
+		mimeType string
+	)
+
+	if req, err = NewRequest(u.String()); err != nil {
+		return nil, "", err
+	} else if res, body, err = r.fetch(ctx, req, maxIconSize+1); err != nil {
+		return nil, "", err
+	} else if res.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("Unexpected HTTP status: %s", res.Status)
+	} else if len(body) == 0 {
+		return nil, "", fmt.Errorf("Icon is empty")
+	} else if len(body) > maxIconSize {
+		return nil, "", fmt.Errorf("Icon is larger than %d bytes", maxIconSize)
+	}
+
+	// Servers often get the Content-Type of icons wrong, so we trust our
+	// own nose first. SVG images cannot be sniffed, though.
+	if mimeType = http.DetectContentType(body); iconExtensions[mimeType] == "" {
+		mimeType, _, _ = strings.Cut(res.Header.Get("Content-Type"), ";")
+		mimeType = strings.ToLower(strings.TrimSpace(mimeType))
+	}
+
+	if iconExtensions[mimeType] == "" {
+		return nil, "", fmt.Errorf("Unsupported icon type %q", mimeType)
+	}
+
+	return body, mimeType, nil
+} // func (r *Reader) fetchIcon(ctx context.Context, u *url.URL) ([]byte, string, error)
+
+// saveIcon writes a Feed's icon to the icon folder and returns its path. If
+// the Feed's old icon was stored under a different name, it is removed.
+func saveIcon(f *model.Feed, mimeType string, data []byte) (string, error) {
+	var (
+		err  error
+		dir  = common.Path(path.Icons)
+		dst  = filepath.Join(dir, fmt.Sprintf("%d%s", f.ID, iconExtensions[mimeType]))
+		part = dst + ".part"
+	)
+
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	} else if err = os.WriteFile(part, data, 0644); err != nil {
+		return "", err
+	} else if err = os.Rename(part, dst); err != nil {
+		os.Remove(part) // nolint: errcheck
+		return "", err
+	}
+
+	if f.Meta.Icon != "" && f.Meta.Icon != dst {
+		os.Remove(f.Meta.Icon) // nolint: errcheck
+	}
+
+	return dst, nil
+} // func saveIcon(f *model.Feed, mimeType string, data []byte) (string, error)
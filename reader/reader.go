@@ -8,8 +8,16 @@
 package reader
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -25,31 +33,60 @@ import (
 // TODO Set to reasonable value when testing is done.
 const (
 	checkInterval = time.Second * 30 // nolint: unused
+	fetchTimeout  = time.Second * 90
+	backoffBase   = time.Minute
+	backoffMax    = time.Hour * 24
 )
 
+// maxArticleSize is the maximum number of bytes we read when fetching the
+// full text of an article.
+const maxArticleSize = 8 * 1024 * 1024
+
+// maxFeedSize is the maximum number of bytes we read when fetching a Feed.
+const maxFeedSize = 32 * 1024 * 1024
+
+// ErrFeedTooLarge is returned when a Feed is larger than maxFeedSize.
+// Parsing the first maxFeedSize bytes would only fail in confusing ways.
+var ErrFeedTooLarge = fmt.Errorf("Feed exceeds %d bytes", maxFeedSize)
+
+// DefaultMaxFailures is the number of consecutive failed attempts to fetch a
+// Feed after which the Reader disables it.
+const DefaultMaxFailures = 16
+
 // Reader provides fetching and parsing of RSS feeds.
 type Reader struct {
-	log       *log.Logger
-	pool      *database.Pool
-	q         chan model.Feed
-	active    atomic.Bool
-	workerCnt int
-	bl        *blacklist.Blacklist
+	log         *log.Logger
+	pool        *database.Pool
+	q           chan model.Feed
+	active      atomic.Bool
+	workerCnt   int
+	bl          *blacklist.Blacklist
+	maxFailures int
+	callback    *url.URL
+	wg          sync.WaitGroup
+	done        chan struct{}
+	lock        sync.Mutex
+	waiting     map[int64][]waiter
+	hosts       *throttle
 }
 
 // New creates a new Reader. Duh.
-func New(workers int) (*Reader, error) {
+// The Reader's workers get their database connections from the given Opener.
+func New(workers int, open database.Opener) (*Reader, error) {
 	var (
 		err error
 		rdr = &Reader{
-			q:         make(chan model.Feed, workers),
-			workerCnt: workers,
+			q:           make(chan model.Feed, workers),
+			workerCnt:   workers,
+			maxFailures: DefaultMaxFailures,
+			waiting:     make(map[int64][]waiter),
+			hosts:       newThrottle(DefaultHostLimits()),
 		}
 	)
 
 	if rdr.log, err = common.GetLogger(logdomain.Reader); err != nil {
 		return nil, err
-	} else if rdr.pool, err = database.NewPool(workers); err != nil {
+	} else if rdr.pool, err = database.NewPoolFrom(workers, open); err != nil {
 		rdr.log.Printf("[ERROR] Cannot open database Pool: %s\n",
 			err.Error())
 		return nil, err
@@ -60,26 +97,82 @@ func New(workers int) (*Reader, error) {
 	}
 
 	return rdr, nil
-} // func New() (*Reader, error)
+} // func New(workers int, open database.Opener) (*Reader, error)
 
 // IsActive returns the Reader's active flag
 func (r *Reader) IsActive() bool {
 	return r.active.Load()
 } // func (r *Reader) IsActive() bool
 
-// Stop tells the Reader to stop.
-func (r *Reader) Stop() {
-	r.active.Store(false)
-} // func (r *Reader) Stop()
-
-// Start starts the Reader's worker goroutines.
-func (r *Reader) Start() {
+// SetMaxFailures sets the number of consecutive failures after which a Feed
+// is disabled. A value of zero or less means Feeds are never disabled.
+// It must be called before the Reader is started.
+func (r *Reader) SetMaxFailures(n int) {
+	r.maxFailures = n
+} // func (r *Reader) SetMaxFailures(n int)
+
+// Start starts the Reader's worker goroutines, along with the feeder that
+// queues Feeds as they become due. They keep running until ctx is cancelled,
+// use Wait to wait for them to finish.
+func (r *Reader) Start(ctx context.Context) {
+	r.start(ctx, true)
+} // func (r *Reader) Start(ctx context.Context)
+
+// StartWorkers starts the Reader's worker goroutines, but not the feeder, so
+// the Reader only fetches the Feeds passed to Refresh. Otherwise it works
+// like Start.
+func (r *Reader) StartWorkers(ctx context.Context) {
+	r.start(ctx, false)
+} // func (r *Reader) StartWorkers(ctx context.Context)
+
+func (r *Reader) start(ctx context.Context, feeder bool) {
+	r.done = make(chan struct{})
 	r.active.Store(true)
-	go r.feeder()
+	r.wg.Add(r.workerCnt)
+	if feeder {
+		r.wg.Add(1)
+		go r.feeder(ctx)
+	}
 	for i := 0; i < r.workerCnt; i++ {
-		go r.worker(i + 1)
+		go r.worker(ctx, i+1)
+	}
+	go r.shutdown()
+} // func (r *Reader) start(ctx context.Context, feeder bool)
+
+// Wait blocks until the Reader has stopped after the Context passed to Start
+// was cancelled. Feeds that were being processed at that time are processed
+// to the end, then the Blacklist is saved and the database Pool is closed.
+// The Reader cannot be used anymore after that.
+func (r *Reader) Wait() {
+	if r.done != nil {
+		<-r.done
+	}
+} // func (r *Reader) Wait()
+
+func (r *Reader) shutdown() {
+	var err error
+
+	defer close(r.done)
+
+	r.wg.Wait()
+	r.active.Store(false)
+	r.cancelRefresh()
+
+	r.log.Println("[INFO] Reader has stopped, cleaning up.")
+
+	if r.bl.Changed() {
+		if err = r.bl.Dump(common.Path(path.Blacklist)); err != nil {
+			r.log.Printf("[ERROR] Failed to dump Blacklist to %s: %s\n",
+				common.Path(path.Blacklist),
+				err.Error())
+		}
+	}
+
+	if err = r.pool.Close(); err != nil {
+		r.log.Printf("[ERROR] Failed to close database Pool: %s\n",
+			err.Error())
 	}
-} // func (r *Reader) Start()
+} // func (r *Reader) shutdown()
 
 func (r *Reader) getPendingFeeds() ([]model.Feed, error) {
 	var db = r.pool.Get()
@@ -89,17 +182,24 @@ func (r *Reader) getPendingFeeds() ([]model.Feed, error) {
 } // func (r *Reader) getPendingFeeds() ([]model.Feed, error)
 
 // This method could have used a better name, but I just could not resist the pun.
-func (r *Reader) feeder() {
+func (r *Reader) feeder(ctx context.Context) {
+	defer r.wg.Done()
+	defer r.log.Println("[INFO] Reader/feeder stopping.")
+
 	var ticker = time.NewTicker(checkInterval)
 	defer ticker.Stop()
 
-	for r.IsActive() {
-		<-ticker.C
-		r.checkFeeds()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.checkFeeds(ctx)
+		}
 	}
-} // func (r *Reader) feeder()
+} // func (r *Reader) feeder(ctx context.Context)
 
-func (r *Reader) checkFeeds() {
+func (r *Reader) checkFeeds(ctx context.Context) {
 	var (
 		err   error
 		feeds []model.Feed
@@ -113,6 +213,10 @@ func (r *Reader) checkFeeds() {
 		}
 	}
 
+	if r.callback != nil {
+		r.renewSubscriptions()
+	}
+
 	if feeds, err = r.getPendingFeeds(); err != nil {
 		r.log.Printf("[ERROR] Failed to load feeds that are due for a refresh: %s\n",
 			err.Error())
@@ -120,61 +224,427 @@ func (r *Reader) checkFeeds() {
 	}
 
 	for _, f := range feeds {
-		r.q <- f
+		select {
+		case r.q <- f:
+		case <-ctx.Done():
+			return
+		}
 	}
-} // func (r *Reader) checkFeeds()
+} // func (r *Reader) checkFeeds(ctx context.Context)
 
-func (r *Reader) worker(n int) {
+func (r *Reader) worker(ctx context.Context, n int) {
+	defer r.wg.Done()
 	defer r.log.Printf("[INFO] Reader/worker_%02d stopping.\n", n)
 
-	var ticker = time.NewTicker(time.Second * 5)
-	defer ticker.Stop()
-	for r.IsActive() {
+	for {
 		var (
-			err error
-			f   model.Feed
+			err   error
+			f     model.Feed
+			cnt   int
+			start time.Time
 		)
 
+		// Once we have picked up a Feed, we process it even if ctx
+		// gets cancelled in the meantime, so we do not leave it in a
+		// half-finished state.
 		select {
+		case <-ctx.Done():
+			return
 		case f = <-r.q:
-			if err = r.process(f); err != nil {
-				r.log.Printf("[ERROR] Error processing Feed %s (%d): %s\n",
-					f.Title,
-					f.ID,
-					err.Error())
+			var te *ThrottledError
+
+			start = time.Now()
+			if cnt, err = r.process(ctx, f); err != nil {
+				switch {
+				case ctx.Err() != nil && errors.Is(err, ctx.Err()):
+					r.log.Printf("[DEBUG] Processing Feed %s (%d) was interrupted\n",
+						f.Title,
+						f.ID)
+				case errors.As(err, &te) && te.Status == 0:
+					// We did not even try, so it does not count
+					// as a failure.
+					r.postpone(&f, te.Until)
+				default:
+					r.log.Printf("[ERROR] Error processing Feed %s (%d): %s\n",
+						f.Title,
+						f.ID,
+						err.Error())
+					r.recordFailure(&f, err)
+				}
+			} else if f.Failures > 0 {
+				r.resetFailures(&f)
 			}
-		case <-ticker.C:
+			r.report(RefreshResult{
+				Feed:     f,
+				NewItems: cnt,
+				Err:      err,
+				Duration: time.Since(start),
+			})
+
+			if err == nil {
+				r.checkIcon(ctx, f)
+			}
+		}
+	}
+} // func (r *Reader) worker(ctx context.Context, n int)
+
+// backoffDelay returns the time to wait before the next attempt to fetch a
+// Feed that has failed cnt times in a row.
+func backoffDelay(cnt int) time.Duration {
+	var delay = backoffBase
+
+	for i := 1; i < cnt && delay < backoffMax; i++ {
+		delay *= 2
+	}
+
+	if delay > backoffMax {
+		delay = backoffMax
+	}
+
+	return delay
+} // func backoffDelay(cnt int) time.Duration
+
+// recordFailure counts a failed attempt to fetch a Feed. If the Feed's host
+// has asked us to back off, we wait at least as long as it asked us to, but
+// we do not disable the Feed for it.
+func (r *Reader) recordFailure(f *model.Feed, ferr error) {
+	var (
+		err    error
+		db     database.Store
+		te     *ThrottledError
+		active = true
+		cnt    = f.Failures + 1
+		next   = time.Now().Add(backoffDelay(cnt))
+	)
+
+	if errors.As(ferr, &te) {
+		if te.Until.After(next) {
+			next = te.Until
+		}
+	} else if r.maxFailures > 0 && cnt >= r.maxFailures {
+		r.log.Printf("[WARN] Feed %s (%d) has failed %d times in a row, disabling it.\n",
+			f.Title,
+			f.ID,
+			cnt)
+		active = false
+	}
+
+	db = r.pool.Get()
+	defer r.pool.Put(db)
+
+	if err = db.FeedRecordFailure(f, ferr.Error(), next, active); err != nil {
+		r.log.Printf("[ERROR] Failed to record failure of Feed %s (%d): %s\n",
+			f.Title,
+			f.ID,
+			err.Error())
+	}
+} // func (r *Reader) recordFailure(f *model.Feed, ferr error)
+
+// postpone makes the Reader leave a Feed alone until the given time.
+func (r *Reader) postpone(f *model.Feed, until time.Time) {
+	var (
+		err error
+		db  = r.pool.Get()
+	)
+
+	defer r.pool.Put(db)
+
+	r.log.Printf("[DEBUG] Postpone Feed %s (%d) until %s\n",
+		f.Title,
+		f.ID,
+		until.Format(common.TimestampFormat))
+
+	if err = db.FeedPostpone(f, until); err != nil {
+		r.log.Printf("[ERROR] Failed to postpone Feed %s (%d): %s\n",
+			f.Title,
+			f.ID,
+			err.Error())
+	}
+} // func (r *Reader) postpone(f *model.Feed, until time.Time)
+
+func (r *Reader) resetFailures(f *model.Feed) {
+	var (
+		err error
+		db  = r.pool.Get()
+	)
+
+	defer r.pool.Put(db)
+
+	if err = db.FeedResetFailures(f); err != nil {
+		r.log.Printf("[ERROR] Failed to reset failure counter of Feed %s (%d): %s\n",
+			f.Title,
+			f.ID,
+			err.Error())
+	}
+} // func (r *Reader) resetFailures(f *model.Feed)
+
+// fetchContent downloads the article the given Item links to, extracts the
+// main text from it and stores it in the database.
+func (r *Reader) fetchContent(ctx context.Context, db database.Store, item *model.Item) {
+	var (
+		err     error
+		req     *http.Request
+		res     *http.Response
+		body    []byte
+		content string
+	)
+
+	if req, err = NewRequest(item.URL.String()); err != nil {
+		r.log.Printf("[ERROR] Cannot create request for article %s: %s\n",
+			item.URL,
+			err.Error())
+		return
+	} else if res, body, err = r.fetch(ctx, req, maxArticleSize); err != nil {
+		r.log.Printf("[ERROR] Failed to fetch article %s: %s\n",
+			item.URL,
+			err.Error())
+		return
+	}
+
+	if res.StatusCode != http.StatusOK {
+		r.log.Printf("[ERROR] Unexpected HTTP status fetching article %s: %s\n",
+			item.URL,
+			res.Status)
+		return
+	} else if ctype := res.Header.Get("Content-Type"); ctype != "" && !strings.Contains(ctype, "html") {
+		r.log.Printf("[DEBUG] Article %s has Content-Type %s, not extracting text\n",
+			item.URL,
+			ctype)
+		return
+	} else if content, err = Extract(bytes.NewReader(body)); err != nil {
+		r.log.Printf("[ERROR] Failed to extract content from article %s: %s\n",
+			item.URL,
+			err.Error())
+		return
+	} else if err = db.ItemContentAdd(item, content); err != nil {
+		r.log.Printf("[ERROR] Failed to store content of Item %q (%d): %s\n",
+			item.Headline,
+			item.ID,
+			err.Error())
+	}
+} // func (r *Reader) fetchContent(ctx context.Context, db database.Store, item *model.Item)
+
+// addEnclosures stores the Enclosures of a newly added Item. Whether they get
+// downloaded is up to the download manager.
+func (r *Reader) addEnclosures(db database.Store, item *model.Item, list []*gofeed.Enclosure) {
+	for _, fe := range list {
+		var (
+			err error
+			e   = &model.Enclosure{
+				ItemID:   item.ID,
+				MimeType: fe.Type,
+			}
+		)
+
+		if fe.URL == "" {
+			continue
+		} else if e.URL, err = item.URL.Parse(strings.TrimSpace(fe.URL)); err != nil {
+			r.log.Printf("[ERROR] Cannot parse URL of Enclosure %q of Item %q: %s\n",
+				fe.URL,
+				item.Headline,
+				err.Error())
 			continue
+		} else if fe.Length != "" {
+			if e.Length, err = strconv.ParseInt(strings.TrimSpace(fe.Length), 10, 64); err != nil || e.Length < 0 {
+				r.log.Printf("[DEBUG] Invalid length %q of Enclosure %s\n",
+					fe.Length,
+					e.URL)
+				e.Length = 0
+			}
+		}
+
+		if err = db.EnclosureAdd(e); err != nil {
+			r.log.Printf("[ERROR] Failed to add Enclosure %s of Item %q: %s\n",
+				e.URL,
+				item.Headline,
+				err.Error())
 		}
 	}
-} // func (r *Reader) worker()
+} // func (r *Reader) addEnclosures(db database.Store, item *model.Item, list []*gofeed.Enclosure)
+
+// itemChanged returns true if the Feed has changed the Headline or
+// Description of an Item we already know.
+func itemChanged(old, cur *model.Item) bool {
+	if strings.TrimSpace(cur.Headline) == "" {
+		return false
+	}
+
+	return strings.TrimSpace(old.Headline) != strings.TrimSpace(cur.Headline) ||
+		strings.TrimSpace(old.Description) != strings.TrimSpace(cur.Description)
+} // func itemChanged(old, cur *model.Item) bool
+
+// reviseItem saves the current version of an Item as a Revision and updates
+// the Item with the Headline and Description from the Feed.
+func (r *Reader) reviseItem(db database.Store, old, cur *model.Item) {
+	var err error
+
+	r.log.Printf("[DEBUG] Item %q (%d) was changed by its Feed\n",
+		old.Headline,
+		old.ID)
+
+	if err = db.ItemRevisionAdd(old, time.Now()); err != nil {
+		r.log.Printf("[ERROR] Failed to save revision of Item %q (%d): %s\n",
+			old.Headline,
+			old.ID,
+			err.Error())
+	} else if err = db.ItemUpdate(old, cur.Headline, cur.Description); err != nil {
+		r.log.Printf("[ERROR] Failed to update Item %q (%d): %s\n",
+			old.Headline,
+			old.ID,
+			err.Error())
+	}
+} // func (r *Reader) reviseItem(db database.Store, old, cur *model.Item)
 
-func (r *Reader) process(f model.Feed) error {
+// process fetches a Feed and adds its new Items to the database. ctx only
+// limits how long we wait to fetch the Feed, once we have it, we process it
+// to the end.
+func (r *Reader) process(ctx context.Context, f model.Feed) (int, error) {
 	var (
 		err  error
-		db   *database.Database
+		cnt  int
+		db   database.Store
 		fp   = gofeed.NewParser()
 		feed *gofeed.Feed
+		req  *http.Request
+		res  *http.Response
+		body []byte
 	)
 
-	if feed, err = fp.ParseURL(f.URL.String()); err != nil {
-		return err
+	if req, err = NewRequest(f.URL.String()); err != nil {
+		r.log.Printf("[ERROR] Cannot create request for Feed %s (%s): %s\n",
+			f.Title,
+			f.URL,
+			err.Error())
+		return 0, err
+	}
+
+	applyFeedSettings(req, &f)
+
+	if f.ETag != "" {
+		req.Header.Set("If-None-Match", f.ETag)
+	}
+
+	if f.LastModified != "" {
+		req.Header.Set("If-Modified-Since", f.LastModified)
+	}
+
+	if res, body, err = r.fetch(ctx, req, maxFeedSize+1); res == nil {
+		return 0, err
 	}
 
 	db = r.pool.Get()
 	defer r.pool.Put(db)
 
+	if errors.As(err, new(*ThrottledError)) {
+		// The host has asked us to back off.
+		if err2 := db.FeedUpdateHTTPState(&f, f.ETag, f.LastModified, res.StatusCode); err2 != nil {
+			r.log.Printf("[ERROR] Failed to store HTTP state of Feed %s (%d): %s\n",
+				f.Title,
+				f.ID,
+				err2.Error())
+		}
+		return 0, err
+	} else if err != nil {
+		// We got an answer, but could not read all of it.
+		return 0, err
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		// We keep the raw body around to look for a WebSub hub.
+		if len(body) > maxFeedSize {
+			return 0, ErrFeedTooLarge
+		} else if feed, err = fp.Parse(bytes.NewReader(body)); err != nil {
+			return 0, err
+		}
+	case http.StatusNotModified:
+		r.log.Printf("[TRACE] Feed %s (%d) has not been modified since %s\n",
+			f.Title,
+			f.ID,
+			f.LastRefresh.Format(common.TimestampFormat))
+		// Moving the Feed resets its conditional request state, but
+		// the server just told us it is still valid.
+		var etag, lastMod = f.ETag, f.LastModified
+		r.checkMoved(db, &f, res)
+		if err = db.FeedUpdateHTTPState(&f, etag, lastMod, res.StatusCode); err != nil {
+			return 0, err
+		} else if err = db.FeedUpdateRefresh(&f, time.Now()); err != nil {
+			return 0, err
+		}
+		r.reschedule(db, &f, res.Header, nil, nil)
+		return 0, nil
+	default:
+		if err = db.FeedUpdateHTTPState(&f, f.ETag, f.LastModified, res.StatusCode); err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("Unexpected HTTP status fetching %s: %s",
+			f.URL,
+			res.Status)
+	}
+
+	r.checkMoved(db, &f, res)
+
+	if cnt, err = r.ingest(ctx, db, &f, feed); err != nil {
+		return 0, err
+	}
+
+	r.updateMeta(db, &f, feed)
+
+	if r.callback != nil {
+		r.checkHub(db, &f, res.Header, body)
+	}
+
+	r.reschedule(db, &f, res.Header, body, feed)
+
+	if err = db.FeedUpdateHTTPState(&f, res.Header.Get("ETag"), res.Header.Get("Last-Modified"), res.StatusCode); err != nil {
+		r.log.Printf("[ERROR] Failed to store HTTP state of Feed %s (%d): %s\n",
+			f.Title,
+			f.ID,
+			err.Error())
+	}
+
+	if err = db.FeedUpdateRefresh(&f, time.Now()); err != nil {
+		r.log.Printf("[ERROR] Failed to set LastRefresh on Feed %s (%d): %s\n",
+			f.Title,
+			f.ID,
+			err.Error())
+	}
+
+	return cnt, nil
+} // func (r *Reader) process(ctx context.Context, f model.Feed) (int, error)
+
+// ingest adds the Items of a parsed Feed to the database. Items we know
+// already are checked for changes.
+// To keep lock contention between the workers low, we first look up which
+// Items are new or changed, then write all of them in a single transaction.
+// Full articles are fetched after the transaction is committed.
+// ingest returns the number of Items that were added.
+func (r *Reader) ingest(ctx context.Context, db database.Store, f *model.Feed, feed *gofeed.Feed) (int, error) {
+	type revision struct {
+		old, cur *model.Item
+	}
+
+	var (
+		err     error
+		fresh   = make([]*model.Item, 0, len(feed.Items))
+		encl    = make(map[*model.Item][]*gofeed.Enclosure)
+		changed []revision
+		added   []*model.Item
+		rules   []categoryRule
+	)
+
 	r.log.Printf("[DEBUG] Processing Feed %s, %d items\n",
 		feed.Title,
 		len(feed.Items))
 
-	// Should I use a transaction for adding new Items? :tinking-emoji:
-
 	for _, fitem := range feed.Items {
-		var item = model.Item{
+		var item = &model.Item{
 			FeedID:      f.ID,
 			Headline:    fitem.Title,
 			Description: fitem.Description,
+			GUID:        fitem.GUID,
+			Categories:  itemCategories(fitem),
+			Authors:     itemAuthors(fitem),
 		}
 
 		if item.URL, err = url.Parse(fitem.Link); err != nil {
@@ -191,37 +661,88 @@ func (r *Reader) process(f model.Feed) error {
 			item.Timestamp = time.Now()
 		}
 
-		var exists bool
+		var existing *model.Item
 
-		if r.bl.Match(&item) {
+		if r.bl.Match(item) {
 			// r.bl.Sort()
 			continue
 		}
 
-		if exists, err = db.ItemExists(&item); err != nil {
+		if existing, err = db.ItemGetByKey(item); err != nil {
 			r.log.Printf("[ERROR] Failed to check for Item %q: %s\n",
 				item.URL,
 				err.Error())
 			continue
-		} else if exists {
-			// r.log.Printf("[DEBUG] Item %q already exists in database.\n",
-			// 	item.URL)
+		} else if existing != nil {
+			if itemChanged(existing, item) {
+				changed = append(changed, revision{existing, item})
+			}
 			continue
-		} else if err = db.ItemAdd(&item); err != nil {
+		}
+
+		fresh = append(fresh, item)
+		if len(fitem.Enclosures) > 0 {
+			encl[item] = fitem.Enclosures
+		}
+	}
+
+	if len(fresh) == 0 && len(changed) == 0 {
+		return 0, nil
+	} else if len(fresh) > 0 {
+		rules = r.categoryRules(db, f)
+	}
+
+	if err = db.Begin(); err != nil {
+		r.log.Printf("[ERROR] Cannot start transaction to add Items of Feed %s (%d): %s\n",
+			f.Title,
+			f.ID,
+			err.Error())
+		return 0, err
+	}
+
+	for _, rev := range changed {
+		r.reviseItem(db, rev.old, rev.cur)
+	}
+
+	for _, item := range fresh {
+		var ok bool
+
+		if ok, err = db.ItemUpsert(item); err != nil {
 			r.log.Printf("[ERROR] Failed to add item %q (%s) to database: %s\n",
 				item.URL,
 				item.Headline,
 				err.Error())
 			continue
+		} else if !ok {
+			// Another worker, or the same Feed, has added an Item
+			// with the same URL in the meantime.
+			continue
+		} else if len(encl[item]) > 0 {
+			r.addEnclosures(db, item, encl[item])
 		}
+
+		if len(rules) > 0 {
+			r.applyCategories(db, item, rules)
+		}
+
+		added = append(added, item)
 	}
 
-	if err = db.FeedUpdateRefresh(&f, time.Now()); err != nil {
-		r.log.Printf("[ERROR] Failed to set LastRefresh on Feed %s (%d): %s\n",
+	if err = db.Commit(); err != nil {
+		r.log.Printf("[ERROR] Failed to commit Items of Feed %s (%d): %s\n",
 			f.Title,
 			f.ID,
 			err.Error())
+		// A failed Commit ends the transaction, too, there is nothing
+		// left to roll back.
+		return 0, err
+	}
+
+	if f.FetchFull {
+		for _, item := range added {
+			r.fetchContent(ctx, db, item)
+		}
 	}
 
-	return nil
-} // func (r *Reader) process(f model.Feed)
+	return len(added), nil
+} // func (r *Reader) ingest(ctx context.Context, db database.Store, f *model.Feed, feed *gofeed.Feed) (int, error)
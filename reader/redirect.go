@@ -0,0 +1,80 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/redirect.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 23:31:47 krylon>
+
+package reader
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/model"
+)
+
+// permanentRedirect returns the URL we ended up at if the request that
+// produced the given response was redirected permanently (301 or 308).
+// If a temporary redirect follows, we only consider the permanent redirects
+// before it. If there was no permanent redirect, it returns nil.
+func permanentRedirect(res *http.Response) (*url.URL, int) {
+	var (
+		chain  []*http.Request
+		target *url.URL
+		code   int
+	)
+
+	// The http.Client links each request it makes to follow a redirect to
+	// the response that caused it, so we walk the chain backwards.
+	for req := res.Request; req != nil; {
+		chain = append(chain, req)
+		if req.Response == nil {
+			break
+		}
+		req = req.Response.Request
+	}
+
+	for i := len(chain) - 2; i >= 0; i-- {
+		var status = chain[i].Response.StatusCode
+
+		if status != http.StatusMovedPermanently && status != http.StatusPermanentRedirect {
+			break
+		}
+
+		target = chain[i].URL
+		code = status
+	}
+
+	return target, code
+} // func permanentRedirect(res *http.Response) (*url.URL, int)
+
+// checkMoved updates the URL of a Feed that has moved permanently. We only
+// call it after we have successfully fetched the Feed from its new location.
+func (r *Reader) checkMoved(db database.Store, f *model.Feed, res *http.Response) {
+	var (
+		err    error
+		target *url.URL
+		code   int
+		old    = f.URL.String()
+	)
+
+	if target, code = permanentRedirect(res); target == nil || target.String() == old {
+		return
+	}
+
+	r.log.Printf("[INFO] Feed %s (%d) has moved from %s to %s\n",
+		f.Title,
+		f.ID,
+		old,
+		target)
+
+	if err = db.FeedSetURL(f, target, fmt.Sprintf("Moved permanently (HTTP %d)", code)); err != nil {
+		r.log.Printf("[ERROR] Failed to update URL of Feed %s (%d) to %s: %s\n",
+			f.Title,
+			f.ID,
+			target,
+			err.Error())
+	}
+} // func (r *Reader) checkMoved(db database.Store, f *model.Feed, res *http.Response)
@@ -0,0 +1,125 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/refresh.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 22:41:09 krylon>
+
+package reader
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/blicero/badnews/model"
+)
+
+// ErrNotRunning is returned if we ask the Reader to refresh Feeds while it is
+// not running.
+var ErrNotRunning = errors.New("reader is not running")
+
+// RefreshResult is the outcome of a manual refresh of a Feed.
+type RefreshResult struct {
+	Feed     model.Feed
+	NewItems int
+	Err      error
+	Duration time.Duration
+}
+
+// waiter is someone waiting for a Feed to be refreshed.
+type waiter struct {
+	f       model.Feed
+	results chan<- RefreshResult
+}
+
+// Refresh queues the given Feeds for an immediate refresh, regardless of
+// when they are due. The returned channel receives one RefreshResult for
+// each Feed as soon as the Feed has been processed.
+// If ctx is cancelled before a Feed could be queued, or the Reader stops
+// before it got around to the Feed, the Feed's RefreshResult carries the
+// respective error.
+func (r *Reader) Refresh(ctx context.Context, feeds ...model.Feed) (<-chan RefreshResult, error) {
+	if !r.IsActive() {
+		return nil, ErrNotRunning
+	}
+
+	var results = make(chan RefreshResult, len(feeds))
+
+	r.lock.Lock()
+	for _, f := range feeds {
+		r.waiting[f.ID] = append(r.waiting[f.ID], waiter{f: f, results: results})
+	}
+	r.lock.Unlock()
+
+	// The queue only has room for one Feed per worker, we do not want
+	// our caller to wait for that.
+	go r.enqueue(ctx, feeds, results)
+
+	return results, nil
+} // func (r *Reader) Refresh(ctx context.Context, feeds ...model.Feed) (<-chan RefreshResult, error)
+
+func (r *Reader) enqueue(ctx context.Context, feeds []model.Feed, results chan<- RefreshResult) {
+	for idx, f := range feeds {
+		select {
+		case r.q <- f:
+			continue
+		case <-ctx.Done():
+			r.withdraw(feeds[idx:], results, ctx.Err())
+		case <-r.done:
+			r.withdraw(feeds[idx:], results, ErrNotRunning)
+		}
+		return
+	}
+} // func (r *Reader) enqueue(ctx context.Context, feeds []model.Feed, results chan<- RefreshResult)
+
+// withdraw removes the given Feeds from the list of Feeds we are waiting for
+// and reports the error as their result.
+func (r *Reader) withdraw(feeds []model.Feed, results chan<- RefreshResult, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, f := range feeds {
+		var list = r.waiting[f.ID]
+
+		for idx, w := range list {
+			if w.results == results {
+				list = append(list[:idx], list[idx+1:]...)
+				results <- RefreshResult{Feed: f, Err: err}
+				break
+			}
+		}
+
+		if len(list) == 0 {
+			delete(r.waiting, f.ID)
+		} else {
+			r.waiting[f.ID] = list
+		}
+	}
+} // func (r *Reader) withdraw(feeds []model.Feed, results chan<- RefreshResult, err error)
+
+// report passes the outcome of processing a Feed on to anyone who asked for
+// it to be refreshed.
+func (r *Reader) report(res RefreshResult) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, w := range r.waiting[res.Feed.ID] {
+		w.results <- res
+	}
+
+	delete(r.waiting, res.Feed.ID)
+} // func (r *Reader) report(res RefreshResult)
+
+// cancelRefresh reports all Feeds that are still waiting to be refreshed as
+// failed once the Reader has stopped.
+func (r *Reader) cancelRefresh() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for id, list := range r.waiting {
+		for _, w := range list {
+			w.results <- RefreshResult{Feed: w.f, Err: ErrNotRunning}
+		}
+		delete(r.waiting, id)
+	}
+} // func (r *Reader) cancelRefresh()
@@ -0,0 +1,267 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/schedule.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 20:31:16 krylon>
+
+package reader
+
+import (
+	"bytes"
+	"encoding/xml"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/model"
+	"github.com/mmcdole/gofeed"
+)
+
+// Instead of checking every Feed at a fixed interval, we try to check it
+// about twice as often as it publishes new Items. Where the Feed (or the
+// server) tells us how long we may cache it, we do not check it more often
+// than that, and we honor the skipHours and skipDays of RSS feeds.
+// The UpdateInterval set by the user is either a lower or an upper bound,
+// depending on the Feed's IntervalBound.
+
+const (
+	minInterval    = time.Minute * 5
+	maxInterval    = time.Hour * 24
+	jitterFactor   = 0.1
+	cadenceSamples = 20
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// scanSchedule extracts the ttl, skipHours and skipDays elements from the
+// channel of an RSS feed.
+func scanSchedule(body []byte) (ttl time.Duration, hours uint32, days uint8) {
+	var dec = xml.NewDecoder(bytes.NewReader(body))
+	dec.Strict = false
+
+	for {
+		var (
+			err error
+			tok xml.Token
+		)
+
+		if tok, err = dec.Token(); err != nil {
+			return
+		}
+
+		var elt, ok = tok.(xml.StartElement)
+
+		if !ok {
+			continue
+		}
+
+		switch elt.Name.Local {
+		case "item", "entry":
+			return
+		case "ttl":
+			var (
+				txt     string
+				minutes int
+			)
+
+			if err = dec.DecodeElement(&txt, &elt); err != nil {
+				continue
+			} else if minutes, err = strconv.Atoi(strings.TrimSpace(txt)); err == nil && minutes > 0 {
+				ttl = time.Minute * time.Duration(minutes)
+			}
+		case "skipHours":
+			var skip struct {
+				Hours []string `xml:"hour"`
+			}
+
+			if err = dec.DecodeElement(&skip, &elt); err != nil {
+				continue
+			}
+
+			for _, h := range skip.Hours {
+				var hour int
+
+				// Some feeds use 24 for midnight.
+				if hour, err = strconv.Atoi(strings.TrimSpace(h)); err == nil && hour >= 0 && hour <= 24 {
+					hours |= 1 << uint(hour%24)
+				}
+			}
+		case "skipDays":
+			var skip struct {
+				Days []string `xml:"day"`
+			}
+
+			if err = dec.DecodeElement(&skip, &elt); err != nil {
+				continue
+			}
+
+			for _, d := range skip.Days {
+				if wd, ok := weekdays[strings.ToLower(strings.TrimSpace(d))]; ok {
+					days |= 1 << uint(wd)
+				}
+			}
+		}
+	}
+} // func scanSchedule(body []byte) (ttl time.Duration, hours uint32, days uint8)
+
+// maxAge returns the max-age from the Cache-Control header of a response,
+// or zero if there is none.
+func maxAge(header http.Header) time.Duration {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		var (
+			name, value, _ = strings.Cut(strings.TrimSpace(directive), "=")
+			seconds        int
+			err            error
+		)
+
+		if !strings.EqualFold(name, "max-age") {
+			continue
+		} else if seconds, err = strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds > 0 {
+			return time.Second * time.Duration(seconds)
+		}
+	}
+
+	return 0
+} // func maxAge(header http.Header) time.Duration
+
+// measureCadence returns the median time between the most recent Items of a
+// Feed, or zero if the Feed has too few Items with a timestamp.
+func measureCadence(feed *gofeed.Feed) time.Duration {
+	var (
+		stamps = make([]time.Time, 0, len(feed.Items))
+		gaps   []time.Duration
+		now    = time.Now()
+	)
+
+	for _, item := range feed.Items {
+		var stamp *time.Time
+
+		if item.PublishedParsed != nil {
+			stamp = item.PublishedParsed
+		} else if item.UpdatedParsed != nil {
+			stamp = item.UpdatedParsed
+		}
+
+		if stamp != nil && !stamp.IsZero() && stamp.Before(now) {
+			stamps = append(stamps, *stamp)
+		}
+	}
+
+	if len(stamps) < 3 {
+		return 0
+	}
+
+	slices.SortFunc(stamps, func(a, b time.Time) int { return b.Compare(a) })
+
+	if len(stamps) > cadenceSamples+1 {
+		stamps = stamps[:cadenceSamples+1]
+	}
+
+	gaps = make([]time.Duration, 0, len(stamps)-1)
+
+	for i := 1; i < len(stamps); i++ {
+		if gap := stamps[i-1].Sub(stamps[i]); gap > 0 {
+			gaps = append(gaps, gap)
+		}
+	}
+
+	if len(gaps) < 2 {
+		return 0
+	}
+
+	slices.Sort(gaps)
+
+	return gaps[len(gaps)/2]
+} // func measureCadence(feed *gofeed.Feed) time.Duration
+
+// nextRefresh computes when the given Feed should be refreshed next.
+func nextRefresh(f *model.Feed, cacheAge time.Duration, now time.Time) time.Time {
+	var (
+		interval = f.UpdateInterval
+		next     time.Time
+		sched    = &f.Schedule
+	)
+
+	if sched.Cadence > 0 {
+		interval = min(max(sched.Cadence/2, minInterval), maxInterval)
+	}
+
+	interval = max(interval, sched.TTL, cacheAge)
+
+	switch f.Bound {
+	case model.BoundLower:
+		interval = max(interval, f.UpdateInterval)
+	case model.BoundUpper:
+		interval = min(interval, f.UpdateInterval)
+	}
+
+	// Spread out the Feeds, so they do not all become due at the same time.
+	interval += time.Duration((rand.Float64()*2 - 1) * jitterFactor * float64(interval))
+	next = now.Add(interval)
+
+	if sched.SkipHours == 0 && sched.SkipDays == 0 {
+		return next
+	}
+
+	var skipped = next
+
+	for i := 0; i < 24*7 && sched.Skip(skipped); i++ {
+		skipped = skipped.Truncate(time.Hour).Add(time.Hour)
+	}
+
+	if sched.Skip(skipped) {
+		// The Feed asks us never to check it, we ignore that.
+		return next
+	} else if f.Bound == model.BoundUpper && skipped.Sub(now) > f.UpdateInterval {
+		return next
+	}
+
+	return skipped
+} // func nextRefresh(f *model.Feed, cacheAge time.Duration, now time.Time) time.Time
+
+// reschedule updates the Feed's schedule after we have fetched it. feed and
+// body are nil if the Feed was not modified, in that case we keep what we
+// learned before.
+func (r *Reader) reschedule(db database.Store, f *model.Feed, header http.Header, body []byte, feed *gofeed.Feed) {
+	var (
+		err   error
+		sched = f.Schedule
+		c     = f.Clone()
+	)
+
+	if feed != nil {
+		sched.TTL, sched.SkipHours, sched.SkipDays = scanSchedule(body)
+
+		if cadence := measureCadence(feed); cadence == 0 {
+			sched.Cadence = 0
+		} else if sched.Cadence == 0 {
+			sched.Cadence = cadence
+		} else {
+			// Smooth out the measurements, so a single burst of
+			// Items does not throw us off too much.
+			sched.Cadence = (sched.Cadence*3 + cadence) / 4
+		}
+	}
+
+	c.Schedule = sched
+	sched.Next = nextRefresh(c, maxAge(header), time.Now())
+
+	if err = db.FeedSetSchedule(f, sched); err != nil {
+		r.log.Printf("[ERROR] Failed to update schedule of Feed %s (%d): %s\n",
+			f.Title,
+			f.ID,
+			err.Error())
+	}
+} // func (r *Reader) reschedule(db database.Store, f *model.Feed, header http.Header, body []byte, feed *gofeed.Feed)
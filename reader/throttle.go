@@ -0,0 +1,354 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/throttle.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 23:58:20 krylon>
+
+package reader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blicero/badnews/common"
+)
+
+// Unless configured otherwise, we make at most DefaultHostConcurrency
+// requests to the same host at a time. After an initial burst of
+// DefaultHostBurst requests, we make on average one request every
+// DefaultHostInterval.
+const (
+	DefaultHostConcurrency = 2
+	DefaultHostInterval    = time.Second
+	DefaultHostBurst       = 4
+)
+
+// retryAfterDefault is how long we leave a host alone that answered with
+// HTTP 429 without telling us how long to wait.
+const retryAfterDefault = time.Minute * 5
+
+// HostLimits restricts how hard the Reader hits any single host.
+// A Concurrency or Interval of zero or less means no limit.
+type HostLimits struct {
+	Concurrency int
+	Interval    time.Duration
+	Burst       int
+}
+
+// DefaultHostLimits returns the HostLimits the Reader uses unless configured
+// otherwise.
+func DefaultHostLimits() HostLimits {
+	return HostLimits{
+		Concurrency: DefaultHostConcurrency,
+		Interval:    DefaultHostInterval,
+		Burst:       DefaultHostBurst,
+	}
+} // func DefaultHostLimits() HostLimits
+
+// ThrottledError is returned when a host has asked us to back off. If Status
+// is zero, we did not make a request at all, because the host had asked us
+// to back off before.
+type ThrottledError struct {
+	Host   string
+	Until  time.Time
+	Status int
+}
+
+func (e *ThrottledError) Error() string {
+	if e.Status == 0 {
+		return fmt.Sprintf("Host %s is throttled until %s",
+			e.Host,
+			e.Until.Format(time.DateTime))
+	}
+
+	return fmt.Sprintf("Host %s asked us to back off until %s (HTTP %d)",
+		e.Host,
+		e.Until.Format(time.DateTime),
+		e.Status)
+} // func (e *ThrottledError) Error() string
+
+// HostStatus describes how the Reader is throttling requests to a host.
+type HostStatus struct {
+	Host         string
+	InFlight     int
+	Waiting      int
+	BlockedUntil time.Time
+	Status       int
+}
+
+// Blocked returns true if the host has asked us to back off.
+func (s *HostStatus) Blocked() bool {
+	return time.Now().Before(s.BlockedUntil)
+} // func (s *HostStatus) Blocked() bool
+
+// Busy returns true if the Reader is currently throttling requests to the
+// host in any way.
+func (s *HostStatus) Busy() bool {
+	return s.InFlight > 0 || s.Waiting > 0 || s.Blocked()
+} // func (s *HostStatus) Busy() bool
+
+type hostState struct {
+	slots   chan struct{}
+	tokens  float64
+	last    time.Time
+	waiting int
+	until   time.Time
+	status  int
+}
+
+// throttle keeps track of the requests we make to each host. It limits the
+// number of concurrent requests and uses a token bucket to limit the rate.
+type throttle struct {
+	lock   sync.Mutex
+	limits HostLimits
+	hosts  map[string]*hostState
+}
+
+func newThrottle(limits HostLimits) *throttle {
+	if limits.Burst < 1 {
+		limits.Burst = 1
+	}
+
+	return &throttle{
+		limits: limits,
+		hosts:  make(map[string]*hostState),
+	}
+} // func newThrottle(limits HostLimits) *throttle
+
+// state returns the state for the given host. The caller must hold the lock.
+func (t *throttle) state(host string) *hostState {
+	var st = t.hosts[host]
+
+	if st == nil {
+		st = &hostState{
+			tokens: float64(t.limits.Burst),
+			last:   time.Now(),
+		}
+		if t.limits.Concurrency > 0 {
+			st.slots = make(chan struct{}, t.limits.Concurrency)
+		}
+		t.hosts[host] = st
+	}
+
+	return st
+} // func (t *throttle) state(host string) *hostState
+
+// acquire blocks until we may make a request to the given host. If the host
+// has asked us to back off, it returns a ThrottledError right away. If ctx
+// is cancelled while we wait, it returns ctx.Err().
+// If acquire returns nil, the caller must call release when it is done.
+func (t *throttle) acquire(ctx context.Context, host string) error {
+	var st *hostState
+
+	t.lock.Lock()
+	st = t.state(host)
+	st.waiting++
+	t.lock.Unlock()
+
+	defer func() {
+		t.lock.Lock()
+		st.waiting--
+		t.lock.Unlock()
+	}()
+
+	if st.slots != nil {
+		select {
+		case st.slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	for {
+		var (
+			wait time.Duration
+			now  = time.Now()
+		)
+
+		t.lock.Lock()
+		if now.Before(st.until) {
+			var err = &ThrottledError{Host: host, Until: st.until}
+			t.lock.Unlock()
+			t.freeSlot(st)
+			return err
+		} else if t.limits.Interval <= 0 {
+			t.lock.Unlock()
+			return nil
+		}
+
+		st.tokens += float64(now.Sub(st.last)) / float64(t.limits.Interval)
+		st.last = now
+		if st.tokens > float64(t.limits.Burst) {
+			st.tokens = float64(t.limits.Burst)
+		}
+
+		if st.tokens >= 1 {
+			st.tokens--
+			t.lock.Unlock()
+			return nil
+		}
+
+		wait = time.Duration((1 - st.tokens) * float64(t.limits.Interval))
+		t.lock.Unlock()
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			t.freeSlot(st)
+			return ctx.Err()
+		}
+	}
+} // func (t *throttle) acquire(ctx context.Context, host string) error
+
+// release marks a request to the given host as finished.
+func (t *throttle) release(host string) {
+	var st *hostState
+
+	t.lock.Lock()
+	st = t.hosts[host]
+	t.lock.Unlock()
+
+	if st != nil {
+		t.freeSlot(st)
+	}
+} // func (t *throttle) release(host string)
+
+func (t *throttle) freeSlot(st *hostState) {
+	if st.slots != nil {
+		<-st.slots
+	}
+} // func (t *throttle) freeSlot(st *hostState)
+
+// block makes us leave the given host alone until the given time.
+func (t *throttle) block(host string, until time.Time, status int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var st = t.state(host)
+
+	if until.After(st.until) {
+		st.until = until
+		st.status = status
+	}
+} // func (t *throttle) block(host string, until time.Time, status int)
+
+// status returns the current throttling state of the given host.
+func (t *throttle) status(host string) HostStatus {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var (
+		st = t.hosts[host]
+		hs = HostStatus{Host: host}
+	)
+
+	if st != nil {
+		hs.InFlight = len(st.slots)
+		hs.Waiting = st.waiting
+		hs.BlockedUntil = st.until
+		hs.Status = st.status
+	}
+
+	return hs
+} // func (t *throttle) status(host string) HostStatus
+
+// retryAfter parses the Retry-After header of a response, which may hold
+// either a number of seconds or a timestamp. We never wait longer than
+// backoffMax.
+func retryAfter(h http.Header, now time.Time) (time.Time, bool) {
+	var (
+		err   error
+		secs  int64
+		until time.Time
+		val   = strings.TrimSpace(h.Get("Retry-After"))
+	)
+
+	if val == "" {
+		return until, false
+	} else if secs, err = strconv.ParseInt(val, 10, 64); err == nil {
+		if secs < 0 {
+			return until, false
+		} else if secs > int64(backoffMax/time.Second) {
+			secs = int64(backoffMax / time.Second)
+		}
+		until = now.Add(time.Duration(secs) * time.Second)
+	} else if until, err = http.ParseTime(val); err != nil {
+		return until, false
+	} else if until.Before(now) {
+		until = now
+	} else if until.After(now.Add(backoffMax)) {
+		until = now.Add(backoffMax)
+	}
+
+	return until, true
+} // func retryAfter(h http.Header, now time.Time) (time.Time, bool)
+
+// SetHostLimits sets the limits for requests to any single host.
+// It must be called before the Reader is started.
+func (r *Reader) SetHostLimits(limits HostLimits) {
+	r.hosts = newThrottle(limits)
+} // func (r *Reader) SetHostLimits(limits HostLimits)
+
+// HostStatus returns how the Reader is currently throttling requests to the
+// given host.
+func (r *Reader) HostStatus(host string) HostStatus {
+	return r.hosts.status(host)
+} // func (r *Reader) HostStatus(host string) HostStatus
+
+// fetch performs a request and reads at most limit bytes of the response
+// body, observing the limits for the request's host. ctx only limits how
+// long we wait for our turn, once we have made the request, we see it
+// through.
+// If the host answers with HTTP 429, or 503 with a Retry-After header, we
+// leave it alone for the time it asks for and return a ThrottledError along
+// with the response.
+func (r *Reader) fetch(ctx context.Context, req *http.Request, limit int64) (*http.Response, []byte, error) {
+	var (
+		err  error
+		res  *http.Response
+		body []byte
+		host = req.URL.Host
+	)
+
+	if err = r.hosts.acquire(ctx, host); err != nil {
+		return nil, nil, err
+	}
+
+	defer r.hosts.release(host)
+
+	if res, err = Client().Do(req); err != nil {
+		return nil, nil, err
+	}
+
+	defer res.Body.Close() // nolint: errcheck
+
+	switch res.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		var until, ok = retryAfter(res.Header, time.Now())
+
+		if !ok && res.StatusCode == http.StatusServiceUnavailable {
+			break
+		} else if !ok {
+			until = time.Now().Add(retryAfterDefault)
+		}
+
+		r.log.Printf("[WARN] %s asked us to back off until %s (%s)\n",
+			host,
+			until.Format(common.TimestampFormat),
+			res.Status)
+		r.hosts.block(host, until, res.StatusCode)
+		return res, nil, &ThrottledError{Host: host, Until: until, Status: res.StatusCode}
+	}
+
+	if body, err = io.ReadAll(io.LimitReader(res.Body, limit)); err != nil {
+		return res, nil, err
+	}
+
+	return res, body, nil
+} // func (r *Reader) fetch(ctx context.Context, req *http.Request, limit int64) (*http.Response, []byte, error)
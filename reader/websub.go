@@ -0,0 +1,610 @@
+// /home/krylon/go/src/github.com/blicero/badnews/reader/websub.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 19:12:40 krylon>
+
+package reader
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" // nolint: gosec
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blicero/badnews/database"
+	"github.com/blicero/badnews/model"
+	"github.com/mmcdole/gofeed"
+)
+
+// Feeds that advertise a WebSub hub (https://www.w3.org/TR/websub/) can push
+// updates to us instead of us polling them. We subscribe when we see a hub
+// while processing a Feed, the web server passes the hub's requests on to the
+// Reader. As long as a subscription is active, the Feed is only polled once
+// a day, in case the hub stops pushing updates without telling us.
+
+const (
+	leaseDuration  = time.Hour * 24 * 10
+	renewMargin    = time.Hour * 24
+	pendingTimeout = time.Hour
+	secretSize     = 24
+)
+
+// ErrUnknownSubscription is returned if a hub refers to a Subscription we do
+// not know about.
+var ErrUnknownSubscription = errors.New("unknown subscription")
+
+// ErrInvalidSignature is returned if the signature of a pushed update does
+// not match the Subscription's secret.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// SetCallback sets the base URL under which the web server can be reached by
+// WebSub hubs. If it is not set, the Reader does not subscribe to hubs.
+// It must be called before the Reader is started.
+func (r *Reader) SetCallback(base *url.URL) {
+	r.callback = base
+} // func (r *Reader) SetCallback(base *url.URL)
+
+// callbackURL returns the URL the hub for the given Feed should deliver to.
+func (r *Reader) callbackURL(f *model.Feed) string {
+	return r.callback.JoinPath("websub", strconv.FormatInt(f.ID, 10)).String()
+} // func (r *Reader) callbackURL(f *model.Feed) string
+
+// discoverHub looks for the hub and self links of a Feed, first in the
+// Link headers of the response, then in the Feed itself.
+func discoverHub(header http.Header, body []byte) (hub, self string) {
+	for _, val := range header.Values("Link") {
+		for _, link := range strings.Split(val, ",") {
+			var (
+				href   string
+				params []string
+			)
+
+			params = strings.Split(link, ";")
+			href = strings.TrimSpace(params[0])
+
+			if !strings.HasPrefix(href, "<") || !strings.HasSuffix(href, ">") {
+				continue
+			}
+
+			href = href[1 : len(href)-1]
+
+			for _, p := range params[1:] {
+				var name, value, ok = strings.Cut(strings.TrimSpace(p), "=")
+
+				if !ok || !strings.EqualFold(name, "rel") {
+					continue
+				}
+
+				for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+					switch strings.ToLower(rel) {
+					case "hub":
+						if hub == "" {
+							hub = href
+						}
+					case "self":
+						if self == "" {
+							self = href
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if hub != "" {
+		return hub, self
+	}
+
+	var dec = xml.NewDecoder(bytes.NewReader(body))
+	dec.Strict = false
+
+	for {
+		var (
+			err error
+			tok xml.Token
+		)
+
+		if tok, err = dec.Token(); err != nil {
+			return hub, self
+		}
+
+		var elt, ok = tok.(xml.StartElement)
+
+		if !ok {
+			continue
+		}
+
+		switch elt.Name.Local {
+		case "item", "entry":
+			// The hub is advertised at the top of the Feed, there is no
+			// need to look at the Items.
+			return hub, self
+		case "link":
+			var rel, href string
+
+			for _, attr := range elt.Attr {
+				switch attr.Name.Local {
+				case "rel":
+					rel = attr.Value
+				case "href":
+					href = attr.Value
+				}
+			}
+
+			if href == "" {
+				continue
+			}
+
+			for _, rv := range strings.Fields(rel) {
+				switch strings.ToLower(rv) {
+				case "hub":
+					if hub == "" {
+						hub = href
+					}
+				case "self":
+					if self == "" {
+						self = href
+					}
+				}
+			}
+		}
+	}
+} // func discoverHub(header http.Header, body []byte) (hub, self string)
+
+// checkHub subscribes to the Feed's hub, if it advertises one and we have not
+// subscribed already.
+func (r *Reader) checkHub(db database.Store, f *model.Feed, header http.Header, body []byte) {
+	var (
+		err        error
+		hub, self  string
+		hubURL     *url.URL
+		topic      = f.URL
+		sub        *model.Subscription
+		secret     = make([]byte, secretSize)
+		hubAddress string
+	)
+
+	if hub, self = discoverHub(header, body); hub == "" {
+		return
+	} else if hubURL, err = f.URL.Parse(hub); err != nil {
+		r.log.Printf("[ERROR] Cannot parse hub URL %q of Feed %s (%d): %s\n",
+			hub,
+			f.Title,
+			f.ID,
+			err.Error())
+		return
+	} else if self != "" {
+		if topic, err = f.URL.Parse(self); err != nil {
+			r.log.Printf("[ERROR] Cannot parse self URL %q of Feed %s (%d): %s\n",
+				self,
+				f.Title,
+				f.ID,
+				err.Error())
+			return
+		}
+	}
+
+	hubAddress = hubURL.String()
+
+	if sub, err = db.WebSubGetByFeed(f); err != nil {
+		r.log.Printf("[ERROR] Cannot load Subscription of Feed %s (%d): %s\n",
+			f.Title,
+			f.ID,
+			err.Error())
+		return
+	} else if sub != nil && sub.State == model.SubUnsubscribing {
+		// The Feed was deactivated and is back now. We start over, so
+		// we do not confirm our old request to unsubscribe anymore.
+		if err = db.WebSubDelete(sub); err != nil {
+			r.log.Printf("[ERROR] Cannot delete Subscription of Feed %s (%d): %s\n",
+				f.Title,
+				f.ID,
+				err.Error())
+			return
+		}
+	} else if sub != nil && sub.Hub.String() == hubAddress && sub.Topic.String() == topic.String() {
+		// Renewing existing subscriptions is handled by
+		// renewSubscriptions.
+		return
+	}
+
+	if _, err = rand.Read(secret); err != nil {
+		r.log.Printf("[ERROR] Cannot generate secret for Subscription: %s\n",
+			err.Error())
+		return
+	}
+
+	sub = &model.Subscription{
+		FeedID: f.ID,
+		Hub:    hubURL,
+		Topic:  topic,
+		Secret: hex.EncodeToString(secret),
+	}
+
+	if err = r.subscribe(db, f, sub); err != nil {
+		r.log.Printf("[ERROR] Failed to subscribe to Feed %s (%d) via %s: %s\n",
+			f.Title,
+			f.ID,
+			hubAddress,
+			err.Error())
+	}
+} // func (r *Reader) checkHub(db database.Store, f *model.Feed, header http.Header, body []byte)
+
+// subscribe asks the hub to (re-)subscribe us to the given Subscription's
+// topic. The Subscription is saved before we send the request, because the
+// hub may verify our intent before it answers.
+func (r *Reader) subscribe(db database.Store, f *model.Feed, sub *model.Subscription) error {
+	var (
+		err  error
+		res  *http.Response
+		form = url.Values{
+			"hub.callback":      []string{r.callbackURL(f)},
+			"hub.mode":          []string{"subscribe"},
+			"hub.topic":         []string{sub.Topic.String()},
+			"hub.secret":        []string{sub.Secret},
+			"hub.lease_seconds": []string{strconv.Itoa(int(leaseDuration.Seconds()))},
+		}
+	)
+
+	r.log.Printf("[INFO] Subscribe to Feed %s (%d) via %s\n",
+		f.Title,
+		f.ID,
+		sub.Hub)
+
+	sub.Requested = time.Now()
+
+	if err = db.WebSubAdd(sub); err != nil {
+		return err
+	} else if res, err = postHub(sub.Hub, form); err != nil {
+		// renewSubscriptions will try again later.
+		return err
+	}
+
+	defer res.Body.Close() // nolint: errcheck
+
+	switch {
+	case res.StatusCode >= 200 && res.StatusCode < 300:
+		return nil
+	case res.StatusCode >= 400 && res.StatusCode < 500:
+		if err = db.WebSubSetState(sub, model.SubDenied); err != nil {
+			return err
+		}
+		fallthrough
+	default:
+		return fmt.Errorf("Hub %s replied with %s", sub.Hub, res.Status)
+	}
+} // func (r *Reader) subscribe(db database.Store, f *model.Feed, sub *model.Subscription) error
+
+// unsubscribe asks the hub to stop pushing updates for the given
+// Subscription's topic. Like subscribe, it saves the new state of the
+// Subscription before it sends the request, so Verify knows we asked for it.
+func (r *Reader) unsubscribe(db database.Store, f *model.Feed, sub *model.Subscription) error {
+	var (
+		err  error
+		res  *http.Response
+		form = url.Values{
+			"hub.callback": []string{r.callbackURL(f)},
+			"hub.mode":     []string{"unsubscribe"},
+			"hub.topic":    []string{sub.Topic.String()},
+		}
+	)
+
+	r.log.Printf("[INFO] Unsubscribe from Feed %s (%d) via %s\n",
+		f.Title,
+		f.ID,
+		sub.Hub)
+
+	if err = db.WebSubSetState(sub, model.SubUnsubscribing); err != nil {
+		return err
+	} else if res, err = postHub(sub.Hub, form); err != nil {
+		// Until the lease runs out, we reject whatever the hub pushes
+		// to us.
+		return err
+	}
+
+	defer res.Body.Close() // nolint: errcheck
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("Hub %s replied with %s", sub.Hub, res.Status)
+	}
+
+	return nil
+} // func (r *Reader) unsubscribe(db database.Store, f *model.Feed, sub *model.Subscription) error
+
+// postHub sends a (un)subscription request to the given hub.
+func postHub(hub *url.URL, form url.Values) (*http.Response, error) {
+	var (
+		err error
+		req *http.Request
+	)
+
+	if req, err = http.NewRequest(http.MethodPost, hub.String(), strings.NewReader(form.Encode())); err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", UserAgent())
+
+	return Client().Do(req)
+} // func postHub(hub *url.URL, form url.Values) (*http.Response, error)
+
+// renewSubscriptions renews Subscriptions whose lease is about to expire and
+// retries Subscriptions the hub has not confirmed in time.
+func (r *Reader) renewSubscriptions() {
+	var (
+		err  error
+		db   = r.pool.Get()
+		now  = time.Now()
+		subs []*model.Subscription
+	)
+
+	defer r.pool.Put(db)
+
+	if subs, err = db.WebSubGetRenewable(now.Add(renewMargin), now.Add(-pendingTimeout)); err != nil {
+		r.log.Printf("[ERROR] Failed to load Subscriptions due for renewal: %s\n",
+			err.Error())
+		return
+	}
+
+	for _, sub := range subs {
+		var f *model.Feed
+
+		if f, err = db.FeedGetByID(sub.FeedID); err != nil {
+			r.log.Printf("[ERROR] Failed to load Feed %d: %s\n",
+				sub.FeedID,
+				err.Error())
+			continue
+		} else if f == nil {
+			// Without the Feed, there is no callback the hub could
+			// verify our intent to unsubscribe at, so we let the
+			// lease run out. Updates pushed to us in the meantime
+			// are rejected.
+			if err = db.WebSubDelete(sub); err != nil {
+				r.log.Printf("[ERROR] Failed to delete Subscription %d: %s\n",
+					sub.ID,
+					err.Error())
+			}
+			continue
+		} else if !f.Active {
+			if err = r.unsubscribe(db, f, sub); err != nil {
+				r.log.Printf("[ERROR] Failed to unsubscribe from Feed %s (%d): %s\n",
+					f.Title,
+					f.ID,
+					err.Error())
+			}
+			continue
+		} else if err = r.subscribe(db, f, sub); err != nil {
+			r.log.Printf("[ERROR] Failed to renew Subscription to Feed %s (%d): %s\n",
+				f.Title,
+				f.ID,
+				err.Error())
+		}
+	}
+} // func (r *Reader) renewSubscriptions()
+
+// getSubscription loads a Feed and its Subscription.
+func (r *Reader) getSubscription(db database.Store, feedID int64) (*model.Feed, *model.Subscription, error) {
+	var (
+		err error
+		f   *model.Feed
+		sub *model.Subscription
+	)
+
+	if f, err = db.FeedGetByID(feedID); err != nil {
+		return nil, nil, err
+	} else if f == nil {
+		return nil, nil, nil
+	} else if sub, err = db.WebSubGetByFeed(f); err != nil {
+		return nil, nil, err
+	}
+
+	return f, sub, nil
+} // func (r *Reader) getSubscription(db database.Store, feedID int64) (*model.Feed, *model.Subscription, error)
+
+// Verify handles a hub's request to verify our intent to (un)subscribe to
+// the given Feed, or its notice that our request was denied. It returns true
+// if we confirm the request. We only confirm requests we have made
+// ourselves, i.e. there has to be a Subscription for the Feed and topic, and
+// to unsubscribe, we must have asked the hub to do so.
+func (r *Reader) Verify(feedID int64, mode, topic string, lease int) (bool, error) {
+	var (
+		err error
+		db  = r.pool.Get()
+		f   *model.Feed
+		sub *model.Subscription
+	)
+
+	defer r.pool.Put(db)
+
+	if f, sub, err = r.getSubscription(db, feedID); err != nil {
+		r.log.Printf("[ERROR] Failed to load Subscription of Feed %d: %s\n",
+			feedID,
+			err.Error())
+		return false, err
+	} else if sub == nil {
+		r.log.Printf("[INFO] Hub tried to verify %s for Feed %d, which we have no Subscription for\n",
+			mode,
+			feedID)
+		return false, nil
+	} else if topic != sub.Topic.String() {
+		r.log.Printf("[INFO] Hub %s tried to verify unknown topic %q for Feed %s (%d)\n",
+			sub.Hub,
+			topic,
+			f.Title,
+			f.ID)
+		return false, nil
+	}
+
+	switch mode {
+	case "subscribe":
+		// A hub verifies our request when we subscribe or renew the
+		// Subscription. Anything else is stale or forged, and
+		// confirming it would stop us from polling the Feed.
+		if sub.State != model.SubPending && sub.State != model.SubActive {
+			r.log.Printf("[INFO] Hub %s tried to confirm Subscription to Feed %s (%d), which is %s\n",
+				sub.Hub,
+				f.Title,
+				f.ID,
+				sub.State)
+			return false, nil
+		}
+
+		// We never ask for a longer lease than leaseDuration.
+		if lease <= 0 || lease > int(leaseDuration.Seconds()) {
+			lease = int(leaseDuration.Seconds())
+		}
+
+		r.log.Printf("[INFO] Hub %s confirmed Subscription to Feed %s (%d) for %d seconds\n",
+			sub.Hub,
+			f.Title,
+			f.ID,
+			lease)
+
+		if err = db.WebSubSetActive(sub, time.Now().Add(time.Duration(lease)*time.Second)); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	case "unsubscribe":
+		if sub.State != model.SubUnsubscribing {
+			r.log.Printf("[INFO] Hub %s tried to unsubscribe us from Feed %s (%d), but we did not ask for that\n",
+				sub.Hub,
+				f.Title,
+				f.ID)
+			return false, nil
+		}
+
+		r.log.Printf("[INFO] Hub %s confirmed we unsubscribed from Feed %s (%d)\n",
+			sub.Hub,
+			f.Title,
+			f.ID)
+
+		if err = db.WebSubDelete(sub); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	case "denied":
+		r.log.Printf("[INFO] Hub %s denied Subscription to Feed %s (%d)\n",
+			sub.Hub,
+			f.Title,
+			f.ID)
+
+		if err = db.WebSubSetState(sub, model.SubDenied); err != nil {
+			return false, err
+		}
+
+		return true, nil
+	default:
+		return false, nil
+	}
+} // func (r *Reader) Verify(feedID int64, mode, topic string, lease int) (bool, error)
+
+// checkSignature verifies the X-Hub-Signature of a pushed update.
+func checkSignature(secret, signature string, body []byte) bool {
+	var (
+		err            error
+		algo, sig      string
+		ok             bool
+		expected, hsum []byte
+		newHash        func() hash.Hash
+	)
+
+	if algo, sig, ok = strings.Cut(signature, "="); !ok {
+		return false
+	}
+
+	switch strings.ToLower(algo) {
+	case "sha1":
+		newHash = sha1.New
+	case "sha256":
+		newHash = sha256.New
+	case "sha384":
+		newHash = sha512.New384
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return false
+	}
+
+	if expected, err = hex.DecodeString(sig); err != nil {
+		return false
+	}
+
+	var mac = hmac.New(newHash, []byte(secret))
+	mac.Write(body) // nolint: errcheck
+	hsum = mac.Sum(nil)
+
+	return hmac.Equal(expected, hsum)
+} // func checkSignature(secret, signature string, body []byte) bool
+
+// Push processes an update the hub pushed to us for the given Feed. The
+// Items are added the same way as when we poll the Feed.
+func (r *Reader) Push(feedID int64, body io.Reader, signature string) error {
+	var (
+		err  error
+		db   = r.pool.Get()
+		f    *model.Feed
+		sub  *model.Subscription
+		buf  []byte
+		feed *gofeed.Feed
+		now  = time.Now()
+	)
+
+	defer r.pool.Put(db)
+
+	if f, sub, err = r.getSubscription(db, feedID); err != nil {
+		r.log.Printf("[ERROR] Failed to load Subscription of Feed %d: %s\n",
+			feedID,
+			err.Error())
+		return err
+	} else if sub == nil || sub.State != model.SubActive || !f.Active {
+		return ErrUnknownSubscription
+	} else if buf, err = io.ReadAll(io.LimitReader(body, maxFeedSize)); err != nil {
+		return err
+	} else if !checkSignature(sub.Secret, signature, buf) {
+		r.log.Printf("[INFO] Discard update for Feed %s (%d) with invalid signature\n",
+			f.Title,
+			f.ID)
+		return ErrInvalidSignature
+	} else if feed, err = gofeed.NewParser().Parse(bytes.NewReader(buf)); err != nil {
+		r.log.Printf("[ERROR] Cannot parse update pushed for Feed %s (%d): %s\n",
+			f.Title,
+			f.ID,
+			err.Error())
+		return err
+	}
+
+	if _, err = r.ingest(context.Background(), db, f, feed); err != nil {
+		return err
+	}
+
+	if err = db.WebSubSetLastPush(sub, now); err != nil {
+		r.log.Printf("[ERROR] Failed to record push for Feed %s (%d): %s\n",
+			f.Title,
+			f.ID,
+			err.Error())
+	} else if err = db.FeedUpdateRefresh(f, now); err != nil {
+		r.log.Printf("[ERROR] Failed to set LastRefresh on Feed %s (%d): %s\n",
+			f.Title,
+			f.ID,
+			err.Error())
+	}
+
+	return nil
+} // func (r *Reader) Push(feedID int64, body io.Reader, signature string) error
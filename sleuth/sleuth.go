@@ -8,12 +8,12 @@
 package sleuth
 
 import (
+	"context"
 	"log"
 	"sync/atomic"
 	"time"
 
 	"github.com/blicero/badnews/common"
-	"github.com/blicero/badnews/common/path"
 	"github.com/blicero/badnews/database"
 	"github.com/blicero/badnews/logdomain"
 	"github.com/blicero/badnews/model"
@@ -25,31 +25,28 @@ const pulse = time.Millisecond * 2500
 // Sleuth treats search requests kinda like a batch queue
 type Sleuth struct {
 	log     *log.Logger
-	db      *database.Database
+	db      database.Store
 	searchQ chan *model.Search
 	active  atomic.Bool
 }
 
-// Create creates and returns a new instance of Sleuth.
-func Create() (*Sleuth, error) {
+// Create creates and returns a new instance of Sleuth that runs its queries
+// against the given Store. The Sleuth takes ownership of the Store, Run closes
+// it before returning.
+func Create(db database.Store) (*Sleuth, error) {
 	var (
 		err error
-		s   = new(Sleuth)
+		s   = &Sleuth{db: db}
 	)
 
 	if s.log, err = common.GetLogger(logdomain.Search); err != nil {
 		return nil, err
-	} else if s.db, err = database.Open(common.Path(path.Database)); err != nil {
-		s.log.Printf("[CRITICAL] Cannot open database at %s: %s\n",
-			common.Path(path.Database),
-			err.Error())
-		return nil, err
 	}
 
 	s.searchQ = make(chan *model.Search)
 
 	return s, nil
-} // func Create() (*Sleuth, error)
+} // func Create(db database.Store) (*Sleuth, error)
 
 // IsActive returns the Sleuth's active flag
 func (s *Sleuth) IsActive() bool {
@@ -57,25 +54,34 @@ func (s *Sleuth) IsActive() bool {
 }
 
 // Run executes the Sleuth's main loop, it waits for new search queries
-// and executes them.
-func (s *Sleuth) Run() {
+// and executes them. Run returns after ctx has been cancelled and the
+// query being executed at that time, if any, has finished. It closes the
+// Sleuth's database connection before returning.
+func (s *Sleuth) Run(ctx context.Context) {
 	s.active.Store(true)
 	defer s.active.Store(false)
 
 	s.log.Println("[INFO] Sleuth main loop starting up")
 	defer s.log.Println("[INFO] Sleuth main loop finishing")
 
-	var ticker = time.NewTicker(pulse)
-	defer ticker.Stop()
+	var done = make(chan struct{})
+
+	go func() {
+		defer close(done)
+		s.feeder(ctx)
+	}()
 
-	go s.feeder()
+	defer s.db.Close() // nolint: errcheck
+	defer func() { <-done }()
 
-	for s.IsActive() {
+	for {
 		var (
 			err error
 			q   *model.Search
 		)
 		select {
+		case <-ctx.Done():
+			return
 		case q = <-s.searchQ:
 			// do something
 			if err = s.db.SearchStart(q); err != nil {
@@ -90,13 +96,11 @@ func (s *Sleuth) Run() {
 					q.ID,
 					err.Error())
 			}
-		case <-ticker.C:
-			continue
 		}
 	}
-} // func (s *Sleuth) Run()
+} // func (s *Sleuth) Run(ctx context.Context)
 
-func (s *Sleuth) feeder() {
+func (s *Sleuth) feeder(ctx context.Context) {
 	var (
 		err        error
 		searchList []*model.Search
@@ -112,10 +116,14 @@ func (s *Sleuth) feeder() {
 	}
 
 	for _, q := range searchList {
-		s.searchQ <- q
+		select {
+		case s.searchQ <- q:
+		case <-ctx.Done():
+			return
+		}
 	}
 
-	for s.IsActive() {
+	for {
 		var q *model.Search
 
 		// s.log.Println("[INFO] Sleuth feeder loop fetching one Query from database.")
@@ -125,11 +133,19 @@ func (s *Sleuth) feeder() {
 				err.Error())
 			return
 		} else if q != nil {
-			s.searchQ <- q
+			select {
+			case s.searchQ <- q:
+			case <-ctx.Done():
+				return
+			}
 		} else {
 			// s.log.Printf("[INFO] No pending queries were found, sleeping for %s\n",
 			// 	pulse)
-			time.Sleep(pulse)
+			select {
+			case <-time.After(pulse):
+			case <-ctx.Done():
+				return
+			}
 		}
 	}
-} // func (s *Sleuth) feeder()
+} // func (s *Sleuth) feeder(ctx context.Context)
@@ -7,9 +7,12 @@
 package web
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
+
+	"github.com/blicero/badnews/database/memory"
 )
 
 func TestServerCreate(t *testing.T) {
@@ -17,12 +20,12 @@ func TestServerCreate(t *testing.T) {
 
 	addr = fmt.Sprintf("[::1]:%d", testPort)
 
-	if srv, err = Create(addr); err != nil {
+	if srv, err = Create(addr, memory.New().Open); err != nil {
 		srv = nil
 		t.Fatalf("Error creating Server: %s",
 			err.Error())
 	}
 
-	go srv.ListenAndServe()
+	go srv.ListenAndServe(context.Background())
 	time.Sleep(time.Second)
 } // func TestServerCreate(t *testing.T)
@@ -0,0 +1,52 @@
+// /home/krylon/go/src/github.com/blicero/badnews/web/02_diff_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 15:51:03 krylon>
+
+package web
+
+import "testing"
+
+func TestDiffHTML(t *testing.T) {
+	type testCase struct {
+		old      string
+		cur      string
+		expected string
+	}
+
+	var cases = []testCase{
+		{
+			old:      "The quick brown fox",
+			cur:      "The quick brown fox",
+			expected: "The quick brown fox",
+		},
+		{
+			old:      "The quick brown fox",
+			cur:      "The slow brown fox",
+			expected: "The <ins>slow</ins> <del>quick</del> brown fox",
+		},
+		{
+			old:      "Minister resigns",
+			cur:      "Minister resigns <today>",
+			expected: "Minister resigns <ins>&lt;today&gt;</ins>",
+		},
+		{
+			old:      "",
+			cur:      "New",
+			expected: "<ins>New</ins>",
+		},
+	}
+
+	for _, c := range cases {
+		var res = diffHTML(c.old, c.cur)
+
+		if res != c.expected {
+			t.Errorf("Unexpected diff of %q and %q:\nExpected: %s\nGot:      %s",
+				c.old,
+				c.cur,
+				c.expected,
+				res)
+		}
+	}
+} // func TestDiffHTML(t *testing.T)
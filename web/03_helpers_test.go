@@ -0,0 +1,77 @@
+// /home/krylon/go/src/github.com/blicero/badnews/web/03_helpers_test.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 18:44:09 krylon>
+
+package web
+
+import "testing"
+
+func TestParseHeaders(t *testing.T) {
+	var (
+		err     error
+		headers map[string]string
+	)
+
+	if headers, err = parseHeaders("x-api-key: 4711\n\n  Accept-Language:de  \r\n"); err != nil {
+		t.Fatalf("Failed to parse headers: %s", err.Error())
+	} else if len(headers) != 2 {
+		t.Errorf("Expected 2 headers, got %d: %v", len(headers), headers)
+	} else if headers["X-Api-Key"] != "4711" {
+		t.Errorf("Unexpected value for X-Api-Key: %q", headers["X-Api-Key"])
+	} else if headers["Accept-Language"] != "de" {
+		t.Errorf("Unexpected value for Accept-Language: %q", headers["Accept-Language"])
+	}
+
+	for _, bad := range []string{"No colon here", "Bad Name: value", ": empty"} {
+		if _, err = parseHeaders(bad); err == nil {
+			t.Errorf("Invalid header %q was accepted", bad)
+		}
+	}
+} // func TestParseHeaders(t *testing.T)
+
+func TestParseIDList(t *testing.T) {
+	var (
+		err error
+		ids []int64
+	)
+
+	if ids, err = parseIDList("1, 2,,42"); err != nil {
+		t.Fatalf("Failed to parse ID list: %s", err.Error())
+	} else if len(ids) != 3 || ids[0] != 1 || ids[1] != 2 || ids[2] != 42 {
+		t.Errorf("Unexpected result: %v", ids)
+	}
+
+	if ids, err = parseIDList(""); err != nil {
+		t.Errorf("Failed to parse empty ID list: %s", err.Error())
+	} else if len(ids) != 0 {
+		t.Errorf("Expected empty list, got %v", ids)
+	}
+
+	if _, err = parseIDList("1,two,3"); err == nil {
+		t.Error("Invalid ID list was accepted")
+	}
+} // func TestParseIDList(t *testing.T)
+
+func TestIsInlineMimeType(t *testing.T) {
+	var cases = map[string]bool{
+		"audio/mpeg":               true,
+		"video/mp4":                true,
+		"image/jpeg":               true,
+		"Image/PNG; charset=x":     true,
+		"image/svg+xml":            false,
+		"text/html":                false,
+		"application/octet-stream": false,
+		"":                         false,
+	}
+
+	for mt, expect := range cases {
+		if inline := isInlineMimeType(mt); inline != expect {
+			t.Errorf("isInlineMimeType(%q) returned %t, expected %t",
+				mt,
+				inline,
+				expect)
+		}
+	}
+} // func TestIsInlineMimeType(t *testing.T)
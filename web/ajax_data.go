@@ -15,3 +15,11 @@ type Reply struct {
 	Message   string            `json:"message"`
 	Payload   map[string]string `json:"payload"`
 }
+
+// feedCandidate is a Feed found by autodiscovery, as sent to the client.
+type feedCandidate struct {
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+	Homepage string `json:"homepage"`
+	Type     string `json:"type"`
+}
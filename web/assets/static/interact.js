@@ -236,6 +236,8 @@ function load_items(cnt, offset=0) {
     const req = $.get(url,
                       {
                           "hideBoring": settings.news.hideBoring,
+                          "unread": settings.news.unreadOnly,
+                          "starred": settings.news.starredOnly,
                       },
                       (res) => {
                           if (res.status) {
@@ -265,6 +267,142 @@ function load_items(cnt, offset=0) {
     })
 } // function load_items(cnt)
 
+function reload_items(cnt) {
+    item_cnt = 0
+    $('#items')[0].innerHTML = ''
+    load_items(cnt)
+} // function reload_items(cnt)
+
+// toggle_item_filter flips one of the filters for the item views, name is
+// either 'unreadOnly' or 'starredOnly', reload is called to refresh the list.
+function toggle_item_filter(name, reload) {
+    const state = !settings.news[name]
+    saveSetting('news', name, state)
+    reload()
+} // function toggle_item_filter(name, reload)
+
+function init_item_filters() {
+    $('#filter_unread')[0].checked = settings.news.unreadOnly
+    $('#filter_starred')[0].checked = settings.news.starredOnly
+} // function init_item_filters()
+
+function set_item_read(id, read) {
+    const state = read ? 'read' : 'unread'
+    const url = `/ajax/item/${id}/${state}`
+
+    const req = $.post(url,
+                       {},
+                       (res) => {
+                           if (!res.status) {
+                               console.log(res.message)
+                               msg_add(res.message, 2)
+                               return
+                           }
+
+                           const row = $(`#item_row_${id}`)
+                           if (res.payload.read == 'true') {
+                               row.removeClass('unread')
+                           } else {
+                               row.addClass('unread')
+                           }
+                       },
+                       'json')
+
+    req.fail(() => {
+        msg_add(`Error marking Item ${id} as ${state}`, 2)
+    })
+} // function set_item_read(id, read)
+
+function toggle_item_read(id) {
+    const unread = $(`#item_row_${id}`).hasClass('unread')
+    set_item_read(id, unread)
+} // function toggle_item_read(id)
+
+function toggle_item_star(id) {
+    const url = `/ajax/item/${id}/star`
+    const star = $(`#item_star_${id}`)[0]
+    const starred = star.dataset.starred != 'true'
+
+    const req = $.post(url,
+                       { "starred": starred },
+                       (res) => {
+                           if (!res.status) {
+                               console.log(res.message)
+                               msg_add(res.message, 2)
+                               return
+                           }
+
+                           star.dataset.starred = res.payload.starred
+                           star.innerHTML = res.payload.starred == 'true' ? '&#9733;' : '&#9734;'
+                       },
+                       'json')
+
+    req.fail(() => {
+        msg_add(`Error starring Item ${id}`, 2)
+    })
+} // function toggle_item_star(id)
+
+// mark_read_above marks the given Item and all Items above it as read. On
+// pages that show the Items of a single Feed, view_feed holds its ID, and
+// only Items from that Feed are marked.
+function mark_read_above(id) {
+    const url = `/ajax/item/${id}/read_above`
+    const feed_id = (typeof view_feed !== 'undefined') ? view_feed : 0
+    const params = feed_id != 0 ? { "feed": feed_id } : {}
+
+    const req = $.post(url,
+                       params,
+                       (res) => {
+                           if (!res.status) {
+                               console.log(res.message)
+                               msg_add(res.message, 2)
+                               return
+                           }
+
+                           const rows = $('#items tr.unread')
+                           for (const row of rows) {
+                               row.classList.remove('unread')
+                               if (Number(row.dataset.item) == id) {
+                                   break
+                               }
+                           }
+
+                           msg_add(res.message, 1)
+                       },
+                       'json')
+
+    req.fail(() => {
+        msg_add(`Error marking Items above ${id} as read`, 2)
+    })
+} // function mark_read_above(id)
+
+function mark_shown_read() {
+    const rows = $('#items tr.unread')
+    const ids = _.map(rows, (row) => row.dataset.item)
+
+    if (ids.length == 0) {
+        return
+    }
+
+    const req = $.post('/ajax/items/read',
+                       { "items": ids.join(',') },
+                       (res) => {
+                           if (!res.status) {
+                               console.log(res.message)
+                               msg_add(res.message, 2)
+                               return
+                           }
+
+                           rows.removeClass('unread')
+                           msg_add(res.message, 1)
+                       },
+                       'json')
+
+    req.fail(() => {
+        msg_add('Error marking Items as read', 2)
+    })
+} // function mark_shown_read()
+
 function add_tag(item_id) {
     const sel_id = `#item_tag_sel_${item_id}`
     const sel = $(sel_id)[0]
@@ -408,6 +546,268 @@ function toggle_feed_active(feed_id) {
     })
 } // function toggle_feed_active(feed_id)
 
+function toggle_feed_full(feed_id) {
+    const url = `/ajax/feed/${feed_id}/toggle_full`
+    const req = $.get(
+        url,
+        {},
+        (res) => {
+            if (res.status) {
+                msg_add(`Full article flag was toggled successfully`, 1)
+            } else {
+                msg_add(res.message, 3)
+            }
+        },
+        'json')
+
+    req.fail((reply, status, xhr) => {
+        msg_add(status, 3)
+    })
+} // function toggle_feed_full(feed_id)
+
+function toggle_feed_download(feed_id) {
+    const url = `/ajax/feed/${feed_id}/toggle_download`
+    const req = $.get(
+        url,
+        {},
+        (res) => {
+            if (res.status) {
+                msg_add(`Download flag was toggled successfully`, 1)
+            } else {
+                msg_add(res.message, 3)
+            }
+        },
+        'json')
+
+    req.fail((reply, status, xhr) => {
+        msg_add(status, 3)
+    })
+} // function toggle_feed_download(feed_id)
+
+function feed_refresh(feed_id) {
+    const url = `/ajax/feed/${feed_id}/refresh`
+    const button = $(`#feed_refresh_${feed_id}`)
+
+    button.prop('disabled', true)
+    msg_add('Refreshing Feed...', 1)
+
+    const req = $.get(
+        url,
+        {},
+        (res) => {
+            if (res.status) {
+                msg_add(res.message, 1)
+            } else {
+                msg_add(res.message, 3)
+            }
+        },
+        'json')
+
+    req.fail((reply, status, xhr) => {
+        msg_add(status, 3)
+    })
+
+    req.always(() => {
+        button.prop('disabled', false)
+    })
+} // function feed_refresh(feed_id)
+
+function feeds_refresh() {
+    const url = '/ajax/feeds/refresh'
+    const button = $('#feeds_refresh')
+
+    button.prop('disabled', true)
+    msg_add('Refreshing all Feeds...', 1)
+
+    const req = $.get(
+        url,
+        {},
+        (res) => {
+            if (res.status) {
+                msg_add(res.message, 1)
+            } else {
+                msg_add(res.message, 3)
+                if (res.payload && res.payload.errors) {
+                    console.log(res.payload.errors)
+                }
+            }
+        },
+        'json')
+
+    req.fail((reply, status, xhr) => {
+        msg_add(status, 3)
+    })
+
+    req.always(() => {
+        button.prop('disabled', false)
+    })
+} // function feeds_refresh()
+
+function feed_set_interval_bound(feed_id) {
+    const bound = $(`#feed_interval_bound_${feed_id}`)[0].value
+    const url = `/ajax/feed/${feed_id}/interval_bound/${bound}`
+    const req = $.get(
+        url,
+        {},
+        (res) => {
+            if (res.status) {
+                msg_add(res.message, 1)
+            } else {
+                msg_add(res.message, 3)
+            }
+        },
+        'json')
+
+    req.fail((reply, status, xhr) => {
+        msg_add(status, 3)
+    })
+} // function feed_set_interval_bound(feed_id)
+
+function feed_set_retention(feed_id) {
+    const url = `/ajax/feed/${feed_id}/retention`
+    const form = $(`#feed_retention_form_${feed_id}`)
+
+    const req = $.post(
+        url,
+        form.serialize(),
+        (res) => {
+            if (res.status) {
+                msg_add(res.message, 1)
+            } else {
+                msg_add(res.message, 3)
+            }
+        },
+        'json')
+
+    req.fail((reply, status, xhr) => {
+        msg_add(status, 3)
+    })
+} // function feed_set_retention(feed_id)
+
+function feed_http_settings_save(feed_id) {
+    const url = `/ajax/feed/${feed_id}/http_settings`
+    const form = $(`#feed_http_form_${feed_id}`)
+
+    const req = $.post(
+        url,
+        form.serialize(),
+        (res) => {
+            if (res.status) {
+                msg_add(res.message, 1)
+                form.find('input[name="auth_secret"]').val('')
+            } else {
+                msg_add(res.message, 3)
+            }
+        },
+        'json')
+
+    req.fail((reply, status, xhr) => {
+        msg_add(status, 3)
+    })
+} // function feed_http_settings_save(feed_id)
+
+function feed_update(feed_id) {
+    const url = `/ajax/feed/${feed_id}/update`
+    const form = $(`#feed_edit_form_${feed_id}`)
+
+    const req = $.post(
+        url,
+        form.serialize(),
+        (res) => {
+            if (res.status) {
+                msg_add(res.message, 1)
+                window.location.reload()
+            } else {
+                msg_add(res.message, 3)
+            }
+        },
+        'json')
+
+    req.fail((reply, status, xhr) => {
+        if (reply.responseJSON) {
+            msg_add(reply.responseJSON.message, 3)
+        } else {
+            msg_add(status, 3)
+        }
+    })
+} // function feed_update(feed_id)
+
+function category_map_add(feed_id) {
+    const url = `/ajax/feed/${feed_id}/category_map/add`
+    const form = $(`#category_map_form_${feed_id}`)
+
+    const req = $.post(
+        url,
+        form.serialize(),
+        (res) => {
+            if (res.status) {
+                msg_add(res.message, 1)
+                window.location.reload()
+            } else {
+                msg_add(res.message, 3)
+            }
+        },
+        'json')
+
+    req.fail((reply, status, xhr) => {
+        if (reply.responseJSON) {
+            msg_add(reply.responseJSON.message, 3)
+        } else {
+            msg_add(status, 3)
+        }
+    })
+} // function category_map_add(feed_id)
+
+function category_map_delete(id) {
+    const url = `/ajax/category_map/${id}/delete`
+
+    const req = $.get(
+        url,
+        {},
+        (res) => {
+            if (res.status) {
+                $(`#category_map_${id}`).remove()
+            } else {
+                msg_add(res.message, 3)
+            }
+        },
+        'json')
+
+    req.fail((reply, status, xhr) => {
+        if (reply.responseJSON) {
+            msg_add(reply.responseJSON.message, 3)
+        } else {
+            msg_add(status, 3)
+        }
+    })
+} // function category_map_delete(id)
+
+function show_revisions(item_id) {
+    const div = $(`#item_revisions_${item_id}`)[0]
+
+    if (div.innerHTML != '') {
+        div.innerHTML = ''
+        return
+    }
+
+    const url = `/ajax/item/${item_id}/revisions`
+    const req = $.get(
+        url,
+        {},
+        (res) => {
+            if (res.status) {
+                div.innerHTML = res.payload.content
+            } else {
+                msg_add(res.message, 3)
+            }
+        },
+        'json')
+
+    req.fail((reply, status, xhr) => {
+        msg_add(status, 3)
+    })
+} // function show_revisions(item_id)
+
 function feed_delete(id) {
     const url = `/ajax/feed/${id}/delete`
 
@@ -428,6 +828,46 @@ function feed_delete(id) {
                       'json')
 } // function feed_delete(id)
 
+function opml_import() {
+    const url = '/ajax/opml/import'
+    const form = $('#opml_import_form')[0]
+    const data = new FormData(form)
+
+    const req = $.ajax({
+        url: url,
+        type: 'POST',
+        data: data,
+        processData: false,
+        contentType: false,
+        dataType: 'json',
+        success: (res) => {
+            if (res.status) {
+                const out = $('#opml_import_result')[0]
+                let txt = res.message
+                if (res.payload.added != '') {
+                    txt += `\n\nAdded:\n${res.payload.added}`
+                }
+                if (res.payload.duplicates != '') {
+                    txt += `\n\nDuplicates (skipped):\n${res.payload.duplicates}`
+                }
+                if (res.payload.errors != '') {
+                    txt += `\n\nErrors:\n${res.payload.errors}`
+                }
+                out.innerText = txt
+                msg_add(res.message, 1)
+            } else {
+                console.log(res.message)
+                msg_add(res.message, 3)
+            }
+        },
+    })
+
+    req.fail((reply, status, xhr) => {
+        console.log(status)
+        msg_add(status, 3)
+    })
+} // function opml_import()
+
 function blacklist_add_pattern() {
     const url = "/ajax/blacklist/add"
     const txtid = "#blacklist-pattern"
@@ -18,6 +18,8 @@ var settings = {
 
     "news": {
         "hideBoring": false,
+        "unreadOnly": false,
+        "starredOnly": false,
     }
 };
 
@@ -53,6 +55,16 @@ function initSettings() {
     if (typeof(item) == "boolean") {
         settings.news.hideBoring = item
     }
+
+    item = JSON.parse(localStorage.getItem("news.unreadOnly"))
+    if (typeof(item) == "boolean") {
+        settings.news.unreadOnly = item
+    }
+
+    item = JSON.parse(localStorage.getItem("news.starredOnly"))
+    if (typeof(item) == "boolean") {
+        settings.news.starredOnly = item
+    }
 } // function initSettings()
 
 function saveSetting(category, attribute, newValue) {
@@ -0,0 +1,91 @@
+// /home/krylon/go/src/github.com/blicero/badnews/web/diff.go
+// -*- mode: go; coding: utf-8; -*-
+// Created on 17. 10. 2026 by Benjamin Walkenhorst
+// (c) 2024 Benjamin Walkenhorst
+// Time-stamp: <2026-10-17 15:42:19 krylon>
+//
+// Rendering the changes between revisions of an Item.
+
+package web
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/blicero/badnews/common"
+	"github.com/blicero/badnews/model"
+)
+
+// diffHTML compares two texts word by word and renders the result as HTML,
+// with removed words wrapped in <del> and added words wrapped in <ins>.
+func diffHTML(old, cur string) string {
+	var (
+		a   = strings.Fields(old)
+		b   = strings.Fields(cur)
+		lcs = make([][]int, len(a)+1)
+		out strings.Builder
+		i   int
+		j   int
+	)
+
+	for i = range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i = len(a) - 1; i >= 0; i-- {
+		for j = len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j = 0, 0
+	for i < len(a) || j < len(b) {
+		if out.Len() > 0 {
+			out.WriteString(" ")
+		}
+
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out.WriteString(html.EscapeString(a[i]))
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			out.WriteString("<ins>" + html.EscapeString(b[j]) + "</ins>")
+			j++
+		default:
+			out.WriteString("<del>" + html.EscapeString(a[i]) + "</del>")
+			i++
+		}
+	}
+
+	return out.String()
+} // func diffHTML(old, cur string) string
+
+// revisionHistory renders the changes from each revision of an Item to the
+// next one, the last revision is compared with the Item's current version.
+func revisionHistory(item *model.Item, revs []model.Revision) string {
+	var out strings.Builder
+
+	for idx, rev := range revs {
+		var headline, description string
+
+		if idx < len(revs)-1 {
+			headline, description = revs[idx+1].Headline, revs[idx+1].Description
+		} else {
+			headline, description = item.Headline, item.Description
+		}
+
+		fmt.Fprintf(&out,
+			"<div class=\"revision\">\n<small>Changed on %s</small>\n<h6>%s</h6>\n<p>%s</p>\n</div>\n",
+			rev.Timestamp.Format(common.TimestampFormatMinute),
+			diffHTML(rev.Headline, headline),
+			diffHTML(rev.Description, description))
+	}
+
+	return out.String()
+} // func revisionHistory(item *model.Item, revs []model.Revision) string
@@ -12,14 +12,113 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/textproto"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/blicero/badnews/common"
+	"github.com/blicero/badnews/model"
 	"github.com/gorilla/sessions"
 )
 
+var headerName = regexp.MustCompile("^[-!#$%&'*+.^_`|~0-9A-Za-z]+$")
+
+// parseHeaders parses HTTP headers entered by the user, one "Name: value"
+// pair per line. Empty lines are ignored.
+func parseHeaders(text string) (map[string]string, error) {
+	var headers = make(map[string]string)
+
+	for _, line := range strings.Split(text, "\n") {
+		var (
+			name, value string
+			ok          bool
+		)
+
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		} else if name, value, ok = strings.Cut(line, ":"); !ok {
+			return nil, fmt.Errorf("Invalid header %q: expected \"Name: value\"", line)
+		} else if name = strings.TrimSpace(name); !headerName.MatchString(name) {
+			return nil, fmt.Errorf("Invalid header name %q", name)
+		}
+
+		headers[textproto.CanonicalMIMEHeaderKey(name)] = strings.TrimSpace(value)
+	}
+
+	return headers, nil
+} // func parseHeaders(text string) (map[string]string, error)
+
+// parseItemFilter reads the optional "unread" and "starred" flags from the
+// form data of a request. Missing flags are false.
+func parseItemFilter(r *http.Request) (model.ItemFilter, error) {
+	var (
+		err    error
+		filter model.ItemFilter
+	)
+
+	if str := r.FormValue("unread"); str != "" {
+		if filter.Unread, err = strconv.ParseBool(str); err != nil {
+			return filter, fmt.Errorf("Cannot parse unread flag %q: %w", str, err)
+		}
+	}
+
+	if str := r.FormValue("starred"); str != "" {
+		if filter.Starred, err = strconv.ParseBool(str); err != nil {
+			return filter, fmt.Errorf("Cannot parse starred flag %q: %w", str, err)
+		}
+	}
+
+	return filter, nil
+} // func parseItemFilter(r *http.Request) (model.ItemFilter, error)
+
+// parseIDList parses a comma-separated list of Item IDs.
+func parseIDList(text string) ([]int64, error) {
+	var ids = make([]int64, 0, strings.Count(text, ",")+1)
+
+	for _, str := range strings.Split(text, ",") {
+		var (
+			err error
+			id  int64
+		)
+
+		if str = strings.TrimSpace(str); str == "" {
+			continue
+		} else if id, err = strconv.ParseInt(str, 10, 64); err != nil {
+			return nil, fmt.Errorf("Invalid ID %q: %w", str, err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+} // func parseIDList(text string) ([]int64, error)
+
+// rasterImages are the image types that cannot carry scripts, unlike SVG.
+var rasterImages = []string{
+	"image/avif",
+	"image/bmp",
+	"image/gif",
+	"image/jpeg",
+	"image/png",
+	"image/webp",
+}
+
+// isInlineMimeType returns true if a file of the given MIME type is safe to
+// display in the browser, i.e. it is audio, video or a raster image.
+// Everything else is offered as a download.
+func isInlineMimeType(mimeType string) bool {
+	var mt, _, _ = strings.Cut(mimeType, ";")
+
+	mt = strings.ToLower(strings.TrimSpace(mt))
+
+	return strings.HasPrefix(mt, "audio/") ||
+		strings.HasPrefix(mt, "video/") ||
+		slices.Contains(rasterImages, mt)
+} // func isInlineMimeType(mimeType string) bool
+
 func errJSON(msg string) []byte { // nolint: unused,deadcode
 	var res = fmt.Sprintf(
 		`
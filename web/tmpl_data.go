@@ -17,6 +17,7 @@ import (
 	"github.com/blicero/badnews/blacklist"
 	"github.com/blicero/badnews/common"
 	"github.com/blicero/badnews/model"
+	"github.com/blicero/badnews/reader"
 
 	"github.com/hashicorp/logutils"
 )
@@ -53,7 +54,8 @@ type tmplDataBase struct { // nolint: unused
 
 type tmplDataIndex struct { // nolint: unused,deadcode
 	tmplDataBase
-	Feeds []model.Feed
+	Feeds     []model.Feed
+	UnreadCnt map[int64]int64
 }
 
 type tmplDataItems struct {
@@ -80,6 +82,10 @@ type tmplDataFeedDetails struct {
 	Items       []*model.Item
 	Tags        []*model.Tag
 	Suggestions map[int64][]advisor.SuggestedTag
+	History     []model.FeedMove
+	Throttle    *reader.HostStatus
+	Mappings    []model.CategoryMapping
+	Categories  []string
 }
 
 type tmplDataTagForm struct {
@@ -90,9 +96,10 @@ type tmplDataTagForm struct {
 
 type tmplDataTagAll struct {
 	tmplDataBase
-	Tags    []*model.Tag
-	ItemCnt map[int64]int64
-	Tag     model.Tag
+	Tags      []*model.Tag
+	ItemCnt   map[int64]int64
+	UnreadCnt map[int64]int64
+	Tag       model.Tag
 }
 
 type tmplDataBlacklist struct {
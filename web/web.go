@@ -9,6 +9,7 @@ package web
 
 import (
 	"bytes"
+	"context"
 	"embed"
 	"encoding/json"
 	"errors"
@@ -23,6 +24,7 @@ import (
 	"regexp"
 	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"text/template"
 	"time"
@@ -35,6 +37,8 @@ import (
 	"github.com/blicero/badnews/judge"
 	"github.com/blicero/badnews/logdomain"
 	"github.com/blicero/badnews/model"
+	"github.com/blicero/badnews/opml"
+	"github.com/blicero/badnews/reader"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
 )
@@ -48,6 +52,7 @@ const (
 	sessionNameFrontend = "Frontend"
 	sessionMaxAge       = 3600 * 24 * 7 // 1 week
 	suggPerItem         = 10
+	shutdownTimeout     = time.Second * 30
 )
 
 //go:embed assets
@@ -67,10 +72,12 @@ type Server struct {
 	judge     *judge.Judge
 	adv       *advisor.Advisor
 	bl        *blacklist.Blacklist
+	rdr       *reader.Reader
 }
 
-// Create creates and returns a new Server.
-func Create(addr string) (*Server, error) {
+// Create creates and returns a new Server. The Server gets its database
+// connections from the given Opener.
+func Create(addr string, open database.Opener) (*Server, error) {
 	var (
 		key1 = []byte(sessionKey)
 		key2 = []byte(sessionKey)
@@ -79,9 +86,11 @@ func Create(addr string) (*Server, error) {
 	slices.Reverse(key2)
 
 	var (
-		err error
-		msg string
-		srv = &Server{
+		err     error
+		msg     string
+		judgeDB database.Store
+		advDB   database.Store
+		srv     = &Server{
 			Addr: addr,
 			mimeTypes: map[string]string{
 				".css":  "text/css",
@@ -111,25 +120,36 @@ func Create(addr string) (*Server, error) {
 			"Error creating Logger: %s\n",
 			err.Error())
 		return nil, err
-	} else if srv.pool, err = database.NewPool(poolSize); err != nil {
+	} else if srv.pool, err = database.NewPoolFrom(poolSize, open); err != nil {
 		srv.log.Printf("[ERROR] Cannot allocate database connection pool: %s\n",
 			err.Error())
 		return nil, err
 	} else if srv.pool == nil {
 		srv.log.Printf("[CANTHAPPEN] Database pool is nil!\n")
 		return nil, errors.New("Database pool is nil")
-	} else if srv.judge, err = judge.New(); err != nil {
+	} else if judgeDB, err = open(); err != nil {
+		srv.log.Printf("[ERROR] Cannot open database for Judge: %s\n",
+			err.Error())
+		srv.pool.Close() // nolint: errcheck
+		return nil, err
+	} else if srv.judge, err = judge.New(judgeDB); err != nil {
 		srv.log.Printf("[ERROR] Failed to create Judge: %s\n",
 			err.Error())
+		judgeDB.Close()  // nolint: errcheck
 		srv.pool.Close() // nolint: errcheck
 		return nil, err
 		// } else if err = srv.judge.Train(); err != nil {
 		// 	srv.log.Printf("[CRITICAL] Failed to train classifier: %s\n",
 		// 		err.Error())
 		// 	return nil, err
-	} else if srv.adv, err = advisor.NewAdvisor(); err != nil {
+	} else if advDB, err = open(); err != nil {
+		srv.log.Printf("[CRITICAL] Cannot open database for Advisor: %s\n",
+			err.Error())
+		return nil, err
+	} else if srv.adv, err = advisor.NewAdvisor(advDB); err != nil {
 		srv.log.Printf("[CRITICAL] Failed to create Advisor: %s\n",
 			err.Error())
+		advDB.Close() // nolint: errcheck
 		return nil, err
 		// } else if err = srv.adv.Train(); err != nil {
 		// 	srv.log.Printf("[CRITICAL] Failed to train Advisor: %s\n",
@@ -195,6 +215,10 @@ func Create(addr string) (*Server, error) {
 	srv.router.HandleFunc("/items/{cnt:(?:\\d+)}{offset:(?:/\\d+)?}", srv.handleItemPage)
 	srv.router.HandleFunc("/feed/{id:(?:\\d+$)}", srv.handleFeedDetails)
 	srv.router.HandleFunc("/feed/all", srv.handleFeedPage)
+	srv.router.HandleFunc("/feed/export.opml", srv.handleOPMLExport)
+	srv.router.HandleFunc("/enclosure/{id:(?:\\d+)}", srv.handleEnclosure)
+	srv.router.HandleFunc("/icon/{id:(?:\\d+)}", srv.handleFeedIcon)
+	srv.router.HandleFunc("/websub/{id:(?:\\d+)}", srv.handleWebSub)
 	srv.router.HandleFunc("/tags/all", srv.handleTagAll)
 	srv.router.HandleFunc("/blacklist", srv.handleBlacklist)
 	srv.router.HandleFunc("/search/main", srv.handleSearchMain)
@@ -202,12 +226,29 @@ func Create(addr string) (*Server, error) {
 	// AJAX Handlers
 	srv.router.HandleFunc("/ajax/beacon", srv.handleBeacon)
 	srv.router.HandleFunc("/ajax/subscribe", srv.handleSubscribe)
+	srv.router.HandleFunc("/ajax/discover", srv.handleAjaxDiscover)
+	srv.router.HandleFunc("/ajax/opml/import", srv.handleAjaxOPMLImport)
 	srv.router.HandleFunc("/ajax/items/{offset:(?:\\d+)}/{cnt:(?:\\d+)}", srv.handleAjaxItems)
 	srv.router.HandleFunc("/ajax/feed_items/{id:(?:\\d+)$}", srv.handleAjaxItemsByFeed)
 	srv.router.HandleFunc("/ajax/feed/{id:(?:\\d+)}/toggle", srv.handleAjaxFeedToggle)
+	srv.router.HandleFunc("/ajax/feed/{id:(?:\\d+)}/toggle_full", srv.handleAjaxFeedToggleFull)
+	srv.router.HandleFunc("/ajax/feed/{id:(?:\\d+)}/toggle_download", srv.handleAjaxFeedToggleDownload)
+	srv.router.HandleFunc("/ajax/feed/{id:(?:\\d+)}/refresh", srv.handleAjaxFeedRefresh)
+	srv.router.HandleFunc("/ajax/feeds/refresh", srv.handleAjaxFeedsRefresh)
+	srv.router.HandleFunc("/ajax/feed/{id:(?:\\d+)}/interval_bound/{bound:(?:\\d+)}", srv.handleAjaxFeedSetBound)
+	srv.router.HandleFunc("/ajax/feed/{id:(?:\\d+)}/retention", srv.handleAjaxFeedSetRetention)
+	srv.router.HandleFunc("/ajax/feed/{id:(?:\\d+)}/http_settings", srv.handleAjaxFeedHTTPSettings)
+	srv.router.HandleFunc("/ajax/feed/{id:(?:\\d+)}/update", srv.handleAjaxFeedUpdate)
 	srv.router.HandleFunc("/ajax/feed/{id:(?:\\d+)}/delete", srv.handleAjaxFeedDelete)
+	srv.router.HandleFunc("/ajax/feed/{id:(?:\\d+)}/category_map/add", srv.handleAjaxCategoryMapAdd)
+	srv.router.HandleFunc("/ajax/category_map/{id:(?:\\d+)}/delete", srv.handleAjaxCategoryMapDelete)
 	srv.router.HandleFunc("/ajax/item_rate", srv.handleAjaxRateItem)
 	srv.router.HandleFunc("/ajax/item_unrate/{id:(?:\\d+)$}", srv.handleAjaxUnrateItem)
+	srv.router.HandleFunc("/ajax/item/{id:(?:\\d+)}/revisions", srv.handleAjaxItemRevisions)
+	srv.router.HandleFunc("/ajax/item/{id:(?:\\d+)}/{state:(?:read|unread)}", srv.handleAjaxItemSetRead)
+	srv.router.HandleFunc("/ajax/item/{id:(?:\\d+)}/star", srv.handleAjaxItemSetStarred)
+	srv.router.HandleFunc("/ajax/item/{id:(?:\\d+)}/read_above", srv.handleAjaxItemMarkReadAbove)
+	srv.router.HandleFunc("/ajax/items/read", srv.handleAjaxItemsMarkRead)
 	srv.router.HandleFunc("/ajax/tag/all", srv.handleAjaxTagView)
 	srv.router.HandleFunc("/ajax/tag/submit", srv.handleAjaxTagSubmit)
 	srv.router.HandleFunc("/ajax/tag/details/{id:(?:\\d+)$}", srv.handleAjaxTagDetails)
@@ -221,14 +262,56 @@ func Create(addr string) (*Server, error) {
 	srv.router.HandleFunc("/ajax/search/delete/{id:(?:\\d+)$}", srv.handleAjaxSearchDelete)
 
 	return srv, nil
-} // func Create(addr string) (*Server, error)
+} // func Create(addr string, open database.Opener) (*Server, error)
+
+// SetReader sets the Reader that WebSub callbacks and requests to refresh
+// Feeds are passed on to. Without a Reader, the Server rejects them.
+func (srv *Server) SetReader(rdr *reader.Reader) {
+	srv.rdr = rdr
+} // func (srv *Server) SetReader(rdr *reader.Reader)
+
+// ListenAndServe runs the server's ListenAndServe method until ctx is
+// cancelled. It then waits for pending requests to finish, saves the
+// Blacklist and closes the database Pool before it returns.
+func (srv *Server) ListenAndServe(ctx context.Context) error {
+	var (
+		err  error
+		done = make(chan error, 1)
+	)
 
-// ListenAndServe runs the server's  ListenAndServe method
-func (srv *Server) ListenAndServe() {
 	srv.log.Printf("[DEBUG] Server start listening on %s.\n", srv.Addr)
 	defer srv.log.Println("[DEBUG] Server has quit.")
-	srv.web.ListenAndServe() // nolint: errcheck
-} // func (srv *Server) ListenAndServe()
+
+	go func() {
+		<-ctx.Done()
+
+		var sctx, cancel = context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		srv.log.Println("[INFO] Server is shutting down.")
+		done <- srv.web.Shutdown(sctx)
+	}()
+
+	if err = srv.web.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		srv.log.Printf("[ERROR] Server failed: %s\n", err.Error())
+		return err
+	} else if err = <-done; err != nil {
+		srv.log.Printf("[ERROR] Failed to shut down Server cleanly: %s\n",
+			err.Error())
+	}
+
+	if srv.bl.Changed() {
+		if err = srv.bl.Dump(common.Path(path.Blacklist)); err != nil {
+			srv.log.Printf("[ERROR] Failed to dump Blacklist to %s: %s\n",
+				common.Path(path.Blacklist),
+				err.Error())
+		}
+	}
+
+	srv.pool.Close() // nolint: errcheck
+
+	return err
+} // func (srv *Server) ListenAndServe(ctx context.Context) error
 
 func (srv *Server) handleFavIco(w http.ResponseWriter, request *http.Request) {
 	srv.log.Printf("[TRACE] Handle request for %s\n",
@@ -346,7 +429,7 @@ func (srv *Server) handleMain(w http.ResponseWriter, r *http.Request) {
 		err  error
 		msg  string
 		tmpl *template.Template
-		db   *database.Database
+		db   database.Store
 		sess *sessions.Session
 		data = tmplDataIndex{
 			tmplDataBase: tmplDataBase{
@@ -376,6 +459,12 @@ func (srv *Server) handleMain(w http.ResponseWriter, r *http.Request) {
 		srv.log.Println("[CRITICAL] " + msg)
 		srv.sendErrorMessage(w, msg)
 		return
+	} else if data.UnreadCnt, err = db.FeedGetUnreadCnt(); err != nil {
+		msg = fmt.Sprintf("Failed to load number of unread Items per Feed: %s",
+			err.Error())
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
 	}
 
 	if err = sess.Save(r, w); err != nil {
@@ -402,7 +491,7 @@ func (srv *Server) handleItemPage(w http.ResponseWriter, r *http.Request) {
 		err       error
 		msg       string
 		tmpl      *template.Template
-		db        *database.Database
+		db        database.Store
 		offsetStr string
 		sess      *sessions.Session
 		data      = tmplDataItems{
@@ -493,7 +582,7 @@ func (srv *Server) handleFeedDetails(w http.ResponseWriter, r *http.Request) {
 		err    error
 		msg    string
 		tmpl   *template.Template
-		db     *database.Database
+		db     database.Store
 		feedID int64
 		sess   *sessions.Session
 		data   = tmplDataFeedDetails{
@@ -537,6 +626,31 @@ func (srv *Server) handleFeedDetails(w http.ResponseWriter, r *http.Request) {
 		srv.log.Println("[CRITICAL] " + msg)
 		srv.sendErrorMessage(w, msg)
 		return
+	} else if data.History, err = db.FeedHistoryGetByFeed(data.Feed); err != nil {
+		msg = fmt.Sprintf("Failed to load history of Feed %d: %s", feedID, err.Error())
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if data.Mappings, err = db.CategoryMapGetByFeed(data.Feed); err != nil {
+		msg = fmt.Sprintf("Failed to load category mappings of Feed %d: %s", feedID, err.Error())
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if data.Categories, err = db.CategoryGetByFeed(data.Feed); err != nil {
+		msg = fmt.Sprintf("Failed to load categories of Feed %d: %s", feedID, err.Error())
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if data.Tags, err = db.TagGetSorted(); err != nil {
+		msg = fmt.Sprintf("Failed to load Tags: %s", err.Error())
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	if srv.rdr != nil {
+		var st = srv.rdr.HostStatus(data.Feed.URL.Host)
+		data.Throttle = &st
 	}
 
 	if err = sess.Save(r, w); err != nil {
@@ -563,7 +677,7 @@ func (srv *Server) handleFeedPage(w http.ResponseWriter, r *http.Request) {
 		err  error
 		msg  string
 		tmpl *template.Template
-		db   *database.Database
+		db   database.Store
 		sess *sessions.Session
 		data = tmplDataIndex{
 			tmplDataBase: tmplDataBase{
@@ -593,6 +707,12 @@ func (srv *Server) handleFeedPage(w http.ResponseWriter, r *http.Request) {
 		srv.log.Println("[CRITICAL] " + msg)
 		srv.sendErrorMessage(w, msg)
 		return
+	} else if data.UnreadCnt, err = db.FeedGetUnreadCnt(); err != nil {
+		msg = fmt.Sprintf("Failed to load number of unread Items per Feed: %s",
+			err.Error())
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
 	}
 
 	if err = sess.Save(r, w); err != nil {
@@ -610,6 +730,351 @@ func (srv *Server) handleFeedPage(w http.ResponseWriter, r *http.Request) {
 	}
 } // func (srv *Server) handleFeedPage(w http.ResponseWriter, r *http.Request)
 
+func (srv *Server) handleOPMLExport(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+	var (
+		err error
+		msg string
+		db  database.Store
+		buf bytes.Buffer
+	)
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if err = opml.Export(db, &buf); err != nil {
+		msg = fmt.Sprintf("Failed to export Feeds: %s", err.Error())
+		srv.log.Println("[ERROR] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"%s-%s.opml\"",
+			common.AppName,
+			time.Now().Format(common.TimestampFormatDate)))
+	w.WriteHeader(200)
+	if _, err = w.Write(buf.Bytes()); err != nil {
+		srv.log.Printf("[ERROR] Failed to send OPML document: %s\n",
+			err.Error())
+	}
+} // func (srv *Server) handleOPMLExport(w http.ResponseWriter, r *http.Request)
+
+func (srv *Server) handleEnclosure(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+	var (
+		err   error
+		msg   string
+		db    database.Store
+		encl  *model.Enclosure
+		idstr string
+		id    int64
+		rel   string
+	)
+
+	idstr = mux.Vars(r)["id"]
+
+	if id, err = strconv.ParseInt(idstr, 10, 64); err != nil {
+		msg = fmt.Sprintf("Cannot parse Enclosure ID %q: %s",
+			idstr,
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if encl, err = db.EnclosureGetByID(id); err != nil {
+		msg = fmt.Sprintf("Failed to load Enclosure %d: %s",
+			id,
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if encl == nil || !encl.IsDownloaded() {
+		srv.log.Printf("[INFO] Enclosure %d has not been downloaded\n", id)
+		http.NotFound(w, r)
+		return
+	} else if rel, err = filepath.Rel(common.Path(path.Downloads), encl.Path); err != nil || strings.HasPrefix(rel, "..") {
+		msg = fmt.Sprintf("Path of Enclosure %d is outside the download folder: %s",
+			id,
+			encl.Path)
+		srv.log.Printf("[CANTHAPPEN] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	// The MIME type comes from the Feed, so we cannot trust it. Like with
+	// Feed icons, we keep the browser from running any scripts the file
+	// might contain, and anything we would not play or display inline is
+	// offered as a download.
+	if encl.MimeType != "" {
+		w.Header().Set("Content-Type", encl.MimeType)
+	}
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if !isInlineMimeType(encl.MimeType) {
+		w.Header().Set("Content-Disposition", "attachment")
+	}
+
+	// ServeFile takes care of Range requests, so clients can seek.
+	http.ServeFile(w, r, encl.Path)
+} // func (srv *Server) handleEnclosure(w http.ResponseWriter, r *http.Request)
+
+// handleFeedIcon delivers the cached icon of a Feed, so we do not have to
+// link to the Feed's website.
+func (srv *Server) handleFeedIcon(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+	var (
+		err   error
+		msg   string
+		db    database.Store
+		feed  *model.Feed
+		idstr string
+		id    int64
+		rel   string
+	)
+
+	idstr = mux.Vars(r)["id"]
+
+	if id, err = strconv.ParseInt(idstr, 10, 64); err != nil {
+		msg = fmt.Sprintf("Cannot parse Feed ID %q: %s",
+			idstr,
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if feed, err = db.FeedGetByID(id); err != nil {
+		msg = fmt.Sprintf("Failed to load Feed %d: %s",
+			id,
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if feed == nil || !feed.Meta.HasIcon() {
+		http.NotFound(w, r)
+		return
+	} else if rel, err = filepath.Rel(common.Path(path.Icons), feed.Meta.Icon); err != nil || strings.HasPrefix(rel, "..") {
+		msg = fmt.Sprintf("Icon of Feed %d is outside the icon folder: %s",
+			id,
+			feed.Meta.Icon)
+		srv.log.Printf("[CANTHAPPEN] %s\n", msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	}
+
+	// Icons may be SVG images, which can contain scripts.
+	w.Header().Set("Content-Type", feed.Meta.IconType)
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if common.Debug {
+		w.Header().Set("Cache-Control", "no-store, max-age=0")
+	} else {
+		w.Header().Set("Cache-Control", "max-age=86400")
+	}
+
+	http.ServeFile(w, r, feed.Meta.Icon)
+} // func (srv *Server) handleFeedIcon(w http.ResponseWriter, r *http.Request)
+
+// handleWebSub handles the requests of WebSub hubs to our callback URL: GET
+// requests to verify our intent to subscribe, and POST requests to deliver
+// updates.
+func (srv *Server) handleWebSub(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle %s request for %s from %s\n",
+		r.Method,
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+	var (
+		err   error
+		idstr string
+		id    int64
+		ok    bool
+		lease int
+	)
+
+	idstr = mux.Vars(r)["id"]
+
+	if srv.rdr == nil {
+		http.NotFound(w, r)
+		return
+	} else if id, err = strconv.ParseInt(idstr, 10, 64); err != nil {
+		srv.log.Printf("[CANTHAPPEN] Cannot parse Feed ID %q: %s\n",
+			idstr,
+			err.Error())
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		var (
+			q    = r.URL.Query()
+			mode = q.Get("hub.mode")
+		)
+
+		if lstr := q.Get("hub.lease_seconds"); lstr != "" {
+			if lease, err = strconv.Atoi(lstr); err != nil {
+				http.Error(w, "Invalid lease", http.StatusBadRequest)
+				return
+			}
+		}
+
+		if ok, err = srv.rdr.Verify(id, mode, q.Get("hub.topic"), lease); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		} else if !ok {
+			http.NotFound(w, r)
+		} else if mode == "denied" {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			// We echo whatever the hub sent us, so the browser must
+			// not guess it is anything but plain text.
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("X-Content-Type-Options", "nosniff")
+			w.WriteHeader(http.StatusOK)
+			io.WriteString(w, q.Get("hub.challenge")) // nolint: errcheck
+		}
+	case http.MethodPost:
+		err = srv.rdr.Push(id, r.Body, r.Header.Get("X-Hub-Signature"))
+
+		switch {
+		case err == nil:
+			w.WriteHeader(http.StatusAccepted)
+		case errors.Is(err, reader.ErrUnknownSubscription):
+			// This tells the hub to drop the subscription.
+			w.WriteHeader(http.StatusGone)
+		case errors.Is(err, reader.ErrInvalidSignature):
+			// The hub must not learn whether the signature was
+			// valid, we simply ignore the update.
+			w.WriteHeader(http.StatusAccepted)
+		default:
+			srv.log.Printf("[ERROR] Failed to process update pushed for Feed %d: %s\n",
+				id,
+				err.Error())
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+} // func (srv *Server) handleWebSub(w http.ResponseWriter, r *http.Request)
+
+func (srv *Server) handleAjaxOPMLImport(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+	const maxUploadSize = 8 * 1024 * 1024
+	var (
+		err     error
+		sess    *sessions.Session
+		rbuf    []byte
+		db      database.Store
+		fh      io.ReadCloser
+		result  *opml.Result
+		names   []string
+		res     Reply
+		msg     string
+		hstatus = 200
+	)
+
+	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
+		res.Message = fmt.Sprintf(
+			"Error getting/creating session %s: %s",
+			sessionNameFrontend,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		sess = nil
+		hstatus = 403
+		goto SEND_RESPONSE
+	} else if err = r.ParseMultipartForm(maxUploadSize); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse form data: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if fh, _, err = r.FormFile("opml"); err != nil {
+		res.Message = fmt.Sprintf("Cannot get uploaded file: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	}
+
+	defer fh.Close() // nolint: errcheck
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if result, err = opml.Import(db, fh); err != nil {
+		res.Message = fmt.Sprintf("Failed to import OPML file: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	}
+
+	res.Status = true
+	res.Message = result.String()
+	res.Payload = make(map[string]string, 3)
+
+	names = make([]string, len(result.Added))
+	for i, f := range result.Added {
+		names[i] = f.Title
+	}
+	res.Payload["added"] = strings.Join(names, "\n")
+
+	names = make([]string, len(result.Duplicates))
+	for i, f := range result.Duplicates {
+		names[i] = f.Title
+	}
+	res.Payload["duplicates"] = strings.Join(names, "\n")
+
+	names = make([]string, len(result.Errors))
+	for i, e := range result.Errors {
+		names[i] = e.Error()
+	}
+	res.Payload["errors"] = strings.Join(names, "\n")
+
+SEND_RESPONSE:
+	if sess != nil {
+		if err = sess.Save(r, w); err != nil {
+			srv.log.Printf("[ERROR] Failed to set session cookie: %s\n",
+				err.Error())
+		}
+	}
+	res.Timestamp = time.Now()
+	if rbuf, err = json.Marshal(&res); err != nil {
+		srv.log.Printf("[ERROR] Error serializing response: %s\n",
+			err.Error())
+		rbuf = errJSON(err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(hstatus)
+	if _, err = w.Write(rbuf); err != nil {
+		msg = fmt.Sprintf("Failed to send result: %s",
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+	}
+} // func (srv *Server) handleAjaxOPMLImport(w http.ResponseWriter, r *http.Request)
+
 func (srv *Server) handleTagAll(w http.ResponseWriter, r *http.Request) {
 	const tmplName = "tags"
 	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
@@ -620,7 +1085,7 @@ func (srv *Server) handleTagAll(w http.ResponseWriter, r *http.Request) {
 		msg  string
 		tmpl *template.Template
 		sess *sessions.Session
-		db   *database.Database
+		db   database.Store
 		data = tmplDataTagAll{
 			tmplDataBase: tmplDataBase{
 				Title: "Items",
@@ -658,6 +1123,12 @@ func (srv *Server) handleTagAll(w http.ResponseWriter, r *http.Request) {
 		srv.log.Println("[CRITICAL] " + msg)
 		srv.sendErrorMessage(w, msg)
 		return
+	} else if data.UnreadCnt, err = db.TagGetUnreadCnt(); err != nil {
+		msg = fmt.Sprintf("Failed to load unread Item count: %s",
+			err.Error())
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
 	}
 
 	if err = sess.Save(r, w); err != nil {
@@ -737,7 +1208,7 @@ func (srv *Server) handleSearchMain(w http.ResponseWriter, r *http.Request) {
 	var (
 		err  error
 		msg  string
-		db   *database.Database
+		db   database.Store
 		tmpl *template.Template
 		sess *sessions.Session
 		data = tmplDataSearchMain{
@@ -819,7 +1290,7 @@ func (srv *Server) handleSubscribe(w http.ResponseWriter, r *http.Request) {
 		sess     *sessions.Session
 		feed     model.Feed
 		rbuf     []byte
-		db       *database.Database
+		db       database.Store
 		interval int64
 		res      Reply
 		msg      string
@@ -909,20 +1380,115 @@ SEND_RESPONSE:
 	}
 } // func (srv *Server) handleSubscribe(w http.ResponseWriter, r *http.Request)
 
-func (srv *Server) handleAjaxFeedToggle(w http.ResponseWriter, r *http.Request) {
+func (srv *Server) handleAjaxDiscover(w http.ResponseWriter, r *http.Request) {
 	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
 		r.URL.EscapedPath(),
 		r.RemoteAddr)
-
 	var (
-		err     error
-		sess    *sessions.Session
-		feed    *model.Feed
-		idstr   string
-		feedID  int64
-		rbuf    []byte
-		db      *database.Database
-		vars    map[string]string
+		err        error
+		sess       *sessions.Session
+		rbuf, cbuf []byte
+		addr       string
+		candidates []reader.Candidate
+		clist      []feedCandidate
+		res        Reply
+		msg        string
+		hstatus    = 200
+	)
+
+	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
+		res.Message = fmt.Sprintf(
+			"Error getting/creating session %s: %s",
+			sessionNameFrontend,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		sess = nil
+		hstatus = 403
+		goto SEND_RESPONSE
+	} else if err = r.ParseForm(); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse form data: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if addr = r.FormValue("url"); addr == "" {
+		res.Message = "No URL was given"
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if candidates, err = reader.Discover(addr); err != nil {
+		res.Message = fmt.Sprintf("Failed to discover Feeds at %s: %s",
+			addr,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	}
+
+	clist = make([]feedCandidate, len(candidates))
+	for i, c := range candidates {
+		clist[i] = feedCandidate{
+			Title:    c.Title,
+			URL:      c.URL.String(),
+			Homepage: c.Homepage.String(),
+			Type:     c.Type,
+		}
+	}
+
+	if cbuf, err = json.Marshal(clist); err != nil {
+		res.Message = fmt.Sprintf("Cannot serialize Feed candidates: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	}
+
+	res.Message = fmt.Sprintf("Found %d Feed(s) at %s",
+		len(candidates),
+		addr)
+	res.Status = true
+	res.Payload = map[string]string{
+		"candidates": string(cbuf),
+	}
+
+SEND_RESPONSE:
+	if sess != nil {
+		if err = sess.Save(r, w); err != nil {
+			srv.log.Printf("[ERROR] Failed to set session cookie: %s\n",
+				err.Error())
+		}
+	}
+	res.Timestamp = time.Now()
+	if rbuf, err = json.Marshal(&res); err != nil {
+		srv.log.Printf("[ERROR] Error serializing response: %s\n",
+			err.Error())
+		rbuf = errJSON(err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(hstatus)
+	if _, err = w.Write(rbuf); err != nil {
+		msg = fmt.Sprintf("Failed to send result: %s",
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+	}
+} // func (srv *Server) handleAjaxDiscover(w http.ResponseWriter, r *http.Request)
+
+func (srv *Server) handleAjaxFeedToggle(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+
+	var (
+		err     error
+		sess    *sessions.Session
+		feed    *model.Feed
+		idstr   string
+		feedID  int64
+		rbuf    []byte
+		db      database.Store
+		vars    map[string]string
 		res     Reply
 		msg     string
 		hstatus = 200
@@ -1006,7 +1572,7 @@ SEND_RESPONSE:
 	}
 } // func (srv *Server) handleAjaxFeedToggle(w http.ResponseWriter, r *http.Request)
 
-func (srv *Server) handleAjaxFeedDelete(w http.ResponseWriter, r *http.Request) {
+func (srv *Server) handleAjaxFeedToggleFull(w http.ResponseWriter, r *http.Request) {
 	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
 		r.URL.EscapedPath(),
 		r.RemoteAddr)
@@ -1014,21 +1580,31 @@ func (srv *Server) handleAjaxFeedDelete(w http.ResponseWriter, r *http.Request)
 	var (
 		err     error
 		sess    *sessions.Session
-		rbuf    []byte
-		idstr   string
-		fid     int64
 		feed    *model.Feed
-		db      *database.Database
+		idstr   string
+		feedID  int64
+		rbuf    []byte
+		db      database.Store
+		vars    map[string]string
 		res     Reply
-		rvars   map[string]string
+		msg     string
 		hstatus = 200
 	)
 
-	rvars = mux.Vars(r)
-	idstr = rvars["id"]
+	vars = mux.Vars(r)
+	idstr = vars["id"]
 
-	if fid, err = strconv.ParseInt(idstr, 10, 64); err != nil {
-		res.Message = fmt.Sprintf("Failed to parse Feed ID %q: %s\n",
+	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
+		res.Message = fmt.Sprintf(
+			"Error getting/creating session %s: %s",
+			sessionNameFrontend,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		sess = nil
+		hstatus = 403
+		goto SEND_RESPONSE
+	} else if feedID, err = strconv.ParseInt(idstr, 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse Feed ID %q: %s",
 			idstr,
 			err.Error())
 		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
@@ -1036,58 +1612,23 @@ func (srv *Server) handleAjaxFeedDelete(w http.ResponseWriter, r *http.Request)
 		goto SEND_RESPONSE
 	}
 
-	srv.log.Printf("[INFO] Delete RSS Feed %d\n", fid)
-
 	db = srv.pool.Get()
 	defer srv.pool.Put(db)
 
-	if err = db.Begin(); err != nil {
-		res.Message = fmt.Sprintf("Failed to initiate transaction: %s",
-			err.Error())
-		srv.log.Printf("[ERROR] %s\n", res.Message)
-		hstatus = 500
-		goto SEND_RESPONSE
-	}
-
-	defer func() {
-		if res.Status {
-			db.Commit() // nolint: errcheck
-		} else {
-			db.Rollback() // nolint: errcheck
-		}
-	}()
-
-	if feed, err = db.FeedGetByID(fid); err != nil {
-		res.Message = fmt.Sprintf("Failed to fetch Feed %d from Database: %s",
-			fid,
+	if feed, err = db.FeedGetByID(feedID); err != nil {
+		res.Message = fmt.Sprintf("Failed to load Feed %d: %s",
+			feedID,
 			err.Error())
 		srv.log.Printf("[ERROR] %s\n", res.Message)
 		hstatus = 500
 		goto SEND_RESPONSE
 	} else if feed == nil {
-		res.Message = fmt.Sprintf("Feed %d was not found in Database",
-			fid)
-		srv.log.Printf("[ERROR] %s\n", res.Message)
-		hstatus = 500
-		goto SEND_RESPONSE
-	} else if err = db.TagLinkDeleteByFeed(feed); err != nil {
-		res.Message = fmt.Sprintf("Failed to delete Tag links to Items for Feed %s (%d): %s",
-			feed.Title,
-			feed.ID,
-			err.Error())
-		srv.log.Printf("[ERROR] %s\n", res.Message)
-		hstatus = 500
-		goto SEND_RESPONSE
-	} else if err = db.ItemDeleteByFeed(feed); err != nil {
-		res.Message = fmt.Sprintf("Failed to delete Items for Feed %s (%d): %s",
-			feed.Title,
-			feed.ID,
-			err.Error())
+		res.Message = fmt.Sprintf("Feed %d was not found in database", feedID)
 		srv.log.Printf("[ERROR] %s\n", res.Message)
 		hstatus = 500
 		goto SEND_RESPONSE
-	} else if err = db.FeedDelete(feed); err != nil {
-		res.Message = fmt.Sprintf("Failed to delete Feed %s (%d): %s",
+	} else if err = db.FeedSetFetchFull(feed, !feed.FetchFull); err != nil {
+		res.Message = fmt.Sprintf("Failed to toggle full article flag for Feed %s (%d): %s",
 			feed.Title,
 			feed.ID,
 			err.Error())
@@ -1096,10 +1637,13 @@ func (srv *Server) handleAjaxFeedDelete(w http.ResponseWriter, r *http.Request)
 		goto SEND_RESPONSE
 	}
 
-	res.Message = fmt.Sprintf("Feed %s (%d) has been deleted successfully",
+	res.Message = fmt.Sprintf("Successfully toggled full article flag for Feed %s (%d)",
 		feed.Title,
 		feed.ID)
 	res.Status = true
+	res.Payload = map[string]string{
+		"id": strconv.Itoa(int(feed.ID)),
+	}
 
 SEND_RESPONSE:
 	if sess != nil {
@@ -1119,139 +1663,1539 @@ SEND_RESPONSE:
 	w.Header().Set("Cache-Control", "no-store, max-age=0")
 	w.WriteHeader(hstatus)
 	if _, err = w.Write(rbuf); err != nil {
-		var msg = fmt.Sprintf("Failed to send result: %s",
+		msg = fmt.Sprintf("Failed to send result: %s",
 			err.Error())
 		srv.log.Println("[ERROR] " + msg)
 	}
-} // func (srv *Server) handleAjaxFeedDelete(w http.ResponseWriter, r *http.Request)
+} // func (srv *Server) handleAjaxFeedToggleFull(w http.ResponseWriter, r *http.Request)
 
-func (srv *Server) handleAjaxItems(w http.ResponseWriter, r *http.Request) {
+func (srv *Server) handleAjaxFeedToggleDownload(w http.ResponseWriter, r *http.Request) {
 	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
 		r.URL.EscapedPath(),
 		r.RemoteAddr)
 
-	const tmplName = "item_view"
 	var (
-		err         error
-		sess        *sessions.Session
-		rbuf        []byte
-		db          *database.Database
-		buf         bytes.Buffer
-		tmpl        *template.Template
-		cnt, offset int64
-		hideBoring  bool
-		hbstr       string
-		res         Reply
-		msg, rating string
-		feeds       []model.Feed
-		tags        []*model.Tag
-		items       []*model.Item
-		vars        map[string]string
-		hstatus     = 200
-		data        = tmplDataItemView{
-			tmplDataBase: tmplDataBase{
-				Debug: common.Debug,
-				URL:   r.URL.String(),
-			},
-		}
+		err     error
+		sess    *sessions.Session
+		feed    *model.Feed
+		idstr   string
+		feedID  int64
+		rbuf    []byte
+		db      database.Store
+		vars    map[string]string
+		res     Reply
+		msg     string
+		hstatus = 200
 	)
 
 	vars = mux.Vars(r)
+	idstr = vars["id"]
 
 	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
-		msg = fmt.Sprintf("Error getting client session from session store: %s",
-			err.Error())
-		srv.log.Println("[CRITICAL] " + msg)
-		srv.sendErrorMessage(w, msg)
-		return
-	} else if cnt, err = strconv.ParseInt(vars["cnt"], 10, 64); err != nil {
-		res.Message = fmt.Sprintf("Cannot parse item count %q: %s",
-			vars["cnt"],
+		res.Message = fmt.Sprintf(
+			"Error getting/creating session %s: %s",
+			sessionNameFrontend,
 			err.Error())
-		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
-		hstatus = 400
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		sess = nil
+		hstatus = 403
 		goto SEND_RESPONSE
-	} else if offset, err = strconv.ParseInt(vars["offset"], 10, 64); err != nil {
-		res.Message = fmt.Sprintf("Cannot parse offset %q: %s",
-			vars["offset"],
+	} else if feedID, err = strconv.ParseInt(idstr, 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse Feed ID %q: %s",
+			idstr,
 			err.Error())
 		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
 		hstatus = 400
 		goto SEND_RESPONSE
-	} else if err = r.ParseForm(); err != nil {
-		res.Message = fmt.Sprintf("Cannot parse form data: %s",
-			err.Error())
-		srv.log.Printf("[ERROR] %s\n", res.Message)
-		hstatus = 400
-		goto SEND_RESPONSE
-	}
-
-	hbstr = r.FormValue("hideBoring")
-	if hideBoring, err = strconv.ParseBool(hbstr); err != nil {
-		res.Message = fmt.Sprintf("Cannot parse hideBoring flag %q: %s",
-			hbstr,
-			err.Error())
-		srv.log.Printf("[ERROR] %s\n", res.Message)
-		hstatus = 500
-		goto SEND_RESPONSE
 	}
 
-	srv.log.Printf("[DEBUG] Hide Boring Items? %t\n",
-		hideBoring)
-
-	srv.log.Printf("[DEBUG] Load %d items, offset %d",
-		cnt,
-		offset)
-
 	db = srv.pool.Get()
 	defer srv.pool.Put(db)
 
-	if items, err = db.ItemGetRecentPaged(cnt, offset); err != nil {
-		res.Message = fmt.Sprintf("Failed to load recent items: %s",
+	if feed, err = db.FeedGetByID(feedID); err != nil {
+		res.Message = fmt.Sprintf("Failed to load Feed %d: %s",
+			feedID,
 			err.Error())
-		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		srv.log.Printf("[ERROR] %s\n", res.Message)
 		hstatus = 500
 		goto SEND_RESPONSE
-	} else if feeds, err = db.FeedGetAll(); err != nil {
-		res.Message = fmt.Sprintf("Failed to load all Feeds from database: %s",
-			err.Error())
-		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+	} else if feed == nil {
+		res.Message = fmt.Sprintf("Feed %d was not found in database", feedID)
+		srv.log.Printf("[ERROR] %s\n", res.Message)
 		hstatus = 500
 		goto SEND_RESPONSE
-	} else if tags, err = db.TagGetSorted(); err != nil {
-		res.Message = fmt.Sprintf("Failed to load all Tags: %s",
+	} else if err = db.FeedSetDownload(feed, !feed.Download); err != nil {
+		res.Message = fmt.Sprintf("Failed to toggle download flag for Feed %s (%d): %s",
+			feed.Title,
+			feed.ID,
 			err.Error())
 		srv.log.Printf("[ERROR] %s\n", res.Message)
 		hstatus = 500
 		goto SEND_RESPONSE
-	} else if tmpl = srv.tmpl.Lookup(tmplName); tmpl == nil {
-		res.Message = fmt.Sprintf("Could not find template %q", tmplName)
-		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+	}
+
+	res.Message = fmt.Sprintf("Successfully toggled download flag for Feed %s (%d)",
+		feed.Title,
+		feed.ID)
+	res.Status = true
+	res.Payload = map[string]string{
+		"id": strconv.Itoa(int(feed.ID)),
+	}
+
+SEND_RESPONSE:
+	if sess != nil {
+		if err = sess.Save(r, w); err != nil {
+			srv.log.Printf("[ERROR] Failed to set session cookie: %s\n",
+				err.Error())
+		}
+	}
+	res.Timestamp = time.Now()
+	if rbuf, err = json.Marshal(&res); err != nil {
+		srv.log.Printf("[ERROR] Error serializing response: %s\n",
+			err.Error())
+		rbuf = errJSON(err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(hstatus)
+	if _, err = w.Write(rbuf); err != nil {
+		msg = fmt.Sprintf("Failed to send result: %s",
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+	}
+} // func (srv *Server) handleAjaxFeedToggleDownload(w http.ResponseWriter, r *http.Request)
+
+func (srv *Server) handleAjaxFeedRefresh(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+
+	var (
+		err     error
+		sess    *sessions.Session
+		feed    *model.Feed
+		idstr   string
+		feedID  int64
+		rbuf    []byte
+		db      database.Store
+		vars    map[string]string
+		res     Reply
+		msg     string
+		results <-chan reader.RefreshResult
+		result  reader.RefreshResult
+		hstatus = 200
+	)
+
+	vars = mux.Vars(r)
+	idstr = vars["id"]
+
+	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
+		res.Message = fmt.Sprintf(
+			"Error getting/creating session %s: %s",
+			sessionNameFrontend,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		sess = nil
+		hstatus = 403
+		goto SEND_RESPONSE
+	} else if feedID, err = strconv.ParseInt(idstr, 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse Feed ID %q: %s",
+			idstr,
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if srv.rdr == nil {
+		res.Message = "Cannot refresh Feeds without a Reader"
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 503
+		goto SEND_RESPONSE
+	}
+
+	db = srv.pool.Get()
+	feed, err = db.FeedGetByID(feedID)
+	srv.pool.Put(db)
+
+	if err != nil {
+		res.Message = fmt.Sprintf("Failed to load Feed %d: %s",
+			feedID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if feed == nil {
+		res.Message = fmt.Sprintf("Feed %d was not found in database", feedID)
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 404
+		goto SEND_RESPONSE
+	} else if results, err = srv.rdr.Refresh(r.Context(), *feed); err != nil {
+		res.Message = fmt.Sprintf("Cannot refresh Feed %s (%d): %s",
+			feed.Title,
+			feed.ID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 503
+		goto SEND_RESPONSE
+	}
+
+	select {
+	case result = <-results:
+	case <-r.Context().Done():
+		srv.log.Printf("[INFO] Client went away while Feed %s (%d) was refreshed\n",
+			feed.Title,
+			feed.ID)
+		return
+	}
+
+	res.Payload = map[string]string{
+		"id":        strconv.Itoa(int(feed.ID)),
+		"new_items": strconv.Itoa(result.NewItems),
+		"duration":  result.Duration.Round(time.Millisecond).String(),
+	}
+
+	if result.Err != nil {
+		res.Message = fmt.Sprintf("Failed to refresh Feed %s (%d) after %s: %s",
+			feed.Title,
+			feed.ID,
+			res.Payload["duration"],
+			result.Err.Error())
+		res.Payload["error"] = result.Err.Error()
+		goto SEND_RESPONSE
+	}
+
+	res.Message = fmt.Sprintf("Refreshed Feed %s in %s, %d new Item(s)",
+		feed.Title,
+		res.Payload["duration"],
+		result.NewItems)
+	res.Status = true
+
+SEND_RESPONSE:
+	if sess != nil {
+		if err = sess.Save(r, w); err != nil {
+			srv.log.Printf("[ERROR] Failed to set session cookie: %s\n",
+				err.Error())
+		}
+	}
+	res.Timestamp = time.Now()
+	if rbuf, err = json.Marshal(&res); err != nil {
+		srv.log.Printf("[ERROR] Error serializing response: %s\n",
+			err.Error())
+		rbuf = errJSON(err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(hstatus)
+	if _, err = w.Write(rbuf); err != nil {
+		msg = fmt.Sprintf("Failed to send result: %s",
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+	}
+} // func (srv *Server) handleAjaxFeedRefresh(w http.ResponseWriter, r *http.Request)
+
+func (srv *Server) handleAjaxFeedsRefresh(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+
+	var (
+		err      error
+		sess     *sessions.Session
+		all      []model.Feed
+		feeds    []model.Feed
+		rbuf     []byte
+		db       database.Store
+		res      Reply
+		msg      string
+		results  <-chan reader.RefreshResult
+		errs     []string
+		newItems int
+		begin    time.Time
+		hstatus  = 200
+	)
+
+	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
+		res.Message = fmt.Sprintf(
+			"Error getting/creating session %s: %s",
+			sessionNameFrontend,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		sess = nil
+		hstatus = 403
+		goto SEND_RESPONSE
+	} else if srv.rdr == nil {
+		res.Message = "Cannot refresh Feeds without a Reader"
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 503
+		goto SEND_RESPONSE
+	}
+
+	db = srv.pool.Get()
+	all, err = db.FeedGetAll()
+	srv.pool.Put(db)
+
+	if err != nil {
+		res.Message = fmt.Sprintf("Failed to load Feeds: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	}
+
+	feeds = make([]model.Feed, 0, len(all))
+	for _, f := range all {
+		if f.Active {
+			feeds = append(feeds, f)
+		}
+	}
+
+	begin = time.Now()
+
+	if results, err = srv.rdr.Refresh(r.Context(), feeds...); err != nil {
+		res.Message = fmt.Sprintf("Cannot refresh Feeds: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 503
+		goto SEND_RESPONSE
+	}
+
+	for range feeds {
+		var result reader.RefreshResult
+
+		select {
+		case result = <-results:
+		case <-r.Context().Done():
+			srv.log.Printf("[INFO] Client went away while %d Feeds were refreshed\n",
+				len(feeds))
+			return
+		}
+
+		if result.Err != nil {
+			errs = append(errs, fmt.Sprintf("%s (%d): %s",
+				result.Feed.Title,
+				result.Feed.ID,
+				result.Err.Error()))
+		} else {
+			newItems += result.NewItems
+		}
+	}
+
+	res.Payload = map[string]string{
+		"feeds":     strconv.Itoa(len(feeds)),
+		"failed":    strconv.Itoa(len(errs)),
+		"new_items": strconv.Itoa(newItems),
+		"duration":  time.Since(begin).Round(time.Millisecond).String(),
+		"errors":    strings.Join(errs, "\n"),
+	}
+
+	if len(errs) > 0 {
+		res.Message = fmt.Sprintf("Failed to refresh %d of %d Feed(s) after %s, %d new Item(s)",
+			len(errs),
+			len(feeds),
+			res.Payload["duration"],
+			newItems)
+		goto SEND_RESPONSE
+	}
+
+	res.Message = fmt.Sprintf("Refreshed %d Feed(s) in %s, %d new Item(s)",
+		len(feeds),
+		res.Payload["duration"],
+		newItems)
+	res.Status = true
+
+SEND_RESPONSE:
+	if sess != nil {
+		if err = sess.Save(r, w); err != nil {
+			srv.log.Printf("[ERROR] Failed to set session cookie: %s\n",
+				err.Error())
+		}
+	}
+	res.Timestamp = time.Now()
+	if rbuf, err = json.Marshal(&res); err != nil {
+		srv.log.Printf("[ERROR] Error serializing response: %s\n",
+			err.Error())
+		rbuf = errJSON(err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(hstatus)
+	if _, err = w.Write(rbuf); err != nil {
+		msg = fmt.Sprintf("Failed to send result: %s",
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+	}
+} // func (srv *Server) handleAjaxFeedsRefresh(w http.ResponseWriter, r *http.Request)
+
+func (srv *Server) handleAjaxFeedSetBound(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+
+	var (
+		err     error
+		sess    *sessions.Session
+		feed    *model.Feed
+		idstr   string
+		feedID  int64
+		bound   int64
+		rbuf    []byte
+		db      database.Store
+		vars    map[string]string
+		res     Reply
+		msg     string
+		hstatus = 200
+	)
+
+	vars = mux.Vars(r)
+	idstr = vars["id"]
+
+	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
+		res.Message = fmt.Sprintf(
+			"Error getting/creating session %s: %s",
+			sessionNameFrontend,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		sess = nil
+		hstatus = 403
+		goto SEND_RESPONSE
+	} else if feedID, err = strconv.ParseInt(idstr, 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse Feed ID %q: %s",
+			idstr,
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if bound, err = strconv.ParseInt(vars["bound"], 10, 8); err != nil ||
+		(model.IntervalBound(bound) != model.BoundLower && model.IntervalBound(bound) != model.BoundUpper) {
+		res.Message = fmt.Sprintf("Invalid interval bound %q",
+			vars["bound"])
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if feed, err = db.FeedGetByID(feedID); err != nil {
+		res.Message = fmt.Sprintf("Failed to load Feed %d: %s",
+			feedID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if feed == nil {
+		res.Message = fmt.Sprintf("Feed %d was not found in database", feedID)
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if err = db.FeedSetIntervalBound(feed, model.IntervalBound(bound)); err != nil {
+		res.Message = fmt.Sprintf("Failed to set interval bound for Feed %s (%d): %s",
+			feed.Title,
+			feed.ID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	}
+
+	res.Message = fmt.Sprintf("Update interval of Feed %s (%d) is now the %s bound",
+		feed.Title,
+		feed.ID,
+		strings.ToLower(feed.Bound.String()))
+	res.Status = true
+	res.Payload = map[string]string{
+		"id": strconv.Itoa(int(feed.ID)),
+	}
+
+SEND_RESPONSE:
+	if sess != nil {
+		if err = sess.Save(r, w); err != nil {
+			srv.log.Printf("[ERROR] Failed to set session cookie: %s\n",
+				err.Error())
+		}
+	}
+	res.Timestamp = time.Now()
+	if rbuf, err = json.Marshal(&res); err != nil {
+		srv.log.Printf("[ERROR] Error serializing response: %s\n",
+			err.Error())
+		rbuf = errJSON(err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(hstatus)
+	if _, err = w.Write(rbuf); err != nil {
+		msg = fmt.Sprintf("Failed to send result: %s",
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+	}
+} // func (srv *Server) handleAjaxFeedSetBound(w http.ResponseWriter, r *http.Request)
+
+func (srv *Server) handleAjaxFeedSetRetention(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+
+	var (
+		err     error
+		sess    *sessions.Session
+		feed    *model.Feed
+		idstr   string
+		feedID  int64
+		days    int64
+		rbuf    []byte
+		db      database.Store
+		res     Reply
+		msg     string
+		hstatus = 200
+	)
+
+	idstr = mux.Vars(r)["id"]
+
+	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
+		res.Message = fmt.Sprintf(
+			"Error getting/creating session %s: %s",
+			sessionNameFrontend,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		sess = nil
+		hstatus = 403
+		goto SEND_RESPONSE
+	} else if feedID, err = strconv.ParseInt(idstr, 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse Feed ID %q: %s",
+			idstr,
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if err = r.ParseForm(); err != nil {
+		res.Message = fmt.Sprintf("Error parsing form data: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if days, err = strconv.ParseInt(strings.TrimSpace(r.FormValue("days")), 10, 32); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse retention period %q: %s",
+			r.FormValue("days"),
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if feed, err = db.FeedGetByID(feedID); err != nil {
+		res.Message = fmt.Sprintf("Failed to load Feed %d: %s",
+			feedID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if feed == nil {
+		res.Message = fmt.Sprintf("Feed %d was not found in database", feedID)
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 404
+		goto SEND_RESPONSE
+	} else if err = db.FeedSetRetention(feed, int(days)); err != nil {
+		res.Message = fmt.Sprintf("Failed to set retention period for Feed %s (%d): %s",
+			feed.Title,
+			feed.ID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	}
+
+	switch {
+	case feed.Retention == 0:
+		res.Message = fmt.Sprintf("Feed %s (%d) uses the default retention period",
+			feed.Title,
+			feed.ID)
+	case feed.Retention < 0:
+		res.Message = fmt.Sprintf("Items of Feed %s (%d) are kept forever",
+			feed.Title,
+			feed.ID)
+	default:
+		res.Message = fmt.Sprintf("Items of Feed %s (%d) are kept for %d day(s)",
+			feed.Title,
+			feed.ID,
+			feed.Retention)
+	}
+	res.Status = true
+	res.Payload = map[string]string{
+		"id": strconv.Itoa(int(feed.ID)),
+	}
+
+SEND_RESPONSE:
+	if sess != nil {
+		if err = sess.Save(r, w); err != nil {
+			srv.log.Printf("[ERROR] Failed to set session cookie: %s\n",
+				err.Error())
+		}
+	}
+	res.Timestamp = time.Now()
+	if rbuf, err = json.Marshal(&res); err != nil {
+		srv.log.Printf("[ERROR] Error serializing response: %s\n",
+			err.Error())
+		rbuf = errJSON(err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(hstatus)
+	if _, err = w.Write(rbuf); err != nil {
+		msg = fmt.Sprintf("Failed to send result: %s",
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+	}
+} // func (srv *Server) handleAjaxFeedSetRetention(w http.ResponseWriter, r *http.Request)
+
+func (srv *Server) handleAjaxFeedHTTPSettings(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+
+	var (
+		err     error
+		sess    *sessions.Session
+		feed    *model.Feed
+		idstr   string
+		feedID  int64
+		kind    int64
+		headers map[string]string
+		auth    model.Credentials
+		rbuf    []byte
+		db      database.Store
+		res     Reply
+		msg     string
+		hstatus = 200
+	)
+
+	idstr = mux.Vars(r)["id"]
+
+	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
+		res.Message = fmt.Sprintf(
+			"Error getting/creating session %s: %s",
+			sessionNameFrontend,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		sess = nil
+		hstatus = 403
+		goto SEND_RESPONSE
+	} else if feedID, err = strconv.ParseInt(idstr, 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse Feed ID %q: %s",
+			idstr,
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if err = r.ParseForm(); err != nil {
+		res.Message = fmt.Sprintf("Error parsing form data: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if headers, err = parseHeaders(r.FormValue("headers")); err != nil {
+		res.Message = err.Error()
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if kind, err = strconv.ParseInt(r.FormValue("auth_kind"), 10, 8); err != nil || kind < int64(model.AuthNone) || kind > int64(model.AuthBearer) {
+		res.Message = fmt.Sprintf("Invalid authentication method %q",
+			r.FormValue("auth_kind"))
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if feed, err = db.FeedGetByID(feedID); err != nil {
+		res.Message = fmt.Sprintf("Failed to load Feed %d: %s",
+			feedID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if feed == nil {
+		res.Message = fmt.Sprintf("Feed %d was not found in database", feedID)
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 404
+		goto SEND_RESPONSE
+	}
+
+	auth.Kind = model.AuthKind(kind)
+
+	if auth.Kind != model.AuthNone {
+		auth.Username = strings.TrimSpace(r.FormValue("auth_user"))
+		// We never send the secret to the browser, so an empty field
+		// means the user wants to keep the current one.
+		if auth.Secret = r.FormValue("auth_secret"); auth.Secret == "" {
+			auth.Secret = feed.Auth.Secret
+		}
+	}
+
+	if err = db.FeedSetHTTPSettings(feed, headers, auth); err != nil {
+		res.Message = fmt.Sprintf("Failed to save HTTP settings for Feed %s (%d): %s",
+			feed.Title,
+			feed.ID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	}
+
+	res.Message = fmt.Sprintf("Saved HTTP settings for Feed %s (%d)",
+		feed.Title,
+		feed.ID)
+	res.Status = true
+	res.Payload = map[string]string{
+		"id":      strconv.Itoa(int(feed.ID)),
+		"headers": strconv.Itoa(len(headers)),
+	}
+
+SEND_RESPONSE:
+	if sess != nil {
+		if err = sess.Save(r, w); err != nil {
+			srv.log.Printf("[ERROR] Failed to set session cookie: %s\n",
+				err.Error())
+		}
+	}
+	res.Timestamp = time.Now()
+	if rbuf, err = json.Marshal(&res); err != nil {
+		srv.log.Printf("[ERROR] Error serializing response: %s\n",
+			err.Error())
+		rbuf = errJSON(err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(hstatus)
+	if _, err = w.Write(rbuf); err != nil {
+		msg = fmt.Sprintf("Failed to send result: %s",
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+	}
+} // func (srv *Server) handleAjaxFeedHTTPSettings(w http.ResponseWriter, r *http.Request)
+
+func (srv *Server) handleAjaxFeedUpdate(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+
+	var (
+		err      error
+		sess     *sessions.Session
+		feed     *model.Feed
+		idstr    string
+		feedID   int64
+		interval int64
+		addr     *url.URL
+		homepage *url.URL
+		oldURL   string
+		rbuf     []byte
+		db       database.Store
+		res      Reply
+		msg      string
+		hstatus  = 200
+	)
+
+	idstr = mux.Vars(r)["id"]
+
+	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
+		res.Message = fmt.Sprintf(
+			"Error getting/creating session %s: %s",
+			sessionNameFrontend,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		sess = nil
+		hstatus = 403
+		goto SEND_RESPONSE
+	} else if feedID, err = strconv.ParseInt(idstr, 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse Feed ID %q: %s",
+			idstr,
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if err = r.ParseForm(); err != nil {
+		res.Message = fmt.Sprintf("Error parsing form data: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if addr, err = url.Parse(strings.TrimSpace(r.FormValue("url"))); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse URL %q: %s",
+			r.FormValue("url"),
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if homepage, err = url.Parse(strings.TrimSpace(r.FormValue("homepage"))); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse Homepage URL %q: %s",
+			r.FormValue("homepage"),
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if interval, err = strconv.ParseInt(r.FormValue("interval"), 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse refresh interval %q: %s",
+			r.FormValue("interval"),
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if feed, err = db.FeedGetByID(feedID); err != nil {
+		res.Message = fmt.Sprintf("Failed to load Feed %d: %s",
+			feedID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if feed == nil {
+		res.Message = fmt.Sprintf("Feed %d was not found in database", feedID)
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 404
+		goto SEND_RESPONSE
+	}
+
+	oldURL = feed.URL.String()
+
+	if err = db.FeedUpdate(feed, r.FormValue("title"), addr, homepage, time.Second*time.Duration(interval)); err != nil {
+		res.Message = fmt.Sprintf("Failed to update Feed %s (%d): %s",
+			feed.Title,
+			feed.ID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		if errors.Is(err, database.ErrInvalidValue) {
+			hstatus = 400
+		} else {
+			hstatus = 500
+		}
+		goto SEND_RESPONSE
+	}
+
+	res.Message = fmt.Sprintf("Updated Feed %s (%d)",
+		feed.Title,
+		feed.ID)
+	res.Status = true
+	res.Payload = map[string]string{
+		"id":    strconv.Itoa(int(feed.ID)),
+		"moved": strconv.FormatBool(oldURL != feed.URL.String()),
+	}
+
+SEND_RESPONSE:
+	if sess != nil {
+		if err = sess.Save(r, w); err != nil {
+			srv.log.Printf("[ERROR] Failed to set session cookie: %s\n",
+				err.Error())
+		}
+	}
+	res.Timestamp = time.Now()
+	if rbuf, err = json.Marshal(&res); err != nil {
+		srv.log.Printf("[ERROR] Error serializing response: %s\n",
+			err.Error())
+		rbuf = errJSON(err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(hstatus)
+	if _, err = w.Write(rbuf); err != nil {
+		msg = fmt.Sprintf("Failed to send result: %s",
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+	}
+} // func (srv *Server) handleAjaxFeedUpdate(w http.ResponseWriter, r *http.Request)
+
+func (srv *Server) handleAjaxFeedDelete(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+
+	var (
+		err     error
+		sess    *sessions.Session
+		rbuf    []byte
+		idstr   string
+		fid     int64
+		feed    *model.Feed
+		db      database.Store
+		res     Reply
+		rvars   map[string]string
+		hstatus = 200
+	)
+
+	rvars = mux.Vars(r)
+	idstr = rvars["id"]
+
+	if fid, err = strconv.ParseInt(idstr, 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Failed to parse Feed ID %q: %s\n",
+			idstr,
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	}
+
+	srv.log.Printf("[INFO] Delete RSS Feed %d\n", fid)
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if err = db.Begin(); err != nil {
+		res.Message = fmt.Sprintf("Failed to initiate transaction: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	}
+
+	defer func() {
+		if res.Status {
+			db.Commit() // nolint: errcheck
+		} else {
+			db.Rollback() // nolint: errcheck
+		}
+	}()
+
+	if feed, err = db.FeedGetByID(fid); err != nil {
+		res.Message = fmt.Sprintf("Failed to fetch Feed %d from Database: %s",
+			fid,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if feed == nil {
+		res.Message = fmt.Sprintf("Feed %d was not found in Database",
+			fid)
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if err = db.TagLinkDeleteByFeed(feed); err != nil {
+		res.Message = fmt.Sprintf("Failed to delete Tag links to Items for Feed %s (%d): %s",
+			feed.Title,
+			feed.ID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if err = db.ItemDeleteByFeed(feed); err != nil {
+		res.Message = fmt.Sprintf("Failed to delete Items for Feed %s (%d): %s",
+			feed.Title,
+			feed.ID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if err = db.FeedDelete(feed); err != nil {
+		res.Message = fmt.Sprintf("Failed to delete Feed %s (%d): %s",
+			feed.Title,
+			feed.ID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if feed.Meta.HasIcon() {
+		if err = os.Remove(feed.Meta.Icon); err != nil && !os.IsNotExist(err) {
+			srv.log.Printf("[ERROR] Failed to remove icon of Feed %s (%d): %s\n",
+				feed.Title,
+				feed.ID,
+				err.Error())
+		}
+	}
+
+	res.Message = fmt.Sprintf("Feed %s (%d) has been deleted successfully",
+		feed.Title,
+		feed.ID)
+	res.Status = true
+
+SEND_RESPONSE:
+	if sess != nil {
+		if err = sess.Save(r, w); err != nil {
+			srv.log.Printf("[ERROR] Failed to set session cookie: %s\n",
+				err.Error())
+		}
+	}
+	res.Timestamp = time.Now()
+	if rbuf, err = json.Marshal(&res); err != nil {
+		srv.log.Printf("[ERROR] Error serializing response: %s\n",
+			err.Error())
+		rbuf = errJSON(err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(hstatus)
+	if _, err = w.Write(rbuf); err != nil {
+		var msg = fmt.Sprintf("Failed to send result: %s",
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+	}
+} // func (srv *Server) handleAjaxFeedDelete(w http.ResponseWriter, r *http.Request)
+
+func (srv *Server) handleAjaxCategoryMapAdd(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+
+	var (
+		err     error
+		sess    *sessions.Session
+		feed    *model.Feed
+		tag     *model.Tag
+		idstr   string
+		feedID  int64
+		tagID   int64
+		rbuf    []byte
+		db      database.Store
+		res     Reply
+		msg     string
+		hstatus = 200
+		m       model.CategoryMapping
+	)
+
+	idstr = mux.Vars(r)["id"]
+
+	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
+		res.Message = fmt.Sprintf(
+			"Error getting/creating session %s: %s",
+			sessionNameFrontend,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		sess = nil
+		hstatus = 403
+		goto SEND_RESPONSE
+	} else if feedID, err = strconv.ParseInt(idstr, 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse Feed ID %q: %s",
+			idstr,
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if err = r.ParseForm(); err != nil {
+		res.Message = fmt.Sprintf("Error parsing form data: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if tagID, err = strconv.ParseInt(r.FormValue("tag"), 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse Tag ID %q: %s",
+			r.FormValue("tag"),
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if feed, err = db.FeedGetByID(feedID); err != nil {
+		res.Message = fmt.Sprintf("Failed to load Feed %d: %s",
+			feedID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if feed == nil {
+		res.Message = fmt.Sprintf("Feed %d was not found in database", feedID)
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 404
+		goto SEND_RESPONSE
+	} else if tag, err = db.TagGetByID(tagID); err != nil {
+		res.Message = fmt.Sprintf("Failed to load Tag %d: %s",
+			tagID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if tag == nil {
+		res.Message = fmt.Sprintf("Tag %d was not found in database", tagID)
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 404
+		goto SEND_RESPONSE
+	}
+
+	m = model.CategoryMapping{
+		FeedID:   feed.ID,
+		Category: r.FormValue("category"),
+		TagID:    tag.ID,
+	}
+
+	if err = db.CategoryMapAdd(&m); err != nil {
+		res.Message = fmt.Sprintf("Failed to map category %q of Feed %s to Tag %s: %s",
+			m.Category,
+			feed.Title,
+			tag.Name,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		if errors.Is(err, database.ErrInvalidValue) {
+			hstatus = 400
+		} else {
+			hstatus = 500
+		}
+		goto SEND_RESPONSE
+	}
+
+	res.Message = fmt.Sprintf("Items of Feed %s in category %q get Tag %s",
+		feed.Title,
+		m.Category,
+		tag.Name)
+	res.Status = true
+	res.Payload = map[string]string{
+		"id": strconv.FormatInt(m.ID, 10),
+	}
+
+SEND_RESPONSE:
+	if sess != nil {
+		if err = sess.Save(r, w); err != nil {
+			srv.log.Printf("[ERROR] Failed to set session cookie: %s\n",
+				err.Error())
+		}
+	}
+	res.Timestamp = time.Now()
+	if rbuf, err = json.Marshal(&res); err != nil {
+		srv.log.Printf("[ERROR] Error serializing response: %s\n",
+			err.Error())
+		rbuf = errJSON(err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(hstatus)
+	if _, err = w.Write(rbuf); err != nil {
+		msg = fmt.Sprintf("Failed to send result: %s",
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+	}
+} // func (srv *Server) handleAjaxCategoryMapAdd(w http.ResponseWriter, r *http.Request)
+
+func (srv *Server) handleAjaxCategoryMapDelete(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+
+	var (
+		err     error
+		sess    *sessions.Session
+		idstr   string
+		rbuf    []byte
+		db      database.Store
+		res     Reply
+		msg     string
+		hstatus = 200
+		m       model.CategoryMapping
+	)
+
+	idstr = mux.Vars(r)["id"]
+
+	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
+		res.Message = fmt.Sprintf(
+			"Error getting/creating session %s: %s",
+			sessionNameFrontend,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		sess = nil
+		hstatus = 403
+		goto SEND_RESPONSE
+	} else if m.ID, err = strconv.ParseInt(idstr, 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse mapping ID %q: %s",
+			idstr,
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if err = db.CategoryMapDelete(&m); err != nil {
+		res.Message = fmt.Sprintf("Failed to delete category mapping %d: %s",
+			m.ID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	}
+
+	res.Message = fmt.Sprintf("Deleted category mapping %d", m.ID)
+	res.Status = true
+
+SEND_RESPONSE:
+	if sess != nil {
+		if err = sess.Save(r, w); err != nil {
+			srv.log.Printf("[ERROR] Failed to set session cookie: %s\n",
+				err.Error())
+		}
+	}
+	res.Timestamp = time.Now()
+	if rbuf, err = json.Marshal(&res); err != nil {
+		srv.log.Printf("[ERROR] Error serializing response: %s\n",
+			err.Error())
+		rbuf = errJSON(err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(hstatus)
+	if _, err = w.Write(rbuf); err != nil {
+		msg = fmt.Sprintf("Failed to send result: %s",
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+	}
+} // func (srv *Server) handleAjaxCategoryMapDelete(w http.ResponseWriter, r *http.Request)
+
+func (srv *Server) handleAjaxItems(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+
+	const tmplName = "item_view"
+	var (
+		err         error
+		sess        *sessions.Session
+		rbuf        []byte
+		db          database.Store
+		buf         bytes.Buffer
+		tmpl        *template.Template
+		cnt, offset int64
+		hideBoring  bool
+		hbstr       string
+		filter      model.ItemFilter
+		res         Reply
+		msg, rating string
+		feeds       []model.Feed
+		tags        []*model.Tag
+		items       []*model.Item
+		vars        map[string]string
+		hstatus     = 200
+		data        = tmplDataItemView{
+			tmplDataBase: tmplDataBase{
+				Debug: common.Debug,
+				URL:   r.URL.String(),
+			},
+		}
+	)
+
+	vars = mux.Vars(r)
+
+	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
+		msg = fmt.Sprintf("Error getting client session from session store: %s",
+			err.Error())
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if cnt, err = strconv.ParseInt(vars["cnt"], 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse item count %q: %s",
+			vars["cnt"],
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if offset, err = strconv.ParseInt(vars["offset"], 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse offset %q: %s",
+			vars["offset"],
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if err = r.ParseForm(); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse form data: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	}
+
+	hbstr = r.FormValue("hideBoring")
+	if hideBoring, err = strconv.ParseBool(hbstr); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse hideBoring flag %q: %s",
+			hbstr,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if filter, err = parseItemFilter(r); err != nil {
+		res.Message = err.Error()
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	}
+
+	srv.log.Printf("[DEBUG] Hide Boring Items? %t\n",
+		hideBoring)
+
+	srv.log.Printf("[DEBUG] Load %d items, offset %d",
+		cnt,
+		offset)
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if items, err = db.ItemGetRecentPaged(cnt, offset, filter); err != nil {
+		res.Message = fmt.Sprintf("Failed to load recent items: %s",
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if feeds, err = db.FeedGetAll(); err != nil {
+		res.Message = fmt.Sprintf("Failed to load all Feeds from database: %s",
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if tags, err = db.TagGetSorted(); err != nil {
+		res.Message = fmt.Sprintf("Failed to load all Tags: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if tmpl = srv.tmpl.Lookup(tmplName); tmpl == nil {
+		res.Message = fmt.Sprintf("Could not find template %q", tmplName)
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	}
+
+	data.Items = make([]*model.Item, 0, len(items))
+
+	for _, i := range items {
+		if srv.bl.Match(i) {
+			srv.bl.Sort()
+			continue
+		}
+		data.Items = append(data.Items, i)
+	}
+
+	data.Suggestions = make(map[int64][]advisor.SuggestedTag, len(data.Items))
+
+	for _, i := range data.Items {
+		if i.Tags, err = db.TagLinkGetByItem(i); err != nil {
+			res.Message = fmt.Sprintf("Failed to load linked tags for Item %d: %s",
+				i.ID,
+				err.Error())
+			srv.log.Printf("[ERROR] %s\n", res.Message)
+			hstatus = 500
+			goto SEND_RESPONSE
+		} else if i.Enclosures, err = db.EnclosureGetByItem(i); err != nil {
+			res.Message = fmt.Sprintf("Failed to load Enclosures for Item %d: %s",
+				i.ID,
+				err.Error())
+			srv.log.Printf("[ERROR] %s\n", res.Message)
+			hstatus = 500
+			goto SEND_RESPONSE
+		} else if i.EffectiveRating() == 0 {
+			srv.log.Printf("[TRACE] Using classifier to guess rating for item %q (%d)\n",
+				i.Headline,
+				i.ID)
+			if rating, err = srv.judge.Rate(i); err != nil {
+				srv.log.Printf("[ERROR] Failed to rate Item %q (%d): %s\n",
+					i.Headline,
+					i.ID,
+					err.Error())
+				continue
+			}
+
+			srv.log.Printf("[TRACE] Item %q (%d) has been classified as %q\n",
+				i.Headline,
+				i.ID,
+				rating)
+
+			switch rating {
+			case "boring":
+				i.Guessed = -1
+			case "interesting":
+				i.Guessed = 1
+			}
+		}
+
+		data.Suggestions[i.ID] = srv.adv.Suggest(i, suggPerItem)
+	}
+
+	data.Feeds = make(map[int64]model.Feed, len(feeds))
+	data.Tags = tags
+
+	for _, f := range feeds {
+		data.Feeds[f.ID] = f
+	}
+
+	if err = tmpl.Execute(&buf, &data); err != nil {
+		res.Message = fmt.Sprintf("Failed to render template %q: %s",
+			tmplName,
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	}
+
+	res.Payload = map[string]string{
+		"content": buf.String(),
+		"count":   strconv.Itoa(len(data.Items)),
+	}
+	res.Status = true
+
+SEND_RESPONSE:
+	if sess != nil {
+		if err = sess.Save(r, w); err != nil {
+			srv.log.Printf("[ERROR] Failed to set session cookie: %s\n",
+				err.Error())
+		}
+	}
+	res.Timestamp = time.Now()
+	if rbuf, err = json.Marshal(&res); err != nil {
+		srv.log.Printf("[ERROR] Error serializing response: %s\n",
+			err.Error())
+		rbuf = errJSON(err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(hstatus)
+	if _, err = w.Write(rbuf); err != nil {
+		msg = fmt.Sprintf("Failed to send result: %s",
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+	}
+} // func (srv *Server) handleAjaxItems(w http.ResponseWriter, r *http.Request)
+
+func (srv *Server) handleAjaxItemsByFeed(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+	const (
+		tmplName = "item_view"
+		offset   = 0
+		cnt      = 50
+	)
+	var (
+		err         error
+		sess        *sessions.Session
+		rbuf        []byte
+		db          database.Store
+		buf         bytes.Buffer
+		tmpl        *template.Template
+		items       []*model.Item
+		feedID      int64
+		res         Reply
+		msg, rating string
+		vars        map[string]string
+		feeds       []model.Feed
+		filter      model.ItemFilter
+		hstatus     = 200
+		data        = tmplDataFeedDetails{
+			tmplDataBase: tmplDataBase{
+				Debug: common.Debug,
+				URL:   r.URL.String(),
+			},
+		}
+	)
+
+	vars = mux.Vars(r)
+
+	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
+		msg = fmt.Sprintf("Error getting client session from session store: %s",
+			err.Error())
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if feedID, err = strconv.ParseInt(vars["id"], 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse item count %q: %s",
+			vars["cnt"],
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if filter, err = parseItemFilter(r); err != nil {
+		res.Message = err.Error()
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if data.Feed, err = db.FeedGetByID(feedID); err != nil {
+		res.Message = fmt.Sprintf("Failed to get Feed %d: %s",
+			feedID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if feeds, err = db.FeedGetAll(); err != nil {
+		res.Message = fmt.Sprintf("Failed to load all Feeds from database: %s",
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if items, err = db.ItemGetByFeed(data.Feed, cnt, offset, filter); err != nil {
+		res.Message = fmt.Sprintf("Failed to get recent Items for Feed %s (%d): %s",
+			data.Feed.Title,
+			data.Feed.ID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if data.Tags, err = db.TagGetSorted(); err != nil {
+		res.Message = fmt.Sprintf("Failed to load Tags: %s", err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if tmpl = srv.tmpl.Lookup(tmplName); tmpl == nil {
+		res.Message = fmt.Sprintf("Could not find template %q", tmplName)
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
 		hstatus = 500
 		goto SEND_RESPONSE
 	}
 
 	data.Items = make([]*model.Item, 0, len(items))
 
-	for _, i := range items {
-		if srv.bl.Match(i) {
+	for _, item := range items {
+		if srv.bl.Match(item) {
 			srv.bl.Sort()
 			continue
+		} else if item.Tags, err = db.TagLinkGetByItem(item); err != nil {
+			res.Message = fmt.Sprintf("Failed to load Tags for Item %d: %s",
+				item.ID,
+				err.Error())
+			srv.log.Printf("[ERROR] %s\n", res.Message)
+			hstatus = 500
+			goto SEND_RESPONSE
+		} else if item.Enclosures, err = db.EnclosureGetByItem(item); err != nil {
+			res.Message = fmt.Sprintf("Failed to load Enclosures for Item %d: %s",
+				item.ID,
+				err.Error())
+			srv.log.Printf("[ERROR] %s\n", res.Message)
+			hstatus = 500
+			goto SEND_RESPONSE
 		}
-		data.Items = append(data.Items, i)
+
+		data.Items = append(data.Items, item)
+	}
+
+	data.Feeds = make(map[int64]model.Feed, len(feeds))
+
+	for _, f := range feeds {
+		data.Feeds[f.ID] = f
 	}
 
 	data.Suggestions = make(map[int64][]advisor.SuggestedTag, len(data.Items))
 
 	for _, i := range data.Items {
-		if i.Tags, err = db.TagLinkGetByItem(i); err != nil {
-			res.Message = fmt.Sprintf("Failed to load linked tags for Item %d: %s",
-				i.ID,
-				err.Error())
-			srv.log.Printf("[ERROR] %s\n", res.Message)
-			hstatus = 500
-			goto SEND_RESPONSE
-		} else if i.EffectiveRating() == 0 {
+		if i.EffectiveRating() == 0 {
 			srv.log.Printf("[TRACE] Using classifier to guess rating for item %q (%d)\n",
 				i.Headline,
 				i.ID)
@@ -1279,27 +3223,137 @@ func (srv *Server) handleAjaxItems(w http.ResponseWriter, r *http.Request) {
 		data.Suggestions[i.ID] = srv.adv.Suggest(i, suggPerItem)
 	}
 
-	data.Feeds = make(map[int64]model.Feed, len(feeds))
-	data.Tags = tags
-
-	for _, f := range feeds {
-		data.Feeds[f.ID] = f
-	}
-
 	if err = tmpl.Execute(&buf, &data); err != nil {
 		res.Message = fmt.Sprintf("Failed to render template %q: %s",
 			tmplName,
 			err.Error())
-		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	}
+
+	res.Payload = map[string]string{
+		"items": buf.String(),
+		"count": strconv.Itoa(len(data.Items)),
+	}
+	res.Status = true
+
+SEND_RESPONSE:
+	if sess != nil {
+		if err = sess.Save(r, w); err != nil {
+			srv.log.Printf("[ERROR] Failed to set session cookie: %s\n",
+				err.Error())
+		}
+	}
+	res.Timestamp = time.Now()
+	if rbuf, err = json.Marshal(&res); err != nil {
+		srv.log.Printf("[ERROR] Error serializing response: %s\n",
+			err.Error())
+		rbuf = errJSON(err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(hstatus)
+	if _, err = w.Write(rbuf); err != nil {
+		msg = fmt.Sprintf("Failed to send result: %s",
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+	}
+} // func (srv *Server) handleAjaxItemsByFeed(w http.ResponseWriter, r *http.Request)
+
+func (srv *Server) handleAjaxRateItem(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+
+	var (
+		err         error
+		sess        *sessions.Session
+		rbuf        []byte
+		db          database.Store
+		idstr, rstr string
+		id, rating  int64
+		item        *model.Item
+		res         Reply
+		msg         string
+		hstatus     = 200
+	)
+
+	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
+		msg = fmt.Sprintf("Error getting client session from session store: %s",
+			err.Error())
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if err = r.ParseForm(); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse form data: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	}
+
+	idstr = r.FormValue("item")
+	rstr = r.FormValue("rating")
+
+	if id, err = strconv.ParseInt(idstr, 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse item ID %q: %s",
+			idstr,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n",
+			res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if rating, err = strconv.ParseInt(rstr, 10, 8); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse rating %q: %s",
+			rstr,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n",
+			res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if item, err = db.ItemGetByID(id); err != nil {
+		res.Message = fmt.Sprintf("Failed to lookup Item %d in database: %s",
+			id,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n",
+			res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if item == nil {
+		res.Message = fmt.Sprintf("Item %d does not exist in database", id)
+		srv.log.Printf("[ERROR] %s\n",
+			res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if err = db.ItemRate(item, int8(rating)); err != nil {
+		res.Message = fmt.Sprintf("Failed to rate Item %q (%d): %s",
+			item.Headline,
+			item.ID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n",
+			res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if err = srv.judge.Learn(item); err != nil {
+		res.Message = fmt.Sprintf("Failed to train classifier on Item %q (%d): %s",
+			item.Headline,
+			item.ID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n",
+			res.Message)
 		hstatus = 500
 		goto SEND_RESPONSE
 	}
 
-	res.Payload = map[string]string{
-		"content": buf.String(),
-		"count":   strconv.Itoa(len(data.Items)),
-	}
 	res.Status = true
+	res.Message = "Success"
 
 SEND_RESPONSE:
 	if sess != nil {
@@ -1323,37 +3377,25 @@ SEND_RESPONSE:
 			err.Error())
 		srv.log.Println("[ERROR] " + msg)
 	}
-} // func (srv *Server) handleAjaxItems(w http.ResponseWriter, r *http.Request)
+} // func (srv *Server) handleAjaxRateItem(w http.ResponseWriter, r *http.Request)
 
-func (srv *Server) handleAjaxItemsByFeed(w http.ResponseWriter, r *http.Request) {
+func (srv *Server) handleAjaxUnrateItem(w http.ResponseWriter, r *http.Request) {
 	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
 		r.URL.EscapedPath(),
 		r.RemoteAddr)
-	const (
-		tmplName = "item_view"
-		offset   = 0
-		cnt      = 50
-	)
+
 	var (
-		err         error
-		sess        *sessions.Session
-		rbuf        []byte
-		db          *database.Database
-		buf         bytes.Buffer
-		tmpl        *template.Template
-		items       []*model.Item
-		feedID      int64
-		res         Reply
-		msg, rating string
-		vars        map[string]string
-		feeds       []model.Feed
-		hstatus     = 200
-		data        = tmplDataFeedDetails{
-			tmplDataBase: tmplDataBase{
-				Debug: common.Debug,
-				URL:   r.URL.String(),
-			},
-		}
+		err     error
+		sess    *sessions.Session
+		rbuf    []byte
+		db      database.Store
+		idstr   string
+		id      int64
+		item    *model.Item
+		res     = Reply{Payload: make(map[string]string, 2)}
+		msg     string
+		vars    map[string]string
+		hstatus = 200
 	)
 
 	vars = mux.Vars(r)
@@ -1364,120 +3406,169 @@ func (srv *Server) handleAjaxItemsByFeed(w http.ResponseWriter, r *http.Request)
 		srv.log.Println("[CRITICAL] " + msg)
 		srv.sendErrorMessage(w, msg)
 		return
-	} else if feedID, err = strconv.ParseInt(vars["id"], 10, 64); err != nil {
-		res.Message = fmt.Sprintf("Cannot parse item count %q: %s",
-			vars["cnt"],
+	}
+
+	idstr = vars["id"]
+
+	if id, err = strconv.ParseInt(idstr, 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse item ID %q: %s",
+			idstr,
 			err.Error())
-		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
-		hstatus = 400
+		srv.log.Printf("[ERROR] %s\n",
+			res.Message)
+		hstatus = 500
 		goto SEND_RESPONSE
 	}
 
 	db = srv.pool.Get()
 	defer srv.pool.Put(db)
 
-	if data.Feed, err = db.FeedGetByID(feedID); err != nil {
-		res.Message = fmt.Sprintf("Failed to get Feed %d: %s",
-			feedID,
+	if item, err = db.ItemGetByID(id); err != nil {
+		res.Message = fmt.Sprintf("Failed to lookup Item %d in database: %s",
+			id,
 			err.Error())
-		srv.log.Printf("[ERROR] %s\n", res.Message)
+		srv.log.Printf("[ERROR] %s\n",
+			res.Message)
 		hstatus = 500
 		goto SEND_RESPONSE
-	} else if feeds, err = db.FeedGetAll(); err != nil {
-		res.Message = fmt.Sprintf("Failed to load all Feeds from database: %s",
-			err.Error())
-		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
-		hstatus = 500
+	} else if item == nil {
+		res.Message = fmt.Sprintf("Item %d does not exist in database", id)
+		srv.log.Printf("[ERROR] %s\n",
+			res.Message)
+		hstatus = 400
 		goto SEND_RESPONSE
-	} else if items, err = db.ItemGetByFeed(data.Feed, cnt, offset); err != nil {
-		res.Message = fmt.Sprintf("Failed to get recent Items for Feed %s (%d): %s",
-			data.Feed.Title,
-			data.Feed.ID,
+	} else if err = db.ItemUnrate(item); err != nil {
+		res.Message = fmt.Sprintf("Failed to rate Item %q (%d): %s",
+			item.Headline,
+			item.ID,
 			err.Error())
-		srv.log.Printf("[ERROR] %s\n", res.Message)
-		hstatus = 500
-		goto SEND_RESPONSE
-	} else if data.Tags, err = db.TagGetSorted(); err != nil {
-		res.Message = fmt.Sprintf("Failed to load Tags: %s", err.Error())
-		srv.log.Printf("[ERROR] %s\n", res.Message)
-		hstatus = 500
-		goto SEND_RESPONSE
-	} else if tmpl = srv.tmpl.Lookup(tmplName); tmpl == nil {
-		res.Message = fmt.Sprintf("Could not find template %q", tmplName)
-		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		srv.log.Printf("[ERROR] %s\n",
+			res.Message)
 		hstatus = 500
 		goto SEND_RESPONSE
 	}
 
-	data.Items = make([]*model.Item, 0, len(items))
+	res.Status = true
+	res.Message = "Success"
+	res.Payload["cell"] = fmt.Sprintf(`
+    <button type="button"
+            class="btn btn-primary"
+            onclick="rate_item(%d, 1);" >
+      Interesting
+    </button>
+    <button type="button"
+            class="btn btn-secondary"
+            onclick="rate_item(%d, -1);" >
+      Boring
+    </button>
+`,
+		id, id)
 
-	for _, item := range items {
-		if srv.bl.Match(item) {
-			srv.bl.Sort()
-			continue
-		} else if item.Tags, err = db.TagLinkGetByItem(item); err != nil {
-			res.Message = fmt.Sprintf("Failed to load Tags for Item %d: %s",
-				item.ID,
+SEND_RESPONSE:
+	if sess != nil {
+		if err = sess.Save(r, w); err != nil {
+			srv.log.Printf("[ERROR] Failed to set session cookie: %s\n",
 				err.Error())
-			srv.log.Printf("[ERROR] %s\n", res.Message)
-			hstatus = 500
-			goto SEND_RESPONSE
 		}
+	}
+	res.Timestamp = time.Now()
+	if rbuf, err = json.Marshal(&res); err != nil {
+		srv.log.Printf("[ERROR] Error serializing response: %s\n",
+			err.Error())
+		rbuf = errJSON(err.Error())
+	}
 
-		data.Items = append(data.Items, item)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(hstatus)
+	if _, err = w.Write(rbuf); err != nil {
+		msg = fmt.Sprintf("Failed to send result: %s",
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
 	}
+} // func (srv *Server) handleAjaxUnrateItem(w http.ResponseWriter, r *http.Request)
 
-	data.Feeds = make(map[int64]model.Feed, len(feeds))
+func (srv *Server) handleAjaxItemSetRead(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
 
-	for _, f := range feeds {
-		data.Feeds[f.ID] = f
+	var (
+		err     error
+		sess    *sessions.Session
+		rbuf    []byte
+		db      database.Store
+		idstr   string
+		id      int64
+		item    *model.Item
+		res     = Reply{Payload: make(map[string]string, 1)}
+		msg     string
+		vars    map[string]string
+		hstatus = 200
+	)
+
+	vars = mux.Vars(r)
+
+	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
+		msg = fmt.Sprintf("Error getting client session from session store: %s",
+			err.Error())
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
 	}
 
-	data.Suggestions = make(map[int64][]advisor.SuggestedTag, len(data.Items))
+	idstr = vars["id"]
 
-	for _, i := range data.Items {
-		if i.EffectiveRating() == 0 {
-			srv.log.Printf("[TRACE] Using classifier to guess rating for item %q (%d)\n",
-				i.Headline,
-				i.ID)
-			if rating, err = srv.judge.Rate(i); err != nil {
-				srv.log.Printf("[ERROR] Failed to rate Item %q (%d): %s\n",
-					i.Headline,
-					i.ID,
-					err.Error())
-				continue
-			}
+	if id, err = strconv.ParseInt(idstr, 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse item ID %q: %s",
+			idstr,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n",
+			res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	}
 
-			srv.log.Printf("[TRACE] Item %q (%d) has been classified as %q\n",
-				i.Headline,
-				i.ID,
-				rating)
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
 
-			switch rating {
-			case "boring":
-				i.Guessed = -1
-			case "interesting":
-				i.Guessed = 1
-			}
-		}
+	if item, err = db.ItemGetByID(id); err != nil {
+		res.Message = fmt.Sprintf("Failed to lookup Item %d in database: %s",
+			id,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n",
+			res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if item == nil {
+		res.Message = fmt.Sprintf("Item %d does not exist in database", id)
+		srv.log.Printf("[ERROR] %s\n",
+			res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	}
 
-		data.Suggestions[i.ID] = srv.adv.Suggest(i, suggPerItem)
+	if vars["state"] == "read" {
+		err = db.ItemMarkRead(item)
+	} else {
+		err = db.ItemMarkUnread(item)
 	}
 
-	if err = tmpl.Execute(&buf, &data); err != nil {
-		res.Message = fmt.Sprintf("Failed to render template %q: %s",
-			tmplName,
+	if err != nil {
+		res.Message = fmt.Sprintf("Failed to mark Item %q (%d) as %s: %s",
+			item.Headline,
+			item.ID,
+			vars["state"],
 			err.Error())
-		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		srv.log.Printf("[ERROR] %s\n",
+			res.Message)
 		hstatus = 500
 		goto SEND_RESPONSE
 	}
 
-	res.Payload = map[string]string{
-		"items": buf.String(),
-		"count": strconv.Itoa(len(data.Items)),
-	}
 	res.Status = true
+	res.Message = "Success"
+	res.Payload["read"] = strconv.FormatBool(item.IsRead())
 
 SEND_RESPONSE:
 	if sess != nil {
@@ -1501,26 +3592,31 @@ SEND_RESPONSE:
 			err.Error())
 		srv.log.Println("[ERROR] " + msg)
 	}
-} // func (srv *Server) handleAjaxItemsByFeed(w http.ResponseWriter, r *http.Request)
+} // func (srv *Server) handleAjaxItemSetRead(w http.ResponseWriter, r *http.Request)
 
-func (srv *Server) handleAjaxRateItem(w http.ResponseWriter, r *http.Request) {
+func (srv *Server) handleAjaxItemSetStarred(w http.ResponseWriter, r *http.Request) {
 	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
 		r.URL.EscapedPath(),
 		r.RemoteAddr)
 
 	var (
-		err         error
-		sess        *sessions.Session
-		rbuf        []byte
-		db          *database.Database
-		idstr, rstr string
-		id, rating  int64
-		item        *model.Item
-		res         Reply
-		msg         string
-		hstatus     = 200
+		err     error
+		sess    *sessions.Session
+		rbuf    []byte
+		db      database.Store
+		idstr   string
+		sstr    string
+		id      int64
+		starred bool
+		item    *model.Item
+		res     = Reply{Payload: make(map[string]string, 1)}
+		msg     string
+		vars    map[string]string
+		hstatus = 200
 	)
 
+	vars = mux.Vars(r)
+
 	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
 		msg = fmt.Sprintf("Error getting client session from session store: %s",
 			err.Error())
@@ -1535,8 +3631,8 @@ func (srv *Server) handleAjaxRateItem(w http.ResponseWriter, r *http.Request) {
 		goto SEND_RESPONSE
 	}
 
-	idstr = r.FormValue("item")
-	rstr = r.FormValue("rating")
+	idstr = vars["id"]
+	sstr = r.FormValue("starred")
 
 	if id, err = strconv.ParseInt(idstr, 10, 64); err != nil {
 		res.Message = fmt.Sprintf("Cannot parse item ID %q: %s",
@@ -1544,15 +3640,15 @@ func (srv *Server) handleAjaxRateItem(w http.ResponseWriter, r *http.Request) {
 			err.Error())
 		srv.log.Printf("[ERROR] %s\n",
 			res.Message)
-		hstatus = 500
+		hstatus = 400
 		goto SEND_RESPONSE
-	} else if rating, err = strconv.ParseInt(rstr, 10, 8); err != nil {
-		res.Message = fmt.Sprintf("Cannot parse rating %q: %s",
-			rstr,
+	} else if starred, err = strconv.ParseBool(sstr); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse starred flag %q: %s",
+			sstr,
 			err.Error())
 		srv.log.Printf("[ERROR] %s\n",
 			res.Message)
-		hstatus = 500
+		hstatus = 400
 		goto SEND_RESPONSE
 	}
 
@@ -1573,17 +3669,8 @@ func (srv *Server) handleAjaxRateItem(w http.ResponseWriter, r *http.Request) {
 			res.Message)
 		hstatus = 400
 		goto SEND_RESPONSE
-	} else if err = db.ItemRate(item, int8(rating)); err != nil {
-		res.Message = fmt.Sprintf("Failed to rate Item %q (%d): %s",
-			item.Headline,
-			item.ID,
-			err.Error())
-		srv.log.Printf("[ERROR] %s\n",
-			res.Message)
-		hstatus = 500
-		goto SEND_RESPONSE
-	} else if err = srv.judge.Learn(item); err != nil {
-		res.Message = fmt.Sprintf("Failed to train classifier on Item %q (%d): %s",
+	} else if err = db.ItemSetStarred(item, starred); err != nil {
+		res.Message = fmt.Sprintf("Failed to star Item %q (%d): %s",
 			item.Headline,
 			item.ID,
 			err.Error())
@@ -1595,6 +3682,7 @@ func (srv *Server) handleAjaxRateItem(w http.ResponseWriter, r *http.Request) {
 
 	res.Status = true
 	res.Message = "Success"
+	res.Payload["starred"] = strconv.FormatBool(item.Starred)
 
 SEND_RESPONSE:
 	if sess != nil {
@@ -1618,9 +3706,11 @@ SEND_RESPONSE:
 			err.Error())
 		srv.log.Println("[ERROR] " + msg)
 	}
-} // func (srv *Server) handleAjaxRateItem(w http.ResponseWriter, r *http.Request)
+} // func (srv *Server) handleAjaxItemSetStarred(w http.ResponseWriter, r *http.Request)
 
-func (srv *Server) handleAjaxUnrateItem(w http.ResponseWriter, r *http.Request) {
+// handleAjaxItemMarkReadAbove marks an Item and all Items newer than it as
+// read. If the form contains a Feed ID, only Items of that Feed are marked.
+func (srv *Server) handleAjaxItemMarkReadAbove(w http.ResponseWriter, r *http.Request) {
 	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
 		r.URL.EscapedPath(),
 		r.RemoteAddr)
@@ -1629,11 +3719,15 @@ func (srv *Server) handleAjaxUnrateItem(w http.ResponseWriter, r *http.Request)
 		err     error
 		sess    *sessions.Session
 		rbuf    []byte
-		db      *database.Database
+		db      database.Store
 		idstr   string
+		fstr    string
 		id      int64
+		feedID  int64
+		cnt     int64
 		item    *model.Item
-		res     = Reply{Payload: make(map[string]string, 2)}
+		feed    *model.Feed
+		res     = Reply{Payload: make(map[string]string, 1)}
 		msg     string
 		vars    map[string]string
 		hstatus = 200
@@ -1647,9 +3741,16 @@ func (srv *Server) handleAjaxUnrateItem(w http.ResponseWriter, r *http.Request)
 		srv.log.Println("[CRITICAL] " + msg)
 		srv.sendErrorMessage(w, msg)
 		return
+	} else if err = r.ParseForm(); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse form data: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
 	}
 
 	idstr = vars["id"]
+	fstr = r.FormValue("feed")
 
 	if id, err = strconv.ParseInt(idstr, 10, 64); err != nil {
 		res.Message = fmt.Sprintf("Cannot parse item ID %q: %s",
@@ -1657,8 +3758,18 @@ func (srv *Server) handleAjaxUnrateItem(w http.ResponseWriter, r *http.Request)
 			err.Error())
 		srv.log.Printf("[ERROR] %s\n",
 			res.Message)
-		hstatus = 500
+		hstatus = 400
 		goto SEND_RESPONSE
+	} else if fstr != "" {
+		if feedID, err = strconv.ParseInt(fstr, 10, 64); err != nil {
+			res.Message = fmt.Sprintf("Cannot parse feed ID %q: %s",
+				fstr,
+				err.Error())
+			srv.log.Printf("[ERROR] %s\n",
+				res.Message)
+			hstatus = 400
+			goto SEND_RESPONSE
+		}
 	}
 
 	db = srv.pool.Get()
@@ -1678,8 +3789,26 @@ func (srv *Server) handleAjaxUnrateItem(w http.ResponseWriter, r *http.Request)
 			res.Message)
 		hstatus = 400
 		goto SEND_RESPONSE
-	} else if err = db.ItemUnrate(item); err != nil {
-		res.Message = fmt.Sprintf("Failed to rate Item %q (%d): %s",
+	} else if feedID != 0 {
+		if feed, err = db.FeedGetByID(feedID); err != nil {
+			res.Message = fmt.Sprintf("Failed to lookup Feed %d in database: %s",
+				feedID,
+				err.Error())
+			srv.log.Printf("[ERROR] %s\n",
+				res.Message)
+			hstatus = 500
+			goto SEND_RESPONSE
+		} else if feed == nil {
+			res.Message = fmt.Sprintf("Feed %d does not exist in database", feedID)
+			srv.log.Printf("[ERROR] %s\n",
+				res.Message)
+			hstatus = 400
+			goto SEND_RESPONSE
+		}
+	}
+
+	if cnt, err = db.ItemMarkReadAbove(item, feed); err != nil {
+		res.Message = fmt.Sprintf("Failed to mark Items above %q (%d) as read: %s",
 			item.Headline,
 			item.ID,
 			err.Error())
@@ -1690,20 +3819,8 @@ func (srv *Server) handleAjaxUnrateItem(w http.ResponseWriter, r *http.Request)
 	}
 
 	res.Status = true
-	res.Message = "Success"
-	res.Payload["cell"] = fmt.Sprintf(`
-    <button type="button"
-            class="btn btn-primary"
-            onclick="rate_item(%d, 1);" >
-      Interesting
-    </button>
-    <button type="button"
-            class="btn btn-secondary"
-            onclick="rate_item(%d, -1);" >
-      Boring
-    </button>
-`,
-		id, id)
+	res.Message = fmt.Sprintf("Marked %d Item(s) as read", cnt)
+	res.Payload["count"] = strconv.FormatInt(cnt, 10)
 
 SEND_RESPONSE:
 	if sess != nil {
@@ -1727,7 +3844,187 @@ SEND_RESPONSE:
 			err.Error())
 		srv.log.Println("[ERROR] " + msg)
 	}
-} // func (srv *Server) handleAjaxUnrateItem(w http.ResponseWriter, r *http.Request)
+} // func (srv *Server) handleAjaxItemMarkReadAbove(w http.ResponseWriter, r *http.Request)
+
+// handleAjaxItemsMarkRead marks a list of Items as read, the IDs are passed
+// as a comma-separated list in the form field "items".
+func (srv *Server) handleAjaxItemsMarkRead(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+
+	var (
+		err     error
+		sess    *sessions.Session
+		rbuf    []byte
+		db      database.Store
+		ids     []int64
+		cnt     int64
+		res     = Reply{Payload: make(map[string]string, 1)}
+		msg     string
+		hstatus = 200
+	)
+
+	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
+		msg = fmt.Sprintf("Error getting client session from session store: %s",
+			err.Error())
+		srv.log.Println("[CRITICAL] " + msg)
+		srv.sendErrorMessage(w, msg)
+		return
+	} else if err = r.ParseForm(); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse form data: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	} else if ids, err = parseIDList(r.FormValue("items")); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse list of Items: %s",
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if cnt, err = db.ItemMarkReadList(ids); err != nil {
+		res.Message = fmt.Sprintf("Failed to mark %d Items as read: %s",
+			len(ids),
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n",
+			res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	}
+
+	res.Status = true
+	res.Message = fmt.Sprintf("Marked %d Item(s) as read", cnt)
+	res.Payload["count"] = strconv.FormatInt(cnt, 10)
+
+SEND_RESPONSE:
+	if sess != nil {
+		if err = sess.Save(r, w); err != nil {
+			srv.log.Printf("[ERROR] Failed to set session cookie: %s\n",
+				err.Error())
+		}
+	}
+	res.Timestamp = time.Now()
+	if rbuf, err = json.Marshal(&res); err != nil {
+		srv.log.Printf("[ERROR] Error serializing response: %s\n",
+			err.Error())
+		rbuf = errJSON(err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(hstatus)
+	if _, err = w.Write(rbuf); err != nil {
+		msg = fmt.Sprintf("Failed to send result: %s",
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+	}
+} // func (srv *Server) handleAjaxItemsMarkRead(w http.ResponseWriter, r *http.Request)
+
+func (srv *Server) handleAjaxItemRevisions(w http.ResponseWriter, r *http.Request) {
+	srv.log.Printf("[TRACE] Handle request for %s from %s\n",
+		r.URL.EscapedPath(),
+		r.RemoteAddr)
+
+	var (
+		err     error
+		sess    *sessions.Session
+		item    *model.Item
+		revs    []model.Revision
+		idstr   string
+		itemID  int64
+		rbuf    []byte
+		db      database.Store
+		vars    map[string]string
+		res     Reply
+		msg     string
+		hstatus = 200
+	)
+
+	vars = mux.Vars(r)
+	idstr = vars["id"]
+
+	if sess, err = srv.store.Get(r, sessionNameFrontend); err != nil {
+		res.Message = fmt.Sprintf(
+			"Error getting/creating session %s: %s",
+			sessionNameFrontend,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		sess = nil
+		hstatus = 403
+		goto SEND_RESPONSE
+	} else if itemID, err = strconv.ParseInt(idstr, 10, 64); err != nil {
+		res.Message = fmt.Sprintf("Cannot parse Item ID %q: %s",
+			idstr,
+			err.Error())
+		srv.log.Printf("[CANTHAPPEN] %s\n", res.Message)
+		hstatus = 400
+		goto SEND_RESPONSE
+	}
+
+	db = srv.pool.Get()
+	defer srv.pool.Put(db)
+
+	if item, err = db.ItemGetByID(itemID); err != nil {
+		res.Message = fmt.Sprintf("Failed to load Item %d: %s",
+			itemID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	} else if item == nil {
+		res.Message = fmt.Sprintf("Item %d was not found in database", itemID)
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 404
+		goto SEND_RESPONSE
+	} else if revs, err = db.ItemRevisionGetByItem(item); err != nil {
+		res.Message = fmt.Sprintf("Failed to load revisions of Item %q (%d): %s",
+			item.Headline,
+			item.ID,
+			err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
+	}
+
+	res.Message = fmt.Sprintf("Item %d has %d revision(s)",
+		item.ID,
+		len(revs))
+	res.Status = true
+	res.Payload = map[string]string{
+		"id":      strconv.Itoa(int(item.ID)),
+		"count":   strconv.Itoa(len(revs)),
+		"content": revisionHistory(item, revs),
+	}
+
+SEND_RESPONSE:
+	if sess != nil {
+		if err = sess.Save(r, w); err != nil {
+			srv.log.Printf("[ERROR] Failed to set session cookie: %s\n",
+				err.Error())
+		}
+	}
+	res.Timestamp = time.Now()
+	if rbuf, err = json.Marshal(&res); err != nil {
+		srv.log.Printf("[ERROR] Error serializing response: %s\n",
+			err.Error())
+		rbuf = errJSON(err.Error())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store, max-age=0")
+	w.WriteHeader(hstatus)
+	if _, err = w.Write(rbuf); err != nil {
+		msg = fmt.Sprintf("Failed to send result: %s",
+			err.Error())
+		srv.log.Println("[ERROR] " + msg)
+	}
+} // func (srv *Server) handleAjaxItemRevisions(w http.ResponseWriter, r *http.Request)
 
 func (srv *Server) handleAjaxTagView(w http.ResponseWriter, r *http.Request) {
 	const tmplName = "tag_view"
@@ -1739,7 +4036,7 @@ func (srv *Server) handleAjaxTagView(w http.ResponseWriter, r *http.Request) {
 		sess    *sessions.Session
 		rbuf    []byte
 		tbuf    bytes.Buffer
-		db      *database.Database
+		db      database.Store
 		res     = Reply{Payload: make(map[string]string, 2)}
 		tmpl    *template.Template
 		hstatus = 200
@@ -1772,6 +4069,12 @@ func (srv *Server) handleAjaxTagView(w http.ResponseWriter, r *http.Request) {
 		srv.log.Println("[CRITICAL] " + res.Message)
 		srv.sendErrorMessage(w, res.Message)
 		goto SEND_RESPONSE
+	} else if data.UnreadCnt, err = db.TagGetUnreadCnt(); err != nil {
+		res.Message = fmt.Sprintf("Failed to get unread Item counts for Tags: %s",
+			err.Error())
+		srv.log.Println("[CRITICAL] " + res.Message)
+		srv.sendErrorMessage(w, res.Message)
+		goto SEND_RESPONSE
 	} else if tmpl = srv.tmpl.Lookup(tmplName); tmpl == nil {
 		res.Message = fmt.Sprintf("Failed to lookup template %s",
 			tmplName)
@@ -1824,7 +4127,7 @@ func (srv *Server) handleAjaxTagSubmit(w http.ResponseWriter, r *http.Request) {
 		sess                   *sessions.Session
 		rbuf                   []byte
 		tbuf                   bytes.Buffer
-		db                     *database.Database
+		db                     database.Store
 		res                    = Reply{Payload: make(map[string]string, 3)}
 		msg, idstr, pstr, name string
 		tagID, parentID        int64
@@ -2032,7 +4335,7 @@ func (srv *Server) handleAjaxTagDetails(w http.ResponseWriter, r *http.Request)
 		tag   *model.Tag
 		idstr string
 		id    int64
-		db    *database.Database
+		db    database.Store
 		vars  map[string]string
 		data  = tmplDataTagForm{
 			tmplDataBase: tmplDataBase{
@@ -2137,7 +4440,7 @@ func (srv *Server) handleAjaxTagLinkAdd(w http.ResponseWriter, r *http.Request)
 		item            *model.Item
 		istr, tstr, msg string
 		tagID, itemID   int64
-		db              *database.Database
+		db              database.Store
 		vars            map[string]string
 		res             = Reply{
 			Payload: make(map[string]string, 2),
@@ -2277,7 +4580,7 @@ func (srv *Server) handleAjaxTagLinkRemove(w http.ResponseWriter, r *http.Reques
 		item            *model.Item
 		istr, tstr, msg string
 		tagID, itemID   int64
-		db              *database.Database
+		db              database.Store
 		vars            map[string]string
 		res             = Reply{
 			Payload: make(map[string]string, 2),
@@ -2337,6 +4640,12 @@ func (srv *Server) handleAjaxTagLinkRemove(w http.ResponseWriter, r *http.Reques
 		srv.log.Printf("[ERROR] %s\n", res.Message)
 		hstatus = 400
 		goto SEND_RESPONSE
+	} else if item.Tags, err = db.TagLinkGetByItem(item); err != nil {
+		res.Message = fmt.Sprintf("Failed to load Tags of Item %d: %s",
+			itemID, err.Error())
+		srv.log.Printf("[ERROR] %s\n", res.Message)
+		hstatus = 500
+		goto SEND_RESPONSE
 	} else if err = db.TagLinkDelete(item, tag); err != nil {
 		res.Message = fmt.Sprintf("Failed to remove link of Tag %s (%d) to Item %d: %s",
 			tag.Name,
@@ -2346,12 +4655,15 @@ func (srv *Server) handleAjaxTagLinkRemove(w http.ResponseWriter, r *http.Reques
 		srv.log.Printf("[ERROR] %s\n", res.Message)
 		hstatus = 500
 		goto SEND_RESPONSE
-	} else if err = srv.adv.Unlearn(tag, item); err != nil {
-		srv.log.Printf("[ERROR] Failed to unlearn association of Tag %s (%d) and Item %d: %s\n",
-			tag.Name,
-			tag.ID,
-			item.ID,
-			err.Error())
+	} else if !item.HasAutoTag(tag.ID) {
+		// The Advisor never learned from Tags attached automatically.
+		if err = srv.adv.Unlearn(tag, item); err != nil {
+			srv.log.Printf("[ERROR] Failed to unlearn association of Tag %s (%d) and Item %d: %s\n",
+				tag.Name,
+				tag.ID,
+				item.ID,
+				err.Error())
+		}
 	}
 
 	res.Status = true
@@ -2393,7 +4705,7 @@ func (srv *Server) handleAjaxTagForm(w http.ResponseWriter, r *http.Request) {
 		sess    *sessions.Session
 		rbuf    []byte
 		tbuf    bytes.Buffer
-		db      *database.Database
+		db      database.Store
 		res     = Reply{Payload: make(map[string]string, 3)}
 		msg     string
 		tmpl    *template.Template
@@ -2544,7 +4856,7 @@ func (srv *Server) handleAjaxSearchQueries(w http.ResponseWriter, r *http.Reques
 	const tmplName = "search_queries"
 	var (
 		err     error
-		db      *database.Database
+		db      database.Store
 		sess    *sessions.Session
 		tmpl    *template.Template
 		buf     bytes.Buffer
@@ -2628,7 +4940,7 @@ func (srv *Server) handleAjaxSearchSubmit(w http.ResponseWriter, r *http.Request
 		err       error
 		sess      *sessions.Session
 		rbuf      []byte
-		db        *database.Database
+		db        database.Store
 		res       = Reply{Payload: make(map[string]string, 3)}
 		msg, jStr string
 		query     model.Search
@@ -2724,7 +5036,7 @@ func (srv *Server) handleAjaxSearchResults(w http.ResponseWriter, r *http.Reques
 		sess       *sessions.Session
 		rbuf       []byte
 		tbuf       bytes.Buffer
-		db         *database.Database
+		db         database.Store
 		res        = Reply{Payload: make(map[string]string, 3)}
 		msg, idStr string
 		q          *model.Search
@@ -2788,6 +5100,11 @@ func (srv *Server) handleAjaxSearchResults(w http.ResponseWriter, r *http.Reques
 				i.ID,
 				i.Headline,
 				err.Error())
+		} else if i.Enclosures, err = db.EnclosureGetByItem(i); err != nil {
+			srv.log.Printf("[ERROR] Failed to load Enclosures for Item %d (%q): %s\n",
+				i.ID,
+				i.Headline,
+				err.Error())
 		}
 	}
 
@@ -2844,7 +5161,7 @@ func (srv *Server) handleAjaxSearchDelete(w http.ResponseWriter, r *http.Request
 		idStr, msg string
 		qID        int64
 		q          *model.Search
-		db         *database.Database
+		db         database.Store
 		vars       map[string]string
 		res        = Reply{
 			Payload: make(map[string]string, 2),